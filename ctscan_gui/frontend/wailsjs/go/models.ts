@@ -1,5 +1,335 @@
 export namespace pkg {
 	
+	export class AccountChange {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    action: string;
+	    account: string;
+	    account_sid: string;
+	    group: string;
+	    group_sid: string;
+	    subject: string;
+	    subject_logon_id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.action = source["action"];
+	        this.account = source["account"];
+	        this.account_sid = source["account_sid"];
+	        this.group = source["group"];
+	        this.group_sid = source["group_sid"];
+	        this.subject = source["subject"];
+	        this.subject_logon_id = source["subject_logon_id"];
+	    }
+	}
+	export class AccountChangePage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: AccountChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountChangePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], AccountChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiffChange {
+	    field: string;
+	    before: string;
+	    after: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class DiffEntry {
+	    key: string;
+	    status: string;
+	    before: Record<string, string>;
+	    after: Record<string, string>;
+	    changes: DiffChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.status = source["status"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.changes = this.convertValues(source["changes"], DiffChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArtifactDiff {
+	    name: string;
+	    title: string;
+	    table: string;
+	    skipped: string;
+	    added: number;
+	    removed: number;
+	    changed: number;
+	    entries: DiffEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ArtifactDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.title = source["title"];
+	        this.table = source["table"];
+	        this.skipped = source["skipped"];
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	        this.changed = source["changed"];
+	        this.entries = this.convertValues(source["entries"], DiffEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditCommand {
+	    time: string;
+	    serial: number;
+	    user: string;
+	    auid: number;
+	    run_as: string;
+	    uid: number;
+	    tty: string;
+	    session: string;
+	    pid: number;
+	    ppid: number;
+	    exe: string;
+	    cwd: string;
+	    command: string;
+	    key: string;
+	    success: string;
+	    host: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.serial = source["serial"];
+	        this.user = source["user"];
+	        this.auid = source["auid"];
+	        this.run_as = source["run_as"];
+	        this.uid = source["uid"];
+	        this.tty = source["tty"];
+	        this.session = source["session"];
+	        this.pid = source["pid"];
+	        this.ppid = source["ppid"];
+	        this.exe = source["exe"];
+	        this.cwd = source["cwd"];
+	        this.command = source["command"];
+	        this.key = source["key"];
+	        this.success = source["success"];
+	        this.host = source["host"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class AuditEvent {
+	    time: string;
+	    serial: number;
+	    type: string;
+	    category: string;
+	    user: string;
+	    operator: string;
+	    exe: string;
+	    terminal: string;
+	    addr: string;
+	    result: string;
+	    key: string;
+	    syscall: string;
+	    path: string;
+	    detail: string;
+	    host: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.serial = source["serial"];
+	        this.type = source["type"];
+	        this.category = source["category"];
+	        this.user = source["user"];
+	        this.operator = source["operator"];
+	        this.exe = source["exe"];
+	        this.terminal = source["terminal"];
+	        this.addr = source["addr"];
+	        this.result = source["result"];
+	        this.key = source["key"];
+	        this.syscall = source["syscall"];
+	        this.path = source["path"];
+	        this.detail = source["detail"];
+	        this.host = source["host"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class AuditLog {
+	    commands: AuditCommand[];
+	    events: AuditEvent[];
+	    files: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditLog(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.commands = this.convertValues(source["commands"], AuditCommand);
+	        this.events = this.convertValues(source["events"], AuditEvent);
+	        this.files = source["files"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CollectorInfo {
+	    name: string;
+	    title: string;
+	    platforms: string[];
+	    supported: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CollectorInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.title = source["title"];
+	        this.platforms = source["platforms"];
+	        this.supported = source["supported"];
+	    }
+	}
+	export class CollectorResult {
+	    name: string;
+	    title: string;
+	    count: number;
+	    duration: number;
+	    records: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CollectorResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.title = source["title"];
+	        this.count = source["count"];
+	        this.duration = source["duration"];
+	        this.records = source["records"];
+	    }
+	}
 	export class CronTask {
 	    line: string;
 	
@@ -12,6 +342,54 @@ export namespace pkg {
 	        this.line = source["line"];
 	    }
 	}
+	export class CustodyEntry {
+	    time: string;
+	    action: string;
+	    operator: string;
+	    hostname: string;
+	    package_id: string;
+	    package_sha256: string;
+	    detail: string;
+	    prev_hash: string;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CustodyEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.action = source["action"];
+	        this.operator = source["operator"];
+	        this.hostname = source["hostname"];
+	        this.package_id = source["package_id"];
+	        this.package_sha256 = source["package_sha256"];
+	        this.detail = source["detail"];
+	        this.prev_hash = source["prev_hash"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class DatabaseInfo {
+	    path: string;
+	    read_only: boolean;
+	    schema_version: number;
+	    is_default: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DatabaseInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.read_only = source["read_only"];
+	        this.schema_version = source["schema_version"];
+	        this.is_default = source["is_default"];
+	    }
+	}
+	
+	
 	export class DiskInfo {
 	    mount_point: string;
 	    total_size: number;
@@ -41,7 +419,8 @@ export namespace pkg {
 	    computer: string;
 	    user_id: string;
 	    description: string;
-	    data: Record<string, any>;
+	    source_file: string;
+	    source_sha256: string;
 	    event_record_id: number;
 	    version: number;
 	    qualifiers: number;
@@ -51,6 +430,8 @@ export namespace pkg {
 	    process_id: number;
 	    thread_id: number;
 	    message: string;
+	    offset: number;
+	    recovered: boolean;
 	    system_info: Record<string, any>;
 	    event_data: Record<string, any>;
 	    user_data: Record<string, any>;
@@ -69,7 +450,8 @@ export namespace pkg {
 	        this.computer = source["computer"];
 	        this.user_id = source["user_id"];
 	        this.description = source["description"];
-	        this.data = source["data"];
+	        this.source_file = source["source_file"];
+	        this.source_sha256 = source["source_sha256"];
 	        this.event_record_id = source["event_record_id"];
 	        this.version = source["version"];
 	        this.qualifiers = source["qualifiers"];
@@ -79,59 +461,422 @@ export namespace pkg {
 	        this.process_id = source["process_id"];
 	        this.thread_id = source["thread_id"];
 	        this.message = source["message"];
+	        this.offset = source["offset"];
+	        this.recovered = source["recovered"];
 	        this.system_info = source["system_info"];
 	        this.event_data = source["event_data"];
 	        this.user_data = source["user_data"];
 	    }
 	}
-	export class FileInfo {
+	export class EVTXFile {
+	    id: number;
+	    session_id: string;
 	    path: string;
-	    exists: boolean;
+	    archive: string;
 	    size: number;
-	    mode: string;
-	    // Go type: time
-	    mod_time: any;
-	    // Go type: time
-	    create_time: any;
-	    // Go type: time
-	    access_time: any;
-	    // Go type: time
-	    change_time: any;
-	    is_dir: boolean;
-	    is_symlink: boolean;
-	    owner: string;
-	    group: string;
-	    permissions: string;
-	    description: string;
+	    mod_time: string;
+	    sha256: string;
+	    computer: string;
+	    chunks: number;
+	    chunks_done: number;
+	    corrupt_chunks: number;
+	    recovery: boolean;
+	    events: number;
+	    status: string;
+	    error: string;
+	    started_at: string;
+	    finished_at: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FileInfo(source);
+	        return new EVTXFile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.session_id = source["session_id"];
 	        this.path = source["path"];
-	        this.exists = source["exists"];
+	        this.archive = source["archive"];
 	        this.size = source["size"];
-	        this.mode = source["mode"];
-	        this.mod_time = this.convertValues(source["mod_time"], null);
-	        this.create_time = this.convertValues(source["create_time"], null);
-	        this.access_time = this.convertValues(source["access_time"], null);
-	        this.change_time = this.convertValues(source["change_time"], null);
-	        this.is_dir = source["is_dir"];
-	        this.is_symlink = source["is_symlink"];
-	        this.owner = source["owner"];
-	        this.group = source["group"];
-	        this.permissions = source["permissions"];
-	        this.description = source["description"];
+	        this.mod_time = source["mod_time"];
+	        this.sha256 = source["sha256"];
+	        this.computer = source["computer"];
+	        this.chunks = source["chunks"];
+	        this.chunks_done = source["chunks_done"];
+	        this.corrupt_chunks = source["corrupt_chunks"];
+	        this.recovery = source["recovery"];
+	        this.events = source["events"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.started_at = source["started_at"];
+	        this.finished_at = source["finished_at"];
 	    }
+	}
+	export class EVTXFilterOptions {
+	    source_files: string[];
+	    providers: string[];
+	    channels: string[];
+	    computers: string[];
 	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    static createFrom(source: any = {}) {
+	        return new EVTXFilterOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source_files = source["source_files"];
+	        this.providers = source["providers"];
+	        this.channels = source["channels"];
+	        this.computers = source["computers"];
+	    }
+	}
+	export class EVTXPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    events: EVTXEvent[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EVTXPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.events = this.convertValues(source["events"], EVTXEvent);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EVTXQuery {
+	    session_id: string;
+	    source_file: string;
+	    start: string;
+	    end: string;
+	    event_ids: string;
+	    provider: string;
+	    channel: string;
+	    computer: string;
+	    keyword: string;
+	    recovered: boolean;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EVTXQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.source_file = source["source_file"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.event_ids = source["event_ids"];
+	        this.provider = source["provider"];
+	        this.channel = source["channel"];
+	        this.computer = source["computer"];
+	        this.keyword = source["keyword"];
+	        this.recovered = source["recovered"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	export class EvidenceFile {
+	    path: string;
+	    source: string;
+	    size: number;
+	    sha256: string;
+	    mod_time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.source = source["source"];
+	        this.size = source["size"];
+	        this.sha256 = source["sha256"];
+	        this.mod_time = source["mod_time"];
+	    }
+	}
+	export class EvidenceFileCheck {
+	    path: string;
+	    status: string;
+	    expected: string;
+	    actual: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceFileCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.expected = source["expected"];
+	        this.actual = source["actual"];
+	    }
+	}
+	export class EvidenceHost {
+	    hostname: string;
+	    fingerprint: string;
+	    os: string;
+	    arch: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceHost(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.fingerprint = source["fingerprint"];
+	        this.os = source["os"];
+	        this.arch = source["arch"];
+	    }
+	}
+	export class EvidenceSkippedFile {
+	    source: string;
+	    size: number;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceSkippedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.size = source["size"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class ScanSession {
+	    id: string;
+	    case_name: string;
+	    analyst: string;
+	    hostname: string;
+	    host_fingerprint: string;
+	    start_time: string;
+	    end_time: string;
+	    tool_version: string;
+	    collectors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.case_name = source["case_name"];
+	        this.analyst = source["analyst"];
+	        this.hostname = source["hostname"];
+	        this.host_fingerprint = source["host_fingerprint"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.tool_version = source["tool_version"];
+	        this.collectors = source["collectors"];
+	    }
+	}
+	export class EvidenceManifest {
+	    package_id: string;
+	    created_at: string;
+	    tool_version: string;
+	    operator: string;
+	    note: string;
+	    host: EvidenceHost;
+	    session: ScanSession;
+	    files: EvidenceFile[];
+	    skipped: EvidenceSkippedFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.package_id = source["package_id"];
+	        this.created_at = source["created_at"];
+	        this.tool_version = source["tool_version"];
+	        this.operator = source["operator"];
+	        this.note = source["note"];
+	        this.host = this.convertValues(source["host"], EvidenceHost);
+	        this.session = this.convertValues(source["session"], ScanSession);
+	        this.files = this.convertValues(source["files"], EvidenceFile);
+	        this.skipped = this.convertValues(source["skipped"], EvidenceSkippedFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EvidencePackage {
+	    path: string;
+	    sha256: string;
+	    log_path: string;
+	    manifest: EvidenceManifest;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidencePackage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.sha256 = source["sha256"];
+	        this.log_path = source["log_path"];
+	        this.manifest = this.convertValues(source["manifest"], EvidenceManifest);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class EvidenceVerifyResult {
+	    path: string;
+	    sha256: string;
+	    ok: boolean;
+	    manifest: EvidenceManifest;
+	    files: EvidenceFileCheck[];
+	    custody_ok: boolean;
+	    custody: CustodyEntry[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceVerifyResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.sha256 = source["sha256"];
+	        this.ok = source["ok"];
+	        this.manifest = this.convertValues(source["manifest"], EvidenceManifest);
+	        this.files = this.convertValues(source["files"], EvidenceFileCheck);
+	        this.custody_ok = source["custody_ok"];
+	        this.custody = this.convertValues(source["custody"], CustodyEntry);
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileInfo {
+	    path: string;
+	    exists: boolean;
+	    size: number;
+	    mode: string;
+	    // Go type: time
+	    mod_time: any;
+	    // Go type: time
+	    create_time: any;
+	    // Go type: time
+	    access_time: any;
+	    // Go type: time
+	    change_time: any;
+	    is_dir: boolean;
+	    is_symlink: boolean;
+	    owner: string;
+	    group: string;
+	    permissions: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.exists = source["exists"];
+	        this.size = source["size"];
+	        this.mode = source["mode"];
+	        this.mod_time = this.convertValues(source["mod_time"], null);
+	        this.create_time = this.convertValues(source["create_time"], null);
+	        this.access_time = this.convertValues(source["access_time"], null);
+	        this.change_time = this.convertValues(source["change_time"], null);
+	        this.is_dir = source["is_dir"];
+	        this.is_symlink = source["is_symlink"];
+	        this.owner = source["owner"];
+	        this.group = source["group"];
+	        this.permissions = source["permissions"];
+	        this.description = source["description"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
 		    } else if ("object" === typeof a) {
 		        if (asMap) {
 		            for (const key of Object.keys(a)) {
@@ -164,92 +909,2414 @@ export namespace pkg {
 	        this.packets_recv = source["packets_recv"];
 	    }
 	}
-	export class LoginFailed {
-	    time: string;
-	    event_id: string;
-	    event_type: string;
-	    source: string;
-	    username: string;
-	    ip_address: string;
-	    reason: string;
+	export class JournalCount {
+	    name: string;
+	    count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new LoginFailed(source);
+	        return new JournalCount(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.time = source["time"];
-	        this.event_id = source["event_id"];
-	        this.event_type = source["event_type"];
-	        this.source = source["source"];
+	        this.name = source["name"];
+	        this.count = source["count"];
+	    }
+	}
+	export class JournalEntry {
+	    time: string;
+	    hostname: string;
+	    unit: string;
+	    identifier: string;
+	    pid: number;
+	    priority: number;
+	    message: string;
+	    boot_id: string;
+	    file: string;
+	    offset: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.hostname = source["hostname"];
+	        this.unit = source["unit"];
+	        this.identifier = source["identifier"];
+	        this.pid = source["pid"];
+	        this.priority = source["priority"];
+	        this.message = source["message"];
+	        this.boot_id = source["boot_id"];
+	        this.file = source["file"];
+	        this.offset = source["offset"];
+	    }
+	}
+	export class JournalQuery {
+	    start: string;
+	    end: string;
+	    unit: string;
+	    identifier: string;
+	    priority: string;
+	    keyword: string;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.unit = source["unit"];
+	        this.identifier = source["identifier"];
+	        this.priority = source["priority"];
+	        this.keyword = source["keyword"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	export class JournalResult {
+	    total: number;
+	    truncated: boolean;
+	    records: JournalEntry[];
+	    units: JournalCount[];
+	    files: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.truncated = source["truncated"];
+	        this.records = this.convertValues(source["records"], JournalEntry);
+	        this.units = this.convertValues(source["units"], JournalCount);
+	        this.files = source["files"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LastlogEntry {
+	    uid: number;
+	    user: string;
+	    time: string;
+	    tty: string;
+	    host: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LastlogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uid = source["uid"];
+	        this.user = source["user"];
+	        this.time = source["time"];
+	        this.tty = source["tty"];
+	        this.host = source["host"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class LogClear {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    log: string;
+	    subject: string;
+	    subject_sid: string;
+	    backup_path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogClear(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.log = source["log"];
+	        this.subject = source["subject"];
+	        this.subject_sid = source["subject_sid"];
+	        this.backup_path = source["backup_path"];
+	    }
+	}
+	export class LogClearPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: LogClear[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LogClearPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], LogClear);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LoginFailed {
+	    time: string;
+	    event_id: string;
+	    event_type: string;
+	    source: string;
+	    username: string;
+	    ip_address: string;
+	    reason: string;
+	    port: number;
+	    method: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginFailed(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.event_type = source["event_type"];
+	        this.source = source["source"];
 	        this.username = source["username"];
 	        this.ip_address = source["ip_address"];
 	        this.reason = source["reason"];
+	        this.port = source["port"];
+	        this.method = source["method"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class LoginLogAnomaly {
+	    log_file: string;
+	    offset: number;
+	    kind: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginLogAnomaly(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.log_file = source["log_file"];
+	        this.offset = source["offset"];
+	        this.kind = source["kind"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class UtmpRecord {
+	    time: string;
+	    user: string;
+	    tty: string;
+	    host: string;
+	    ip: string;
+	    pid: number;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UtmpRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.user = source["user"];
+	        this.tty = source["tty"];
+	        this.host = source["host"];
+	        this.ip = source["ip"];
+	        this.pid = source["pid"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class LoginSession {
+	    user: string;
+	    tty: string;
+	    host: string;
+	    ip: string;
+	    pid: number;
+	    login_time: string;
+	    logout_time: string;
+	    duration: number;
+	    status: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.user = source["user"];
+	        this.tty = source["tty"];
+	        this.host = source["host"];
+	        this.ip = source["ip"];
+	        this.pid = source["pid"];
+	        this.login_time = source["login_time"];
+	        this.logout_time = source["logout_time"];
+	        this.duration = source["duration"];
+	        this.status = source["status"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class LoginLogs {
+	    sessions: LoginSession[];
+	    failed: UtmpRecord[];
+	    lastlog: LastlogEntry[];
+	    anomalies: LoginLogAnomaly[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginLogs(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessions = this.convertValues(source["sessions"], LoginSession);
+	        this.failed = this.convertValues(source["failed"], UtmpRecord);
+	        this.lastlog = this.convertValues(source["lastlog"], LastlogEntry);
+	        this.anomalies = this.convertValues(source["anomalies"], LoginLogAnomaly);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class LoginSuccess {
+	    time: string;
+	    event_id: string;
+	    event_type: string;
+	    source: string;
+	    username: string;
+	    ip_address: string;
+	    port: number;
+	    method: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginSuccess(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.event_type = source["event_type"];
+	        this.source = source["source"];
+	        this.username = source["username"];
+	        this.ip_address = source["ip_address"];
+	        this.port = source["port"];
+	        this.method = source["method"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class NetworkConn {
+	    proto: string;
+	    local_addr: string;
+	    remote_addr: string;
+	    status: string;
+	    pid: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkConn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proto = source["proto"];
+	        this.local_addr = source["local_addr"];
+	        this.remote_addr = source["remote_addr"];
+	        this.status = source["status"];
+	        this.pid = source["pid"];
+	    }
+	}
+	export class NetworkInfo {
+	    hostname: string;
+	    ips: string[];
+	    macs: string[];
+	    interfaces: string[];
+	    interface_stats: InterfaceStats[];
+	    gateway: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.ips = source["ips"];
+	        this.macs = source["macs"];
+	        this.interfaces = source["interfaces"];
+	        this.interface_stats = this.convertValues(source["interface_stats"], InterfaceStats);
+	        this.gateway = source["gateway"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProcInfo {
+	    pid: number;
+	    name: string;
+	    ppid: number;
+	    parent_name: string;
+	    create_time: number;
+	    exe: string;
+	    file_ctime: number;
+	    file_mtime: number;
+	    md5: string;
+	    signature: string;
+	    cpu_percent: number;
+	    mem_percent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pid = source["pid"];
+	        this.name = source["name"];
+	        this.ppid = source["ppid"];
+	        this.parent_name = source["parent_name"];
+	        this.create_time = source["create_time"];
+	        this.exe = source["exe"];
+	        this.file_ctime = source["file_ctime"];
+	        this.file_mtime = source["file_mtime"];
+	        this.md5 = source["md5"];
+	        this.signature = source["signature"];
+	        this.cpu_percent = source["cpu_percent"];
+	        this.mem_percent = source["mem_percent"];
+	    }
+	}
+	export class ProcessCreation {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_id: number;
+	    image: string;
+	    command_line: string;
+	    parent_process_id: number;
+	    parent_image: string;
+	    subject: string;
+	    target_user: string;
+	    elevation: string;
+	    integrity: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessCreation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.command_line = source["command_line"];
+	        this.parent_process_id = source["parent_process_id"];
+	        this.parent_image = source["parent_image"];
+	        this.subject = source["subject"];
+	        this.target_user = source["target_user"];
+	        this.elevation = source["elevation"];
+	        this.integrity = source["integrity"];
+	    }
+	}
+	export class ProcessCreationPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: ProcessCreation[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessCreationPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], ProcessCreation);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RDPLoginInfo {
+	    time: string;
+	    username: string;
+	    ip: string;
+	    status: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RDPLoginInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.username = source["username"];
+	        this.ip = source["ip"];
+	        this.status = source["status"];
+	        this.description = source["description"];
+	    }
+	}
+	
+	export class ScheduledTask {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    action: string;
+	    task_name: string;
+	    command: string;
+	    arguments: string;
+	    run_as: string;
+	    author: string;
+	    subject: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduledTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.action = source["action"];
+	        this.task_name = source["task_name"];
+	        this.command = source["command"];
+	        this.arguments = source["arguments"];
+	        this.run_as = source["run_as"];
+	        this.author = source["author"];
+	        this.subject = source["subject"];
+	        this.content = source["content"];
+	    }
+	}
+	export class ScheduledTaskPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: ScheduledTask[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduledTaskPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], ScheduledTask);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScriptBlockPart {
+	    source_file: string;
+	    record_id: number;
+	    message_number: number;
+	    time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptBlockPart(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.message_number = source["message_number"];
+	        this.time = source["time"];
+	    }
+	}
+	export class ScriptBlock {
+	    id: number;
+	    session_id: string;
+	    computer: string;
+	    script_block_id: string;
+	    path: string;
+	    user_id: string;
+	    first_time: string;
+	    last_time: string;
+	    message_total: number;
+	    message_count: number;
+	    complete: boolean;
+	    warning: boolean;
+	    parts: ScriptBlockPart[];
+	    script: string;
+	    decoded: string;
+	    obfuscation: string[];
+	    indicators: string[];
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptBlock(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.session_id = source["session_id"];
+	        this.computer = source["computer"];
+	        this.script_block_id = source["script_block_id"];
+	        this.path = source["path"];
+	        this.user_id = source["user_id"];
+	        this.first_time = source["first_time"];
+	        this.last_time = source["last_time"];
+	        this.message_total = source["message_total"];
+	        this.message_count = source["message_count"];
+	        this.complete = source["complete"];
+	        this.warning = source["warning"];
+	        this.parts = this.convertValues(source["parts"], ScriptBlockPart);
+	        this.script = source["script"];
+	        this.decoded = source["decoded"];
+	        this.obfuscation = source["obfuscation"];
+	        this.indicators = source["indicators"];
+	        this.score = source["score"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScriptBlockPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    blocks: ScriptBlock[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptBlockPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.blocks = this.convertValues(source["blocks"], ScriptBlock);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ScriptBlockQuery {
+	    session_id: string;
+	    computer: string;
+	    keyword: string;
+	    suspicious: boolean;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptBlockQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.computer = source["computer"];
+	        this.keyword = source["keyword"];
+	        this.suspicious = source["suspicious"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	export class ScriptBlockRebuildResult {
+	    session_id: string;
+	    events: number;
+	    blocks: number;
+	    suspicious: number;
+	    incomplete: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptBlockRebuildResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.events = source["events"];
+	        this.blocks = source["blocks"];
+	        this.suspicious = source["suspicious"];
+	        this.incomplete = source["incomplete"];
+	    }
+	}
+	export class ServiceInstall {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    service_name: string;
+	    image_path: string;
+	    service_type: string;
+	    start_type: string;
+	    account: string;
+	    subject: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServiceInstall(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.service_name = source["service_name"];
+	        this.image_path = source["image_path"];
+	        this.service_type = source["service_type"];
+	        this.start_type = source["start_type"];
+	        this.account = source["account"];
+	        this.subject = source["subject"];
+	    }
+	}
+	export class ServiceInstallPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: ServiceInstall[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ServiceInstallPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], ServiceInstall);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ShellHistory {
+	    time: string;
+	    command: string;
+	    user: string;
+	    shell: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShellHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.command = source["command"];
+	        this.user = source["user"];
+	        this.shell = source["shell"];
+	    }
+	}
+	export class SigmaHit {
+	    id: number;
+	    session_id: string;
+	    rule_id: string;
+	    title: string;
+	    level: string;
+	    tags: string[];
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    channel: string;
+	    computer: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaHit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.session_id = source["session_id"];
+	        this.rule_id = source["rule_id"];
+	        this.title = source["title"];
+	        this.level = source["level"];
+	        this.tags = source["tags"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.channel = source["channel"];
+	        this.computer = source["computer"];
+	        this.description = source["description"];
+	    }
+	}
+	export class SigmaHitPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    hits: SigmaHit[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaHitPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.hits = this.convertValues(source["hits"], SigmaHit);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SigmaHitQuery {
+	    session_id: string;
+	    rule_id: string;
+	    level: string;
+	    computer: string;
+	    keyword: string;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaHitQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.rule_id = source["rule_id"];
+	        this.level = source["level"];
+	        this.computer = source["computer"];
+	        this.keyword = source["keyword"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	export class SigmaHitSummary {
+	    rule_id: string;
+	    title: string;
+	    level: string;
+	    tags: string[];
+	    hits: number;
+	    computers: number;
+	    first_seen: string;
+	    last_seen: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaHitSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule_id = source["rule_id"];
+	        this.title = source["title"];
+	        this.level = source["level"];
+	        this.tags = source["tags"];
+	        this.hits = source["hits"];
+	        this.computers = source["computers"];
+	        this.first_seen = source["first_seen"];
+	        this.last_seen = source["last_seen"];
+	    }
+	}
+	export class SigmaRule {
+	    id: string;
+	    title: string;
+	    level: string;
+	    status: string;
+	    description: string;
+	    author: string;
+	    tags: string[];
+	    logsource: string;
+	    file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.level = source["level"];
+	        this.status = source["status"];
+	        this.description = source["description"];
+	        this.author = source["author"];
+	        this.tags = source["tags"];
+	        this.logsource = source["logsource"];
+	        this.file = source["file"];
+	    }
+	}
+	export class SigmaRuleError {
+	    file: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRuleError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.error = source["error"];
+	    }
+	}
+	export class SigmaRuleSet {
+	    dir: string;
+	    rules: SigmaRule[];
+	    errors: SigmaRuleError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.rules = this.convertValues(source["rules"], SigmaRule);
+	        this.errors = this.convertValues(source["errors"], SigmaRuleError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SigmaRunResult {
+	    session_id: string;
+	    rules: number;
+	    events: number;
+	    hits: number;
+	    errors: SigmaRuleError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRunResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.rules = source["rules"];
+	        this.events = source["events"];
+	        this.hits = source["hits"];
+	        this.errors = this.convertValues(source["errors"], SigmaRuleError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SnapshotDiff {
+	    base: ScanSession;
+	    target: ScanSession;
+	    same_host: boolean;
+	    artifacts: ArtifactDiff[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base = this.convertValues(source["base"], ScanSession);
+	        this.target = this.convertValues(source["target"], ScanSession);
+	        this.same_host = source["same_host"];
+	        this.artifacts = this.convertValues(source["artifacts"], ArtifactDiff);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SpecialPrivilege {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    subject: string;
+	    subject_sid: string;
+	    logon_id: string;
+	    privileges: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SpecialPrivilege(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.subject = source["subject"];
+	        this.subject_sid = source["subject_sid"];
+	        this.logon_id = source["logon_id"];
+	        this.privileges = source["privileges"];
+	    }
+	}
+	export class SpecialPrivilegePage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SpecialPrivilege[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SpecialPrivilegePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SpecialPrivilege);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StartupItem {
+	    name: string;
+	    path: string;
+	    type: string;
+	    enabled: boolean;
+	    // Go type: time
+	    lastModTime: any;
+	    size: number;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartupItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.type = source["type"];
+	        this.enabled = source["enabled"];
+	        this.lastModTime = this.convertValues(source["lastModTime"], null);
+	        this.size = source["size"];
+	        this.description = source["description"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SudoCommand {
+	    time: string;
+	    user: string;
+	    run_as: string;
+	    tty: string;
+	    pwd: string;
+	    command: string;
+	    allowed: boolean;
+	    reason: string;
+	    host: string;
+	    log_file: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SudoCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.user = source["user"];
+	        this.run_as = source["run_as"];
+	        this.tty = source["tty"];
+	        this.pwd = source["pwd"];
+	        this.command = source["command"];
+	        this.allowed = source["allowed"];
+	        this.reason = source["reason"];
+	        this.host = source["host"];
+	        this.log_file = source["log_file"];
+	    }
+	}
+	export class SysmonConnectionSummary {
+	    image: string;
+	    protocol: string;
+	    initiated: boolean;
+	    destination_ip: string;
+	    destination_port: number;
+	    destination_hostname: string;
+	    count: number;
+	    processes: number;
+	    computers: number;
+	    first_seen: string;
+	    last_seen: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonConnectionSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.image = source["image"];
+	        this.protocol = source["protocol"];
+	        this.initiated = source["initiated"];
+	        this.destination_ip = source["destination_ip"];
+	        this.destination_port = source["destination_port"];
+	        this.destination_hostname = source["destination_hostname"];
+	        this.count = source["count"];
+	        this.processes = source["processes"];
+	        this.computers = source["computers"];
+	        this.first_seen = source["first_seen"];
+	        this.last_seen = source["last_seen"];
+	    }
+	}
+	export class SysmonDNS {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    query_name: string;
+	    query_status: string;
+	    query_results: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonDNS(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.query_name = source["query_name"];
+	        this.query_status = source["query_status"];
+	        this.query_results = source["query_results"];
+	    }
+	}
+	export class SysmonDNSPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonDNS[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonDNSPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonDNS);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonDNSSummary {
+	    query_name: string;
+	    count: number;
+	    images: string[];
+	    results: string;
+	    first_seen: string;
+	    last_seen: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonDNSSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query_name = source["query_name"];
+	        this.count = source["count"];
+	        this.images = source["images"];
+	        this.results = source["results"];
+	        this.first_seen = source["first_seen"];
+	        this.last_seen = source["last_seen"];
+	    }
+	}
+	export class SysmonFileCreate {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    target_filename: string;
+	    creation_utc_time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonFileCreate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.target_filename = source["target_filename"];
+	        this.creation_utc_time = source["creation_utc_time"];
+	    }
+	}
+	export class SysmonFileCreatePage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonFileCreate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonFileCreatePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonFileCreate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonFileDelete {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    user: string;
+	    target_filename: string;
+	    hashes: string;
+	    is_executable: boolean;
+	    archived: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonFileDelete(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.user = source["user"];
+	        this.target_filename = source["target_filename"];
+	        this.hashes = source["hashes"];
+	        this.is_executable = source["is_executable"];
+	        this.archived = source["archived"];
+	    }
+	}
+	export class SysmonFileDeletePage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonFileDelete[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonFileDeletePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonFileDelete);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonImageLoad {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    image_loaded: string;
+	    signed: boolean;
+	    signature: string;
+	    signature_status: string;
+	    hashes: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonImageLoad(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.image_loaded = source["image_loaded"];
+	        this.signed = source["signed"];
+	        this.signature = source["signature"];
+	        this.signature_status = source["signature_status"];
+	        this.hashes = source["hashes"];
+	    }
+	}
+	export class SysmonImageLoadPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonImageLoad[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonImageLoadPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonImageLoad);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonNetwork {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    user: string;
+	    protocol: string;
+	    initiated: boolean;
+	    source_ip: string;
+	    source_port: number;
+	    source_hostname: string;
+	    destination_ip: string;
+	    destination_port: number;
+	    destination_hostname: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonNetwork(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.user = source["user"];
+	        this.protocol = source["protocol"];
+	        this.initiated = source["initiated"];
+	        this.source_ip = source["source_ip"];
+	        this.source_port = source["source_port"];
+	        this.source_hostname = source["source_hostname"];
+	        this.destination_ip = source["destination_ip"];
+	        this.destination_port = source["destination_port"];
+	        this.destination_hostname = source["destination_hostname"];
+	    }
+	}
+	export class SysmonNetworkActivity {
+	    session_id: string;
+	    connections: SysmonConnectionSummary[];
+	    dns: SysmonDNSSummary[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonNetworkActivity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.connections = this.convertValues(source["connections"], SysmonConnectionSummary);
+	        this.dns = this.convertValues(source["dns"], SysmonDNSSummary);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonNetworkPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonNetwork[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonNetworkPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonNetwork);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonProcess {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    command_line: string;
+	    current_directory: string;
+	    original_file_name: string;
+	    user: string;
+	    logon_id: string;
+	    integrity_level: string;
+	    hashes: string;
+	    parent_process_guid: string;
+	    parent_process_id: number;
+	    parent_image: string;
+	    parent_command_line: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcess(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.command_line = source["command_line"];
+	        this.current_directory = source["current_directory"];
+	        this.original_file_name = source["original_file_name"];
+	        this.user = source["user"];
+	        this.logon_id = source["logon_id"];
+	        this.integrity_level = source["integrity_level"];
+	        this.hashes = source["hashes"];
+	        this.parent_process_guid = source["parent_process_guid"];
+	        this.parent_process_id = source["parent_process_id"];
+	        this.parent_image = source["parent_image"];
+	        this.parent_command_line = source["parent_command_line"];
+	    }
+	}
+	export class SysmonProcessAccess {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    source_process_guid: string;
+	    source_process_id: number;
+	    source_image: string;
+	    target_process_guid: string;
+	    target_process_id: number;
+	    target_image: string;
+	    granted_access: string;
+	    call_trace: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcessAccess(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.source_process_guid = source["source_process_guid"];
+	        this.source_process_id = source["source_process_id"];
+	        this.source_image = source["source_image"];
+	        this.target_process_guid = source["target_process_guid"];
+	        this.target_process_id = source["target_process_id"];
+	        this.target_image = source["target_image"];
+	        this.granted_access = source["granted_access"];
+	        this.call_trace = source["call_trace"];
+	    }
+	}
+	export class SysmonProcessAccessPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonProcessAccess[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcessAccessPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonProcessAccess);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonProcessNode {
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    command_line: string;
+	    user: string;
+	    integrity_level: string;
+	    time: string;
+	    computer: string;
+	    parent_process_guid: string;
+	    connections: number;
+	    dns_queries: number;
+	    file_creates: number;
+	    out_of_range: boolean;
+	    inferred: boolean;
+	    children?: SysmonProcessNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcessNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.command_line = source["command_line"];
+	        this.user = source["user"];
+	        this.integrity_level = source["integrity_level"];
+	        this.time = source["time"];
+	        this.computer = source["computer"];
+	        this.parent_process_guid = source["parent_process_guid"];
+	        this.connections = source["connections"];
+	        this.dns_queries = source["dns_queries"];
+	        this.file_creates = source["file_creates"];
+	        this.out_of_range = source["out_of_range"];
+	        this.inferred = source["inferred"];
+	        this.children = this.convertValues(source["children"], SysmonProcessNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonProcessPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonProcess[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcessPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonProcess);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonProcessTree {
+	    session_id: string;
+	    processes: number;
+	    truncated: boolean;
+	    roots: SysmonProcessNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonProcessTree(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.processes = source["processes"];
+	        this.truncated = source["truncated"];
+	        this.roots = this.convertValues(source["roots"], SysmonProcessNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonRegistry {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    action: string;
+	    process_guid: string;
+	    process_id: number;
+	    image: string;
+	    target_object: string;
+	    details: string;
+	    new_name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonRegistry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.action = source["action"];
+	        this.process_guid = source["process_guid"];
+	        this.process_id = source["process_id"];
+	        this.image = source["image"];
+	        this.target_object = source["target_object"];
+	        this.details = source["details"];
+	        this.new_name = source["new_name"];
+	    }
+	}
+	export class SysmonRegistryPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonRegistry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonRegistryPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonRegistry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SysmonRemoteThread {
+	    id: number;
+	    source_file: string;
+	    record_id: number;
+	    time: string;
+	    event_id: number;
+	    computer: string;
+	    source_process_guid: string;
+	    source_process_id: number;
+	    source_image: string;
+	    target_process_guid: string;
+	    target_process_id: number;
+	    target_image: string;
+	    start_address: string;
+	    start_module: string;
+	    start_function: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonRemoteThread(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_file = source["source_file"];
+	        this.record_id = source["record_id"];
+	        this.time = source["time"];
+	        this.event_id = source["event_id"];
+	        this.computer = source["computer"];
+	        this.source_process_guid = source["source_process_guid"];
+	        this.source_process_id = source["source_process_id"];
+	        this.source_image = source["source_image"];
+	        this.target_process_guid = source["target_process_guid"];
+	        this.target_process_id = source["target_process_id"];
+	        this.target_image = source["target_image"];
+	        this.start_address = source["start_address"];
+	        this.start_module = source["start_module"];
+	        this.start_function = source["start_function"];
+	    }
+	}
+	export class SysmonRemoteThreadPage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    records: SysmonRemoteThread[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SysmonRemoteThreadPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.records = this.convertValues(source["records"], SysmonRemoteThread);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SystemInfo {
+	    hostname: string;
+	    os: string;
+	    arch: string;
+	    cpu_cores: number;
+	    kernel_version: string;
+	    cpu_usage: number;
+	    total_memory: number;
+	    memory_usage: number;
+	    disks: DiskInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SystemInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostname = source["hostname"];
+	        this.os = source["os"];
+	        this.arch = source["arch"];
+	        this.cpu_cores = source["cpu_cores"];
+	        this.kernel_version = source["kernel_version"];
+	        this.cpu_usage = source["cpu_usage"];
+	        this.total_memory = source["total_memory"];
+	        this.memory_usage = source["memory_usage"];
+	        this.disks = this.convertValues(source["disks"], DiskInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SystemUser {
+	    username: string;
+	    uid: string;
+	    gid: string;
+	    home_dir: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SystemUser(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.username = source["username"];
+	        this.uid = source["uid"];
+	        this.gid = source["gid"];
+	        this.home_dir = source["home_dir"];
+	        this.name = source["name"];
+	    }
+	}
+	export class TimelineBucket {
+	    start: string;
+	    end: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.count = source["count"];
+	    }
+	}
+	export class TimelineSourceCount {
+	    source: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineSourceCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.count = source["count"];
+	    }
+	}
+	export class TimelineBuildResult {
+	    session_id: string;
+	    events: number;
+	    skipped: number;
+	    counts: TimelineSourceCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineBuildResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.events = source["events"];
+	        this.skipped = source["skipped"];
+	        this.counts = this.convertValues(source["counts"], TimelineSourceCount);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class LoginSuccess {
+	export class TimelineEvent {
+	    id: number;
 	    time: string;
-	    event_id: string;
-	    event_type: string;
 	    source: string;
-	    username: string;
-	    ip_address: string;
+	    source_type: string;
+	    time_type: string;
+	    macb: string;
+	    host: string;
+	    user: string;
+	    summary: string;
+	    description: string;
+	    filename: string;
+	    ref_table: string;
+	    ref_id: number;
+	    raw_time: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new LoginSuccess(source);
+	        return new TimelineEvent(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
 	        this.time = source["time"];
-	        this.event_id = source["event_id"];
-	        this.event_type = source["event_type"];
 	        this.source = source["source"];
-	        this.username = source["username"];
-	        this.ip_address = source["ip_address"];
+	        this.source_type = source["source_type"];
+	        this.time_type = source["time_type"];
+	        this.macb = source["macb"];
+	        this.host = source["host"];
+	        this.user = source["user"];
+	        this.summary = source["summary"];
+	        this.description = source["description"];
+	        this.filename = source["filename"];
+	        this.ref_table = source["ref_table"];
+	        this.ref_id = source["ref_id"];
+	        this.raw_time = source["raw_time"];
 	    }
 	}
-	export class NetworkConn {
-	    proto: string;
-	    local_addr: string;
-	    remote_addr: string;
-	    status: string;
-	    pid: number;
+	export class TimelineHistogram {
+	    session_id: string;
+	    start: string;
+	    end: string;
+	    seconds: number;
+	    buckets: TimelineBucket[];
+	    sources: TimelineSourceCount[];
+	    hosts: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new NetworkConn(source);
+	        return new TimelineHistogram(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.proto = source["proto"];
-	        this.local_addr = source["local_addr"];
-	        this.remote_addr = source["remote_addr"];
-	        this.status = source["status"];
-	        this.pid = source["pid"];
+	        this.session_id = source["session_id"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.seconds = source["seconds"];
+	        this.buckets = this.convertValues(source["buckets"], TimelineBucket);
+	        this.sources = this.convertValues(source["sources"], TimelineSourceCount);
+	        this.hosts = source["hosts"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class NetworkInfo {
-	    hostname: string;
-	    ips: string[];
-	    macs: string[];
-	    interfaces: string[];
-	    interface_stats: InterfaceStats[];
-	    gateway: string;
+	export class TimelinePage {
+	    session_id: string;
+	    total: number;
+	    page: number;
+	    page_size: number;
+	    events: TimelineEvent[];
 	
 	    static createFrom(source: any = {}) {
-	        return new NetworkInfo(source);
+	        return new TimelinePage(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.hostname = source["hostname"];
-	        this.ips = source["ips"];
-	        this.macs = source["macs"];
-	        this.interfaces = source["interfaces"];
-	        this.interface_stats = this.convertValues(source["interface_stats"], InterfaceStats);
-	        this.gateway = source["gateway"];
+	        this.session_id = source["session_id"];
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	        this.events = this.convertValues(source["events"], TimelineEvent);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -270,101 +3337,160 @@ export namespace pkg {
 		    return a;
 		}
 	}
-	export class ProcInfo {
-	    pid: number;
+	export class TimelineQuery {
+	    session_id: string;
+	    start: string;
+	    end: string;
+	    sources: string[];
+	    host: string;
+	    keyword: string;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.sources = source["sources"];
+	        this.host = source["host"];
+	        this.keyword = source["keyword"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	
+	export class UserInfo {
+	    username: string;
+	    uid: string;
+	    gid: string;
+	    home_dir: string;
 	    name: string;
-	    ppid: number;
-	    parent_name: string;
-	    create_time: number;
-	    exe: string;
-	    file_ctime: number;
-	    file_mtime: number;
-	    md5: string;
-	    signature: string;
-	    cpu_percent: number;
-	    mem_percent: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ProcInfo(source);
+	        return new UserInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.pid = source["pid"];
+	        this.username = source["username"];
+	        this.uid = source["uid"];
+	        this.gid = source["gid"];
+	        this.home_dir = source["home_dir"];
 	        this.name = source["name"];
-	        this.ppid = source["ppid"];
-	        this.parent_name = source["parent_name"];
-	        this.create_time = source["create_time"];
-	        this.exe = source["exe"];
-	        this.file_ctime = source["file_ctime"];
-	        this.file_mtime = source["file_mtime"];
-	        this.md5 = source["md5"];
-	        this.signature = source["signature"];
-	        this.cpu_percent = source["cpu_percent"];
-	        this.mem_percent = source["mem_percent"];
 	    }
 	}
-	export class RDPLoginInfo {
-	    time: string;
-	    username: string;
+	
+	export class WebLogTalker {
 	    ip: string;
-	    status: string;
-	    description: string;
+	    requests: number;
+	    errors: number;
+	    posts: number;
+	    paths: number;
+	    first_time: string;
+	    last_time: string;
+	    user_agent: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new RDPLoginInfo(source);
+	        return new WebLogTalker(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.time = source["time"];
-	        this.username = source["username"];
 	        this.ip = source["ip"];
-	        this.status = source["status"];
-	        this.description = source["description"];
+	        this.requests = source["requests"];
+	        this.errors = source["errors"];
+	        this.posts = source["posts"];
+	        this.paths = source["paths"];
+	        this.first_time = source["first_time"];
+	        this.last_time = source["last_time"];
+	        this.user_agent = source["user_agent"];
 	    }
 	}
-	export class ShellHistory {
-	    time: string;
-	    command: string;
-	    user: string;
-	    shell: string;
+	export class WebLogFinding {
+	    level: string;
+	    category: string;
+	    rule: string;
+	    ip: string;
+	    method: string;
+	    path: string;
+	    sample: string;
+	    match: string;
+	    status: number;
+	    ok_count: number;
+	    count: number;
+	    first_time: string;
+	    last_time: string;
+	    user_agent: string;
+	    log_file: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ShellHistory(source);
+	        return new WebLogFinding(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.time = source["time"];
-	        this.command = source["command"];
-	        this.user = source["user"];
-	        this.shell = source["shell"];
+	        this.level = source["level"];
+	        this.category = source["category"];
+	        this.rule = source["rule"];
+	        this.ip = source["ip"];
+	        this.method = source["method"];
+	        this.path = source["path"];
+	        this.sample = source["sample"];
+	        this.match = source["match"];
+	        this.status = source["status"];
+	        this.ok_count = source["ok_count"];
+	        this.count = source["count"];
+	        this.first_time = source["first_time"];
+	        this.last_time = source["last_time"];
+	        this.user_agent = source["user_agent"];
+	        this.log_file = source["log_file"];
 	    }
 	}
-	export class StartupItem {
-	    name: string;
+	export class WebLogFile {
 	    path: string;
-	    type: string;
-	    enabled: boolean;
-	    // Go type: time
-	    lastModTime: any;
-	    size: number;
-	    description: string;
+	    format: string;
+	    requests: number;
+	    skipped: number;
+	    first_time: string;
+	    last_time: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new StartupItem(source);
+	        return new WebLogFile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
 	        this.path = source["path"];
-	        this.type = source["type"];
-	        this.enabled = source["enabled"];
-	        this.lastModTime = this.convertValues(source["lastModTime"], null);
-	        this.size = source["size"];
-	        this.description = source["description"];
+	        this.format = source["format"];
+	        this.requests = source["requests"];
+	        this.skipped = source["skipped"];
+	        this.first_time = source["first_time"];
+	        this.last_time = source["last_time"];
+	    }
+	}
+	export class WebLogAnalysis {
+	    files: WebLogFile[];
+	    findings: WebLogFinding[];
+	    talkers: WebLogTalker[];
+	    requests: number;
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebLogAnalysis(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], WebLogFile);
+	        this.findings = this.convertValues(source["findings"], WebLogFinding);
+	        this.talkers = this.convertValues(source["talkers"], WebLogTalker);
+	        this.requests = source["requests"];
+	        this.truncated = source["truncated"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -385,32 +3511,63 @@ export namespace pkg {
 		    return a;
 		}
 	}
-	export class SystemInfo {
-	    hostname: string;
-	    os: string;
-	    arch: string;
-	    cpu_cores: number;
-	    kernel_version: string;
-	    cpu_usage: number;
-	    total_memory: number;
-	    memory_usage: number;
-	    disks: DiskInfo[];
+	
+	
+	
+	export class WebshellFile {
+	    path: string;
+	    root: string;
+	    score: number;
+	    level: string;
+	    family: string;
+	    indicators: string[];
+	    size: number;
+	    md5: string;
+	    sha256: string;
+	    entropy: number;
+	    longest_line: number;
+	    mod_time: string;
+	    access_time: string;
+	    change_time: string;
+	    create_time: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SystemInfo(source);
+	        return new WebshellFile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.hostname = source["hostname"];
-	        this.os = source["os"];
-	        this.arch = source["arch"];
-	        this.cpu_cores = source["cpu_cores"];
-	        this.kernel_version = source["kernel_version"];
-	        this.cpu_usage = source["cpu_usage"];
-	        this.total_memory = source["total_memory"];
-	        this.memory_usage = source["memory_usage"];
-	        this.disks = this.convertValues(source["disks"], DiskInfo);
+	        this.path = source["path"];
+	        this.root = source["root"];
+	        this.score = source["score"];
+	        this.level = source["level"];
+	        this.family = source["family"];
+	        this.indicators = source["indicators"];
+	        this.size = source["size"];
+	        this.md5 = source["md5"];
+	        this.sha256 = source["sha256"];
+	        this.entropy = source["entropy"];
+	        this.longest_line = source["longest_line"];
+	        this.mod_time = source["mod_time"];
+	        this.access_time = source["access_time"];
+	        this.change_time = source["change_time"];
+	        this.create_time = source["create_time"];
+	    }
+	}
+	export class WebshellScan {
+	    roots: string[];
+	    scanned: number;
+	    files: WebshellFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WebshellScan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roots = source["roots"];
+	        this.scanned = source["scanned"];
+	        this.files = this.convertValues(source["files"], WebshellFile);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -431,45 +3588,81 @@ export namespace pkg {
 		    return a;
 		}
 	}
-	export class SystemUser {
-	    username: string;
-	    uid: string;
-	    gid: string;
-	    home_dir: string;
-	    name: string;
+	export class WinEventCount {
+	    table: string;
+	    title: string;
+	    count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new SystemUser(source);
+	        return new WinEventCount(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.username = source["username"];
-	        this.uid = source["uid"];
-	        this.gid = source["gid"];
-	        this.home_dir = source["home_dir"];
-	        this.name = source["name"];
+	        this.table = source["table"];
+	        this.title = source["title"];
+	        this.count = source["count"];
 	    }
 	}
-	export class UserInfo {
-	    username: string;
-	    uid: string;
-	    gid: string;
-	    home_dir: string;
-	    name: string;
+	export class WinEventQuery {
+	    session_id: string;
+	    start: string;
+	    end: string;
+	    computer: string;
+	    keyword: string;
+	    process_guid: string;
+	    page: number;
+	    page_size: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new UserInfo(source);
+	        return new WinEventQuery(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.username = source["username"];
-	        this.uid = source["uid"];
-	        this.gid = source["gid"];
-	        this.home_dir = source["home_dir"];
-	        this.name = source["name"];
+	        this.session_id = source["session_id"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.computer = source["computer"];
+	        this.keyword = source["keyword"];
+	        this.process_guid = source["process_guid"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	}
+	export class WinEventRebuildResult {
+	    session_id: string;
+	    events: number;
+	    counts: WinEventCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WinEventRebuildResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.events = source["events"];
+	        this.counts = this.convertValues(source["counts"], WinEventCount);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -2,14 +2,50 @@
 // This file is automatically generated. DO NOT EDIT
 import {pkg} from '../models';
 
+export function BuildTimeline(arg1:string):Promise<pkg.TimelineBuildResult>;
+
+export function CancelTask(arg1:string):Promise<boolean>;
+
+export function CloseScanSession():Promise<void>;
+
+export function CurrentDatabase():Promise<pkg.DatabaseInfo>;
+
+export function CurrentScanSession():Promise<pkg.ScanSession>;
+
+export function DeleteScanSession(arg1:string):Promise<void>;
+
+export function DiffScanSessions(arg1:string,arg2:string):Promise<pkg.SnapshotDiff>;
+
+export function ExportArtifact(arg1:string,arg2:string):Promise<Array<string>>;
+
+export function ExportEVTXEvents(arg1:pkg.EVTXQuery,arg2:string):Promise<Array<string>>;
+
+export function ExportEvidence(arg1:string,arg2:string,arg3:string):Promise<pkg.EvidencePackage>;
+
+export function ExportReport(arg1:string):Promise<string>;
+
+export function ExportSession(arg1:string,arg2:Array<string>,arg3:string,arg4:string):Promise<Array<string>>;
+
+export function ExportTimeline(arg1:pkg.TimelineQuery):Promise<string>;
+
+export function GenerateReport(arg1:string,arg2:string):Promise<string>;
+
 export function GetAllProcesses():Promise<Array<pkg.ProcInfo>>;
 
 export function GetAllUsers():Promise<Array<pkg.SystemUser>>;
 
+export function GetAuditLog():Promise<pkg.AuditLog>;
+
 export function GetCronTasks():Promise<Array<pkg.CronTask>>;
 
+export function GetEVTXFilterOptions(arg1:string):Promise<pkg.EVTXFilterOptions>;
+
+export function GetJournalEntryFields(arg1:string,arg2:number):Promise<Record<string, string>>;
+
 export function GetLoginFailedRecords():Promise<Array<pkg.LoginFailed>>;
 
+export function GetLoginLogs():Promise<pkg.LoginLogs>;
+
 export function GetLoginSuccessRecords():Promise<Array<pkg.LoginSuccess>>;
 
 export function GetNetworkConnections():Promise<Array<pkg.NetworkConn>>;
@@ -22,13 +58,101 @@ export function GetSensitiveFileInfo():Promise<Array<pkg.FileInfo>>;
 
 export function GetShellHistory():Promise<Array<pkg.ShellHistory>>;
 
+export function GetSigmaHitSummary(arg1:string):Promise<Array<pkg.SigmaHitSummary>>;
+
+export function GetSigmaRules():Promise<pkg.SigmaRuleSet>;
+
 export function GetStartupItems():Promise<Array<pkg.StartupItem>>;
 
+export function GetSudoCommands():Promise<Array<pkg.SudoCommand>>;
+
+export function GetSysmonNetworkActivity(arg1:pkg.WinEventQuery):Promise<pkg.SysmonNetworkActivity>;
+
+export function GetSysmonProcessTree(arg1:pkg.WinEventQuery):Promise<pkg.SysmonProcessTree>;
+
 export function GetSystemInfo():Promise<pkg.SystemInfo>;
 
+export function GetTimelineHistogram(arg1:pkg.TimelineQuery):Promise<pkg.TimelineHistogram>;
+
 export function GetUserInfo():Promise<pkg.UserInfo>;
 
-export function ParseEVTXFile(arg1:string):Promise<Array<pkg.EVTXEvent>>;
+export function GetWebLogAnalysis():Promise<pkg.WebLogAnalysis>;
+
+export function GetWinEventSummary(arg1:string):Promise<Array<pkg.WinEventCount>>;
+
+export function ImportEVTXPaths(arg1:Array<string>):Promise<Array<pkg.EVTXFile>>;
+
+export function ListCollectors():Promise<Array<pkg.CollectorInfo>>;
+
+export function ListEVTXFiles(arg1:string):Promise<Array<pkg.EVTXFile>>;
+
+export function ListScanSessions():Promise<Array<pkg.ScanSession>>;
+
+export function NewCaseDatabase(arg1:string):Promise<pkg.DatabaseInfo>;
+
+export function OpenDatabase(arg1:string,arg2:boolean):Promise<pkg.DatabaseInfo>;
+
+export function OpenEvidencePackage():Promise<pkg.DatabaseInfo>;
+
+export function OpenScanSession(arg1:string):Promise<pkg.ScanSession>;
+
+export function PackageEvidence(arg1:string,arg2:string,arg3:string,arg4:string):Promise<pkg.EvidencePackage>;
+
+export function QueryAccountChanges(arg1:pkg.WinEventQuery):Promise<pkg.AccountChangePage>;
+
+export function QueryEVTXEvents(arg1:pkg.EVTXQuery):Promise<pkg.EVTXPage>;
+
+export function QueryJournal(arg1:pkg.JournalQuery):Promise<pkg.JournalResult>;
+
+export function QueryLogClears(arg1:pkg.WinEventQuery):Promise<pkg.LogClearPage>;
+
+export function QueryProcessCreations(arg1:pkg.WinEventQuery):Promise<pkg.ProcessCreationPage>;
+
+export function QueryScheduledTasks(arg1:pkg.WinEventQuery):Promise<pkg.ScheduledTaskPage>;
+
+export function QueryScriptBlocks(arg1:pkg.ScriptBlockQuery):Promise<pkg.ScriptBlockPage>;
+
+export function QueryServiceInstalls(arg1:pkg.WinEventQuery):Promise<pkg.ServiceInstallPage>;
+
+export function QuerySigmaHits(arg1:pkg.SigmaHitQuery):Promise<pkg.SigmaHitPage>;
+
+export function QuerySpecialPrivileges(arg1:pkg.WinEventQuery):Promise<pkg.SpecialPrivilegePage>;
+
+export function QuerySysmonDNS(arg1:pkg.WinEventQuery):Promise<pkg.SysmonDNSPage>;
+
+export function QuerySysmonFileCreates(arg1:pkg.WinEventQuery):Promise<pkg.SysmonFileCreatePage>;
+
+export function QuerySysmonFileDeletes(arg1:pkg.WinEventQuery):Promise<pkg.SysmonFileDeletePage>;
+
+export function QuerySysmonImageLoads(arg1:pkg.WinEventQuery):Promise<pkg.SysmonImageLoadPage>;
+
+export function QuerySysmonNetwork(arg1:pkg.WinEventQuery):Promise<pkg.SysmonNetworkPage>;
+
+export function QuerySysmonProcessAccess(arg1:pkg.WinEventQuery):Promise<pkg.SysmonProcessAccessPage>;
+
+export function QuerySysmonProcesses(arg1:pkg.WinEventQuery):Promise<pkg.SysmonProcessPage>;
+
+export function QuerySysmonRegistry(arg1:pkg.WinEventQuery):Promise<pkg.SysmonRegistryPage>;
+
+export function QuerySysmonRemoteThreads(arg1:pkg.WinEventQuery):Promise<pkg.SysmonRemoteThreadPage>;
+
+export function QueryTimeline(arg1:pkg.TimelineQuery):Promise<pkg.TimelinePage>;
+
+export function RebuildScriptBlocks(arg1:string):Promise<pkg.ScriptBlockRebuildResult>;
+
+export function RebuildWinEvents(arg1:string):Promise<pkg.WinEventRebuildResult>;
+
+export function RecoverEVTXPaths(arg1:Array<string>):Promise<Array<pkg.EVTXFile>>;
+
+export function RefreshJournal():Promise<void>;
+
+export function RenameScanSession(arg1:string,arg2:string):Promise<void>;
+
+export function RunCollector(arg1:string):Promise<pkg.CollectorResult>;
+
+export function RunSigmaRules(arg1:string):Promise<pkg.SigmaRunResult>;
+
+export function SaveAuditLog(arg1:pkg.AuditLog):Promise<void>;
 
 export function SaveCronTasks(arg1:Array<pkg.CronTask>):Promise<void>;
 
@@ -38,6 +162,8 @@ export function SaveFileMonitor(arg1:Array<pkg.FileInfo>):Promise<void>;
 
 export function SaveLoginFailed(arg1:Array<pkg.LoginFailed>):Promise<void>;
 
+export function SaveLoginLogs(arg1:pkg.LoginLogs):Promise<void>;
+
 export function SaveLoginSuccess(arg1:Array<pkg.LoginSuccess>):Promise<void>;
 
 export function SaveNetworkConnections(arg1:Array<pkg.NetworkConn>):Promise<void>;
@@ -52,8 +178,34 @@ export function SaveShellHistory(arg1:Array<pkg.ShellHistory>):Promise<void>;
 
 export function SaveStartupItems(arg1:Array<pkg.StartupItem>):Promise<void>;
 
+export function SaveSudoCommands(arg1:Array<pkg.SudoCommand>):Promise<void>;
+
 export function SaveSystemInfo(arg1:pkg.SystemInfo):Promise<void>;
 
 export function SaveUserInfo(arg1:pkg.UserInfo):Promise<void>;
 
-export function SelectAndParseEVTXFile():Promise<Array<pkg.EVTXEvent>>;
+export function SaveWebLogAnalysis(arg1:pkg.WebLogAnalysis):Promise<void>;
+
+export function SaveWebshellScan(arg1:pkg.WebshellScan):Promise<void>;
+
+export function ScanWebshells(arg1:Array<string>):Promise<pkg.WebshellScan>;
+
+export function SelectAndImportEVTXDirectory():Promise<Array<pkg.EVTXFile>>;
+
+export function SelectAndImportEVTXFiles():Promise<Array<pkg.EVTXFile>>;
+
+export function SelectAndRecoverEVTXFiles():Promise<Array<pkg.EVTXFile>>;
+
+export function SelectAndVerifyEvidence():Promise<pkg.EvidenceVerifyResult>;
+
+export function SelectDatabase(arg1:boolean):Promise<pkg.DatabaseInfo>;
+
+export function SelectSigmaRulesDir():Promise<pkg.SigmaRuleSet>;
+
+export function SetDefaultDatabase():Promise<void>;
+
+export function SetSigmaRulesDir(arg1:string):Promise<pkg.SigmaRuleSet>;
+
+export function StartScanSession(arg1:string,arg2:string):Promise<pkg.ScanSession>;
+
+export function VerifyEvidence(arg1:string,arg2:string):Promise<pkg.EvidenceVerifyResult>;
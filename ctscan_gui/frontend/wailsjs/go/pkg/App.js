@@ -2,6 +2,62 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BuildTimeline(arg1) {
+  return window['go']['pkg']['App']['BuildTimeline'](arg1);
+}
+
+export function CancelTask(arg1) {
+  return window['go']['pkg']['App']['CancelTask'](arg1);
+}
+
+export function CloseScanSession() {
+  return window['go']['pkg']['App']['CloseScanSession']();
+}
+
+export function CurrentDatabase() {
+  return window['go']['pkg']['App']['CurrentDatabase']();
+}
+
+export function CurrentScanSession() {
+  return window['go']['pkg']['App']['CurrentScanSession']();
+}
+
+export function DeleteScanSession(arg1) {
+  return window['go']['pkg']['App']['DeleteScanSession'](arg1);
+}
+
+export function DiffScanSessions(arg1, arg2) {
+  return window['go']['pkg']['App']['DiffScanSessions'](arg1, arg2);
+}
+
+export function ExportArtifact(arg1, arg2) {
+  return window['go']['pkg']['App']['ExportArtifact'](arg1, arg2);
+}
+
+export function ExportEVTXEvents(arg1, arg2) {
+  return window['go']['pkg']['App']['ExportEVTXEvents'](arg1, arg2);
+}
+
+export function ExportEvidence(arg1, arg2, arg3) {
+  return window['go']['pkg']['App']['ExportEvidence'](arg1, arg2, arg3);
+}
+
+export function ExportReport(arg1) {
+  return window['go']['pkg']['App']['ExportReport'](arg1);
+}
+
+export function ExportSession(arg1, arg2, arg3, arg4) {
+  return window['go']['pkg']['App']['ExportSession'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportTimeline(arg1) {
+  return window['go']['pkg']['App']['ExportTimeline'](arg1);
+}
+
+export function GenerateReport(arg1, arg2) {
+  return window['go']['pkg']['App']['GenerateReport'](arg1, arg2);
+}
+
 export function GetAllProcesses() {
   return window['go']['pkg']['App']['GetAllProcesses']();
 }
@@ -10,14 +66,30 @@ export function GetAllUsers() {
   return window['go']['pkg']['App']['GetAllUsers']();
 }
 
+export function GetAuditLog() {
+  return window['go']['pkg']['App']['GetAuditLog']();
+}
+
 export function GetCronTasks() {
   return window['go']['pkg']['App']['GetCronTasks']();
 }
 
+export function GetEVTXFilterOptions(arg1) {
+  return window['go']['pkg']['App']['GetEVTXFilterOptions'](arg1);
+}
+
+export function GetJournalEntryFields(arg1, arg2) {
+  return window['go']['pkg']['App']['GetJournalEntryFields'](arg1, arg2);
+}
+
 export function GetLoginFailedRecords() {
   return window['go']['pkg']['App']['GetLoginFailedRecords']();
 }
 
+export function GetLoginLogs() {
+  return window['go']['pkg']['App']['GetLoginLogs']();
+}
+
 export function GetLoginSuccessRecords() {
   return window['go']['pkg']['App']['GetLoginSuccessRecords']();
 }
@@ -42,20 +114,196 @@ export function GetShellHistory() {
   return window['go']['pkg']['App']['GetShellHistory']();
 }
 
+export function GetSigmaHitSummary(arg1) {
+  return window['go']['pkg']['App']['GetSigmaHitSummary'](arg1);
+}
+
+export function GetSigmaRules() {
+  return window['go']['pkg']['App']['GetSigmaRules']();
+}
+
 export function GetStartupItems() {
   return window['go']['pkg']['App']['GetStartupItems']();
 }
 
+export function GetSudoCommands() {
+  return window['go']['pkg']['App']['GetSudoCommands']();
+}
+
+export function GetSysmonNetworkActivity(arg1) {
+  return window['go']['pkg']['App']['GetSysmonNetworkActivity'](arg1);
+}
+
+export function GetSysmonProcessTree(arg1) {
+  return window['go']['pkg']['App']['GetSysmonProcessTree'](arg1);
+}
+
 export function GetSystemInfo() {
   return window['go']['pkg']['App']['GetSystemInfo']();
 }
 
+export function GetTimelineHistogram(arg1) {
+  return window['go']['pkg']['App']['GetTimelineHistogram'](arg1);
+}
+
 export function GetUserInfo() {
   return window['go']['pkg']['App']['GetUserInfo']();
 }
 
-export function ParseEVTXFile(arg1) {
-  return window['go']['pkg']['App']['ParseEVTXFile'](arg1);
+export function GetWebLogAnalysis() {
+  return window['go']['pkg']['App']['GetWebLogAnalysis']();
+}
+
+export function GetWinEventSummary(arg1) {
+  return window['go']['pkg']['App']['GetWinEventSummary'](arg1);
+}
+
+export function ImportEVTXPaths(arg1) {
+  return window['go']['pkg']['App']['ImportEVTXPaths'](arg1);
+}
+
+export function ListCollectors() {
+  return window['go']['pkg']['App']['ListCollectors']();
+}
+
+export function ListEVTXFiles(arg1) {
+  return window['go']['pkg']['App']['ListEVTXFiles'](arg1);
+}
+
+export function ListScanSessions() {
+  return window['go']['pkg']['App']['ListScanSessions']();
+}
+
+export function NewCaseDatabase(arg1) {
+  return window['go']['pkg']['App']['NewCaseDatabase'](arg1);
+}
+
+export function OpenDatabase(arg1, arg2) {
+  return window['go']['pkg']['App']['OpenDatabase'](arg1, arg2);
+}
+
+export function OpenEvidencePackage() {
+  return window['go']['pkg']['App']['OpenEvidencePackage']();
+}
+
+export function OpenScanSession(arg1) {
+  return window['go']['pkg']['App']['OpenScanSession'](arg1);
+}
+
+export function PackageEvidence(arg1, arg2, arg3, arg4) {
+  return window['go']['pkg']['App']['PackageEvidence'](arg1, arg2, arg3, arg4);
+}
+
+export function QueryAccountChanges(arg1) {
+  return window['go']['pkg']['App']['QueryAccountChanges'](arg1);
+}
+
+export function QueryEVTXEvents(arg1) {
+  return window['go']['pkg']['App']['QueryEVTXEvents'](arg1);
+}
+
+export function QueryJournal(arg1) {
+  return window['go']['pkg']['App']['QueryJournal'](arg1);
+}
+
+export function QueryLogClears(arg1) {
+  return window['go']['pkg']['App']['QueryLogClears'](arg1);
+}
+
+export function QueryProcessCreations(arg1) {
+  return window['go']['pkg']['App']['QueryProcessCreations'](arg1);
+}
+
+export function QueryScheduledTasks(arg1) {
+  return window['go']['pkg']['App']['QueryScheduledTasks'](arg1);
+}
+
+export function QueryScriptBlocks(arg1) {
+  return window['go']['pkg']['App']['QueryScriptBlocks'](arg1);
+}
+
+export function QueryServiceInstalls(arg1) {
+  return window['go']['pkg']['App']['QueryServiceInstalls'](arg1);
+}
+
+export function QuerySigmaHits(arg1) {
+  return window['go']['pkg']['App']['QuerySigmaHits'](arg1);
+}
+
+export function QuerySpecialPrivileges(arg1) {
+  return window['go']['pkg']['App']['QuerySpecialPrivileges'](arg1);
+}
+
+export function QuerySysmonDNS(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonDNS'](arg1);
+}
+
+export function QuerySysmonFileCreates(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonFileCreates'](arg1);
+}
+
+export function QuerySysmonFileDeletes(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonFileDeletes'](arg1);
+}
+
+export function QuerySysmonImageLoads(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonImageLoads'](arg1);
+}
+
+export function QuerySysmonNetwork(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonNetwork'](arg1);
+}
+
+export function QuerySysmonProcessAccess(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonProcessAccess'](arg1);
+}
+
+export function QuerySysmonProcesses(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonProcesses'](arg1);
+}
+
+export function QuerySysmonRegistry(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonRegistry'](arg1);
+}
+
+export function QuerySysmonRemoteThreads(arg1) {
+  return window['go']['pkg']['App']['QuerySysmonRemoteThreads'](arg1);
+}
+
+export function QueryTimeline(arg1) {
+  return window['go']['pkg']['App']['QueryTimeline'](arg1);
+}
+
+export function RebuildScriptBlocks(arg1) {
+  return window['go']['pkg']['App']['RebuildScriptBlocks'](arg1);
+}
+
+export function RebuildWinEvents(arg1) {
+  return window['go']['pkg']['App']['RebuildWinEvents'](arg1);
+}
+
+export function RecoverEVTXPaths(arg1) {
+  return window['go']['pkg']['App']['RecoverEVTXPaths'](arg1);
+}
+
+export function RefreshJournal() {
+  return window['go']['pkg']['App']['RefreshJournal']();
+}
+
+export function RenameScanSession(arg1, arg2) {
+  return window['go']['pkg']['App']['RenameScanSession'](arg1, arg2);
+}
+
+export function RunCollector(arg1) {
+  return window['go']['pkg']['App']['RunCollector'](arg1);
+}
+
+export function RunSigmaRules(arg1) {
+  return window['go']['pkg']['App']['RunSigmaRules'](arg1);
+}
+
+export function SaveAuditLog(arg1) {
+  return window['go']['pkg']['App']['SaveAuditLog'](arg1);
 }
 
 export function SaveCronTasks(arg1) {
@@ -74,6 +322,10 @@ export function SaveLoginFailed(arg1) {
   return window['go']['pkg']['App']['SaveLoginFailed'](arg1);
 }
 
+export function SaveLoginLogs(arg1) {
+  return window['go']['pkg']['App']['SaveLoginLogs'](arg1);
+}
+
 export function SaveLoginSuccess(arg1) {
   return window['go']['pkg']['App']['SaveLoginSuccess'](arg1);
 }
@@ -102,6 +354,10 @@ export function SaveStartupItems(arg1) {
   return window['go']['pkg']['App']['SaveStartupItems'](arg1);
 }
 
+export function SaveSudoCommands(arg1) {
+  return window['go']['pkg']['App']['SaveSudoCommands'](arg1);
+}
+
 export function SaveSystemInfo(arg1) {
   return window['go']['pkg']['App']['SaveSystemInfo'](arg1);
 }
@@ -110,6 +366,54 @@ export function SaveUserInfo(arg1) {
   return window['go']['pkg']['App']['SaveUserInfo'](arg1);
 }
 
-export function SelectAndParseEVTXFile() {
-  return window['go']['pkg']['App']['SelectAndParseEVTXFile']();
+export function SaveWebLogAnalysis(arg1) {
+  return window['go']['pkg']['App']['SaveWebLogAnalysis'](arg1);
+}
+
+export function SaveWebshellScan(arg1) {
+  return window['go']['pkg']['App']['SaveWebshellScan'](arg1);
+}
+
+export function ScanWebshells(arg1) {
+  return window['go']['pkg']['App']['ScanWebshells'](arg1);
+}
+
+export function SelectAndImportEVTXDirectory() {
+  return window['go']['pkg']['App']['SelectAndImportEVTXDirectory']();
+}
+
+export function SelectAndImportEVTXFiles() {
+  return window['go']['pkg']['App']['SelectAndImportEVTXFiles']();
+}
+
+export function SelectAndRecoverEVTXFiles() {
+  return window['go']['pkg']['App']['SelectAndRecoverEVTXFiles']();
+}
+
+export function SelectAndVerifyEvidence() {
+  return window['go']['pkg']['App']['SelectAndVerifyEvidence']();
+}
+
+export function SelectDatabase(arg1) {
+  return window['go']['pkg']['App']['SelectDatabase'](arg1);
+}
+
+export function SelectSigmaRulesDir() {
+  return window['go']['pkg']['App']['SelectSigmaRulesDir']();
+}
+
+export function SetDefaultDatabase() {
+  return window['go']['pkg']['App']['SetDefaultDatabase']();
+}
+
+export function SetSigmaRulesDir(arg1) {
+  return window['go']['pkg']['App']['SetSigmaRulesDir'](arg1);
+}
+
+export function StartScanSession(arg1, arg2) {
+  return window['go']['pkg']['App']['StartScanSession'](arg1, arg2);
+}
+
+export function VerifyEvidence(arg1, arg2) {
+  return window['go']['pkg']['App']['VerifyEvidence'](arg1, arg2);
 }
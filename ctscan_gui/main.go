@@ -2,7 +2,9 @@ package main
 
 import (
 	"embed"
+	"fmt"
 	"log"
+	"os"
 
 	"ctscan_gui/pkg"
 
@@ -15,8 +17,20 @@ import (
 var assets embed.FS
 
 func main() {
+	// 带子命令运行时进入无界面模式，例如 ctscan collect
+	if len(os.Args) > 1 && pkg.IsCLICommand(os.Args[1]) {
+		os.Exit(pkg.RunCLI(os.Args[1:]))
+	}
+
+	// 图形界面模式同样支持 -db、-db-case、-readonly 参数
+	opts, err := pkg.ParseDBFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "解析参数失败: %v\n", err)
+		os.Exit(2)
+	}
+
 	// 创建一个 App 实例
-	app, err := pkg.NewApp()
+	app, err := pkg.NewApp(opts)
 	if err != nil {
 		log.Fatalf("初始化应用失败: %v", err)
 	}
@@ -33,6 +47,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 1},
 		OnStartup:        app.Startup,
+		OnShutdown:       app.Shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -3,24 +3,71 @@ package pkg
 import (
 	"context"
 	"database/sql"
-	"fmt"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"sync"
 )
 
+// Version 工具版本号，发布时可通过 -ldflags "-X ctscan_gui/pkg.Version=x.y.z" 覆盖
+var Version = "1.0.0"
+
 // App struct
 type App struct {
-	ctx context.Context
-	db  *sql.DB
+	ctx      context.Context
+	dbMu     sync.RWMutex // 切换数据库时保护 db、dbPath 与 readOnly，读取通过 database 等方法
+	db       *sql.DB
+	dbPath   string // 数据库文件路径
+	readOnly bool   // 数据库是否以只读方式打开
+
+	evidenceMaxSize int64 // 证据包中单个原始文件的大小上限，0 时使用 maxEvidenceFileSize，小于 0 时不限制
+
+	sessionMu sync.Mutex
+	session   *ScanSession  // 当前扫描会话
+	authLog   *authLogCache // 当前扫描会话中解析过的认证日志，切换会话时清除
+
+	tasksMu    sync.Mutex
+	tasks      map[string]*runningTask // 正在运行的可取消任务
+	onProgress func(Progress)          // 命令行模式下的进度输出，为空时推送 Wails 事件
+
+	evtxMu sync.Mutex // 并行导入EVTX时串行写入数据库
+
+	journalMu sync.Mutex
+	journal   *journalCache // 上一次 journal 查询的结果
+
+	authLogMu sync.Mutex // 同一时间只解析一次认证日志
 }
 
 // NewApp 创建一个新的 App 应用结构体
-func NewApp() (*App, error) {
-	db, err := InitSqliteDB()
+func NewApp(opts DBOptions) (*App, error) {
+	dbPath, err := ResolveDBPath(opts)
+	if err != nil {
+		return nil, err
+	}
+	db, err := InitSqliteDB(dbPath, opts.ReadOnly)
 	if err != nil {
 		return nil, err
 	}
-	return &App{db: db}, nil
+	return &App{db: db, dbPath: dbPath, readOnly: opts.ReadOnly}, nil
+}
+
+// database 返回当前的数据库连接
+// 切换数据库后旧连接会被关闭，正在使用旧连接的查询返回错误而不会读到另一个数据库的数据
+func (a *App) database() *sql.DB {
+	a.dbMu.RLock()
+	defer a.dbMu.RUnlock()
+	return a.db
+}
+
+// databasePath 返回当前数据库文件路径
+func (a *App) databasePath() string {
+	a.dbMu.RLock()
+	defer a.dbMu.RUnlock()
+	return a.dbPath
+}
+
+// isReadOnly 当前数据库是否以只读方式打开
+func (a *App) isReadOnly() bool {
+	a.dbMu.RLock()
+	defer a.dbMu.RUnlock()
+	return a.readOnly
 }
 
 // startup 在应用启动时被调用。保存 context 以便我们调用运行时方法
@@ -28,19 +75,8 @@ func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-// SelectAndParseEVTXFile 弹窗选择EVTX文件并解析
-func (a *App) SelectAndParseEVTXFile() ([]EVTXEvent, error) {
-	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "选择EVTX文件",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "Windows事件日志", Pattern: "*.evtx"},
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	if filePath == "" {
-		return nil, fmt.Errorf("未选择文件")
-	}
-	return a.ParseEVTXFile(filePath)
+// Shutdown 在应用退出时被调用，结束当前扫描会话并关闭数据库
+func (a *App) Shutdown(ctx context.Context) {
+	a.CloseScanSession()
+	a.database().Close()
 }
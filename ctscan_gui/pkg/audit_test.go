@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// auditLine 生成一条记录，所有事件使用同一秒，毫秒与序号相同
+func auditLine(typ string, serial int, body string) string {
+	return fmt.Sprintf("type=%s msg=audit(1717236000.%03d:%d): %s", typ, serial%1000, serial, body)
+}
+
+func auditHex(s string) string {
+	return strings.ToUpper(hex.EncodeToString([]byte(s)))
+}
+
+func TestAuditArgv(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"带引号的参数", `argc=3 a0="ls" a1="-la" a2="/root"`, []string{"ls", "-la", "/root"}},
+		{"含空格的参数十六进制编码", `argc=2 a0="bash" a1=` + auditHex("/tmp/a b.sh"), []string{"bash", "/tmp/a b.sh"}},
+		{"含引号的参数十六进制编码", `argc=3 a0="sh" a1="-c" a2=` + auditHex(`echo "x"`), []string{"sh", "-c", `echo "x"`}},
+		{"长参数拆分为 aN[i]", `argc=2 a0="echo" a1_len=11 a1[0]="hello" a1[1]=` + auditHex(" world"), []string{"echo", "hello world"}},
+		{"空参数", `argc=2 a0="printf" a1=""`, []string{"printf", ""}},
+		{"日志被截断时只保留已有的参数", `argc=4 a0="curl" a1="-o"`, []string{"curl", "-o"}},
+		{"(null) 参数", `argc=1 a0=(null)`, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, rec, ok := parseAuditLine(auditLine("EXECVE", 1, tt.body))
+			if !ok {
+				t.Fatal("无法解析记录")
+			}
+			got := auditArgv(rec)
+			if strings.Join(got, "\x00") != strings.Join(tt.want, "\x00") || len(got) != len(tt.want) {
+				t.Errorf("参数 %q，应为 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAuditLine(t *testing.T) {
+	line := "node=web01 " + auditLine("USER_LOGIN", 42,
+		`pid=100 uid=0 auid=1000 ses=3 msg='op=login acct="alice" exe="/usr/sbin/sshd" hostname=? addr=10.0.0.1 terminal=ssh res=failed'`+
+			"\x1dUID=\"root\" AUID=\"alice\"")
+	node, ts, serial, rec, ok := parseAuditLine(line)
+	if !ok {
+		t.Fatal("无法解析记录")
+	}
+	if node != "web01" || serial != 42 || ts.Unix() != 1717236000 || ts.Nanosecond() != 42000000 || rec.typ != "USER_LOGIN" {
+		t.Errorf("记录头 node=%s serial=%d time=%v type=%s", node, serial, ts, rec.typ)
+	}
+	for key, want := range map[string]string{"acct": "alice", "exe": "/usr/sbin/sshd", "addr": "10.0.0.1", "hostname": "", "res": "failed", "pid": "100"} {
+		if got := rec.str(key); got != want {
+			t.Errorf("%s=%q，应为 %q", key, got, want)
+		}
+	}
+	if rec.enriched["AUID"] != "alice" {
+		t.Errorf("ENRICHED 字段 %v", rec.enriched)
+	}
+	if _, _, _, _, ok := parseAuditLine("type=SYSCALL msg=invalid"); ok {
+		t.Error("格式错误的行不应解析成功")
+	}
+}
+
+func TestReadAuditLogFile(t *testing.T) {
+	syscall := func(serial int, extra string) string {
+		return auditLine("SYSCALL", serial, `arch=c000003e syscall=59 success=yes exit=0 ppid=900 pid=1000 auid=1000 uid=0 tty=pts0 ses=3 comm="x" exe="/usr/bin/x" `+extra)
+	}
+	eoe := func(serial int) string { return auditLine("EOE", serial, "") }
+
+	tests := []struct {
+		name        string
+		lines       []string
+		gz          bool
+		wantSerials []int64 // 事件结束的顺序
+		wantCmds    []string
+		wantEvents  []string // 类型
+		check       func(t *testing.T, l AuditLog)
+	}{
+		{
+			name: "命令执行事件",
+			lines: []string{
+				syscall(100, `key="exec"`),
+				auditLine("EXECVE", 100, `argc=2 a0="bash" a1=`+auditHex("/tmp/a b.sh")),
+				auditLine("CWD", 100, `cwd="/root"`),
+				auditLine("PATH", 100, `item=0 name="/usr/bin/bash" nametype=NORMAL`),
+				eoe(100),
+			},
+			wantSerials: []int64{100},
+			wantCmds:    []string{"bash '/tmp/a b.sh'"},
+			check: func(t *testing.T, l AuditLog) {
+				c := l.Commands[0]
+				if c.User != "alice" || c.AUID != 1000 || c.RunAs != "root" || c.UID != 0 || c.CWD != "/root" ||
+					c.PID != 1000 || c.PPID != 900 || c.Key != "exec" || c.Success != "成功" || c.TTY != "pts0" {
+					t.Errorf("命令记录 %+v", c)
+				}
+			},
+		},
+		{
+			name: "参数分布在多条 EXECVE 记录中",
+			lines: []string{
+				syscall(110, ""),
+				auditLine("EXECVE", 110, `argc=4 a0="tar" a1="czf"`),
+				auditLine("EXECVE", 110, `a2="/tmp/x.tgz" a3_len=10 a3[0]="/etc/" a3[1]="shadow"`),
+				eoe(110),
+			},
+			wantSerials: []int64{110},
+			wantCmds:    []string{"tar czf /tmp/x.tgz /etc/shadow"},
+		},
+		{
+			name: "没有 EXECVE 时使用 PROCTITLE",
+			lines: []string{
+				syscall(120, ""),
+				auditLine("PROCTITLE", 120, "proctitle="+auditHex("python3\x00-c\x00import os")),
+				eoe(120),
+			},
+			wantSerials: []int64{120},
+			wantCmds:    []string{"python3 -c 'import os'"},
+		},
+		{
+			name: "交错写入的事件按 EOE 分组",
+			lines: []string{
+				syscall(200, ""),
+				syscall(201, ""),
+				auditLine("EXECVE", 201, `argc=1 a0="id"`),
+				auditLine("EXECVE", 200, `argc=1 a0="whoami"`),
+				eoe(201),
+				eoe(200),
+			},
+			wantSerials: []int64{201, 200},
+			wantCmds:    []string{"id", "whoami"},
+		},
+		{
+			name: "单条记录的用户空间事件立即结束，不影响未结束的事件",
+			lines: []string{
+				syscall(300, ""),
+				auditLine("USER_LOGIN", 301, `pid=1 uid=0 auid=1000 ses=3 msg='op=login acct="alice" exe="/usr/sbin/sshd" hostname=? addr=10.0.0.1 terminal=ssh res=failed'`),
+				auditLine("EXECVE", 300, `argc=2 a0="cat" a1="/etc/passwd"`),
+				eoe(300),
+			},
+			wantSerials: []int64{301, 300},
+			wantCmds:    []string{"cat /etc/passwd"},
+			wantEvents:  []string{"USER_LOGIN"},
+			check: func(t *testing.T, l AuditLog) {
+				e := l.Events[0]
+				if e.Category != auditCategoryLogin || e.User != "alice" || e.Addr != "10.0.0.1" || e.Result != "失败" || e.Terminal != "ssh" {
+					t.Errorf("登录事件 %+v", e)
+				}
+			},
+		},
+		{
+			name: "没有 EOE 的事件在序号远离后结束",
+			lines: []string{
+				syscall(400, ""),
+				auditLine("EXECVE", 400, `argc=1 a0="first"`),
+				syscall(400+auditSerialWindow+1, ""),
+				auditLine("EXECVE", 400+auditSerialWindow+1, `argc=1 a0="second"`),
+				eoe(400 + auditSerialWindow + 1),
+			},
+			wantSerials: []int64{400, 400 + auditSerialWindow + 1},
+			wantCmds:    []string{"first", "second"},
+		},
+		{
+			name: "文件结束时输出没有 EOE 的事件",
+			lines: []string{
+				syscall(500, ""),
+				auditLine("EXECVE", 500, `argc=1 a0="last"`),
+			},
+			wantSerials: []int64{500},
+			wantCmds:    []string{"last"},
+		},
+		{
+			name: "命中监控规则的文件访问",
+			lines: []string{
+				auditLine("SYSCALL", 600, `arch=c000003e syscall=257 success=no exit=-13 pid=1 auid=1000 uid=1000 comm="vi" exe="/usr/bin/vi" key="passwd_watch"`),
+				auditLine("CWD", 600, `cwd="/etc"`),
+				auditLine("PATH", 600, `item=0 name="/etc/" nametype=PARENT`),
+				auditLine("PATH", 600, `item=1 name="passwd" nametype=NORMAL`),
+				eoe(600),
+				auditLine("SYSCALL", 601, `arch=c000003e syscall=257 success=yes pid=1 auid=1000 uid=1000 comm="vi" exe="/usr/bin/vi" key=(null)`),
+				eoe(601),
+			},
+			wantSerials: []int64{600, 601},
+			wantEvents:  []string{"SYSCALL"},
+			check: func(t *testing.T, l AuditLog) {
+				e := l.Events[0]
+				if e.Syscall != "openat" || e.Path != "/etc/passwd" || e.Result != "失败" || e.Key != "passwd_watch" || e.User != "alice" {
+					t.Errorf("文件监控事件 %+v", e)
+				}
+			},
+		},
+		{
+			name: "gzip 压缩的轮转文件与多主机",
+			lines: []string{
+				"node=a " + syscall(700, ""),
+				"node=b " + syscall(700, ""),
+				"node=a " + auditLine("EXECVE", 700, `argc=1 a0="on-a"`),
+				"node=b " + auditLine("EXECVE", 700, `argc=1 a0="on-b"`),
+				"node=b " + eoe(700),
+				"node=a " + eoe(700),
+			},
+			gz:          true,
+			wantSerials: []int64{700, 700},
+			wantCmds:    []string{"on-b", "on-a"},
+			check: func(t *testing.T, l AuditLog) {
+				if l.Commands[0].Host != "b" || l.Commands[1].Host != "a" {
+					t.Errorf("主机 %s %s", l.Commands[0].Host, l.Commands[1].Host)
+				}
+			},
+		},
+	}
+	users := auditUsers{0: "root", 1000: "alice"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte(strings.Join(tt.lines, "\n") + "\n")
+			name := "audit.log"
+			if tt.gz {
+				var buf bytes.Buffer
+				w := gzip.NewWriter(&buf)
+				w.Write(content)
+				w.Close()
+				content, name = buf.Bytes(), "audit.log.1.gz"
+			}
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			var serials []int64
+			l := AuditLog{}
+			err := readAuditLogFile(context.Background(), path, func(ev *auditEventRecords) {
+				serials = append(serials, ev.serial)
+				l.addEvent(ev, users)
+			})
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if fmt.Sprint(serials) != fmt.Sprint(tt.wantSerials) {
+				t.Errorf("事件结束的顺序 %v，应为 %v", serials, tt.wantSerials)
+			}
+			var cmds, events []string
+			for _, c := range l.Commands {
+				cmds = append(cmds, c.Command)
+				if c.LogFile != path {
+					t.Errorf("命令 %q 的文件为 %s", c.Command, c.LogFile)
+				}
+			}
+			for _, e := range l.Events {
+				events = append(events, e.Type)
+			}
+			if fmt.Sprintf("%q", cmds) != fmt.Sprintf("%q", tt.wantCmds) {
+				t.Errorf("命令 %q，应为 %q", cmds, tt.wantCmds)
+			}
+			if fmt.Sprint(events) != fmt.Sprint(tt.wantEvents) {
+				t.Errorf("事件 %v，应为 %v", events, tt.wantEvents)
+			}
+			if tt.check != nil && !t.Failed() {
+				tt.check(t, l)
+			}
+		})
+	}
+}
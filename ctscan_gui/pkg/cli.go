@@ -0,0 +1,1332 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// cliCommand 表示一个命令行子命令
+type cliCommand struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// cliCommands 所有支持的命令行子命令
+var cliCommands = []cliCommand{
+	{name: "collect", usage: "在无界面模式下运行采集项并写入数据库", run: runCollectCommand},
+	{name: "sessions", usage: "管理扫描会话: [数据库参数] list | rename <id> <案例名> | delete <id>", run: runSessionsCommand},
+	{name: "diff", usage: "对比两个扫描会话: [参数] <基准会话ID> <对比会话ID>", run: runDiffCommand},
+	{name: "report", usage: "为扫描会话生成离线 HTML 报告", run: runReportCommand},
+	{name: "export", usage: "导出扫描会话数据为 json/jsonl/csv/xlsx，EVTX 事件可导出为 Windows 事件 XML 或 ECS NDJSON", run: runExportCommand},
+	{name: "evtx", usage: "导入EVTX文件、目录或 zip 压缩包: [参数] <路径>...，中断后使用 -session 指定同一会话可继续导入，-recover 从损坏的文件中恢复记录", run: runEVTXCommand},
+	{name: "sigma", usage: "对会话中已导入的EVTX事件运行 Sigma 规则: [参数]，-list 列出规则", run: runSigmaCommand},
+	{name: "powershell", usage: "查看从 4104 事件拼接的 PowerShell 脚本块: [参数]，-show <脚本块ID> 输出完整脚本与解码结果", run: runPowerShellCommand},
+	{name: "winevent", usage: "统计会话中的 Windows 安全事件记录: [参数]，-rebuild 从已导入的EVTX事件重新提取", run: runWinEventCommand},
+	{name: "sysmon", usage: "按时间范围还原 Sysmon 进程树: [参数]，-network 输出网络连接与 DNS 查询汇总", run: runSysmonCommand},
+	{name: "timeline", usage: "生成统一时间线并以 plaso l2tcsv 格式导出: [参数]，-build 重新生成", run: runTimelineCommand},
+	{name: "utmp", usage: "解析 wtmp/btmp/utmp/lastlog 并输出登录会话与篡改迹象，不写入数据库: [参数]，-root 指定挂载的磁盘镜像", run: runUtmpCommand},
+	{name: "journal", usage: "不依赖 journalctl 读取 systemd journal 日志: [参数]，-unit/-t/-since/-until/-p/-grep 过滤，-root 指定挂载的磁盘镜像", run: runJournalCommand},
+	{name: "audit", usage: "解析 auditd 日志并输出还原的命令执行记录，不写入数据库: [参数]，-events 输出登录、认证、账户变更与文件监控事件，-root 指定挂载的磁盘镜像", run: runAuditCommand},
+	{name: "weblog", usage: "分析 Web 访问日志中的扫描、注入与 webshell 访问，不写入数据库: [参数] [日志文件...]，不指定文件时自动查找 nginx/Apache/IIS/Tomcat 日志，-top 输出高频来源", run: runWebLogCommand},
+	{name: "webshell", usage: "按静态特征扫描 Web 根目录中的 webshell，不写入数据库: [参数] [目录...]，不指定目录时从 nginx/Apache/IIS 配置与常见位置查找 Web 根目录，-v 输出命中的特征", run: runWebshellCommand},
+	{name: "evidence", usage: "证据包: [参数] pack | verify <证据包> | open <证据包> | log <证据包>", run: runEvidenceCommand},
+}
+
+// IsCLICommand 判断参数是否为命令行子命令，用于区分无界面模式与GUI模式
+func IsCLICommand(name string) bool {
+	if isHelpArg(name) {
+		return true
+	}
+	for _, cmd := range cliCommands {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCLI 执行命令行子命令，返回进程退出码
+func RunCLI(args []string) int {
+	if len(args) == 0 {
+		printCLIUsage()
+		return 2
+	}
+	for _, cmd := range cliCommands {
+		if cmd.name == args[0] {
+			if err := cmd.run(args[1:]); err != nil {
+				if err == flag.ErrHelp {
+					return 0
+				}
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				return 1
+			}
+			return 0
+		}
+	}
+	printCLIUsage()
+	if isHelpArg(args[0]) {
+		return 0
+	}
+	return 2
+}
+
+func isHelpArg(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "--help"
+}
+
+func printCLIUsage() {
+	fmt.Fprintln(os.Stderr, "用法: ctscan <命令> [参数]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "命令:")
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "不带命令运行时启动图形界面")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "数据库参数(所有命令与图形界面通用):")
+	fmt.Fprintln(os.Stderr, "  -db <路径>       数据库文件或目录，也可通过环境变量 "+dbEnvVar+" 指定")
+	fmt.Fprintln(os.Stderr, "  -db-case <案例>  为案例使用单独的数据库文件 ctscan-<案例>.db")
+	fmt.Fprintln(os.Stderr, "  -readonly        以只读方式打开已有数据库")
+}
+
+// selectCollectors 根据 only/skip 列表筛选当前系统支持的采集器
+func selectCollectors(only, skip string) ([]Collector, error) {
+	known := make(map[string]bool)
+	for _, c := range Collectors() {
+		known[c.Name()] = true
+	}
+	onlySet, err := parseNameList(only, known)
+	if err != nil {
+		return nil, err
+	}
+	skipSet, err := parseNameList(skip, known)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []Collector
+	for _, c := range Collectors() {
+		if len(onlySet) > 0 && !onlySet[c.Name()] {
+			continue
+		}
+		if skipSet[c.Name()] || !collectorSupported(c) {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("没有需要运行的采集项")
+	}
+	return selected, nil
+}
+
+// parseNameList 解析逗号分隔的采集项名称，并检查名称是否存在
+func parseNameList(list string, known map[string]bool) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("未知的采集项: %s", name)
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+// collectOutcome 单个采集项在命令行下的运行结果
+type collectOutcome struct {
+	result CollectorResult
+	err    error
+}
+
+func runCollectCommand(args []string) error {
+	fs := flag.NewFlagSet("collect", flag.ContinueOnError)
+	only := fs.String("only", "", "只运行指定的采集项，逗号分隔")
+	skip := fs.String("skip", "", "跳过指定的采集项，逗号分隔")
+	list := fs.Bool("list", false, "列出所有采集项后退出")
+	caseName := fs.String("case", "", "案例名称")
+	analyst := fs.String("analyst", "", "分析人员，默认为当前系统用户")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, c := range Collectors() {
+			platforms := "all"
+			if len(c.Platforms()) > 0 {
+				platforms = strings.Join(c.Platforms(), ",")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name(), c.Title(), platforms)
+		}
+		return w.Flush()
+	}
+
+	if dbOpts.ReadOnly {
+		return errReadOnly
+	}
+	selected, err := selectCollectors(*only, *skip)
+	if err != nil {
+		return err
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	fmt.Fprintf(os.Stderr, "数据库: %s\n", app.dbPath)
+
+	session, err := app.StartScanSession(*caseName, *analyst)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", session.ID)
+
+	// Ctrl+C 取消正在运行的采集项并跳过剩余采集项，已完成的结果仍会保留
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app.onProgress = newStderrProgress()
+	results := make([]collectOutcome, 0, len(selected))
+	for _, c := range selected {
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "已取消，跳过剩余采集项")
+			break
+		}
+		fmt.Fprintf(os.Stderr, "正在采集 %s ...\n", c.Title())
+		result, err := runCollector(ctx, app, c)
+		results = append(results, collectOutcome{result: result, err: err})
+	}
+
+	if err := app.CloseScanSession(); err != nil {
+		return err
+	}
+	return printCollectSummary(results)
+}
+
+func runSessionsCommand(args []string) error {
+	fs := flag.NewFlagSet("sessions", flag.ContinueOnError)
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+
+	switch action {
+	case "list":
+		sessions, err := app.ListScanSessions()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "会话ID\t案例\t分析人员\t主机\t开始时间\t结束时间\t采集项")
+		for _, s := range sessions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.CaseName, s.Analyst, s.Hostname,
+				s.StartTime, s.EndTime, strings.Join(s.Collectors, ","))
+		}
+		return w.Flush()
+	case "rename":
+		if len(args) != 2 {
+			return fmt.Errorf("用法: sessions rename <id> <案例名>")
+		}
+		return app.RenameScanSession(args[0], args[1])
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: sessions delete <id>")
+		}
+		return app.DeleteScanSession(args[0])
+	default:
+		return fmt.Errorf("未知的操作: %s", action)
+	}
+}
+
+func runDiffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	only := fs.String("only", "", "只对比指定的采集项，逗号分隔: "+strings.Join(DiffArtifacts(), ","))
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("用法: diff [参数] <基准会话ID> <对比会话ID>")
+	}
+
+	known := make(map[string]bool)
+	for _, name := range DiffArtifacts() {
+		known[name] = true
+	}
+	onlySet, err := parseNameList(*only, known)
+	if err != nil {
+		return err
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+
+	baseID, err := app.resolveSessionID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	targetID, err := app.resolveSessionID(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	diff, err := app.diffScanSessions(baseID, targetID, onlySet)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	printSnapshotDiff(diff)
+	return nil
+}
+
+func runReportCommand(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	output := fs.String("o", "", "报告保存路径，默认保存在数据库所在目录")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	path, err := app.GenerateReport(id, *output)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+func runExportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	format := fs.String("format", ExportJSONL, "导出格式: "+strings.Join(ExportFormats, "/")+"，"+ExportEVTXXML+"/"+ExportECS+" 只用于 EVTX 事件")
+	only := fs.String("only", "", "只导出指定的采集项或数据表，逗号分隔，默认导出全部")
+	output := fs.String("o", "", "输出路径，xlsx 或单个数据表时为文件，否则为目录；导出 EVTX 事件时 - 为标准输出")
+	start := fs.String("start", "", "EVTX 事件开始时间(UTC)，格式 2006-01-02 15:04:05")
+	end := fs.String("end", "", "EVTX 事件结束时间(UTC)")
+	eventIDs := fs.String("event-ids", "", "EVTX 事件ID，逗号分隔")
+	channel := fs.String("channel", "", "EVTX 事件通道")
+	keyword := fs.String("keyword", "", "在 EVTX 事件的描述、用户数据与提供者中查找")
+	recovered := fs.Bool("recovered", false, "只导出恢复模式中找回的 EVTX 记录")
+	index := fs.String("index", evtxECSIndex, "ECS NDJSON 批量导入的索引名")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	evtxOnly := *format == ExportEVTXXML || *format == ExportECS
+	if !evtxOnly && *output == "-" {
+		return fmt.Errorf("只有导出 EVTX 事件时可以输出到标准输出")
+	}
+
+	var names []string
+	for _, name := range strings.Split(*only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	if evtxOnly {
+		q := EVTXQuery{SessionID: id, Start: *start, End: *end, EventIDs: *eventIDs,
+			Channel: *channel, Keyword: *keyword, Recovered: *recovered}
+		events, err := app.evtxExportEvents(q)
+		if err != nil {
+			return err
+		}
+		path := *output
+		if path == "" {
+			path = filepath.Join(filepath.Dir(app.dbPath), fmt.Sprintf("ctscan-evtx-%s.%s", shortID(id), *format))
+		}
+		if err := writeEVTXEvents(path, events, *format, *index); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "已导出 %d 个事件\n", events.count)
+		if path != "-" {
+			fmt.Println(path)
+		}
+		return nil
+	}
+	paths, err := app.ExportSession(id, names, *format, *output)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func runEVTXCommand(args []string) error {
+	fs := flag.NewFlagSet("evtx", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "导入到已有会话(ID或前缀)，默认新建会话")
+	caseName := fs.String("case", "", "新建会话时的案例名称")
+	analyst := fs.String("analyst", "", "新建会话时的分析人员，默认为当前系统用户")
+	workers := fs.Int("workers", evtxImportWorkers(), "同时解析的文件数")
+	rulesDir := fs.String("rules", "", "Sigma 规则目录，与内置规则一起使用，默认读取配置文件")
+	recovery := fs.Bool("recover", false, "恢复模式：查找块与记录签名，找回空闲空间与损坏块中的记录，可导入任意扩展名的磁盘片段")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("用法: evtx [参数] <文件|目录|zip>...")
+	}
+	if dbOpts.ReadOnly {
+		return errReadOnly
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+
+	var session ScanSession
+	if *sessionID != "" {
+		id, err := app.resolveSessionID(*sessionID)
+		if err != nil {
+			return err
+		}
+		session, err = app.OpenScanSession(id)
+		if err != nil {
+			return err
+		}
+	} else {
+		session, err = app.StartScanSession(*caseName, *analyst)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", session.ID)
+
+	if _, err := app.sessionFor("evtx"); err != nil {
+		return err
+	}
+
+	rules, ruleErrs := loadSigmaRules(sigmaRulesDir(*rulesDir))
+	printSigmaRuleErrors(ruleErrs)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app.onProgress = newStderrProgress()
+	title := "导入EVTX文件"
+	if *recovery {
+		title = "恢复EVTX记录"
+	}
+	task, done := app.startTask(ctx, "evtx", title)
+	files, importErr := app.importEVTXPaths(task, session.ID, fs.Args(), *workers, rules, *recovery)
+	done(importErr)
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(files) > 0 {
+		fmt.Fprintln(w, "状态\t事件\t块\t损坏块\t计算机\tSHA-256\t文件\t错误")
+	}
+	for _, f := range files {
+		if f.Status == EVTXFileFailed {
+			failed++
+		}
+		hash := f.SHA256
+		if len(hash) > 16 {
+			hash = hash[:16]
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d/%d\t%d\t%s\t%s\t%s\t%s\n", f.Status, f.Events, f.ChunksDone, f.Chunks,
+			f.CorruptChunks, f.Computer, hash, f.Path, f.Error)
+	}
+	w.Flush()
+	if *recovery {
+		var recovered int
+		if err := app.db.QueryRow(`SELECT COUNT(*) FROM evtx_event WHERE session_id = ? AND recovered = 1`,
+			session.ID).Scan(&recovered); err == nil {
+			fmt.Fprintf(os.Stderr, "会话中共有 %d 条正常解析无法得到的恢复记录\n", recovered)
+		}
+	}
+
+	// 只有新建的会话才记录结束时间
+	if *sessionID == "" {
+		if err := app.CloseScanSession(); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("已取消，使用 -session %s 重新运行可继续导入", session.ID)
+	}
+	if importErr != nil && len(files) == 0 {
+		return importErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件导入失败", failed)
+	}
+	return nil
+}
+
+func runSigmaCommand(args []string) error {
+	fs := flag.NewFlagSet("sigma", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	rulesDir := fs.String("rules", "", "Sigma 规则目录，与内置规则一起使用，默认读取配置文件")
+	list := fs.Bool("list", false, "只列出已加载的规则")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rules, ruleErrs := loadSigmaRules(sigmaRulesDir(*rulesDir))
+	printSigmaRuleErrors(ruleErrs)
+	if *list {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "级别\t标题\t日志\t标签\t文件")
+		for _, r := range sigmaRuleInfos(rules) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Level, r.Title, r.Logsource, strings.Join(r.Tags, ","), r.File)
+		}
+		return w.Flush()
+	}
+	if dbOpts.ReadOnly {
+		return errReadOnly
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", id)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app.onProgress = newStderrProgress()
+	task, done := app.startTask(ctx, "sigma", "运行Sigma规则")
+	result, err := app.runSigmaRules(task, id, rules)
+	done(err)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d 条规则，%d 个事件，%d 条命中\n", result.Rules, result.Events, result.Hits)
+
+	summaries, err := app.GetSigmaHitSummary(id)
+	if err != nil {
+		return err
+	}
+	if len(summaries) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "级别\t命中\t主机\t首次(UTC)\t最近(UTC)\t规则\t标签")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", s.Level, s.Hits, s.Computers, s.FirstSeen, s.LastSeen,
+			s.Title, strings.Join(s.Tags, ","))
+	}
+	return w.Flush()
+}
+
+func runWinEventCommand(args []string) error {
+	fs := flag.NewFlagSet("winevent", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	rebuild := fs.Bool("rebuild", false, "从已导入的EVTX事件重新提取，用于升级前导入的会话")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rebuild && dbOpts.ReadOnly {
+		return errReadOnly
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", id)
+
+	if *rebuild {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		app.onProgress = newStderrProgress()
+		task, done := app.startTask(ctx, "winevent", "提取Windows安全事件")
+		result, err := app.rebuildWinEvents(task, id)
+		done(err)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d 个事件\n", result.Events)
+	}
+
+	counts, err := app.winEventCounts(id)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "记录\t数量\t数据表")
+	for _, c := range counts {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", c.Title, c.Count, c.Table)
+	}
+	return w.Flush()
+}
+
+func runPowerShellCommand(args []string) error {
+	fs := flag.NewFlagSet("powershell", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	all := fs.Bool("all", false, "列出所有脚本块，默认只列出命中可疑关键字的脚本块")
+	show := fs.String("show", "", "输出指定脚本块(ID或前缀)的完整脚本、解码结果与来源事件")
+	rebuild := fs.Bool("rebuild", false, "从已导入的EVTX事件重新拼接脚本块")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rebuild && dbOpts.ReadOnly {
+		return errReadOnly
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", id)
+
+	if *rebuild {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		app.onProgress = newStderrProgress()
+		task, done := app.startTask(ctx, "powershell", "拼接PowerShell脚本块")
+		result, err := app.rebuildScriptBlocks(task, id)
+		done(err)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d 个事件，%d 个脚本块，%d 个可疑，%d 个不完整\n",
+			result.Events, result.Blocks, result.Suspicious, result.Incomplete)
+	}
+
+	var blocks []ScriptBlock
+	q := ScriptBlockQuery{SessionID: id, Suspicious: !*all && *show == "", PageSize: evtxMaxPageSize}
+	for q.Page = 1; ; q.Page++ {
+		page, err := app.QueryScriptBlocks(q)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, page.Blocks...)
+		if len(blocks) >= page.Total || len(page.Blocks) == 0 {
+			break
+		}
+	}
+
+	if *show != "" {
+		prefix := strings.ToLower(*show)
+		for _, b := range blocks {
+			if !strings.HasPrefix(b.ScriptBlockID, prefix) {
+				continue
+			}
+			fmt.Printf("# 脚本块 %s  计算机 %s  分段 %d/%d  分值 %d\n", b.ScriptBlockID, b.Computer, b.MessageCount, b.MessageTotal, b.Score)
+			for _, p := range b.Parts {
+				fmt.Printf("#   %s  记录 %d  分段 %d  %s\n", p.Time, p.RecordID, p.MessageNumber, p.SourceFile)
+			}
+			for _, ind := range b.Indicators {
+				fmt.Printf("# 可疑: %s\n", ind)
+			}
+			fmt.Println(b.Script)
+			if b.Decoded != "" {
+				fmt.Println()
+				fmt.Println(b.Decoded)
+			}
+			return nil
+		}
+		return fmt.Errorf("脚本块不存在: %s", *show)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "分值\t首次(UTC)\t计算机\t分段\t脚本块ID\t可疑特征")
+	for _, b := range blocks {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%s\n", b.Score, b.FirstTime, b.Computer, b.MessageCount, b.MessageTotal,
+			b.ScriptBlockID, strings.Join(b.Indicators, "; "))
+	}
+	return w.Flush()
+}
+
+func runSysmonCommand(args []string) error {
+	fs := flag.NewFlagSet("sysmon", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	start := fs.String("start", "", "开始时间(UTC)，格式 2006-01-02 15:04:05")
+	end := fs.String("end", "", "结束时间(UTC)，格式 2006-01-02 15:04:05")
+	computer := fs.String("computer", "", "只查看指定计算机")
+	network := fs.Bool("network", false, "输出网络连接与 DNS 查询汇总，而不是进程树")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", id)
+	q := WinEventQuery{SessionID: id, Start: *start, End: *end, Computer: *computer}
+
+	if *network {
+		activity, err := app.GetSysmonNetworkActivity(q)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "次数\t首次(UTC)\t最近(UTC)\t进程\t协议\t目标\t主机名")
+		for _, c := range activity.Connections {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Count, c.FirstSeen, c.LastSeen, c.Image, c.Protocol,
+				net.JoinHostPort(c.DestinationIP, strconv.FormatInt(c.DestinationPort, 10)), c.DestinationHostname)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "次数\t首次(UTC)\t最近(UTC)\t域名\t结果\t进程")
+		for _, d := range activity.DNS {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", d.Count, d.FirstSeen, d.LastSeen, d.QueryName, d.Results,
+				strings.Join(d.Images, ", "))
+		}
+		return w.Flush()
+	}
+
+	tree, err := app.GetSysmonProcessTree(q)
+	if err != nil {
+		return err
+	}
+	if tree.Truncated {
+		fmt.Fprintf(os.Stderr, "共 %d 个进程，只显示最早的 %d 个\n", tree.Processes, sysmonTreeLimit)
+	}
+	var walk func(n *SysmonProcessNode, depth int)
+	walk = func(n *SysmonProcessNode, depth int) {
+		created, note := n.Time, ""
+		if created == "" {
+			created = "-"
+		}
+		switch {
+		case n.Inferred:
+			note = " [推断]"
+		case n.OutOfRange:
+			note = " [范围外]"
+		}
+		fmt.Printf("%s%s  %s  pid=%d  %s%s", strings.Repeat("  ", depth), created, n.Image, n.ProcessID, n.CommandLine, note)
+		if n.Connections+n.DNSQueries+n.FileCreates > 0 {
+			fmt.Printf("  (连接 %d，DNS %d，文件 %d)", n.Connections, n.DNSQueries, n.FileCreates)
+		}
+		fmt.Println()
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	for _, n := range tree.Roots {
+		walk(n, 0)
+	}
+	return nil
+}
+
+func runTimelineCommand(args []string) error {
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "会话ID或前缀，默认为最近一次会话")
+	build := fs.Bool("build", false, "从会话中的采集结果与导入的日志重新生成时间线")
+	start := fs.String("start", "", "开始时间(UTC)，格式 2006-01-02 15:04:05")
+	end := fs.String("end", "", "结束时间(UTC)，格式 2006-01-02 15:04:05")
+	sources := fs.String("source", "", "只导出指定来源，多个来源以逗号分隔，如 EVTX,LOGIN")
+	keyword := fs.String("keyword", "", "只导出描述、用户或文件名中包含关键字的事件")
+	output := fs.String("o", "", "l2tcsv 保存路径，默认输出到标准输出")
+	dbOpts := addDBFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *build && dbOpts.ReadOnly {
+		return errReadOnly
+	}
+
+	app, err := NewApp(*dbOpts)
+	if err != nil {
+		return fmt.Errorf("初始化应用失败: %v", err)
+	}
+	defer app.db.Close()
+	id, err := app.resolveSessionID(*sessionID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "扫描会话: %s\n", id)
+
+	if *build {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		app.onProgress = newStderrProgress()
+		task, done := app.startTask(ctx, "timeline", "生成时间线")
+		result, err := app.buildTimeline(task, id)
+		done(err)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d 个事件，%d 条记录没有可识别的时间\n", result.Events, result.Skipped)
+		for _, c := range result.Counts {
+			fmt.Fprintf(os.Stderr, "  %s\t%d\n", c.Source, c.Count)
+		}
+	}
+
+	q := TimelineQuery{SessionID: id, Start: *start, End: *end, Keyword: *keyword}
+	for _, s := range strings.Split(*sources, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			q.Sources = append(q.Sources, strings.ToUpper(s))
+		}
+	}
+	if *output == "" {
+		where, args, err := q.where(id)
+		if err != nil {
+			return err
+		}
+		events, err := app.queryTimelineEvents(where+` ORDER BY time, id`, args...)
+		if err != nil {
+			return err
+		}
+		return writeL2TCSV(os.Stdout, events)
+	}
+	n, err := app.exportTimeline(q, *output)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "已导出 %d 个事件: %s\n", n, *output)
+	return nil
+}
+
+// printSigmaRuleErrors 输出无法加载的规则，不影响其他规则的运行
+func printSigmaRuleErrors(errs []SigmaRuleError) {
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "跳过规则 %s: %s\n", e.File, e.Error)
+	}
+}
+
+// resolveSessionID 支持使用会话ID前缀指定会话，为空时返回最近一次会话
+func (a *App) resolveSessionID(prefix string) (string, error) {
+	sessions, err := a.ListScanSessions()
+	if err != nil {
+		return "", err
+	}
+	if prefix == "" {
+		if len(sessions) == 0 {
+			return "", fmt.Errorf("数据库中没有扫描会话")
+		}
+		return sessions[0].ID, nil
+	}
+	var matched []string
+	for _, s := range sessions {
+		if s.ID == prefix {
+			return s.ID, nil
+		}
+		if strings.HasPrefix(s.ID, prefix) {
+			matched = append(matched, s.ID)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", fmt.Errorf("扫描会话不存在: %s", prefix)
+	case 1:
+		return matched[0], nil
+	default:
+		return "", fmt.Errorf("会话ID前缀 %s 匹配到多个会话，请输入更长的前缀", prefix)
+	}
+}
+
+// printSnapshotDiff 以文本形式输出对比结果，+ 新增，- 删除，~ 变更
+func printSnapshotDiff(diff SnapshotDiff) {
+	fmt.Printf("基准: %s  %s  %s\n", diff.Base.ID, diff.Base.Hostname, diff.Base.StartTime)
+	fmt.Printf("对比: %s  %s  %s\n", diff.Target.ID, diff.Target.Hostname, diff.Target.StartTime)
+	if !diff.SameHost {
+		fmt.Println("注意: 两个会话的主机指纹不同，可能不是同一台主机")
+	}
+	for _, artifact := range diff.Artifacts {
+		fmt.Println()
+		if artifact.Skipped != "" {
+			fmt.Printf("== %s: 跳过，%s\n", artifact.Title, artifact.Skipped)
+			continue
+		}
+		fmt.Printf("== %s: 新增 %d，删除 %d，变更 %d\n", artifact.Title, artifact.Added, artifact.Removed, artifact.Changed)
+		for _, entry := range artifact.Entries {
+			switch entry.Status {
+			case DiffAdded:
+				fmt.Printf("+ %s\n", entry.Key)
+			case DiffRemoved:
+				fmt.Printf("- %s\n", entry.Key)
+			case DiffChanged:
+				fmt.Printf("~ %s\n", entry.Key)
+				for _, c := range entry.Changes {
+					fmt.Printf("    %s: %s -> %s\n", c.Field, c.Before, c.After)
+				}
+			}
+		}
+	}
+}
+
+func runUtmpCommand(args []string) error {
+	fs := flag.NewFlagSet("utmp", flag.ContinueOnError)
+	root := fs.String("root", "/", "根目录，分析挂载的磁盘镜像时指定挂载点")
+	failed := fs.Bool("failed", false, "输出 btmp 中的登录失败记录")
+	lastlog := fs.Bool("lastlog", false, "输出 lastlog 中每个用户的最后一次登录")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// 不打开数据库，只借用任务的进度输出
+	app := &App{onProgress: newStderrProgress()}
+	ctx, done := app.startTask(ctx, "login-sessions", "登录会话")
+	logs, err := readLoginLogs(ctx, *root)
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch {
+	case *failed:
+		fmt.Fprintln(w, "时间\t用户\t终端\t来源")
+		for _, r := range logs.Failed {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Time, r.User, r.TTY, r.Host)
+		}
+	case *lastlog:
+		fmt.Fprintln(w, "UID\t用户\t时间\t终端\t来源")
+		for _, l := range logs.Lastlog {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", l.UID, l.User, l.Time, l.TTY, l.Host)
+		}
+	default:
+		fmt.Fprintln(w, "用户\t终端\t来源\t登录时间\t登出时间\t时长\t状态")
+		for _, s := range logs.Sessions {
+			duration := ""
+			if s.LogoutTime != "" {
+				duration = (time.Duration(s.Duration) * time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.User, s.TTY, s.Host, s.LoginTime, s.LogoutTime, duration, s.Status)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, an := range logs.Anomalies {
+		fmt.Fprintf(os.Stderr, "[%s] %s@%d: %s\n", an.Kind, an.LogFile, an.Offset, an.Detail)
+	}
+	return nil
+}
+
+func runAuditCommand(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	root := fs.String("root", "/", "根目录，分析挂载的磁盘镜像时指定挂载点")
+	events := fs.Bool("events", false, "输出登录、认证、账户变更与监控文件访问事件")
+	user := fs.String("user", "", "只输出该登录用户(auid)的记录")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app := &App{onProgress: newStderrProgress()}
+	ctx, done := app.startTask(ctx, "audit", "审计日志")
+	l, err := readAuditLog(ctx, *root)
+	done(err)
+	if err != nil {
+		return err
+	}
+	if len(l.Files) == 0 {
+		return fmt.Errorf("没有找到 auditd 日志: %s", filepath.Join(*root, "var/log/audit"))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *events {
+		fmt.Fprintln(w, "时间\t分类\t类型\t用户\t登录用户\t程序\t地址\t结果\t规则\t文件\t说明")
+		for _, e := range l.Events {
+			if *user != "" && e.Operator != *user {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				e.Time, e.Category, e.Type, e.User, e.Operator, e.Exe, e.Addr, e.Result, e.Key, e.Path, e.Detail)
+		}
+	} else {
+		fmt.Fprintln(w, "时间\t登录用户\t执行身份\t终端\t目录\t结果\t命令")
+		for _, c := range l.Commands {
+			if *user != "" && c.User != *user {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Time, c.User, c.RunAs, c.TTY, c.CWD, c.Success, c.Command)
+		}
+	}
+	return w.Flush()
+}
+
+func runWebLogCommand(args []string) error {
+	fs := flag.NewFlagSet("weblog", flag.ContinueOnError)
+	root := fs.String("root", "", "根目录，分析挂载的磁盘镜像时指定挂载点，默认为本机")
+	top := fs.Bool("top", false, "输出请求数最多的来源 IP")
+	level := fs.String("level", "", "只输出该级别及更严重的命中: high | medium | low")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, ok := sigmaLevelRank[*level]; *level != "" && !ok {
+		return fmt.Errorf("不支持的级别: %s", *level)
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = webLogFiles(firstNonEmpty(*root, webLogRoot()))
+		if len(files) == 0 {
+			return fmt.Errorf("没有找到 Web 访问日志，可以直接指定日志文件")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app := &App{onProgress: newStderrProgress()}
+	ctx, done := app.startTask(ctx, "weblog", "Web访问日志")
+	result, err := analyzeWebLogs(ctx, files)
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *top {
+		fmt.Fprintln(w, "IP\t请求数\t错误数\tPOST数\t路径数\t首次\t末次\tUser-Agent")
+		for _, t := range result.Talkers {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n", t.IP, t.Requests, t.Errors, t.Posts, t.Paths, t.FirstTime, t.LastTime, t.UserAgent)
+		}
+	} else {
+		fmt.Fprintln(w, "级别\t分类\t规则\t来源\t次数\t2xx\t首次\t末次\t请求")
+		for _, f := range result.Findings {
+			if *level != "" && sigmaLevelRank[f.Level] > sigmaLevelRank[*level] {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+				f.Level, f.Category, f.Rule, f.IP, f.Count, f.OKCount, f.FirstTime, f.LastTime, truncateRunes(f.Sample, 120))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d 个日志文件，%d 条请求，%d 条命中\n", len(result.Files), result.Requests, len(result.Findings))
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "命中过多，只保留了前 %d 条\n", webLogMaxFindings)
+	}
+	return nil
+}
+
+// runWebshellCommand 扫描 Web 根目录中的可疑脚本，按得分从高到低输出
+func runWebshellCommand(args []string) error {
+	fs := flag.NewFlagSet("webshell", flag.ContinueOnError)
+	root := fs.String("root", "", "根目录，分析挂载的磁盘镜像时指定挂载点，默认为本机")
+	minScore := fs.Int("min", webshellMinScore, "只输出得分不低于该值的文件")
+	verbose := fs.Bool("v", false, "输出命中的特征与 SHA256")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app := &App{onProgress: newStderrProgress()}
+	ctx, done := app.startTask(ctx, "webshell", "Webshell扫描")
+	result, err := scanWebshells(ctx, firstNonEmpty(*root, webLogRoot()), fs.Args())
+	done(err)
+	if err != nil {
+		return err
+	}
+	if len(result.Roots) == 0 {
+		return fmt.Errorf("没有找到 Web 根目录，可以直接指定目录")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "得分\t级别\t家族\t修改时间\t元数据变更时间\tMD5\t文件")
+	found := 0
+	for _, f := range result.Files {
+		if f.Score < *minScore {
+			continue
+		}
+		found++
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", f.Score, f.Level, firstNonEmpty(f.Family, "-"), f.ModTime, firstNonEmpty(f.ChangeTime, "-"), f.MD5, f.Path)
+		if *verbose {
+			fmt.Fprintf(w, "\t\t\t\t\t\t  SHA256 %s\n", f.SHA256)
+			fmt.Fprintf(w, "\t\t\t\t\t\t  %s\n", strings.Join(f.Indicators, "、"))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Web 根目录: %s\n扫描 %d 个文件，%d 个可疑\n", strings.Join(result.Roots, ", "), result.Scanned, found)
+	return nil
+}
+
+func runJournalCommand(args []string) error {
+	fs := flag.NewFlagSet("journal", flag.ContinueOnError)
+	root := fs.String("root", "/", "根目录，分析挂载的磁盘镜像时指定挂载点")
+	var q JournalQuery
+	fs.StringVar(&q.Unit, "unit", "", "只显示该 systemd 单元的记录，如 ssh.service")
+	fs.StringVar(&q.Identifier, "t", "", "只显示该程序(SYSLOG_IDENTIFIER)的记录")
+	fs.StringVar(&q.Start, "since", "", "开始时间(本地时间)，格式 2006-01-02 15:04:05")
+	fs.StringVar(&q.End, "until", "", "结束时间(本地时间)")
+	fs.StringVar(&q.Priority, "p", "", "只显示该级别(0-7)及更严重的记录")
+	fs.StringVar(&q.Keyword, "grep", "", "消息中包含的关键字，不区分大小写")
+	limit := fs.Int("n", 0, "只输出最新的 n 条记录，0 为全部")
+	format := fs.String("o", "short", "输出格式: short | json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "short" && *format != "json" {
+		return fmt.Errorf("不支持的输出格式: %s", *format)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app := &App{onProgress: newStderrProgress()}
+	ctx, done := app.startTask(ctx, "journal", "读取journal日志")
+	result, records, err := queryJournal(ctx, *root, q)
+	done(err)
+	if err != nil {
+		return err
+	}
+	if result.Files == 0 {
+		return fmt.Errorf("未找到 journal 日志文件: %s", filepath.Join(*root, journalDirs[0]))
+	}
+	if *limit > 0 && len(records) > *limit {
+		records = records[:*limit]
+	}
+
+	// 与 journalctl 相同，按时间从旧到新输出
+	w := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(w)
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if *format == "json" {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+			continue
+		}
+		process := r.Identifier
+		if r.PID > 0 {
+			process = fmt.Sprintf("%s[%d]", r.Identifier, r.PID)
+		}
+		fmt.Fprintf(w, "%s %s %s: %s\n", r.Time[:19], r.Hostname, process, r.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "匹配的记录超过 %d 条，只输出最新的部分\n", journalQueryLimit)
+	}
+	return nil
+}
+
+func runEvidenceCommand(args []string) error {
+	fs := flag.NewFlagSet("evidence", flag.ContinueOnError)
+	sessionID := fs.String("session", "", "pack: 会话ID或前缀，默认为最近一次会话")
+	output := fs.String("o", "", "pack: 证据包保存路径; open: 解压目录")
+	operator := fs.String("operator", "", "操作人员，记录到保管记录中，默认为当前系统用户")
+	note := fs.String("note", "", "pack: 备注，例如移交对象或原因")
+	maxSize := fs.Int64("max-size", maxEvidenceFileSize>>20, "pack: 单个原始文件的大小上限(MB)，0 表示不限制，事件日志不受限制")
+	dbOpts := addDBFlags(fs)
+	// 操作名既可以写在参数前也可以写在参数后
+	action := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if action == "" && len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+	if action == "" {
+		return fmt.Errorf("用法: evidence [参数] pack | verify <证据包> | open <证据包> | log <证据包>")
+	}
+	if action != "pack" && len(args) != 1 {
+		return fmt.Errorf("用法: evidence [参数] %s <证据包>", action)
+	}
+
+	switch action {
+	case "pack":
+		app, err := NewApp(*dbOpts)
+		if err != nil {
+			return fmt.Errorf("初始化应用失败: %v", err)
+		}
+		defer app.db.Close()
+		app.evidenceMaxSize = *maxSize << 20
+		if *maxSize <= 0 {
+			app.evidenceMaxSize = -1
+		}
+
+		id, err := app.resolveSessionID(*sessionID)
+		if err != nil {
+			return err
+		}
+		ep, err := app.PackageEvidence(id, *output, *operator, *note)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("证据包: %s\nSHA-256: %s\n保管记录: %s\n文件数: %d\n",
+			ep.Path, ep.SHA256, ep.LogPath, len(ep.Manifest.Files))
+		printEvidenceSkipped(ep.Manifest.Skipped)
+		return nil
+	case "verify":
+		result, err := verifyEvidence(args[0], *operator)
+		if err != nil {
+			return err
+		}
+		return printEvidenceVerify(result)
+	case "open":
+		dbPath, err := openEvidence(args[0], *output, *operator)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("数据库: %s\n可使用 -db %s -readonly 查看\n", dbPath, dbPath)
+		return nil
+	case "log":
+		entries, err := readCustody(custodyLogPath(args[0]))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "时间\t操作\t操作人员\t主机\t说明")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time, e.Action, e.Operator, e.Hostname, e.Detail)
+		}
+		if ferr := w.Flush(); err == nil {
+			err = ferr
+		}
+		return err
+	default:
+		return fmt.Errorf("未知的操作: %s", action)
+	}
+}
+
+// printEvidenceVerify 输出证据包校验结果，未通过时返回错误
+func printEvidenceVerify(result EvidenceVerifyResult) error {
+	m := result.Manifest
+	fmt.Printf("证据包: %s\nSHA-256: %s\n", result.Path, result.SHA256)
+	fmt.Printf("创建时间: %s  操作人员: %s  主机: %s\n", m.CreatedAt, m.Operator, m.Host.Hostname)
+	fmt.Printf("会话: %s  案例: %s\n", m.Session.ID, m.Session.CaseName)
+	bad := 0
+	for _, f := range result.Files {
+		if f.Status != "ok" {
+			bad++
+			fmt.Printf("  [%s] %s\n", f.Status, f.Path)
+		}
+	}
+	fmt.Printf("文件: %d 个，异常 %d 个\n", len(result.Files), bad)
+	printEvidenceSkipped(m.Skipped)
+	custody := "完整"
+	if !result.CustodyOK {
+		custody = "异常"
+	}
+	fmt.Printf("保管记录: %d 条，%s\n", len(result.Custody), custody)
+	for _, e := range result.Errors {
+		fmt.Printf("  %s\n", e)
+	}
+	if !result.OK {
+		return fmt.Errorf("校验未通过")
+	}
+	fmt.Println("校验通过")
+	return nil
+}
+
+// printEvidenceSkipped 输出没有打包的原始文件
+func printEvidenceSkipped(skipped []EvidenceSkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Printf("跳过 %d 个文件:\n", len(skipped))
+	for _, s := range skipped {
+		fmt.Printf("  %s (%d 字节): %s\n", s.Source, s.Size, s.Reason)
+	}
+}
+
+// printCollectSummary 输出采集汇总，有采集项失败时返回错误
+func printCollectSummary(results []collectOutcome) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "采集项\t记录数\t耗时\t状态")
+	failed := 0
+	for _, r := range results {
+		status := "成功"
+		if errors.Is(r.err, context.Canceled) {
+			status = "已取消"
+			failed++
+		} else if r.err != nil {
+			status = "失败: " + r.err.Error()
+			failed++
+		}
+		duration := time.Duration(r.result.Duration) * time.Millisecond
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.result.Title, r.result.Count, duration, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个采集项失败或已取消", failed)
+	}
+	return nil
+}
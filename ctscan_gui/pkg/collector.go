@@ -0,0 +1,227 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Collector 采集器接口，每一种取证数据对应一个采集器
+// 新增数据类型时只需实现该接口并在 init 中调用 RegisterCollector
+type Collector interface {
+	// Name 采集器唯一名称，用于命令行参数与前端调用
+	Name() string
+	// Title 采集器显示名称
+	Title() string
+	// Platforms 支持的操作系统(GOOS)，为空表示支持所有系统
+	Platforms() []string
+	// Schema 采集结果对应的建表语句，需使用 CREATE TABLE IF NOT EXISTS
+	// 已发布的表结构发生变化时，还需要在 migrations 中追加升级步骤
+	Schema() []string
+	// Collect 执行采集，返回带类型的采集结果
+	Collect(ctx context.Context, a *App) (Records, error)
+}
+
+// Records 采集器返回的结果集
+type Records interface {
+	// Len 记录条数
+	Len() int
+	// Items 原始记录，用于返回给前端
+	Items() any
+	// Save 将记录保存到数据库
+	Save(a *App) error
+}
+
+// CollectorInfo 采集器描述信息，供前端在运行时展示
+type CollectorInfo struct {
+	Name      string   `json:"name"`
+	Title     string   `json:"title"`
+	Platforms []string `json:"platforms"`
+	Supported bool     `json:"supported"`
+}
+
+// CollectorResult 单个采集器的运行结果
+type CollectorResult struct {
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Count    int    `json:"count"`
+	Duration int64  `json:"duration"` // 耗时，毫秒
+	Records  any    `json:"records"`
+}
+
+var (
+	collectorsMu    sync.RWMutex
+	collectors      = make(map[string]Collector)
+	collectorsOrder []string
+)
+
+// RegisterCollector 注册采集器，名称重复时 panic
+func RegisterCollector(c Collector) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	if c == nil {
+		panic("pkg: RegisterCollector collector is nil")
+	}
+	if _, dup := collectors[c.Name()]; dup {
+		panic("pkg: RegisterCollector called twice for collector " + c.Name())
+	}
+	collectors[c.Name()] = c
+	collectorsOrder = append(collectorsOrder, c.Name())
+}
+
+// Collectors 按注册顺序返回所有采集器
+func Collectors() []Collector {
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	list := make([]Collector, 0, len(collectorsOrder))
+	for _, name := range collectorsOrder {
+		list = append(list, collectors[name])
+	}
+	return list
+}
+
+// LookupCollector 按名称查找采集器
+func LookupCollector(name string) (Collector, bool) {
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	c, ok := collectors[name]
+	return c, ok
+}
+
+// collectorSupported 判断采集器是否支持当前操作系统
+func collectorSupported(c Collector) bool {
+	platforms := c.Platforms()
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, goos := range platforms {
+		if goos == runtime.GOOS {
+			return true
+		}
+	}
+	return false
+}
+
+// runCollector 运行采集器并保存结果
+func runCollector(ctx context.Context, a *App, c Collector) (CollectorResult, error) {
+	result := CollectorResult{Name: c.Name(), Title: c.Title()}
+	if !collectorSupported(c) {
+		return result, fmt.Errorf("采集器 %s 不支持当前系统: %s", c.Name(), runtime.GOOS)
+	}
+
+	ctx, done := a.startTask(ctx, c.Name(), c.Title())
+	start := time.Now()
+	records, err := c.Collect(ctx, a)
+	if err != nil {
+		// 取消时不保存部分结果，避免会话中出现不完整的数据
+		result.Duration = time.Since(start).Milliseconds()
+		done(err)
+		return result, err
+	}
+	// 没有结果也要记入会话，对比会话时才能区分"未采集"与"已清空"
+	if _, err := a.sessionFor(c.Name()); err != nil {
+		result.Duration = time.Since(start).Milliseconds()
+		done(err)
+		return result, err
+	}
+	err = records.Save(a)
+	result.Duration = time.Since(start).Milliseconds()
+	result.Count = records.Len()
+	result.Records = records.Items()
+	done(err)
+	return result, err
+}
+
+// ListCollectors 列出所有已注册的采集器
+func (a *App) ListCollectors() []CollectorInfo {
+	list := Collectors()
+	infos := make([]CollectorInfo, 0, len(list))
+	for _, c := range list {
+		infos = append(infos, CollectorInfo{
+			Name:      c.Name(),
+			Title:     c.Title(),
+			Platforms: c.Platforms(),
+			Supported: collectorSupported(c),
+		})
+	}
+	return infos
+}
+
+// RunCollector 按名称运行采集器，采集结果会同时写入数据库
+// 运行期间推送 progress 事件，可通过 CancelTask(name) 取消
+func (a *App) RunCollector(name string) (CollectorResult, error) {
+	c, ok := LookupCollector(name)
+	if !ok {
+		return CollectorResult{}, fmt.Errorf("未知的采集器: %s", name)
+	}
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := runCollector(ctx, a, c)
+	return result, taskError(err)
+}
+
+// sliceCollector 基于切片结果的通用采集器实现
+type sliceCollector[T any] struct {
+	name      string
+	title     string
+	platforms []string
+	schema    []string
+	collect   func(ctx context.Context, a *App) ([]T, error)
+	save      func(a *App, items []T) error
+	// count 记录条数，为空时为切片长度；结果为单个汇总结构时使用 sumRecords 统计其中的实际行数
+	count func(items []T) int
+}
+
+func (c *sliceCollector[T]) Name() string        { return c.name }
+func (c *sliceCollector[T]) Title() string       { return c.title }
+func (c *sliceCollector[T]) Platforms() []string { return c.platforms }
+func (c *sliceCollector[T]) Schema() []string    { return c.schema }
+
+func (c *sliceCollector[T]) Collect(ctx context.Context, a *App) (Records, error) {
+	items, err := c.collect(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return &sliceRecords[T]{items: items, save: c.save, count: c.count}, nil
+}
+
+// sliceRecords 切片结果集
+type sliceRecords[T any] struct {
+	items []T
+	save  func(a *App, items []T) error
+	count func(items []T) int
+}
+
+func (r *sliceRecords[T]) Items() any { return r.items }
+
+func (r *sliceRecords[T]) Len() int {
+	if r.count != nil {
+		return r.count(r.items)
+	}
+	return len(r.items)
+}
+
+func (r *sliceRecords[T]) Save(a *App) error {
+	if r.save == nil || len(r.items) == 0 {
+		return nil
+	}
+	return r.save(a, r.items)
+}
+
+// recordSet 包含多种记录的汇总结果，如登录会话、登录失败与 lastlog
+type recordSet interface {
+	recordCount() int
+}
+
+// sumRecords 汇总结果中各类记录的总条数
+func sumRecords[T recordSet](items []T) int {
+	n := 0
+	for _, item := range items {
+		n += item.recordCount()
+	}
+	return n
+}
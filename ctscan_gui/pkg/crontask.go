@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
@@ -119,3 +120,24 @@ func (a *App) getUnixTasks() []CronTask {
 	}
 	return tasks
 }
+
+// cronTaskSchema 定时任务表
+const cronTaskSchema = `CREATE TABLE IF NOT EXISTS cron_task (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	line TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[CronTask]{
+		name:      "cron",
+		title:     "任务计划",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{cronTaskSchema},
+		collect: func(ctx context.Context, a *App) ([]CronTask, error) {
+			return a.GetCronTasks(), nil
+		},
+		save: (*App).SaveCronTasks,
+	})
+}
@@ -0,0 +1,443 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// dbEnvVar 指定数据库位置的环境变量
+const dbEnvVar = "CTSCAN_DB"
+
+// defaultDBName 默认数据库文件名
+const defaultDBName = "ctscan.db"
+
+// portableMarker 程序目录下存在该文件时，数据库总是保存在程序目录
+const portableMarker = "ctscan.portable"
+
+// errReadOnly 只读模式下尝试写入数据库
+var errReadOnly = errors.New("数据库以只读方式打开，无法写入")
+
+// DBOptions 数据库打开选项
+type DBOptions struct {
+	Path     string // 数据库文件或目录，为空时依次使用环境变量、配置文件与默认位置
+	Case     string // 案例名称，不为空时使用该案例单独的数据库文件
+	ReadOnly bool   // 以只读方式打开已有数据库
+}
+
+// DatabaseInfo 当前数据库信息，供前端展示
+type DatabaseInfo struct {
+	Path          string `json:"path"`
+	ReadOnly      bool   `json:"read_only"`
+	SchemaVersion int    `json:"schema_version"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+// appConfig 配置文件内容
+type appConfig struct {
+	DBPath        string `json:"db_path"`
+	SigmaRulesDir string `json:"sigma_rules_dir"` // 自定义 Sigma 规则目录，与内置规则一起使用
+}
+
+// addDBFlags 为命令行注册数据库相关参数
+func addDBFlags(fs *flag.FlagSet) *DBOptions {
+	opts := &DBOptions{}
+	fs.StringVar(&opts.Path, "db", "", "数据库文件或目录，默认读取环境变量 "+dbEnvVar+" 与配置文件")
+	fs.StringVar(&opts.Case, "db-case", "", "为指定案例使用单独的数据库文件 ctscan-<案例>.db")
+	fs.BoolVar(&opts.ReadOnly, "readonly", false, "以只读方式打开已有数据库")
+	return opts
+}
+
+// ParseDBFlags 解析图形界面模式下的数据库参数
+// 启动器可能附带其他参数，如 macOS 的 -psn_0_12345 或 Wails 开发模式的参数，未知参数忽略
+func ParseDBFlags(args []string) (DBOptions, error) {
+	fs := flag.NewFlagSet("ctscan", flag.ContinueOnError)
+	opts := addDBFlags(fs)
+	known, ignored := filterFlags(fs, args)
+	if len(ignored) > 0 {
+		log.Printf("忽略未知参数: %s", strings.Join(ignored, " "))
+	}
+	if err := fs.Parse(known); err != nil {
+		return DBOptions{}, err
+	}
+	return *opts, nil
+}
+
+// filterFlags 从 args 中挑出 fs 定义过的参数及其取值，其余参数原样返回到 ignored
+func filterFlags(fs *flag.FlagSet, args []string) (known, ignored []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(name)
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" || f == nil {
+			ignored = append(ignored, arg)
+			continue
+		}
+		known = append(known, arg)
+		// 非布尔参数的取值可能是下一个参数，如 -db /path/to/ctscan.db
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && b.IsBoolFlag()) && i+1 < len(args) {
+			i++
+			known = append(known, args[i])
+		}
+	}
+	return known, ignored
+}
+
+// configPath 配置文件路径
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取配置目录失败: %v", err)
+	}
+	return filepath.Join(dir, "ctscan", "config.json"), nil
+}
+
+// loadAppConfig 读取配置文件，文件不存在时返回空配置
+func loadAppConfig() (appConfig, error) {
+	var cfg appConfig
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// saveAppConfig 写入配置文件
+func saveAppConfig(cfg appConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// ResolveDBPath 确定数据库文件路径
+// 优先级: 参数 > 环境变量 > 配置文件 > 可移动介质 > 桌面 > 数据目录 > 工作目录
+func ResolveDBPath(opts DBOptions) (string, error) {
+	path := opts.Path
+	if path == "" {
+		path = os.Getenv(dbEnvVar)
+	}
+	if path == "" {
+		cfg, err := loadAppConfig()
+		if err != nil {
+			return "", err
+		}
+		path = cfg.DBPath
+	}
+
+	if path == "" {
+		dir, err := defaultDBDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, defaultDBName)
+	} else if isDirPath(path) {
+		path = filepath.Join(path, defaultDBName)
+	}
+
+	if opts.Case != "" {
+		path = filepath.Join(filepath.Dir(path), caseDBFileName(opts.Case))
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("解析数据库路径失败: %v", err)
+	}
+	if opts.ReadOnly {
+		if _, err := os.Stat(abs); err != nil {
+			return "", fmt.Errorf("只读模式下数据库文件必须已存在: %v", err)
+		}
+	}
+	return abs, nil
+}
+
+// isDirPath 判断路径是否表示目录: 已存在的目录或以分隔符结尾
+func isDirPath(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// caseDBFileName 案例数据库文件名，案例名中不适合作为文件名的字符替换为下划线
+func caseDBFileName(caseName string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(caseName))
+	name = strings.Trim(name, ".")
+	if name == "" {
+		name = "case"
+	}
+	return "ctscan-" + name + ".db"
+}
+
+// defaultDBDir 按顺序选择第一个可写的默认目录
+func defaultDBDir() (string, error) {
+	var candidates []string
+	if dir := removableDataDir(); dir != "" {
+		candidates = append(candidates, dir)
+	}
+	// 兼容旧版本，桌面存在时仍然使用桌面
+	if dir, err := getDesktopPath(); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			candidates = append(candidates, dir)
+		}
+	}
+	if dir := userDataDir(); dir != "" {
+		candidates = append(candidates, dir)
+	}
+	if dir, err := os.Getwd(); err == nil {
+		candidates = append(candidates, dir)
+	}
+
+	for _, dir := range candidates {
+		if dirWritable(dir) {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("找不到可写的数据库目录，请使用 -db 参数或 %s 环境变量指定", dbEnvVar)
+}
+
+// userDataDir 系统约定的应用数据目录，Linux 下遵循 XDG 规范
+func userDataDir() string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "CTScan")
+		}
+	case "darwin":
+		if home != "" {
+			return filepath.Join(home, "Library", "Application Support", "CTScan")
+		}
+	default:
+		if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+			return filepath.Join(dir, "ctscan")
+		}
+		if home != "" {
+			return filepath.Join(home, ".local", "share", "ctscan")
+		}
+	}
+	return ""
+}
+
+// removableDataDir 程序从U盘等可移动介质运行时，数据库保存在程序目录下，避免写入被排查主机
+func removableDataDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	exeDir := filepath.Dir(exe)
+	if _, err := os.Stat(filepath.Join(exeDir, portableMarker)); err == nil {
+		return exeDir
+	}
+	if onRemovableMedia(exeDir) {
+		return filepath.Join(exeDir, "ctscan-data")
+	}
+	return ""
+}
+
+// onRemovableMedia 根据挂载位置与文件系统类型判断目录是否位于可移动介质
+func onRemovableMedia(dir string) bool {
+	switch runtime.GOOS {
+	case "linux":
+		for _, prefix := range []string{"/media/", "/run/media/", "/mnt/"} {
+			if strings.HasPrefix(dir, prefix) {
+				return true
+			}
+		}
+	case "darwin":
+		if strings.HasPrefix(dir, "/Volumes/") {
+			return true
+		}
+	}
+
+	// U盘通常使用 FAT/exFAT 文件系统
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return false
+	}
+	var mount, fstype string
+	for _, p := range partitions {
+		mp := p.Mountpoint
+		if runtime.GOOS == "windows" {
+			if !strings.HasPrefix(strings.ToUpper(dir), strings.ToUpper(mp)) {
+				continue
+			}
+		} else if dir != mp && !strings.HasPrefix(dir, strings.TrimSuffix(mp, "/")+"/") {
+			continue
+		}
+		if len(mp) > len(mount) {
+			mount, fstype = mp, p.Fstype
+		}
+	}
+	switch strings.ToLower(fstype) {
+	case "vfat", "fat", "fat32", "exfat", "msdos":
+		return true
+	}
+	return false
+}
+
+// dirWritable 检查目录是否可写，目录不存在时尝试创建
+func dirWritable(dir string) bool {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".ctscan-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	f.Close()
+	os.Remove(name)
+	return true
+}
+
+// CurrentDatabase 返回当前打开的数据库信息
+func (a *App) CurrentDatabase() DatabaseInfo {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	info := DatabaseInfo{Path: a.databasePath(), ReadOnly: a.isReadOnly()}
+	if version, err := schemaVersion(a.database()); err == nil {
+		info.SchemaVersion = version
+	}
+	if cfg, err := loadAppConfig(); err == nil {
+		info.IsDefault = cfg.DBPath == a.databasePath()
+	}
+	return info
+}
+
+// SelectDatabase 弹窗选择已有数据库并切换
+func (a *App) SelectDatabase(readOnly bool) (DatabaseInfo, error) {
+	path, err := wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title:            "选择数据库文件",
+		DefaultDirectory: filepath.Dir(a.databasePath()),
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "CTScan 数据库", Pattern: "*.db"},
+		},
+	})
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+	if path == "" {
+		return DatabaseInfo{}, fmt.Errorf("未选择文件")
+	}
+	return a.OpenDatabase(path, readOnly)
+}
+
+// NewCaseDatabase 在当前数据库所在目录为案例创建单独的数据库并切换
+func (a *App) NewCaseDatabase(caseName string) (DatabaseInfo, error) {
+	if strings.TrimSpace(caseName) == "" {
+		return DatabaseInfo{}, fmt.Errorf("案例名称不能为空")
+	}
+	path := filepath.Join(filepath.Dir(a.databasePath()), caseDBFileName(caseName))
+	return a.OpenDatabase(path, false)
+}
+
+// OpenDatabase 切换到指定的数据库文件，当前扫描会话会被结束
+// 采集、导入等任务运行期间拒绝切换，否则任务会写入已关闭的数据库
+func (a *App) OpenDatabase(path string, readOnly bool) (DatabaseInfo, error) {
+	if err := a.checkNoRunningTasks(); err != nil {
+		return DatabaseInfo{}, err
+	}
+	path, err := ResolveDBPath(DBOptions{Path: path, ReadOnly: readOnly})
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+	db, err := InitSqliteDB(path, readOnly)
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+
+	// 持有任务锁直到切换完成，期间不会有新任务开始
+	a.tasksMu.Lock()
+	if err := a.runningTasksError(); err != nil {
+		a.tasksMu.Unlock()
+		db.Close()
+		return DatabaseInfo{}, err
+	}
+	if err := a.CloseScanSession(); err != nil {
+		a.tasksMu.Unlock()
+		db.Close()
+		return DatabaseInfo{}, err
+	}
+	a.sessionMu.Lock()
+	a.dbMu.Lock()
+	old := a.db
+	a.db, a.dbPath, a.readOnly = db, path, readOnly
+	a.dbMu.Unlock()
+	a.sessionMu.Unlock()
+	a.tasksMu.Unlock()
+	old.Close()
+
+	return a.CurrentDatabase(), nil
+}
+
+// checkNoRunningTasks 有任务正在运行时返回错误
+func (a *App) checkNoRunningTasks() error {
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+	return a.runningTasksError()
+}
+
+// runningTasksError 需持有 tasksMu
+func (a *App) runningTasksError() error {
+	if len(a.tasks) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(a.tasks))
+	for name := range a.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("任务 %s 正在运行，请等待完成或取消后再切换数据库", strings.Join(names, "、"))
+}
+
+// SetDefaultDatabase 将当前数据库写入配置文件，下次启动时默认打开
+func (a *App) SetDefaultDatabase() error {
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return err
+	}
+	cfg.DBPath = a.databasePath()
+	return saveAppConfig(cfg)
+}
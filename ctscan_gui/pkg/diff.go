@@ -0,0 +1,318 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 差异状态
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// diffSpec 描述一个数据表如何进行快照对比
+type diffSpec struct {
+	collector string   // 对应的采集器名称
+	title     string   // 显示名称
+	table     string   // 数据表
+	key       []string // 自然键，用于在两次采集之间匹配同一条记录
+	fallback  string   // 自然键全部为空时使用的列
+	compare   []string // 自然键相同时需要比较的列
+}
+
+// diffSpecs 支持快照对比的数据表
+var diffSpecs = []diffSpec{
+	{
+		collector: "processes",
+		title:     "进程",
+		table:     "process_info",
+		key:       []string{"exe", "md5"},
+		fallback:  "name",
+		compare:   []string{"signature", "file_ctime", "file_mtime"},
+	},
+	{
+		collector: "connections",
+		title:     "网络连接",
+		table:     "network_connection",
+		key:       []string{"proto", "local_addr", "remote_addr"},
+		compare:   []string{"status", "pid"},
+	},
+	{
+		collector: "startup",
+		title:     "开机启动项",
+		table:     "startup_item",
+		key:       []string{"path"},
+		fallback:  "name",
+		compare:   []string{"name", "type", "enabled", "last_mod_time", "size", "description"},
+	},
+	{
+		collector: "cron",
+		title:     "任务计划",
+		table:     "cron_task",
+		key:       []string{"line"},
+	},
+	{
+		collector: "users",
+		title:     "用户",
+		table:     "user_info",
+		key:       []string{"username"},
+		compare:   []string{"uid", "gid", "home_dir", "name"},
+	},
+	{
+		collector: "files",
+		title:     "文件监控",
+		table:     "file_monitor",
+		key:       []string{"path"},
+		compare:   []string{"file_exists", "size", "mode", "mod_time", "change_time", "owner", "group_name", "permissions"},
+	},
+}
+
+// countField 同一自然键出现多次时(如多个同名进程)，数量变化也视为变更
+const countField = "count"
+
+// DiffChange 单个字段的变化
+type DiffChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DiffEntry 一条差异记录
+type DiffEntry struct {
+	Key     string            `json:"key"`
+	Status  string            `json:"status"`
+	Before  map[string]string `json:"before"`
+	After   map[string]string `json:"after"`
+	Changes []DiffChange      `json:"changes"`
+}
+
+// ArtifactDiff 单个数据表的对比结果
+type ArtifactDiff struct {
+	Name    string      `json:"name"`
+	Title   string      `json:"title"`
+	Table   string      `json:"table"`
+	Skipped string      `json:"skipped"` // 不为空时表示未对比的原因
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	Changed int         `json:"changed"`
+	Entries []DiffEntry `json:"entries"`
+}
+
+// SnapshotDiff 两个扫描会话之间的对比结果
+type SnapshotDiff struct {
+	Base      ScanSession    `json:"base"`
+	Target    ScanSession    `json:"target"`
+	SameHost  bool           `json:"same_host"`
+	Artifacts []ArtifactDiff `json:"artifacts"`
+}
+
+// DiffArtifacts 返回支持快照对比的采集项名称
+func DiffArtifacts() []string {
+	names := make([]string, 0, len(diffSpecs))
+	for _, spec := range diffSpecs {
+		names = append(names, spec.collector)
+	}
+	return names
+}
+
+// DiffScanSessions 对比两个扫描会话，base 为较早的采集，target 为较晚的采集
+func (a *App) DiffScanSessions(baseID, targetID string) (SnapshotDiff, error) {
+	return a.diffScanSessions(baseID, targetID, nil)
+}
+
+// diffScanSessions 对比两个会话，only 不为空时只对比指定的采集项
+func (a *App) diffScanSessions(baseID, targetID string, only map[string]bool) (SnapshotDiff, error) {
+	base, err := a.getScanSession(baseID)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+	target, err := a.getScanSession(targetID)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	result := SnapshotDiff{
+		Base:      base,
+		Target:    target,
+		SameHost:  base.HostFingerprint == target.HostFingerprint,
+		Artifacts: []ArtifactDiff{},
+	}
+	for _, spec := range diffSpecs {
+		if len(only) > 0 && !only[spec.collector] {
+			continue
+		}
+		artifact, err := a.diffArtifact(spec, base, target)
+		if err != nil {
+			return SnapshotDiff{}, fmt.Errorf("对比%s失败: %v", spec.title, err)
+		}
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+	return result, nil
+}
+
+// diffArtifact 对比单个数据表
+func (a *App) diffArtifact(spec diffSpec, base, target ScanSession) (ArtifactDiff, error) {
+	artifact := ArtifactDiff{
+		Name:    spec.collector,
+		Title:   spec.title,
+		Table:   spec.table,
+		Entries: []DiffEntry{},
+	}
+	// 某个会话没有运行该采集项时，对比结果没有意义
+	for _, session := range []ScanSession{base, target} {
+		if !containsString(session.Collectors, spec.collector) {
+			artifact.Skipped = fmt.Sprintf("会话 %s 未采集该项", shortID(session.ID))
+			return artifact, nil
+		}
+	}
+
+	before, err := loadDiffRows(a.database(), spec, base.ID)
+	if err != nil {
+		return artifact, err
+	}
+	after, err := loadDiffRows(a.database(), spec, target.ID)
+	if err != nil {
+		return artifact, err
+	}
+
+	for key, old := range before {
+		cur, ok := after[key]
+		if !ok {
+			artifact.Entries = append(artifact.Entries, DiffEntry{Key: key, Status: DiffRemoved, Before: old})
+			artifact.Removed++
+			continue
+		}
+		var changes []DiffChange
+		fields := append(append([]string{}, spec.compare...), countField)
+		for _, field := range fields {
+			if old[field] != cur[field] {
+				changes = append(changes, DiffChange{Field: field, Before: old[field], After: cur[field]})
+			}
+		}
+		if len(changes) > 0 {
+			artifact.Entries = append(artifact.Entries, DiffEntry{
+				Key: key, Status: DiffChanged, Before: old, After: cur, Changes: changes,
+			})
+			artifact.Changed++
+		}
+	}
+	for key, cur := range after {
+		if _, ok := before[key]; !ok {
+			artifact.Entries = append(artifact.Entries, DiffEntry{Key: key, Status: DiffAdded, After: cur})
+			artifact.Added++
+		}
+	}
+
+	order := map[string]int{DiffAdded: 0, DiffRemoved: 1, DiffChanged: 2}
+	sort.Slice(artifact.Entries, func(i, j int) bool {
+		ei, ej := artifact.Entries[i], artifact.Entries[j]
+		if ei.Status != ej.Status {
+			return order[ei.Status] < order[ej.Status]
+		}
+		return ei.Key < ej.Key
+	})
+	return artifact, nil
+}
+
+// loadDiffRows 读取会话在数据表中的记录，按自然键归并
+func loadDiffRows(db *sql.DB, spec diffSpec, sessionID string) (map[string]map[string]string, error) {
+	columns := append([]string{}, spec.key...)
+	if spec.fallback != "" && !containsString(columns, spec.fallback) {
+		columns = append(columns, spec.fallback)
+	}
+	for _, column := range spec.compare {
+		if !containsString(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE session_id = ? ORDER BY id`,
+		strings.Join(columns, ", "), spec.table), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[string]map[string]string)
+	counts := make(map[string]int)
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]string, len(columns)+1)
+		for i, column := range columns {
+			record[column] = formatDBValue(values[i])
+		}
+
+		key := diffKey(spec, record)
+		counts[key]++
+		// 同一自然键只保留第一条记录
+		if _, ok := records[key]; !ok {
+			records[key] = record
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for key, record := range records {
+		record[countField] = fmt.Sprint(counts[key])
+	}
+	return records, nil
+}
+
+// diffKey 生成记录的自然键
+func diffKey(spec diffSpec, record map[string]string) string {
+	parts := make([]string, 0, len(spec.key))
+	empty := true
+	for _, column := range spec.key {
+		if record[column] != "" {
+			empty = false
+		}
+		parts = append(parts, record[column])
+	}
+	if empty && spec.fallback != "" {
+		return record[spec.fallback]
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formatDBValue 将数据库中读取的值统一转换为字符串
+func formatDBValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Local().Format(sessionTimeLayout)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// shortID 会话ID的前8位，用于展示
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,831 @@
+package pkg
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 证据包内的固定文件
+const (
+	evidenceManifestName = "manifest.json"
+	evidenceDBName       = "database/ctscan.db"
+)
+
+// maxEvidenceFileSize 单个附带文件的默认大小上限，避免把大文件打进证据包
+// 可通过 App.evidenceMaxSize 调整，事件日志不受限制
+const maxEvidenceFileSize = 100 << 20
+
+// EvidenceFile 证据包中的一个文件
+type EvidenceFile struct {
+	Path    string `json:"path"`   // 包内路径
+	Source  string `json:"source"` // 原始路径
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	ModTime string `json:"mod_time"`
+}
+
+// EvidenceSkippedFile 没有打包的原始文件，记录在清单与保管记录中，证据包不完整时可以追溯
+type EvidenceSkippedFile struct {
+	Source string `json:"source"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+}
+
+// EvidenceHost 生成证据包的主机
+type EvidenceHost struct {
+	Hostname    string `json:"hostname"`
+	Fingerprint string `json:"fingerprint"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+}
+
+// EvidenceManifest 证据包清单
+type EvidenceManifest struct {
+	PackageID   string                `json:"package_id"`
+	CreatedAt   string                `json:"created_at"`
+	ToolVersion string                `json:"tool_version"`
+	Operator    string                `json:"operator"`
+	Note        string                `json:"note"`
+	Host        EvidenceHost          `json:"host"`
+	Session     ScanSession           `json:"session"`
+	Files       []EvidenceFile        `json:"files"`
+	Skipped     []EvidenceSkippedFile `json:"skipped"`
+}
+
+// EvidencePackage 证据包生成结果
+type EvidencePackage struct {
+	Path     string           `json:"path"`
+	SHA256   string           `json:"sha256"`
+	LogPath  string           `json:"log_path"`
+	Manifest EvidenceManifest `json:"manifest"`
+}
+
+// EvidenceFileCheck 单个文件的校验结果
+type EvidenceFileCheck struct {
+	Path     string `json:"path"`
+	Status   string `json:"status"` // ok / mismatch / missing / unexpected
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// EvidenceVerifyResult 证据包校验结果
+type EvidenceVerifyResult struct {
+	Path      string              `json:"path"`
+	SHA256    string              `json:"sha256"`
+	OK        bool                `json:"ok"`
+	Manifest  EvidenceManifest    `json:"manifest"`
+	Files     []EvidenceFileCheck `json:"files"`
+	CustodyOK bool                `json:"custody_ok"`
+	Custody   []CustodyEntry      `json:"custody"`
+	Errors    []string            `json:"errors"`
+}
+
+// evidenceSource 需要随数据库一起打包的原始文件
+type evidenceSource struct {
+	collector string // 会话运行过该采集项时才打包
+	dir       string // 包内目录
+	paths     func(a *App, sessionID string) []string
+	unlimited bool // 不受大小上限限制，Security.evtx 等事件日志经常超过 100MB
+}
+
+// evidenceSources 随证据包附带的原始文件
+var evidenceSources = []evidenceSource{
+	{collector: "evtx", dir: "artifacts/evtx", paths: evtxSourcePaths, unlimited: true},
+	{collector: "shell", dir: "artifacts/shell", paths: shellHistoryPaths},
+	{collector: "cron", dir: "artifacts/cron", paths: cronFilePaths},
+	{collector: "startup", dir: "artifacts/startup", paths: startupFilePaths},
+	{collector: "login-failed", dir: "artifacts/auth", paths: authLogFilePaths},
+	{collector: "login-sessions", dir: "artifacts/utmp", paths: loginLogFilePaths},
+	{collector: "audit", dir: "artifacts/audit", paths: auditLogFilePaths},
+	{collector: "weblog", dir: "artifacts/weblog", paths: webLogFilePaths},
+	{collector: "webshell", dir: "artifacts/webshell", paths: webshellFilePaths},
+}
+
+// evtxSourcePaths 会话中导入过的EVTX文件与压缩包
+func evtxSourcePaths(a *App, sessionID string) []string {
+	files, err := a.ListEVTXFiles(sessionID)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, f := range files {
+		// 压缩包中的文件打包整个压缩包
+		if f.Archive != "" {
+			paths = append(paths, f.Archive)
+		} else {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// shellHistoryPaths 当前用户的命令历史文件
+func shellHistoryPaths(a *App, sessionID string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	paths := []string{
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		paths = append(paths, filepath.Join(appData, "Microsoft", "Windows", "PowerShell", "PSReadLine", "ConsoleHost_history.txt"))
+	}
+	return paths
+}
+
+// cronFilePaths 系统与用户的计划任务文件
+func cronFilePaths(a *App, sessionID string) []string {
+	paths := []string{"/etc/crontab", "/etc/anacrontab"}
+	for _, pattern := range []string{"/etc/cron.d/*", "/var/spool/cron/*", "/var/spool/cron/crontabs/*", "/var/at/tabs/*", "/usr/lib/cron/tabs/*"} {
+		matches, _ := filepath.Glob(pattern)
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// authLogFilePaths Linux 认证日志及其轮转文件
+func authLogFilePaths(a *App, sessionID string) []string {
+	return authLogFiles()
+}
+
+// loginLogFilePaths wtmp、btmp、utmp 与 lastlog
+// lastlog 是稀疏文件，表观大小可能远大于实际占用，超过证据包单个文件的大小限制时会被跳过
+func loginLogFilePaths(a *App, sessionID string) []string {
+	return newLoginLogPaths("/").files()
+}
+
+// auditLogFilePaths auditd 日志及其轮转文件
+func auditLogFilePaths(a *App, sessionID string) []string {
+	return auditLogFiles("/")
+}
+
+// webLogFilePaths 会话中分析过的 Web 访问日志
+func webLogFilePaths(a *App, sessionID string) []string {
+	rows, err := a.database().Query(`SELECT DISTINCT path FROM web_log_file WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var paths []string
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// webshellFilePaths 会话中扫描出的可疑脚本
+func webshellFilePaths(a *App, sessionID string) []string {
+	rows, err := a.database().Query(`SELECT DISTINCT path FROM webshell_file WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var paths []string
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// startupFilePaths 启动项对应的文件，如 LaunchAgent plist、启动目录中的快捷方式
+func startupFilePaths(a *App, sessionID string) []string {
+	rows, err := a.database().Query(`SELECT DISTINCT path FROM startup_item WHERE session_id = ? AND path != ''`, sessionID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var paths []string
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// PackageEvidence 将会话数据库、原始文件与报告打包为证据包，并写入保管记录
+// output 为空时保存在数据库所在目录
+func (a *App) PackageEvidence(sessionID, output, operator, note string) (EvidencePackage, error) {
+	session, err := a.getScanSession(sessionID)
+	if err != nil {
+		return EvidencePackage{}, err
+	}
+	if operator == "" {
+		operator = defaultAnalyst()
+	}
+	if output == "" {
+		output = filepath.Join(filepath.Dir(a.databasePath()),
+			fmt.Sprintf("ctscan-evidence-%s-%s.zip", shortID(session.ID), time.Now().Format("20060102-150405")))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ctscan-evidence-")
+	if err != nil {
+		return EvidencePackage{}, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	hostname, _ := os.Hostname()
+	manifest := EvidenceManifest{
+		PackageID:   uuid.NewString(),
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		ToolVersion: Version,
+		Operator:    operator,
+		Note:        note,
+		Host: EvidenceHost{
+			Hostname:    hostname,
+			Fingerprint: hostFingerprint(),
+			OS:          runtime.GOOS,
+			Arch:        runtime.GOARCH,
+		},
+		Session: session,
+		Files:   []EvidenceFile{},
+		Skipped: []EvidenceSkippedFile{},
+	}
+
+	// 只导出该会话的数据，避免把同一数据库中其他案例的数据交给第三方
+	dbCopy := filepath.Join(tmpDir, "ctscan.db")
+	if err := a.extractSessionDB(sessionID, dbCopy); err != nil {
+		return EvidencePackage{}, err
+	}
+	reportPath, err := a.GenerateReport(sessionID, filepath.Join(tmpDir, defaultReportName(session)))
+	if err != nil {
+		return EvidencePackage{}, err
+	}
+
+	type packedFile struct {
+		name, source, local string
+	}
+	files := []packedFile{
+		{name: evidenceDBName, source: a.databasePath(), local: dbCopy},
+		{name: "reports/" + filepath.Base(reportPath), source: reportPath, local: reportPath},
+	}
+	// 之前生成过的报告
+	previous, _ := filepath.Glob(filepath.Join(filepath.Dir(a.databasePath()), fmt.Sprintf("ctscan-report-%s-*.html", shortID(session.ID))))
+	for _, path := range previous {
+		files = append(files, packedFile{name: "reports/" + filepath.Base(path), source: path, local: path})
+	}
+	skip := func(path string, size int64, reason string) {
+		manifest.Skipped = append(manifest.Skipped, EvidenceSkippedFile{Source: path, Size: size, Reason: reason})
+	}
+	maxSize := a.evidenceMaxSize
+	if maxSize == 0 {
+		maxSize = maxEvidenceFileSize
+	}
+	// 原始文件只有在采集主机上打包时才有意义
+	if session.HostFingerprint == manifest.Host.Fingerprint {
+		for _, src := range evidenceSources {
+			if src.collector != "" && !containsString(session.Collectors, src.collector) {
+				continue
+			}
+			for _, path := range src.paths(a, sessionID) {
+				info, err := os.Stat(path)
+				switch {
+				case err != nil:
+					skip(path, 0, err.Error())
+				case !info.Mode().IsRegular():
+					skip(path, info.Size(), "不是普通文件")
+				case !src.unlimited && maxSize > 0 && info.Size() > maxSize:
+					skip(path, info.Size(), fmt.Sprintf("超过大小上限 %d MB", maxSize>>20))
+				default:
+					files = append(files, packedFile{name: src.dir + "/" + evidenceEntryName(path), source: path, local: path})
+				}
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return EvidencePackage{}, err
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return EvidencePackage{}, fmt.Errorf("创建证据包失败: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if seen[f.name] {
+			continue
+		}
+		seen[f.name] = true
+		entry, err := addEvidenceFile(zw, f.name, f.local)
+		if err != nil {
+			// 原始文件可能没有读取权限，跳过即可，数据库与报告必须成功
+			if f.name == evidenceDBName || strings.HasPrefix(f.name, "reports/") {
+				zw.Close()
+				out.Close()
+				os.Remove(output)
+				return EvidencePackage{}, fmt.Errorf("打包 %s 失败: %v", f.name, err)
+			}
+			size := int64(0)
+			if info, serr := os.Stat(f.local); serr == nil {
+				size = info.Size()
+			}
+			skip(f.source, size, err.Error())
+			continue
+		}
+		entry.Source = f.source
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		var w io.Writer
+		header := &zip.FileHeader{Name: evidenceManifestName, Method: zip.Deflate, Modified: time.Now()}
+		if w, err = zw.CreateHeader(header); err == nil {
+			_, err = w.Write(data)
+		}
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(output)
+		return EvidencePackage{}, fmt.Errorf("写入证据包失败: %v", err)
+	}
+
+	sum, err := fileSHA256(output)
+	if err != nil {
+		return EvidencePackage{}, err
+	}
+	logPath := custodyLogPath(output)
+	detail := fmt.Sprintf("会话 %s，%d 个文件", session.ID, len(manifest.Files))
+	if len(manifest.Skipped) > 0 {
+		detail += fmt.Sprintf("，跳过 %d 个文件", len(manifest.Skipped))
+	}
+	if note != "" {
+		detail += "，" + note
+	}
+	if err := appendCustody(logPath, CustodyEntry{
+		Action:        "create",
+		Operator:      operator,
+		PackageID:     manifest.PackageID,
+		PackageSHA256: sum,
+		Detail:        detail,
+	}); err != nil {
+		return EvidencePackage{}, err
+	}
+	// 每个跳过的文件单独记录，说明证据包中缺少哪些原始文件
+	for _, s := range manifest.Skipped {
+		if err := appendCustody(logPath, CustodyEntry{
+			Action:        "skip",
+			Operator:      operator,
+			PackageID:     manifest.PackageID,
+			PackageSHA256: sum,
+			Detail:        fmt.Sprintf("%s (%d 字节): %s", s.Source, s.Size, s.Reason),
+		}); err != nil {
+			return EvidencePackage{}, err
+		}
+	}
+
+	return EvidencePackage{Path: output, SHA256: sum, LogPath: logPath, Manifest: manifest}, nil
+}
+
+// evidenceEntryName 将原始路径转换为包内文件名，保留目录结构便于追溯
+func evidenceEntryName(path string) string {
+	name := filepath.ToSlash(path)
+	name = strings.ReplaceAll(name, ":", "")
+	return strings.TrimLeft(name, "/")
+}
+
+// addEvidenceFile 将文件写入证据包并计算哈希
+func addEvidenceFile(zw *zip.Writer, name, path string) (EvidenceFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return EvidenceFile{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return EvidenceFile{}, err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return EvidenceFile{}, err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return EvidenceFile{}, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, h), f)
+	if err != nil {
+		return EvidenceFile{}, err
+	}
+	return EvidenceFile{
+		Path:    name,
+		Size:    size,
+		SHA256:  hex.EncodeToString(h.Sum(nil)),
+		ModTime: info.ModTime().Format(time.RFC3339),
+	}, nil
+}
+
+// extractSessionDB 复制数据库并删除其他会话的数据
+func (a *App) extractSessionDB(sessionID, path string) error {
+	if _, err := a.database().Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("复制数据库失败: %v", err)
+	}
+	db, err := sql.Open("sqlite3", sqliteDSN(path, false))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := sessionTables(db)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE session_id IS NOT ?`, table), sessionID); err != nil {
+			return fmt.Errorf("清理 %s 失败: %v", table, err)
+		}
+	}
+	if _, err := db.Exec(`DELETE FROM scan_session WHERE id != ?`, sessionID); err != nil {
+		return err
+	}
+	_, err = db.Exec(`VACUUM`)
+	return err
+}
+
+// VerifyEvidence 校验证据包中每个文件的哈希以及保管记录，并追加一条校验记录
+func (a *App) VerifyEvidence(path, operator string) (EvidenceVerifyResult, error) {
+	return verifyEvidence(path, operator)
+}
+
+func verifyEvidence(path, operator string) (EvidenceVerifyResult, error) {
+	result := EvidenceVerifyResult{Path: path, Files: []EvidenceFileCheck{}, Errors: []string{}}
+	if operator == "" {
+		operator = defaultAnalyst()
+	}
+
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return result, fmt.Errorf("读取证据包失败: %v", err)
+	}
+	result.SHA256 = sum
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return result, fmt.Errorf("打开证据包失败: %v", err)
+	}
+	defer zr.Close()
+
+	entries := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	mf, ok := entries[evidenceManifestName]
+	if !ok {
+		return result, fmt.Errorf("证据包中缺少 %s", evidenceManifestName)
+	}
+	if err := readZipJSON(mf, &result.Manifest); err != nil {
+		return result, fmt.Errorf("解析 %s 失败: %v", evidenceManifestName, err)
+	}
+
+	result.OK = true
+	listed := make(map[string]bool)
+	for _, file := range result.Manifest.Files {
+		listed[file.Path] = true
+		check := EvidenceFileCheck{Path: file.Path, Expected: file.SHA256}
+		f, ok := entries[file.Path]
+		if !ok {
+			check.Status = "missing"
+		} else if check.Actual, err = zipEntrySHA256(f); err != nil {
+			check.Status = "mismatch"
+			result.Errors = append(result.Errors, fmt.Sprintf("读取 %s 失败: %v", file.Path, err))
+		} else if check.Actual == file.SHA256 {
+			check.Status = "ok"
+		} else {
+			check.Status = "mismatch"
+		}
+		if check.Status != "ok" {
+			result.OK = false
+		}
+		result.Files = append(result.Files, check)
+	}
+	for name := range entries {
+		if name != evidenceManifestName && !listed[name] {
+			result.OK = false
+			result.Files = append(result.Files, EvidenceFileCheck{Path: name, Status: "unexpected"})
+		}
+	}
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+
+	// 保管记录
+	logPath := custodyLogPath(path)
+	custody, custodyErr := readCustody(logPath)
+	switch {
+	case os.IsNotExist(custodyErr):
+		result.Errors = append(result.Errors, "未找到保管记录: "+logPath)
+	case custodyErr != nil:
+		result.Errors = append(result.Errors, custodyErr.Error())
+	default:
+		result.Custody = custody
+		for _, entry := range custody {
+			if entry.Action != "create" || entry.PackageID != result.Manifest.PackageID {
+				continue
+			}
+			result.CustodyOK = true
+			if entry.PackageSHA256 != sum {
+				result.OK = false
+				result.Errors = append(result.Errors, "证据包哈希与创建时记录的不一致")
+			}
+		}
+		if !result.CustodyOK {
+			result.Errors = append(result.Errors, "保管记录中缺少该证据包的创建记录")
+		}
+	}
+	if !result.CustodyOK {
+		result.OK = false
+	}
+
+	// 记录缺失或哈希链已损坏时不再追加，避免在被篡改的记录后继续写入
+	if custodyErr != nil {
+		return result, nil
+	}
+	status := "通过"
+	if !result.OK {
+		status = "未通过"
+	}
+	if err := appendCustody(logPath, CustodyEntry{
+		Action:        "verify",
+		Operator:      operator,
+		PackageID:     result.Manifest.PackageID,
+		PackageSHA256: sum,
+		Detail:        "校验" + status,
+	}); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	return result, nil
+}
+
+// openEvidence 校验证据包后将其中的数据库解压到 dir，并追加一条打开记录，返回数据库路径
+func openEvidence(path, dir, operator string) (string, error) {
+	result, err := verifyEvidence(path, operator)
+	if err != nil {
+		return "", err
+	}
+	if !result.OK {
+		return "", fmt.Errorf("证据包校验未通过，拒绝打开")
+	}
+	if operator == "" {
+		operator = defaultAnalyst()
+	}
+	if dir == "" {
+		dir = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	var dbPath string
+	for _, f := range zr.File {
+		if f.Name != evidenceDBName {
+			continue
+		}
+		dbPath = filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := extractZipFile(f, dbPath, 0); err != nil {
+			return "", fmt.Errorf("解压数据库失败: %v", err)
+		}
+	}
+	if dbPath == "" {
+		return "", fmt.Errorf("证据包中缺少 %s", evidenceDBName)
+	}
+
+	err = appendCustody(custodyLogPath(path), CustodyEntry{
+		Action:        "open",
+		Operator:      operator,
+		PackageID:     result.Manifest.PackageID,
+		PackageSHA256: result.SHA256,
+		Detail:        "解压数据库到 " + dbPath,
+	})
+	return dbPath, err
+}
+
+// ExportEvidence 弹窗选择保存位置并生成证据包
+func (a *App) ExportEvidence(sessionID, operator, note string) (EvidencePackage, error) {
+	session, err := a.getScanSession(sessionID)
+	if err != nil {
+		return EvidencePackage{}, err
+	}
+	output, err := wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
+		Title:            "保存证据包",
+		DefaultDirectory: filepath.Dir(a.databasePath()),
+		DefaultFilename:  fmt.Sprintf("ctscan-evidence-%s-%s.zip", shortID(session.ID), time.Now().Format("20060102-150405")),
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "证据包", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return EvidencePackage{}, err
+	}
+	if output == "" {
+		return EvidencePackage{}, fmt.Errorf("未选择保存位置")
+	}
+	return a.PackageEvidence(sessionID, output, operator, note)
+}
+
+// SelectAndVerifyEvidence 弹窗选择证据包并校验
+func (a *App) SelectAndVerifyEvidence() (EvidenceVerifyResult, error) {
+	path, err := wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title:            "选择证据包",
+		DefaultDirectory: filepath.Dir(a.databasePath()),
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "证据包", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return EvidenceVerifyResult{}, err
+	}
+	if path == "" {
+		return EvidenceVerifyResult{}, fmt.Errorf("未选择文件")
+	}
+	return verifyEvidence(path, "")
+}
+
+// OpenEvidencePackage 弹窗选择证据包，校验通过后解压并以只读方式打开其中的数据库
+func (a *App) OpenEvidencePackage() (DatabaseInfo, error) {
+	path, err := wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title: "打开证据包",
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "证据包", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+	if path == "" {
+		return DatabaseInfo{}, fmt.Errorf("未选择文件")
+	}
+	dbPath, err := openEvidence(path, "", "")
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+	return a.OpenDatabase(dbPath, true)
+}
+
+func readZipJSON(f *zip.File, v any) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return json.NewDecoder(r).Decode(v)
+}
+
+func zipEntrySHA256(f *zip.File) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractZipFile 解压一个文件，limit 大于 0 时限制写入的大小
+func extractZipFile(f *zip.File, path string, limit int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var src io.Reader = r
+	if limit > 0 {
+		src = io.LimitReader(r, limit+1)
+	}
+	n, err := io.Copy(out, src)
+	if err == nil && limit > 0 && n > limit {
+		err = fmt.Errorf("解压后超过 %d 字节", limit)
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CustodyEntry 保管记录中的一条，每条记录包含上一条记录的哈希，形成哈希链
+// 任何对历史记录的修改或删除都会导致后续记录的哈希校验失败
+type CustodyEntry struct {
+	Time          string `json:"time"`
+	Action        string `json:"action"` // create / verify / open
+	Operator      string `json:"operator"`
+	Hostname      string `json:"hostname"`
+	PackageID     string `json:"package_id"`
+	PackageSHA256 string `json:"package_sha256"`
+	Detail        string `json:"detail"`
+	PrevHash      string `json:"prev_hash"`
+	Hash          string `json:"hash"`
+}
+
+// custodyLogPath 保管记录与证据包放在同一目录，证据包本身创建后不再修改
+func custodyLogPath(pkgPath string) string {
+	return pkgPath + ".custody.log"
+}
+
+// entryHash 计算记录的哈希，不包含 Hash 字段本身
+func (e CustodyEntry) entryHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readCustody 读取并校验保管记录的哈希链
+func readCustody(path string) ([]CustodyEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []CustodyEntry
+	prev := ""
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry CustodyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("保管记录第 %d 行格式错误: %v", line, err)
+		}
+		if entry.PrevHash != prev || entry.entryHash() != entry.Hash {
+			return entries, fmt.Errorf("保管记录第 %d 行哈希校验失败，记录可能被篡改", line)
+		}
+		prev = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// appendCustody 以追加方式写入一条保管记录
+func appendCustody(path string, entry CustodyEntry) error {
+	entries, err := readCustody(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		entry.PrevHash = entries[len(entries)-1].Hash
+	}
+	entry.Time = time.Now().Format(time.RFC3339)
+	entry.Hostname, _ = os.Hostname()
+	entry.Hash = entry.entryHash()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("写入保管记录失败: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入保管记录失败: %v", err)
+	}
+	return nil
+}
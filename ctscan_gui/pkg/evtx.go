@@ -1,11 +1,12 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,15 +15,16 @@ import (
 
 // EVTXEvent 表示解析后的EVTX事件
 type EVTXEvent struct {
-	Time        string         `json:"time"`
-	EventID     int            `json:"event_id"`
-	Provider    string         `json:"provider"`
-	Level       string         `json:"level"`
-	Channel     string         `json:"channel"`
-	Computer    string         `json:"computer"`
-	UserID      string         `json:"user_id"`
-	Description string         `json:"description"`
-	Data        map[string]any `json:"data"`
+	Time        string `json:"time"` // UTC
+	EventID     int    `json:"event_id"`
+	Provider    string `json:"provider"`
+	Level       string `json:"level"`
+	Channel     string `json:"channel"`
+	Computer    string `json:"computer"`
+	UserID      string `json:"user_id"`
+	Description string `json:"description"`
+	SourceFile  string `json:"source_file"`
+	SourceHash  string `json:"source_sha256"` // 来源文件的 SHA-256
 	// 新增字段
 	EventRecordID int    `json:"event_record_id"`
 	Version       int    `json:"version"`
@@ -33,6 +35,9 @@ type EVTXEvent struct {
 	ProcessID     int    `json:"process_id"`
 	ThreadID      int    `json:"thread_id"`
 	Message       string `json:"message"`
+	// 记录在文件中的偏移，Recovered 表示记录是恢复模式中从空闲空间或损坏的块中找回的
+	Offset    int64 `json:"offset"`
+	Recovered bool  `json:"recovered"`
 	// 系统信息
 	SystemInfo map[string]any `json:"system_info"`
 	// 事件数据
@@ -41,310 +46,263 @@ type EVTXEvent struct {
 	UserData map[string]any `json:"user_data"`
 }
 
-// ParseEVTXFile 解析EVTX文件
-func (a *App) ParseEVTXFile(filePath string) ([]EVTXEvent, error) {
-	log.Printf("开始解析EVTX文件: %s", filePath)
+// 解析库中事件的路径，属性直接作为子节点，不带 @ 前缀
+var (
+	evtxSystemPath     = evtx.Path("/Event/System")
+	evtxProviderPath   = evtx.Path("/Event/System/Provider/Name")
+	evtxLevelPath      = evtx.Path("/Event/System/Level")
+	evtxComputerPath   = evtx.Path("/Event/System/Computer")
+	evtxVersionPath    = evtx.Path("/Event/System/Version")
+	evtxQualifiersPath = evtx.Path("/Event/System/EventID/Qualifiers")
+	evtxTaskPath       = evtx.Path("/Event/System/Task")
+	evtxOpcodePath     = evtx.Path("/Event/System/Opcode")
+	evtxKeywordsPath   = evtx.Path("/Event/System/Keywords")
+	evtxProcessIDPath  = evtx.Path("/Event/System/Execution/ProcessID")
+	evtxThreadIDPath   = evtx.Path("/Event/System/Execution/ThreadID")
+	evtxEventDataPath  = evtx.Path("/Event/EventData")
+	evtxUserDataPath   = evtx.Path("/Event/UserData")
+)
+
+// logonTypeNames 登录类型说明
+var logonTypeNames = map[string]string{
+	"2":  "本地交互式登入",
+	"3":  "网络登入",
+	"4":  "批处理登入",
+	"5":  "服务登入",
+	"7":  "工作站解锁",
+	"8":  "网络明文登入",
+	"9":  "新凭证登入",
+	"10": "远程交互式登入 (RDP)",
+	"11": "缓存交互式登入",
+}
 
-	// 检查文件是否存在
+// openEVTXFile 检查并打开EVTX文件
+func openEVTXFile(filePath string) (*evtx.File, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		log.Printf("文件不存在: %s", filePath)
 		return nil, fmt.Errorf("文件不存在: %s", filePath)
 	}
-
-	// 检查文件扩展名
 	if !strings.HasSuffix(strings.ToLower(filePath), ".evtx") {
 		return nil, fmt.Errorf("文件格式错误：必须是 .evtx 文件")
 	}
-
-	// 打开EVTX文件
-	log.Printf("正在打开EVTX文件...")
 	ef, err := evtx.Open(filePath)
 	if err != nil {
-		log.Printf("打开文件失败: %v", err)
 		if strings.Contains(err.Error(), "Corrupted header") {
-			return nil, fmt.Errorf("文件格式错误：不是有效的 EVTX 文件或文件已损坏")
+			return nil, fmt.Errorf("文件格式错误：不是有效的 EVTX 文件或文件已损坏，可以使用恢复模式导入")
 		}
 		return nil, fmt.Errorf("打开文件失败: %v", err)
 	}
-	defer ef.Close()
-	log.Printf("成功打开EVTX文件")
-
-	events := make([]EVTXEvent, 0)
-
-	// 解析所有事件
-	log.Printf("开始解析事件...")
-	for event := range ef.Events() {
-
-		// 获取事件ID
-		eventID := int(event.EventID())
+	return &ef, nil
+}
 
-		// 创建路径
-		providerPath := evtx.Path("System/Provider/@Name")
-		levelPath := evtx.Path("System/Level")
-		computerPath := evtx.Path("System/Computer")
-		userIDPath := evtx.Path("System/Security/@UserID")
-		eventDataPath := evtx.Path("EventData")
-		systemPath := evtx.Path("System")
-		userDataPath := evtx.Path("UserData")
-		versionPath := evtx.Path("System/Version")
-		qualifiersPath := evtx.Path("System/Qualifiers")
-		taskPath := evtx.Path("System/Task")
-		opcodePath := evtx.Path("System/Opcode")
-		keywordsPath := evtx.Path("System/Keywords")
-		processIDPath := evtx.Path("System/Execution/@ProcessID")
-		threadIDPath := evtx.Path("System/Execution/@ThreadID")
+// streamEVTXFile 按块流式解析EVTX文件，每解析完一个块回调一次 fn
+// 块按记录号排序，from 为跳过的块数，用于断点续传；chunks 为块的总数
+// 块损坏时 chunkErr 不为空，events 为损坏前已解析的事件，之后继续解析后面的块；取消时返回 context 错误
+func streamEVTXFile(ctx context.Context, filePath string, from int, fn func(chunk, chunks int, events []EVTXEvent, chunkErr error) error) error {
+	ef, err := openEVTXFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer ef.Close()
 
-		// 获取提供者
-		provider := "未知"
-		if p, err := event.GetString(&providerPath); err == nil && p != "" {
-			provider = p
-		} else {
-			// 尝试从系统信息中获取提供者
-			if sys, err := event.GetMap(&systemPath); err == nil {
-				if providerMap, ok := (*sys)["Provider"]; ok {
-					if providerName, ok := providerMap.(map[string]interface{})["@Name"]; ok {
-						if name, ok := providerName.(string); ok {
-							provider = name
-						}
-					}
-				}
-			}
+	offsets, err := evtxChunkOffsets(ef)
+	if err != nil {
+		return err
+	}
+	progress := progressFrom(ctx)
+	if from < len(offsets) {
+		progress.SetTotal(len(offsets) - from)
+	}
+	for i := from; i < len(offsets); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-
-		// 获取级别
-		level := "未知"
-		if l, err := event.GetInt(&levelPath); err == nil {
-			level = getEventLevel(int(l))
-		} else {
-			// 尝试从系统信息中获取级别
-			if sys, err := event.GetMap(&systemPath); err == nil {
-				if levelVal, ok := (*sys)["Level"]; ok {
-					if levelInt, ok := levelVal.(int); ok {
-						level = getEventLevel(levelInt)
-					}
-				}
-			}
+		events, chunkErr := parseEVTXChunk(ef, offsets[i])
+		if chunkErr != nil {
+			chunkErr = fmt.Errorf("第 %d 个块解析失败: %v", i+1, chunkErr)
+			progress.Fail(chunkErr)
 		}
-
-		// 获取通道
-		channel := event.Channel()
-
-		// 获取计算机名
-		computer := "未知"
-		if c, err := event.GetString(&computerPath); err == nil && c != "" {
-			computer = c
-		} else {
-			// 尝试从系统信息中获取计算机名
-			if sys, err := event.GetMap(&systemPath); err == nil {
-				if comp, ok := (*sys)["Computer"]; ok {
-					if compStr, ok := comp.(string); ok {
-						computer = compStr
-					}
-				}
-			}
+		if err := fn(i, len(offsets), events, chunkErr); err != nil {
+			return err
 		}
+		progress.Step(fmt.Sprintf("块 %d/%d", i+1, len(offsets)))
+	}
+	return nil
+}
 
-		// 获取用户ID
-		userID := "未知"
-		if u, err := event.GetString(&userIDPath); err == nil && u != "" {
-			userID = u
-		} else {
-			// 尝试从系统信息中获取用户ID
-			if sys, err := event.GetMap(&systemPath); err == nil {
-				if security, ok := (*sys)["Security"]; ok {
-					if securityMap, ok := security.(map[string]interface{}); ok {
-						if uid, ok := securityMap["@UserID"]; ok {
-							if uidStr, ok := uid.(string); ok {
-								userID = uidStr
-							}
-						}
-					}
-				}
-			}
-		}
-
-		// 获取事件数据
-		eventData := make(map[string]any)
-		if data, err := event.GetMap(&eventDataPath); err == nil {
-			for key, value := range *data {
-				eventData[key] = value
-			}
-		}
-
-		// 对于登入事件，添加 LogonType、用户名和来源IP
-		if eventID == 4624 || eventID == 4648 || eventID == 4625 || eventID == 4647 {
-			log.Printf("处理登入事件 ID: %d", eventID)
-
-			// 获取事件数据
-			eventDataPath := evtx.Path("EventData")
-			if data, err := event.GetMap(&eventDataPath); err == nil {
-				log.Printf("事件数据内容: %+v", *data)
-
-				// 遍历事件数据中的所有字段
-				for key, value := range *data {
-					switch key {
-					case "LogonType":
-						if logonType, ok := value.(string); ok {
-							eventData["LogonType"] = logonType
-							log.Printf("获取到 LogonType: %s", logonType)
-						}
-					case "TargetUserName":
-						if userName, ok := value.(string); ok {
-							eventData["TargetUserName"] = userName
-							log.Printf("获取到用户名: %s", userName)
-						}
-					case "IpAddress":
-						if ip, ok := value.(string); ok {
-							eventData["IpAddress"] = ip
-							log.Printf("获取到来源IP: %s", ip)
-						}
-					case "TargetDomainName":
-						if domain, ok := value.(string); ok {
-							eventData["TargetDomainName"] = domain
-							log.Printf("获取到域名: %s", domain)
-						}
-					case "WorkstationName":
-						if workstation, ok := value.(string); ok {
-							eventData["WorkstationName"] = workstation
-							log.Printf("获取到工作站名: %s", workstation)
-						}
-					}
-				}
-			} else {
-				log.Printf("获取事件数据失败: %v", err)
-			}
-
-			// 如果上述方法失败，尝试直接从XML路径获取
-			if eventData["LogonType"] == nil {
-				logonTypePath := evtx.Path("EventData/Data[@Name='LogonType']")
-				if logonType, err := event.GetString(&logonTypePath); err == nil {
-					eventData["LogonType"] = logonType
-					log.Printf("通过路径获取到 LogonType: %s", logonType)
-				}
-			}
-
-			if eventData["TargetUserName"] == nil {
-				targetUserNamePath := evtx.Path("EventData/Data[@Name='TargetUserName']")
-				if targetUserName, err := event.GetString(&targetUserNamePath); err == nil {
-					eventData["TargetUserName"] = targetUserName
-					log.Printf("通过路径获取到用户名: %s", targetUserName)
-				}
-			}
-
-			if eventData["IpAddress"] == nil {
-				ipAddressPath := evtx.Path("EventData/Data[@Name='IpAddress']")
-				if ipAddress, err := event.GetString(&ipAddressPath); err == nil {
-					eventData["IpAddress"] = ipAddress
-					log.Printf("通过路径获取到来源IP: %s", ipAddress)
-				}
-			}
-
-			if eventData["TargetDomainName"] == nil {
-				targetDomainNamePath := evtx.Path("EventData/Data[@Name='TargetDomainName']")
-				if targetDomainName, err := event.GetString(&targetDomainNamePath); err == nil {
-					eventData["TargetDomainName"] = targetDomainName
-					log.Printf("通过路径获取到域名: %s", targetDomainName)
-				}
-			}
-
-			if eventData["WorkstationName"] == nil {
-				workstationNamePath := evtx.Path("EventData/Data[@Name='WorkstationName']")
-				if workstationName, err := event.GetString(&workstationNamePath); err == nil {
-					eventData["WorkstationName"] = workstationName
-					log.Printf("通过路径获取到工作站名: %s", workstationName)
-				}
-			}
+// evtxChunkOffsets 按记录号排序返回所有块的偏移，与 evtx.File.Chunks 的顺序一致
+func evtxChunkOffsets(ef *evtx.File) ([]int64, error) {
+	var chunks []evtx.Chunk
+	for i := 0; i < int(ef.Header.ChunkCount); i++ {
+		offset := int64(ef.Header.ChunkDataOffset) + int64(evtx.ChunkSize)*int64(i)
+		chunk, err := fetchRawChunk(ef, offset)
+		if err == io.EOF {
+			break
 		}
-
-		// 获取系统信息
-		systemInfo := make(map[string]any)
-		if sys, err := event.GetMap(&systemPath); err == nil {
-			for key, value := range *sys {
-				systemInfo[key] = value
-			}
+		if err != nil {
+			return nil, fmt.Errorf("读取块失败: %v", err)
 		}
+		chunks = append(chunks, chunk)
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Header.NumFirstRecLog < chunks[j].Header.NumFirstRecLog
+	})
+	offsets := make([]int64, len(chunks))
+	for i, chunk := range chunks {
+		offsets[i] = chunk.Offset
+	}
+	return offsets, nil
+}
 
-		// 获取用户数据
-		userData := make(map[string]any)
-		if data, err := event.GetMap(&userDataPath); err == nil {
-			for key, value := range *data {
-				userData[key] = value
-			}
+// fetchRawChunk 读取块头，文件被截断时解析库会 panic
+func fetchRawChunk(ef *evtx.File, offset int64) (chunk evtx.Chunk, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = io.EOF
 		}
+	}()
+	return ef.FetchRawChunk(offset)
+}
 
-		// 获取消息
-		message := ""
-		messagePath := evtx.Path("System/EventData/Data[@Name='Message']")
-		if msg, err := event.GetString(&messagePath); err == nil && msg != "" {
-			message = msg
+// parseEVTXChunk 解析一个块中的所有事件，块损坏时返回已解析的部分与错误
+func parseEVTXChunk(ef *evtx.File, offset int64) (events []EVTXEvent, err error) {
+	// 解析库遇到损坏的记录会 panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
 		}
-
-		// 获取其他字段
-		version := 0
-		if v, err := event.GetInt(&versionPath); err == nil {
-			version = int(v)
+	}()
+	chunk, err := ef.FetchChunk(offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	bad := 0
+	for _, eo := range chunk.EventOffsets {
+		// EventOffsets 的最后一项是最后一条记录之后的位置
+		if eo > chunk.Header.OffsetLastRec {
+			continue
 		}
-
-		qualifiers := 0
-		if q, err := event.GetInt(&qualifiersPath); err == nil {
-			qualifiers = int(q)
+		event, err := parseEVTXRecord(&chunk, int64(eo))
+		if err != nil {
+			bad++
+			continue
 		}
+		event.Offset = offset + int64(eo)
+		events = append(events, event)
+	}
+	if bad > 0 {
+		return events, fmt.Errorf("%d 条记录已损坏", bad)
+	}
+	return events, nil
+}
 
-		task := 0
-		if t, err := event.GetInt(&taskPath); err == nil {
-			task = int(t)
+// parseEVTXRecord 解析块中的一条记录，单条记录损坏不影响同一块中的其他记录
+func parseEVTXRecord(chunk *evtx.Chunk, offset int64) (evt EVTXEvent, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
 		}
+	}()
+	e := chunk.ParseEvent(offset)
+	event, err := e.GoEvtxMap(chunk)
+	if err != nil {
+		return EVTXEvent{}, err
+	}
+	return newEVTXEvent(event, e.Header), nil
+}
 
-		opcode := 0
-		if o, err := event.GetInt(&opcodePath); err == nil {
-			opcode = int(o)
-		}
+// newEVTXEvent 将解析库的事件转换为 EVTXEvent
+// 记录头中的记录号与时间用于事件本身缺少这两个字段的情况
+func newEVTXEvent(event *evtx.GoEvtxMap, header evtx.EventHeader) EVTXEvent {
+	evt := EVTXEvent{
+		Provider:      evtxProvider(event),
+		Level:         "未知",
+		Channel:       evtxString(event, &evtx.ChannelPath),
+		Computer:      evtxString(event, &evtxComputerPath),
+		UserID:        evtxString(event, &evtx.UserIDPath),
+		EventRecordID: int(header.ID),
+		Version:       evtxInt(event, &evtxVersionPath),
+		Qualifiers:    evtxInt(event, &evtxQualifiersPath),
+		Task:          evtxInt(event, &evtxTaskPath),
+		Opcode:        evtxInt(event, &evtxOpcodePath),
+		Keywords:      evtxString(event, &evtxKeywordsPath),
+		ProcessID:     evtxInt(event, &evtxProcessIDPath),
+		ThreadID:      evtxInt(event, &evtxThreadIDPath),
+		SystemInfo:    evtxMap(event, &evtxSystemPath),
+		EventData:     evtxMap(event, &evtxEventDataPath),
+		UserData:      evtxMap(event, &evtxUserDataPath),
+	}
 
-		keywords := ""
-		if k, err := event.GetString(&keywordsPath); err == nil && k != "" {
-			keywords = k
-		}
+	// 带 Qualifiers 属性时事件ID位于 EventID/Value
+	if id, err := event.GetInt(&evtx.EventIDPath); err == nil {
+		evt.EventID = int(id)
+	} else if id, err := event.GetInt(&evtx.EventIDPath2); err == nil {
+		evt.EventID = int(id)
+	}
+	if id, err := event.GetInt(&evtx.EventRecordIDPath); err == nil {
+		evt.EventRecordID = int(id)
+	}
+	created, err := event.GetTime(&evtx.SystemTimePath)
+	if err != nil {
+		created = time.Time(header.Timestamp.Time())
+	}
+	evt.Time = created.UTC().Format(sessionTimeLayout)
+	if l, err := event.GetInt(&evtxLevelPath); err == nil {
+		evt.Level = getEventLevel(int(l))
+	}
+	if msg, ok := evt.EventData["Message"].(string); ok {
+		evt.Message = msg
+	}
+	evt.Description = getEventDescription(evt)
+	return evt
+}
 
-		processID := 0
-		if p, err := event.GetInt(&processIDPath); err == nil {
-			processID = int(p)
-		}
+// evtxSystemElements System 节点下的标准子节点
+var evtxSystemElements = map[string]bool{
+	"Provider": true, "EventID": true, "Version": true, "Level": true, "Task": true, "Opcode": true,
+	"Keywords": true, "TimeCreated": true, "EventRecordID": true, "Correlation": true,
+	"Execution": true, "Channel": true, "Computer": true, "Security": true,
+}
 
-		threadID := 0
-		if t, err := event.GetInt(&threadIDPath); err == nil {
-			threadID = int(t)
+// evtxProvider 获取事件提供者
+// 只有 Name 属性的 Provider 节点会被解析库转换为 System 下的 {名称: ""}，此时取该键
+func evtxProvider(event *evtx.GoEvtxMap) string {
+	if p, err := event.GetString(&evtxProviderPath); err == nil && p != "" {
+		return p
+	}
+	for key, value := range evtxMap(event, &evtxSystemPath) {
+		if s, ok := value.(string); ok && s == "" && !evtxSystemElements[key] {
+			return key
 		}
+	}
+	return ""
+}
 
-		// 转换事件数据
-		evt := EVTXEvent{
-			Time:          event.TimeCreated().Format("2006-01-02 15:04:05"),
-			EventID:       eventID,
-			Provider:      provider,
-			Level:         level,
-			Channel:       channel,
-			Computer:      computer,
-			UserID:        userID,
-			Description:   getEventDescription(event),
-			Data:          make(map[string]any),
-			EventRecordID: int(event.EventRecordID()),
-			Version:       version,
-			Qualifiers:    qualifiers,
-			Task:          task,
-			Opcode:        opcode,
-			Keywords:      keywords,
-			ProcessID:     processID,
-			ThreadID:      threadID,
-			Message:       message,
-			SystemInfo:    systemInfo,
-			EventData:     eventData,
-			UserData:      userData,
-		}
+func evtxString(event *evtx.GoEvtxMap, path *evtx.GoEvtxPath) string {
+	s, _ := event.GetString(path)
+	return s
+}
 
-		events = append(events, evt)
+func evtxInt(event *evtx.GoEvtxMap, path *evtx.GoEvtxPath) int {
+	i, err := event.GetInt(path)
+	if err != nil {
+		return 0
 	}
+	return int(i)
+}
 
-	log.Printf("解析完成，共解析 %d 个事件", len(events))
-	return events, nil
+// evtxMap 获取路径下的子节点，不存在时返回空 map
+func evtxMap(event *evtx.GoEvtxMap, path *evtx.GoEvtxPath) map[string]any {
+	e, err := event.Get(path)
+	if err != nil {
+		return map[string]any{}
+	}
+	switch m := (*e).(type) {
+	case evtx.GoEvtxMap:
+		return m
+	case map[string]any:
+		return m
+	}
+	return map[string]any{}
 }
 
 // SaveEVTXFile 保存上传的EVTX文件
@@ -381,115 +339,53 @@ func (a *App) SaveEVTXFile(filePath string) (string, error) {
 
 // getEventLevel 获取事件级别
 func getEventLevel(level int) string {
-	levelStr := "未知"
 	switch level {
 	case 1:
-		levelStr = "严重"
+		return "严重"
 	case 2:
-		levelStr = "错误"
+		return "错误"
 	case 3:
-		levelStr = "警告"
-	case 4:
-		levelStr = "信息"
+		return "警告"
+	case 0, 4:
+		// 0 为 LogAlways，安全日志的审核事件均为该级别，事件查看器中显示为信息
+		return "信息"
 	case 5:
-		levelStr = "详细"
+		return "详细"
 	}
-	log.Printf("事件级别转换: %d -> %s", level, levelStr)
-	return levelStr
+	return "未知"
 }
 
 // getEventDescription 获取事件描述
-func getEventDescription(event *evtx.GoEvtxMap) string {
-	// 获取基本信息
-	providerPath := evtx.Path("System/Provider/@Name")
-	provider := "未知"
-	if p, err := event.GetString(&providerPath); err == nil && p != "" {
-		provider = p
-	}
-
-	// 获取事件ID
-	eventID := event.EventID()
-
-	// 获取事件数据
-	eventDataPath := evtx.Path("EventData")
-	description := fmt.Sprintf("事件ID: %d, 提供者: %s", eventID, provider)
+func getEventDescription(evt EVTXEvent) string {
+	description := fmt.Sprintf("事件ID: %d, 提供者: %s", evt.EventID, evt.Provider)
 
 	// 添加登入类型标注
-	switch eventID {
-	case 4624, 4648: // 登入成功
-		// 检查是否是RDP登入
-		logonTypePath := evtx.Path("EventData/Data[@Name='LogonType']")
-		if logonType, err := event.GetInt(&logonTypePath); err == nil {
-			switch logonType {
-			case 2: // 交互式登入
-				description += "\n登入类型: 本地交互式登入"
-			case 3: // 网络登入
-				description += "\n登入类型: 网络登入"
-			case 4: // 批处理登入
-				description += "\n登入类型: 批处理登入"
-			case 5: // 服务登入
-				description += "\n登入类型: 服务登入"
-			case 7: // 解锁
-				description += "\n登入类型: 工作站解锁"
-			case 8: // 网络明文
-				description += "\n登入类型: 网络明文登入"
-			case 9: // 新凭证
-				description += "\n登入类型: 新凭证登入"
-			case 10: // 远程交互
-				description += "\n登入类型: 远程交互式登入 (RDP)"
-			case 11: // 缓存交互
-				description += "\n登入类型: 缓存交互式登入"
-			default:
-				description += fmt.Sprintf("\n登入类型: 未知 (%d)", logonType)
-			}
-		}
-	case 4625, 4647: // 登入失败
-		// 检查是否是RDP登入
-		logonTypePath := evtx.Path("EventData/Data[@Name='LogonType']")
-		if logonType, err := event.GetInt(&logonTypePath); err == nil {
-			switch logonType {
-			case 2: // 交互式登入
-				description += "\n登入类型: 本地交互式登入"
-			case 3: // 网络登入
-				description += "\n登入类型: 网络登入"
-			case 4: // 批处理登入
-				description += "\n登入类型: 批处理登入"
-			case 5: // 服务登入
-				description += "\n登入类型: 服务登入"
-			case 7: // 解锁
-				description += "\n登入类型: 工作站解锁"
-			case 8: // 网络明文
-				description += "\n登入类型: 网络明文登入"
-			case 9: // 新凭证
-				description += "\n登入类型: 新凭证登入"
-			case 10: // 远程交互
-				description += "\n登入类型: 远程交互式登入 (RDP)"
-			case 11: // 缓存交互
-				description += "\n登入类型: 缓存交互式登入"
-			default:
-				description += fmt.Sprintf("\n登入类型: 未知 (%d)", logonType)
+	switch evt.EventID {
+	case 4624, 4625, 4648, 4647:
+		if logonType, ok := evt.EventData["LogonType"].(string); ok {
+			if name, ok := logonTypeNames[logonType]; ok {
+				description += "\n登入类型: " + name
+			} else {
+				description += fmt.Sprintf("\n登入类型: 未知 (%s)", logonType)
 			}
 		}
 	}
 
-	// 尝试获取更多详细信息
-	if eventData, err := event.GetMap(&eventDataPath); err == nil {
-		// 遍历事件数据，添加到描述中
-		for key, value := range *eventData {
-			// 跳过一些不重要的字段
-			if key == "SubjectUserSid" || key == "SubjectUserName" || key == "SubjectDomainName" {
-				continue
-			}
-			description += fmt.Sprintf("\n%s: %v", key, value)
+	keys := make([]string, 0, len(evt.EventData))
+	for key := range evt.EventData {
+		// 跳过一些不重要的字段
+		if key == "SubjectUserSid" || key == "SubjectUserName" || key == "SubjectDomainName" || key == "Message" {
+			continue
 		}
+		keys = append(keys, key)
 	}
-
-	// 尝试获取消息
-	messagePath := evtx.Path("System/EventData/Data[@Name='Message']")
-	if message, err := event.GetString(&messagePath); err == nil && message != "" {
-		description += fmt.Sprintf("\n消息: %s", message)
+	sort.Strings(keys)
+	for _, key := range keys {
+		description += fmt.Sprintf("\n%s: %v", key, evt.EventData[key])
 	}
 
-	log.Printf("事件描述: %s", description)
+	if evt.Message != "" {
+		description += fmt.Sprintf("\n消息: %s", evt.Message)
+	}
 	return description
 }
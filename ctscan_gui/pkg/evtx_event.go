@@ -0,0 +1,700 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// evtxEventSchema 解析后的EVTX事件，时间统一为 UTC
+const evtxEventSchema = `CREATE TABLE IF NOT EXISTS evtx_event (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	source_file TEXT,
+	record_id INTEGER,
+	time DATETIME,
+	event_id INTEGER,
+	provider TEXT,
+	level TEXT,
+	channel TEXT,
+	computer TEXT,
+	user_id TEXT,
+	description TEXT,
+	version INTEGER,
+	qualifiers INTEGER,
+	task INTEGER,
+	opcode INTEGER,
+	keywords TEXT,
+	process_id INTEGER,
+	thread_id INTEGER,
+	message TEXT,
+	event_data TEXT,
+	user_data TEXT,
+	system_info TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// evtxFileSchema 导入过的EVTX文件及进度，chunks_done 与事件在同一事务中更新，用于断点续传
+const evtxFileSchema = `CREATE TABLE IF NOT EXISTS evtx_file (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	path TEXT,
+	size INTEGER,
+	mod_time DATETIME,
+	chunks INTEGER DEFAULT 0,
+	chunks_done INTEGER DEFAULT 0,
+	events INTEGER DEFAULT 0,
+	status TEXT,
+	error TEXT,
+	started_at DATETIME,
+	finished_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// evtxEventIndexes 事件表按会话查询时使用的索引
+var evtxEventIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_evtx_event_time ON evtx_event (session_id, time)`,
+	`CREATE INDEX IF NOT EXISTS idx_evtx_event_id ON evtx_event (session_id, event_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_evtx_event_source ON evtx_event (session_id, source_file, record_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_evtx_file_path ON evtx_file (session_id, path)`,
+}
+
+// migrateEVTXEvent 新增EVTX事件表与文件导入进度表
+func migrateEVTXEvent(tx *sql.Tx) error {
+	return execAll(tx, append([]string{evtxEventSchema, evtxFileSchema}, evtxEventIndexes...)...)
+}
+
+// migrateEVTXProvenance 记录EVTX文件的哈希、原始计算机名、所在压缩包与损坏的块数
+func migrateEVTXProvenance(tx *sql.Tx) error {
+	for _, c := range []struct{ table, column, definition string }{
+		{"evtx_file", "sha256", "TEXT"},
+		{"evtx_file", "computer", "TEXT"},
+		{"evtx_file", "archive", "TEXT"},
+		{"evtx_file", "corrupt_chunks", "INTEGER DEFAULT 0"},
+		{"evtx_event", "source_sha256", "TEXT"},
+	} {
+		if err := addColumn(tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateEVTXRecovery 记录文件的导入模式，以及事件在文件中的偏移与是否为恢复的记录
+func migrateEVTXRecovery(tx *sql.Tx) error {
+	for _, c := range []struct{ table, column, definition string }{
+		{"evtx_file", "recovery", "INTEGER DEFAULT 0"},
+		{"evtx_event", "recovered", "INTEGER DEFAULT 0"},
+		{"evtx_event", "record_offset", "INTEGER DEFAULT 0"},
+	} {
+		if err := addColumn(tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evtxBatchSize 每个事务写入的事件数，事务只在块的边界提交
+const evtxBatchSize = 1000
+
+// EVTX 文件导入状态
+const (
+	EVTXFileParsing  = "parsing"
+	EVTXFileDone     = "done"
+	EVTXFileCanceled = "canceled"
+	EVTXFileFailed   = "failed"
+)
+
+// EVTXFile 导入过的EVTX文件
+type EVTXFile struct {
+	ID            int64  `json:"id"`
+	SessionID     string `json:"session_id"`
+	Path          string `json:"path"`    // 压缩包中的文件为 <压缩包>!/<文件>
+	Archive       string `json:"archive"` // 所在的压缩包
+	Size          int64  `json:"size"`
+	ModTime       string `json:"mod_time"`
+	SHA256        string `json:"sha256"`
+	Computer      string `json:"computer"` // 日志中记录的原始计算机名
+	Chunks        int    `json:"chunks"`
+	ChunksDone    int    `json:"chunks_done"`
+	CorruptChunks int    `json:"corrupt_chunks"`
+	Recovery      bool   `json:"recovery"` // 以恢复模式导入，块数为按 64KB 划分的分段数
+	Events        int    `json:"events"`
+	Status        string `json:"status"`
+	Error         string `json:"error"` // 失败原因，或最后一个损坏块的错误
+	StartedAt     string `json:"started_at"`
+	FinishedAt    string `json:"finished_at"`
+}
+
+// evtxSource 待导入的EVTX文件
+type evtxSource struct {
+	name     string // 记录到数据库中的来源文件
+	path     string // 实际读取的文件，压缩包中的文件为解压后的临时文件
+	archive  string
+	recovery bool  // 以恢复模式导入
+	err      error // 压缩包无法读取等导入前就已发生的错误
+}
+
+// EVTXQuery 事件查询条件，字段为空表示不限制
+type EVTXQuery struct {
+	SessionID  string `json:"session_id"` // 为空时使用当前会话或最近一次会话
+	SourceFile string `json:"source_file"`
+	Start      string `json:"start"`     // UTC，格式 2006-01-02 15:04:05
+	End        string `json:"end"`       // UTC，包含该时间
+	EventIDs   string `json:"event_ids"` // 逗号分隔，如 4624,4625
+	Provider   string `json:"provider"`
+	Channel    string `json:"channel"`
+	Computer   string `json:"computer"`
+	Keyword    string `json:"keyword"`   // 在描述、用户数据与提供者中模糊匹配
+	Recovered  bool   `json:"recovered"` // 只查询恢复模式中找回的记录
+	Page       int    `json:"page"`      // 从 1 开始
+	PageSize   int    `json:"page_size"`
+}
+
+// EVTXPage 一页查询结果
+type EVTXPage struct {
+	SessionID string      `json:"session_id"`
+	Total     int         `json:"total"`
+	Page      int         `json:"page"`
+	PageSize  int         `json:"page_size"`
+	Events    []EVTXEvent `json:"events"`
+}
+
+// EVTXFilterOptions 会话中可用于筛选的取值
+type EVTXFilterOptions struct {
+	SourceFiles []string `json:"source_files"`
+	Providers   []string `json:"providers"`
+	Channels    []string `json:"channels"`
+	Computers   []string `json:"computers"`
+}
+
+// 分页大小
+const (
+	evtxDefaultPageSize = 100
+	evtxMaxPageSize     = 1000
+)
+
+const evtxEventColumns = `source_file, source_sha256, record_id, time, event_id, provider, level, channel, computer, user_id,
+	description, version, qualifiers, task, opcode, keywords, process_id, thread_id, message,
+	event_data, user_data, system_info, recovered, record_offset`
+
+// ingestEVTXFile 导入EVTX文件，取消时已写入的事件会保留，返回文件的导入状态
+// 文件中损坏的块不影响其余块的导入，只记录损坏的块数与错误
+// 每批事件写入前运行 Sigma 规则并提取 Windows 安全事件，结果与事件在同一事务中写入
+func (a *App) ingestEVTXFile(ctx context.Context, sessionID string, src evtxSource, rules []*sigmaRule) (EVTXFile, error) {
+	info, err := os.Stat(src.path)
+	if err != nil {
+		return EVTXFile{}, fmt.Errorf("文件不存在: %s", src.path)
+	}
+	hash, err := fileSHA256(src.path)
+	if err != nil {
+		return EVTXFile{}, fmt.Errorf("计算文件哈希失败: %v", err)
+	}
+	file, err := a.prepareEVTXFile(sessionID, src, info, hash)
+	if err != nil {
+		return EVTXFile{}, err
+	}
+	if file.Status == EVTXFileDone {
+		return file, nil
+	}
+
+	var batch []EVTXEvent
+	chunksDone, corrupt, chunkErr := file.ChunksDone, 0, ""
+	flush := func() error {
+		if len(batch) == 0 && chunksDone == file.ChunksDone {
+			return nil
+		}
+		derived := deriveEVTXBatch(rules, batch)
+		if err := a.saveEVTXBatch(&file, batch, derived, chunksDone, corrupt, chunkErr); err != nil {
+			return err
+		}
+		batch, corrupt = batch[:0], 0
+		return nil
+	}
+	stream := streamEVTXFile
+	if src.recovery {
+		stream = streamRecoveredEVTX
+	}
+	err = stream(ctx, src.path, file.ChunksDone, func(chunk, chunks int, events []EVTXEvent, cerr error) error {
+		file.Chunks = chunks
+		if cerr != nil {
+			corrupt++
+			chunkErr = cerr.Error()
+		}
+		for i := range events {
+			events[i].SourceFile = src.name
+			events[i].SourceHash = hash
+		}
+		batch = append(batch, events...)
+		chunksDone = chunk + 1
+		if len(batch) >= evtxBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	// 取消或出错时保留已完成的块，下次导入时继续
+	if flushErr := flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+
+	file.Status = EVTXFileDone
+	switch {
+	case errors.Is(err, context.Canceled):
+		file.Status = EVTXFileCanceled
+	case err != nil:
+		file.Status, file.Error = EVTXFileFailed, err.Error()
+	}
+	finished := time.Now()
+	a.evtxMu.Lock()
+	_, dbErr := a.database().Exec(`UPDATE evtx_file SET status = ?, error = ?, finished_at = ? WHERE id = ?`,
+		file.Status, file.Error, finished, file.ID)
+	a.evtxMu.Unlock()
+	if dbErr != nil && err == nil {
+		err = fmt.Errorf("更新EVTX文件状态失败: %v", dbErr)
+	}
+	file.FinishedAt = finished.Format(sessionTimeLayout)
+	return file, err
+}
+
+// prepareEVTXFile 获取文件在会话中的导入记录，不存在时新建，文件内容或导入模式已变化时清除旧的事件重新导入
+func (a *App) prepareEVTXFile(sessionID string, src evtxSource, info os.FileInfo, hash string) (EVTXFile, error) {
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+
+	row := a.database().QueryRow(`SELECT `+evtxFileColumns+`
+		FROM evtx_file WHERE session_id = ? AND path = ? ORDER BY id DESC LIMIT 1`, sessionID, src.name)
+	file, err := scanEVTXFile(row)
+	now := time.Now()
+	if err == sql.ErrNoRows {
+		res, err := a.database().Exec(`INSERT INTO evtx_file (session_id, path, archive, size, mod_time, sha256, computer,
+			recovery, status, error, started_at)
+			VALUES (?, ?, ?, ?, ?, ?, '', ?, ?, '', ?)`,
+			sessionID, src.name, src.archive, info.Size(), info.ModTime(), hash, src.recovery, EVTXFileParsing, now)
+		if err != nil {
+			return EVTXFile{}, fmt.Errorf("保存EVTX文件记录失败: %v", err)
+		}
+		file.ID, _ = res.LastInsertId()
+		file.SessionID, file.Path, file.Archive = sessionID, src.name, src.archive
+		file.Size, file.SHA256, file.Recovery = info.Size(), hash, src.recovery
+		file.ModTime = info.ModTime().Format(sessionTimeLayout)
+		file.Status, file.StartedAt = EVTXFileParsing, now.Format(sessionTimeLayout)
+		return file, nil
+	}
+	if err != nil {
+		return EVTXFile{}, fmt.Errorf("读取EVTX文件记录失败: %v", err)
+	}
+
+	if file.SHA256 == hash && file.Recovery == src.recovery {
+		if file.Status != EVTXFileDone {
+			// 上次失败的原因不再适用，损坏块的错误需要保留
+			if file.Status == EVTXFileFailed && file.CorruptChunks == 0 {
+				file.Error = ""
+			}
+			file.Status = EVTXFileParsing
+			if _, err := a.database().Exec(`UPDATE evtx_file SET status = ?, error = ? WHERE id = ?`, file.Status, file.Error, file.ID); err != nil {
+				return EVTXFile{}, fmt.Errorf("更新EVTX文件状态失败: %v", err)
+			}
+		}
+		return file, nil
+	}
+
+	// 文件在两次导入之间被修改，已导入的事件不再可信；切换导入模式时块的划分不同，同样需要重新导入
+	tx, err := a.database().Begin()
+	if err != nil {
+		return EVTXFile{}, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM evtx_event WHERE session_id = ? AND source_file = ?`, sessionID, src.name); err != nil {
+		return EVTXFile{}, fmt.Errorf("清除旧的EVTX事件失败: %v", err)
+	}
+	for _, table := range evtxDerivedTables() {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id = ? AND source_file = ?`, sessionID, src.name); err != nil {
+			return EVTXFile{}, fmt.Errorf("清除旧的规则命中与提取记录失败: %v", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE evtx_file SET archive = ?, size = ?, mod_time = ?, sha256 = ?, computer = '',
+		chunks = 0, chunks_done = 0, corrupt_chunks = 0, events = 0, recovery = ?,
+		status = ?, error = '', started_at = ?, finished_at = NULL WHERE id = ?`,
+		src.archive, info.Size(), info.ModTime(), hash, src.recovery, EVTXFileParsing, now, file.ID); err != nil {
+		return EVTXFile{}, fmt.Errorf("更新EVTX文件记录失败: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return EVTXFile{}, fmt.Errorf("提交事务失败: %v", err)
+	}
+	return EVTXFile{
+		ID:        file.ID,
+		SessionID: sessionID,
+		Path:      src.name,
+		Archive:   src.archive,
+		Size:      info.Size(),
+		ModTime:   info.ModTime().Format(sessionTimeLayout),
+		SHA256:    hash,
+		Recovery:  src.recovery,
+		Status:    EVTXFileParsing,
+		StartedAt: now.Format(sessionTimeLayout),
+	}, nil
+}
+
+// evtxDerived 由一批事件得到的规则命中与结构化记录
+type evtxDerived struct {
+	hits      []SigmaHit
+	winEvents winEventRows
+}
+
+// deriveEVTXBatch 对一批事件运行 Sigma 规则与 Windows 安全事件提取器
+func deriveEVTXBatch(rules []*sigmaRule, events []EVTXEvent) evtxDerived {
+	return evtxDerived{hits: matchSigmaRules(rules, events), winEvents: extractWinEvents(events)}
+}
+
+// evtxDerivedTables 由事件得到的数据表，事件来源文件变化时一起清除
+func evtxDerivedTables() []string {
+	return append([]string{"sigma_hit"}, winEventTables()...)
+}
+
+// saveEVTXBatch 在一个事务中写入一批事件及其规则命中与结构化记录，并更新文件的导入进度
+// corrupt 为这批事件所在块中损坏的块数，chunkErr 为最后一个损坏块的错误
+func (a *App) saveEVTXBatch(file *EVTXFile, events []EVTXEvent, derived evtxDerived, chunksDone, corrupt int, chunkErr string) error {
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+
+	tx, err := a.database().Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO evtx_event (session_id, ` + evtxEventColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		created, _ := time.Parse(sessionTimeLayout, e.Time)
+		_, err := stmt.Exec(file.SessionID, e.SourceFile, e.SourceHash, e.EventRecordID, created, e.EventID,
+			e.Provider, e.Level, e.Channel, e.Computer, e.UserID, e.Description, e.Version, e.Qualifiers,
+			e.Task, e.Opcode, e.Keywords, e.ProcessID, e.ThreadID, e.Message,
+			jsonText(e.EventData), jsonText(e.UserData), jsonText(e.SystemInfo), e.Recovered, e.Offset)
+		if err != nil {
+			return fmt.Errorf("插入EVTX事件失败: %v", err)
+		}
+	}
+	if err := insertSigmaHits(tx, file.SessionID, derived.hits); err != nil {
+		return err
+	}
+	if err := insertWinEvents(tx, file.SessionID, derived.winEvents); err != nil {
+		return err
+	}
+
+	// 以第一个事件的计算机名作为日志的原始计算机名
+	computer := file.Computer
+	if computer == "" {
+		for _, e := range events {
+			if e.Computer != "" {
+				computer = e.Computer
+				break
+			}
+		}
+	}
+	errText := file.Error
+	if chunkErr != "" {
+		errText = chunkErr
+	}
+	if _, err := tx.Exec(`UPDATE evtx_file SET chunks = ?, chunks_done = ?, events = events + ?,
+		corrupt_chunks = corrupt_chunks + ?, computer = ?, error = ? WHERE id = ?`,
+		file.Chunks, chunksDone, len(events), corrupt, computer, errText, file.ID); err != nil {
+		return fmt.Errorf("更新EVTX文件进度失败: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	file.ChunksDone = chunksDone
+	file.Events += len(events)
+	file.CorruptChunks += corrupt
+	file.Computer, file.Error = computer, errText
+	return nil
+}
+
+// jsonText 将事件数据序列化为 JSON 文本保存
+func jsonText(v map[string]any) string {
+	if len(v) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+const evtxFileColumns = `id, session_id, path, archive, size, mod_time, sha256, computer, chunks, chunks_done,
+	corrupt_chunks, recovery, events, status, error, started_at, finished_at`
+
+func scanEVTXFile(row rowScanner) (EVTXFile, error) {
+	var (
+		file                       EVTXFile
+		modTime, started, finished sql.NullTime
+		archive, hash, computer    sql.NullString
+		status, errText            sql.NullString
+		corrupt                    sql.NullInt64
+		recovery                   sql.NullBool
+	)
+	err := row.Scan(&file.ID, &file.SessionID, &file.Path, &archive, &file.Size, &modTime, &hash, &computer,
+		&file.Chunks, &file.ChunksDone, &corrupt, &recovery, &file.Events, &status, &errText, &started, &finished)
+	if err != nil {
+		return EVTXFile{}, err
+	}
+	file.Archive, file.SHA256, file.Computer = archive.String, hash.String, computer.String
+	file.CorruptChunks, file.Recovery = int(corrupt.Int64), recovery.Bool
+	file.Status, file.Error = status.String, errText.String
+	file.ModTime = formatNullTime(modTime)
+	file.StartedAt = formatNullTime(started)
+	file.FinishedAt = formatNullTime(finished)
+	return file, nil
+}
+
+// ListEVTXFiles 列出会话中导入过的EVTX文件，sessionID 为空时使用当前会话或最近一次会话
+func (a *App) ListEVTXFiles(sessionID string) ([]EVTXFile, error) {
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.database().Query(`SELECT `+evtxFileColumns+` FROM evtx_file WHERE session_id = ? ORDER BY id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("查询EVTX文件失败: %v", err)
+	}
+	defer rows.Close()
+
+	files := []EVTXFile{}
+	for rows.Next() {
+		file, err := scanEVTXFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取EVTX文件失败: %v", err)
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// evtxSessionID 查询事件时使用的会话，未指定时使用当前会话或最近一次会话
+func (a *App) evtxSessionID(sessionID string) (string, error) {
+	if sessionID != "" {
+		return sessionID, nil
+	}
+	return a.exportSessionID()
+}
+
+// where 生成查询条件
+func (q EVTXQuery) where(sessionID string) (string, []any, error) {
+	conds := []string{"session_id = ?"}
+	args := []any{sessionID}
+
+	if q.SourceFile != "" {
+		conds = append(conds, "source_file = ?")
+		args = append(args, q.SourceFile)
+	}
+	if q.Start != "" {
+		start, err := time.Parse(sessionTimeLayout, q.Start)
+		if err != nil {
+			return "", nil, fmt.Errorf("开始时间格式错误: %s", q.Start)
+		}
+		conds = append(conds, "time >= ?")
+		args = append(args, start)
+	}
+	if q.End != "" {
+		end, err := time.Parse(sessionTimeLayout, q.End)
+		if err != nil {
+			return "", nil, fmt.Errorf("结束时间格式错误: %s", q.End)
+		}
+		// 包含结束时间所在的整秒
+		conds = append(conds, "time < ?")
+		args = append(args, end.Add(time.Second))
+	}
+	if strings.TrimSpace(q.EventIDs) != "" {
+		var marks []string
+		for _, s := range strings.FieldsFunc(q.EventIDs, func(r rune) bool { return r == ',' || r == ' ' || r == '，' }) {
+			id, err := strconv.Atoi(s)
+			if err != nil {
+				return "", nil, fmt.Errorf("事件ID格式错误: %s", s)
+			}
+			marks = append(marks, "?")
+			args = append(args, id)
+		}
+		if len(marks) > 0 {
+			conds = append(conds, "event_id IN ("+strings.Join(marks, ", ")+")")
+		}
+	}
+	for _, f := range []struct{ column, value string }{
+		{"provider", q.Provider},
+		{"channel", q.Channel},
+		{"computer", q.Computer},
+	} {
+		if f.value != "" {
+			conds = append(conds, f.column+" = ?")
+			args = append(args, f.value)
+		}
+	}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		conds = append(conds, "(description LIKE ? OR user_data LIKE ? OR provider LIKE ?)")
+		args = append(args, like, like, like)
+	}
+	if q.Recovered {
+		conds = append(conds, "recovered = 1")
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// QueryEVTXEvents 分页查询会话中的EVTX事件，按时间排序
+func (a *App) QueryEVTXEvents(q EVTXQuery) (EVTXPage, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return EVTXPage{}, err
+	}
+	where, args, err := q.where(sessionID)
+	if err != nil {
+		return EVTXPage{}, err
+	}
+	page := EVTXPage{SessionID: sessionID, Page: q.Page, PageSize: q.PageSize, Events: []EVTXEvent{}}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize < 1 {
+		page.PageSize = evtxDefaultPageSize
+	}
+	if page.PageSize > evtxMaxPageSize {
+		page.PageSize = evtxMaxPageSize
+	}
+
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM evtx_event WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return EVTXPage{}, fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	args = append(args, page.PageSize, (page.Page-1)*page.PageSize)
+	page.Events, err = a.queryEVTXEvents(where+` ORDER BY time, source_file, record_id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return EVTXPage{}, err
+	}
+	return page, nil
+}
+
+// queryEVTXEvents 按条件读取事件
+func (a *App) queryEVTXEvents(where string, args ...any) ([]EVTXEvent, error) {
+	rows, err := a.database().Query(`SELECT `+evtxEventColumns+` FROM evtx_event WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	defer rows.Close()
+
+	events := []EVTXEvent{}
+	for rows.Next() {
+		var (
+			e                               EVTXEvent
+			created                         sql.NullTime
+			eventData, userData, systemInfo sql.NullString
+		)
+		var hash sql.NullString
+		var recovered sql.NullBool
+		var offset sql.NullInt64
+		if err := rows.Scan(&e.SourceFile, &hash, &e.EventRecordID, &created, &e.EventID, &e.Provider, &e.Level,
+			&e.Channel, &e.Computer, &e.UserID, &e.Description, &e.Version, &e.Qualifiers, &e.Task,
+			&e.Opcode, &e.Keywords, &e.ProcessID, &e.ThreadID, &e.Message,
+			&eventData, &userData, &systemInfo, &recovered, &offset); err != nil {
+			return nil, fmt.Errorf("读取EVTX事件失败: %v", err)
+		}
+		e.SourceHash = hash.String
+		e.Recovered, e.Offset = recovered.Bool, offset.Int64
+		if created.Valid {
+			e.Time = created.Time.UTC().Format(sessionTimeLayout)
+		}
+		e.EventData = jsonMap(eventData.String)
+		e.UserData = jsonMap(userData.String)
+		e.SystemInfo = jsonMap(systemInfo.String)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// scanEVTXEvents 按ID分批读取会话中的事件，fn 返回的文本作为进度信息
+func (a *App) scanEVTXEvents(ctx context.Context, sessionID string, fn func(events []EVTXEvent) string) error {
+	var minID, maxID sql.NullInt64
+	if err := a.database().QueryRow(`SELECT MIN(id), MAX(id) FROM evtx_event WHERE session_id = ?`, sessionID).
+		Scan(&minID, &maxID); err != nil {
+		return fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	if !minID.Valid {
+		return fmt.Errorf("会话中没有EVTX事件")
+	}
+
+	progress := progressFrom(ctx)
+	progress.SetTotal(int((maxID.Int64-minID.Int64)/evtxBatchSize + 1))
+	for from := minID.Int64; from <= maxID.Int64; from += evtxBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		events, err := a.queryEVTXEvents(`session_id = ? AND id >= ? AND id < ?`, sessionID, from, from+evtxBatchSize)
+		if err != nil {
+			return err
+		}
+		progress.Step(fn(events))
+	}
+	return nil
+}
+
+func jsonMap(s string) map[string]any {
+	m := map[string]any{}
+	if s != "" {
+		json.Unmarshal([]byte(s), &m)
+	}
+	return m
+}
+
+// GetEVTXFilterOptions 返回会话中出现过的来源文件、提供者、通道与计算机名
+func (a *App) GetEVTXFilterOptions(sessionID string) (EVTXFilterOptions, error) {
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return EVTXFilterOptions{}, err
+	}
+	var opts EVTXFilterOptions
+	for _, f := range []struct {
+		column string
+		values *[]string
+	}{
+		{"source_file", &opts.SourceFiles},
+		{"provider", &opts.Providers},
+		{"channel", &opts.Channels},
+		{"computer", &opts.Computers},
+	} {
+		values, err := a.distinctEVTXValues(sessionID, f.column)
+		if err != nil {
+			return EVTXFilterOptions{}, err
+		}
+		*f.values = values
+	}
+	return opts, nil
+}
+
+func (a *App) distinctEVTXValues(sessionID, column string) ([]string, error) {
+	rows, err := a.database().Query(fmt.Sprintf(`SELECT DISTINCT %s FROM evtx_event
+		WHERE session_id = ? AND %s != '' ORDER BY %s`, column, column, column), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,522 @@
+package pkg
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 只适用于EVTX事件的导出格式
+const (
+	// ExportEVTXXML Windows 事件 XML，与 wevtutil qe /f:xml /e:Events 的输出结构相同
+	ExportEVTXXML = "xml"
+	// ExportECS Elastic Common Schema 文档，Elasticsearch/OpenSearch 的 _bulk 批量导入格式
+	ExportECS = "ndjson"
+)
+
+// EVTXExportFormats 导出EVTX事件时支持的格式
+var EVTXExportFormats = append(append([]string{}, ExportFormats...), ExportEVTXXML, ExportECS)
+
+const (
+	// evtxECSIndex 批量导入时默认的索引名
+	evtxECSIndex = "ctscan-evtx"
+	ecsVersion   = "8.11.0"
+	evtxXMLNS    = "http://schemas.microsoft.com/win/2004/08/events/event"
+)
+
+// evtxExport 待导出的事件，写入时按ID分批读取，不一次性加载到内存
+type evtxExport struct {
+	a         *App
+	sessionID string
+	where     string
+	args      []any
+	minID     int64
+	maxID     int64
+	count     int
+}
+
+// evtxExportEvents 查询符合条件的所有事件(不分页)，事件按导入顺序(ID)输出
+func (a *App) evtxExportEvents(q EVTXQuery) (*evtxExport, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	where, args, err := q.where(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	x := &evtxExport{a: a, sessionID: sessionID, where: where, args: args}
+	var minID, maxID sql.NullInt64
+	if err := a.database().QueryRow(`SELECT MIN(id), MAX(id), COUNT(*) FROM evtx_event WHERE `+where, args...).
+		Scan(&minID, &maxID, &x.count); err != nil {
+		return nil, fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	if x.count == 0 {
+		return nil, fmt.Errorf("没有符合条件的事件")
+	}
+	x.minID, x.maxID = minID.Int64, maxID.Int64
+	return x, nil
+}
+
+// each 按ID分批读取事件，与 scanEVTXEvents 相同，每批调用一次 fn
+func (x *evtxExport) each(fn func(events []EVTXEvent) error) error {
+	for from := x.minID; from <= x.maxID; from += evtxBatchSize {
+		args := append(append([]any{}, x.args...), from, from+evtxBatchSize)
+		events, err := x.a.queryEVTXEvents(x.where+` AND id >= ? AND id < ? ORDER BY id`, args...)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			continue
+		}
+		if err := fn(events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEVTXEvents 按格式将事件写入 output，output 为 - 时写到标准输出
+// index 为批量导入的索引名，只用于 ndjson
+func writeEVTXEvents(output string, x *evtxExport, format, index string) error {
+	if !containsString(EVTXExportFormats, format) {
+		return fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	if format != ExportEVTXXML && format != ExportECS {
+		ds, err := structDataset("evtx_event", x.each)
+		if err != nil {
+			return err
+		}
+		_, err = writeDatasets([]exportDataset{ds}, format, output)
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if output != "-" {
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+		var err error
+		if f, err = os.Create(output); err != nil {
+			return fmt.Errorf("创建文件失败: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	var err error
+	if format == ExportEVTXXML {
+		err = writeEVTXXML(bw, x.each)
+	} else {
+		err = writeECSBulk(bw, x.sessionID, x.each, index)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if f != nil {
+		return f.Close()
+	}
+	return nil
+}
+
+// writeEVTXXML 以 Windows 事件 XML 格式写出事件，根节点为 Events
+// 解析时事件数据已转换为键值对，EventData 中 Data 的顺序按名称排序，UserData 中的属性无法还原
+func writeEVTXXML(w io.Writer, batches func(fn func(events []EVTXEvent) error) error) error {
+	if _, err := io.WriteString(w, "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<Events>\n"); err != nil {
+		return err
+	}
+	err := batches(func(events []EVTXEvent) error {
+		for _, e := range events {
+			if _, err := io.WriteString(w, evtxEventXML(e)+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "</Events>\n")
+	return err
+}
+
+// evtxEventXML 生成一个事件的 XML，属性使用单引号，与 wevtutil 一致
+func evtxEventXML(e EVTXEvent) string {
+	var b strings.Builder
+	if e.Recovered {
+		fmt.Fprintf(&b, "<!-- 恢复的记录: %s 偏移 0x%x -->", xmlComment(e.SourceFile), e.Offset)
+	}
+	b.WriteString("<Event xmlns='" + evtxXMLNS + "'><System>")
+
+	provider := evtxSystemMap(e.SystemInfo, "Provider")
+	b.WriteString("<Provider" + xmlAttr("Name", e.Provider) + xmlAttr("Guid", provider["Guid"]) +
+		xmlAttr("EventSourceName", provider["EventSourceName"]) + "/>")
+	if e.Qualifiers != 0 {
+		fmt.Fprintf(&b, "<EventID Qualifiers='%d'>%d</EventID>", e.Qualifiers, e.EventID)
+	} else {
+		fmt.Fprintf(&b, "<EventID>%d</EventID>", e.EventID)
+	}
+	fmt.Fprintf(&b, "<Version>%d</Version>", e.Version)
+	b.WriteString(xmlElement("Level", exportString(e.SystemInfo["Level"])))
+	fmt.Fprintf(&b, "<Task>%d</Task><Opcode>%d</Opcode>", e.Task, e.Opcode)
+	b.WriteString(xmlElement("Keywords", e.Keywords))
+	b.WriteString("<TimeCreated" + xmlAttr("SystemTime", evtxSystemTime(e)) + "/>")
+	fmt.Fprintf(&b, "<EventRecordID>%d</EventRecordID>", e.EventRecordID)
+	correlation := evtxSystemMap(e.SystemInfo, "Correlation")
+	b.WriteString("<Correlation" + xmlAttr("ActivityID", correlation["ActivityID"]) +
+		xmlAttr("RelatedActivityID", correlation["RelatedActivityID"]) + "/>")
+	fmt.Fprintf(&b, "<Execution ProcessID='%d' ThreadID='%d'/>", e.ProcessID, e.ThreadID)
+	b.WriteString(xmlElement("Channel", e.Channel))
+	b.WriteString(xmlElement("Computer", e.Computer))
+	b.WriteString("<Security" + xmlAttr("UserID", e.UserID) + "/>")
+	b.WriteString("</System>")
+
+	if len(e.EventData) > 0 {
+		b.WriteString("<EventData>")
+		for _, k := range evtxDataKeys(e.EventData) {
+			v := exportString(e.EventData[k])
+			switch {
+			case k == "Binary":
+				b.WriteString(xmlElement("Binary", v))
+			case evtxUnnamedDataRe.MatchString(k):
+				b.WriteString(xmlElement("Data", v))
+			case v == "":
+				b.WriteString("<Data" + xmlAttr("Name", k) + "/>")
+			default:
+				b.WriteString("<Data" + xmlAttr("Name", k) + ">" + xmlText(v) + "</Data>")
+			}
+		}
+		b.WriteString("</EventData>")
+	}
+	if len(e.UserData) > 0 {
+		b.WriteString("<UserData>")
+		writeXMLNodes(&b, e.UserData)
+		b.WriteString("</UserData>")
+	}
+	b.WriteString("</Event>")
+	return b.String()
+}
+
+// evtxUnnamedDataRe 没有 Name 属性的 Data 节点，解析库以 Data、Data1、Data2… 作为键
+var evtxUnnamedDataRe = regexp.MustCompile(`^Data\d*$`)
+
+// evtxDataKeys 返回 EventData 的键，没有名称的 Data 按出现的顺序排在前面，其余按名称排序
+func evtxDataKeys(m map[string]any) []string {
+	keys := sortedKeys(m)
+	index := func(k string) int {
+		if !evtxUnnamedDataRe.MatchString(k) {
+			return -1
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(k, "Data"))
+		return n
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := index(keys[i]), index(keys[j])
+		if a >= 0 && b >= 0 {
+			return a < b
+		}
+		return a >= 0 && b < 0
+	})
+	return keys
+}
+
+// writeXMLNodes 将嵌套的键值对写为子节点
+func writeXMLNodes(b *strings.Builder, m map[string]any) {
+	for _, k := range sortedKeys(m) {
+		switch v := m[k].(type) {
+		case map[string]any:
+			b.WriteString("<" + k + ">")
+			writeXMLNodes(b, v)
+			b.WriteString("</" + k + ">")
+		case []any:
+			for _, item := range v {
+				if child, ok := item.(map[string]any); ok {
+					b.WriteString("<" + k + ">")
+					writeXMLNodes(b, child)
+					b.WriteString("</" + k + ">")
+				} else {
+					b.WriteString(xmlElement(k, exportString(item)))
+				}
+			}
+		default:
+			b.WriteString(xmlElement(k, exportString(v)))
+		}
+	}
+}
+
+// evtxSystemMap 返回 System 下的子节点，只有一个属性的节点会被解析为字符串，此时返回空
+func evtxSystemMap(system map[string]any, key string) map[string]string {
+	out := map[string]string{}
+	if m, ok := system[key].(map[string]any); ok {
+		for k, v := range m {
+			out[k] = exportString(v)
+		}
+	}
+	return out
+}
+
+// evtxSystemTime 事件的原始时间，保留 100 纳秒精度，缺少时使用入库的时间
+func evtxSystemTime(e EVTXEvent) string {
+	if t := evtxSystemMap(e.SystemInfo, "TimeCreated")["SystemTime"]; t != "" {
+		return t
+	}
+	t, err := time.Parse(sessionTimeLayout, e.Time)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.0000000Z")
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xmlAttr 生成属性，值为空时省略
+func xmlAttr(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return " " + name + "='" + xmlText(value) + "'"
+}
+
+// xmlElement 生成只有文本的节点，文本为空时为自闭合节点
+func xmlElement(name, text string) string {
+	if text == "" {
+		return "<" + name + "/>"
+	}
+	return "<" + name + ">" + xmlText(text) + "</" + name + ">"
+}
+
+// xmlComment 注释中不能出现 --
+func xmlComment(s string) string {
+	return strings.ReplaceAll(s, "--", "- -")
+}
+
+// writeECSBulk 以 _bulk 格式写出 ECS 文档，每个事件一行操作一行文档
+// 文档ID由来源文件哈希、记录偏移与记录号生成，重复导入同一份数据不会产生重复的文档
+func writeECSBulk(w io.Writer, sessionID string, batches func(fn func(events []EVTXEvent) error) error, index string) error {
+	if index == "" {
+		index = evtxECSIndex
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return batches(func(events []EVTXEvent) error {
+		for _, e := range events {
+			action := map[string]any{"index": map[string]any{"_index": index, "_id": ecsDocumentID(e)}}
+			if err := enc.Encode(action); err != nil {
+				return err
+			}
+			if err := enc.Encode(ecsDocument(sessionID, e)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func ecsDocumentID(e EVTXEvent) string {
+	source := e.SourceHash
+	if source == "" {
+		source = e.SourceFile
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", source, e.Offset, e.EventRecordID)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ecsLogLevels 事件级别对应的 log.level
+var ecsLogLevels = map[string]string{
+	"0": "information", "1": "critical", "2": "error", "3": "warning", "4": "information", "5": "verbose",
+}
+
+// 审核成功与审核失败的关键字位
+const (
+	evtxKeywordAuditFailure = 0x10000000000000
+	evtxKeywordAuditSuccess = 0x20000000000000
+)
+
+// ecsDocument 将事件转换为 ECS 文档，字段与 Winlogbeat 的 winlog.* 一致
+// user、source、destination 与 process 从常见的事件数据字段中提取
+func ecsDocument(sessionID string, e EVTXEvent) map[string]any {
+	doc := map[string]any{}
+	timestamp := e.Time
+	if t, err := time.Parse(sessionTimeLayout, e.Time); err == nil {
+		timestamp = t.UTC().Format(time.RFC3339)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, evtxSystemTime(e)); err == nil {
+		timestamp = t.UTC().Format("2006-01-02T15:04:05.000Z")
+	}
+	ecsSet(doc, "@timestamp", timestamp)
+	ecsSet(doc, "ecs.version", ecsVersion)
+	ecsSet(doc, "message", firstNonEmpty(e.Message, e.Description))
+
+	code := strconv.Itoa(e.EventID)
+	ecsSet(doc, "event.kind", "event")
+	ecsSet(doc, "event.code", code)
+	ecsSet(doc, "event.provider", e.Provider)
+	ecsSet(doc, "event.module", "windows")
+	ecsSet(doc, "event.dataset", e.Channel)
+	if keywords, err := strconv.ParseUint(strings.TrimPrefix(e.Keywords, "0x"), 16, 64); err == nil {
+		switch {
+		case keywords&evtxKeywordAuditFailure != 0:
+			ecsSet(doc, "event.outcome", "failure")
+		case keywords&evtxKeywordAuditSuccess != 0:
+			ecsSet(doc, "event.outcome", "success")
+		}
+	}
+	ecsSet(doc, "log.level", ecsLogLevels[exportString(e.SystemInfo["Level"])])
+	ecsSet(doc, "log.file.path", e.SourceFile)
+	ecsSet(doc, "host.name", e.Computer)
+
+	ecsSet(doc, "winlog.channel", e.Channel)
+	ecsSet(doc, "winlog.computer_name", e.Computer)
+	ecsSet(doc, "winlog.event_id", code)
+	ecsSet(doc, "winlog.provider_name", e.Provider)
+	ecsSet(doc, "winlog.provider_guid", evtxSystemMap(e.SystemInfo, "Provider")["Guid"])
+	ecsSet(doc, "winlog.record_id", strconv.Itoa(e.EventRecordID))
+	ecsSet(doc, "winlog.task", strconv.Itoa(e.Task))
+	ecsSet(doc, "winlog.opcode", strconv.Itoa(e.Opcode))
+	ecsSet(doc, "winlog.version", e.Version)
+	ecsSet(doc, "winlog.keywords", e.Keywords)
+	ecsSet(doc, "winlog.activity_id", evtxSystemMap(e.SystemInfo, "Correlation")["ActivityID"])
+	ecsSet(doc, "winlog.process.pid", e.ProcessID)
+	ecsSet(doc, "winlog.process.thread.id", e.ThreadID)
+	ecsSet(doc, "winlog.user.identifier", e.UserID)
+	if len(e.EventData) > 0 {
+		ecsSet(doc, "winlog.event_data", ecsStrings(e.EventData))
+	}
+	if len(e.UserData) > 0 {
+		ecsSet(doc, "winlog.user_data", e.UserData)
+	}
+
+	data := ecsStrings(e.EventData)
+	for k, v := range flattenUserData(e.UserData) {
+		if _, ok := data[k]; !ok {
+			data[k] = v
+		}
+	}
+	user, domain := firstNonEmpty(data["TargetUserName"], data["SubjectUserName"], data["AccountName"]),
+		firstNonEmpty(data["TargetDomainName"], data["SubjectDomainName"], data["AccountDomain"])
+	if user == "" {
+		// Sysmon 的 User 为 域\用户 形式
+		if d, u, ok := strings.Cut(data["User"], `\`); ok {
+			user, domain = u, d
+		} else {
+			user = data["User"]
+		}
+	}
+	ecsSet(doc, "user.name", user)
+	ecsSet(doc, "user.domain", domain)
+	ecsSet(doc, "user.id", firstNonEmpty(data["TargetUserSid"], data["TargetSid"], data["SubjectUserSid"], e.UserID))
+
+	ecsSetIP(doc, "source", firstNonEmpty(data["IpAddress"], data["SourceIp"], data["SourceAddress"], data["ClientAddress"]),
+		firstNonEmpty(data["IpPort"], data["SourcePort"]))
+	ecsSetIP(doc, "destination", firstNonEmpty(data["DestinationIp"], data["DestAddress"]),
+		firstNonEmpty(data["DestinationPort"], data["DestPort"]))
+	ecsSet(doc, "source.domain", data["WorkstationName"])
+	ecsSet(doc, "destination.domain", data["DestinationHostname"])
+
+	ecsSet(doc, "process.executable", firstNonEmpty(data["Image"], data["NewProcessName"], data["ProcessName"]))
+	ecsSet(doc, "process.command_line", data["CommandLine"])
+	if pid, err := strconv.ParseInt(firstNonEmpty(data["ProcessId"], data["NewProcessId"]), 0, 64); err == nil && pid > 0 {
+		ecsSet(doc, "process.pid", pid)
+	}
+	ecsSet(doc, "process.entity_id", data["ProcessGuid"])
+	ecsSet(doc, "process.parent.executable", firstNonEmpty(data["ParentImage"], data["ParentProcessName"]))
+	ecsSet(doc, "process.parent.command_line", data["ParentCommandLine"])
+
+	ecsSet(doc, "ctscan.session_id", sessionID)
+	ecsSet(doc, "ctscan.source_sha256", e.SourceHash)
+	ecsSet(doc, "ctscan.record_offset", e.Offset)
+	if e.Recovered {
+		ecsSet(doc, "ctscan.recovered", true)
+	}
+	return doc
+}
+
+// ecsSet 按点分隔的路径写入嵌套字段，空值与 - 不写入
+func ecsSet(doc map[string]any, path string, value any) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		if v == "" || v == "-" {
+			return
+		}
+	}
+	keys := strings.Split(path, ".")
+	m := doc
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := m[k].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			m[k] = child
+		}
+		m = child
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// ecsSetIP 写入地址与端口，不是IP地址的值(如 - 或主机名)不写入
+func ecsSetIP(doc map[string]any, field, ip, port string) {
+	addr := net.ParseIP(strings.Trim(ip, "[]"))
+	if addr == nil {
+		return
+	}
+	ecsSet(doc, field+".ip", addr.String())
+	if p, err := strconv.Atoi(port); err == nil && p > 0 {
+		ecsSet(doc, field+".port", p)
+	}
+}
+
+// ecsStrings 将事件数据的值转换为字符串，避免同一字段在不同事件中类型不同导致映射冲突
+func ecsStrings(m map[string]any) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if _, nested := v.(map[string]any); nested {
+			continue
+		}
+		out[k] = exportString(v)
+	}
+	return out
+}
+
+// flattenUserData UserData 只有一个根节点，返回根节点下的字段
+func flattenUserData(m map[string]any) map[string]string {
+	out := map[string]string{}
+	for _, v := range m {
+		if child, ok := v.(map[string]any); ok {
+			for k, s := range ecsStrings(child) {
+				out[k] = s
+			}
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" && v != "-" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,316 @@
+package pkg
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxEVTXArchiveSize 一个压缩包中EVTX文件解压后的总大小上限，避免解压炸弹占满被调查主机的磁盘
+const maxEVTXArchiveSize = 4 << 30
+
+// maxEVTXImportWorkers 同时解析的文件数上限，写入数据库是串行的，过多的并发没有意义
+const maxEVTXImportWorkers = 4
+
+// evtxImportWorkers 默认的并行解析数
+func evtxImportWorkers() int {
+	n := runtime.NumCPU()
+	if n > maxEVTXImportWorkers {
+		n = maxEVTXImportWorkers
+	}
+	return n
+}
+
+// ImportEVTXPaths 导入EVTX文件、目录(递归查找 .evtx)或 zip 压缩包到当前会话，可通过 CancelTask("evtx") 取消
+// 单个文件解析失败或损坏不影响其他文件，每个文件的状态与错误见返回结果
+// 未完成的文件再次导入时从中断处继续，导入的同时运行内置与自定义目录中的 Sigma 规则
+func (a *App) ImportEVTXPaths(paths []string) ([]EVTXFile, error) {
+	sessionID, err := a.sessionFor("evtx")
+	if err != nil {
+		return nil, err
+	}
+	rules, _ := loadSigmaRules(sigmaRulesDir(""))
+	ctx, done := a.startTask(nil, "evtx", "导入EVTX文件")
+	files, err := a.importEVTXPaths(ctx, sessionID, paths, evtxImportWorkers(), rules, false)
+	done(err)
+	return files, taskError(err)
+}
+
+// RecoverEVTXPaths 以恢复模式导入，用于文件头或块损坏的日志以及从磁盘中截取的片段
+// 在文件中查找块与记录签名，找回块的空闲空间与损坏块中的记录，找回的记录标记为恢复并记录其偏移
+// 直接指定的文件不限制扩展名，目录中只查找 .evtx 文件，可通过 CancelTask("evtx") 取消
+func (a *App) RecoverEVTXPaths(paths []string) ([]EVTXFile, error) {
+	sessionID, err := a.sessionFor("evtx")
+	if err != nil {
+		return nil, err
+	}
+	rules, _ := loadSigmaRules(sigmaRulesDir(""))
+	ctx, done := a.startTask(nil, "evtx", "恢复EVTX记录")
+	files, err := a.importEVTXPaths(ctx, sessionID, paths, evtxImportWorkers(), rules, true)
+	done(err)
+	return files, taskError(err)
+}
+
+// SelectAndImportEVTXFiles 弹窗选择一个或多个EVTX文件或 zip 压缩包并导入
+func (a *App) SelectAndImportEVTXFiles() ([]EVTXFile, error) {
+	paths, err := wailsruntime.OpenMultipleFilesDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title: "选择EVTX文件或压缩包",
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "Windows事件日志 (*.evtx, *.zip)", Pattern: "*.evtx;*.zip"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("未选择文件")
+	}
+	return a.ImportEVTXPaths(paths)
+}
+
+// SelectAndImportEVTXDirectory 弹窗选择目录(如 winevt\Logs)并导入其中所有EVTX文件
+func (a *App) SelectAndImportEVTXDirectory() ([]EVTXFile, error) {
+	dir, err := wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title: "选择EVTX日志目录",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("未选择文件")
+	}
+	return a.ImportEVTXPaths([]string{dir})
+}
+
+// SelectAndRecoverEVTXFiles 弹窗选择损坏的EVTX文件或截取的磁盘片段，以恢复模式导入
+func (a *App) SelectAndRecoverEVTXFiles() ([]EVTXFile, error) {
+	paths, err := wailsruntime.OpenMultipleFilesDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title: "选择损坏的EVTX文件或磁盘片段",
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "所有文件 (*.*)", Pattern: "*.*"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("未选择文件")
+	}
+	return a.RecoverEVTXPaths(paths)
+}
+
+// importEVTXPaths 使用 workers 个协程并行导入，返回每个文件的导入结果，recovery 为 true 时以恢复模式导入
+// 只有一个文件时进度按块汇报，多个文件时按文件汇报
+func (a *App) importEVTXPaths(ctx context.Context, sessionID string, paths []string, workers int, rules []*sigmaRule, recovery bool) ([]EVTXFile, error) {
+	sources, cleanup, err := collectEVTXSources(paths, recovery)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("未找到EVTX文件")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	progress := progressFrom(ctx)
+	fileCtx := ctx
+	if len(sources) > 1 {
+		progress.SetTotal(len(sources))
+		fileCtx = withProgress(ctx, nil)
+	}
+
+	files := make([]EVTXFile, len(sources))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				src := sources[i]
+				var file EVTXFile
+				err := src.err
+				if err == nil {
+					file, err = a.ingestEVTXFile(fileCtx, sessionID, src, rules)
+				}
+				if err != nil && file.ID == 0 {
+					// 文件记录创建之前就失败了，如无法读取
+					file = EVTXFile{SessionID: sessionID, Path: src.name, Archive: src.archive, Status: EVTXFileFailed, Error: err.Error()}
+				}
+				if err != nil && !errors.Is(err, context.Canceled) && len(sources) > 1 {
+					progress.Fail(fmt.Errorf("%s: %v", filepath.Base(src.name), err))
+				}
+				files[i] = file
+				if len(sources) > 1 {
+					progress.Step(filepath.Base(src.name))
+				}
+			}
+		}()
+	}
+	for i := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		// 未开始的文件没有结果
+		var started []EVTXFile
+		for _, f := range files {
+			if f.Path != "" {
+				started = append(started, f)
+			}
+		}
+		return started, err
+	}
+	// 大脚本的分段可能分散在多个文件与批次中，全部导入后再拼接
+	if _, err := a.rebuildScriptBlocks(withProgress(ctx, nil), sessionID); err != nil {
+		return files, err
+	}
+	// 只有一个文件时直接返回它的错误
+	if len(files) == 1 && files[0].Status == EVTXFileFailed {
+		return files, errors.New(files[0].Error)
+	}
+	return files, nil
+}
+
+// collectEVTXSources 展开目录与压缩包，返回所有待导入的EVTX文件
+// 压缩包中的EVTX文件解压到临时目录，导入结束后由 cleanup 删除
+// 恢复模式中直接指定的文件不限制扩展名，以便导入从磁盘中截取的片段
+func collectEVTXSources(paths []string, recovery bool) (sources []evtxSource, cleanup func(), err error) {
+	var tempDirs []string
+	cleanup = func() {
+		for _, dir := range tempDirs {
+			os.RemoveAll(dir)
+		}
+	}
+	seen := make(map[string]bool)
+	add := func(src evtxSource) {
+		src.recovery = recovery
+		if !seen[src.name] {
+			seen[src.name] = true
+			sources = append(sources, src)
+		}
+	}
+	// 压缩包损坏时作为一个失败的文件记录，不影响其他文件
+	addArchive := func(archive string) {
+		extracted, dir, err := extractEVTXArchive(archive)
+		if dir != "" {
+			tempDirs = append(tempDirs, dir)
+		}
+		for _, src := range extracted {
+			add(src)
+		}
+		if err != nil {
+			add(evtxSource{name: archive, archive: archive, err: err})
+		}
+	}
+
+	for _, p := range paths {
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("解析文件路径失败: %v", err)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("文件不存在: %s", p)
+		}
+		if !info.IsDir() {
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".zip":
+				addArchive(p)
+			case ".evtx":
+				add(evtxSource{name: p, path: p})
+			default:
+				if recovery {
+					add(evtxSource{name: p, path: p})
+					continue
+				}
+				return nil, cleanup, fmt.Errorf("文件格式错误：必须是 .evtx 文件、目录或 zip 压缩包: %s", p)
+			}
+			continue
+		}
+
+		var found []string
+		err = filepath.WalkDir(p, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// 跳过没有权限的子目录
+				return nil
+			}
+			if !d.IsDir() {
+				switch strings.ToLower(filepath.Ext(name)) {
+				case ".evtx", ".zip":
+					found = append(found, name)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("遍历目录失败: %v", err)
+		}
+		sort.Strings(found)
+		for _, name := range found {
+			if strings.EqualFold(filepath.Ext(name), ".zip") {
+				addArchive(name)
+				continue
+			}
+			add(evtxSource{name: name, path: name})
+		}
+	}
+	return sources, cleanup, nil
+}
+
+// extractEVTXArchive 将压缩包中的EVTX文件解压到临时目录，解压失败的文件带有错误
+// 来源记录为 <压缩包>!/<压缩包内路径>，每次导入解压到单独的目录，同时导入同一压缩包时互不影响
+func extractEVTXArchive(archive string) ([]evtxSource, string, error) {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, "", fmt.Errorf("打开压缩包失败: %v", err)
+	}
+	defer zr.Close()
+
+	dir, err := os.MkdirTemp("", "ctscan-evtx-")
+	if err != nil {
+		return nil, "", fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	var sources []evtxSource
+	remain := int64(maxEVTXArchiveSize)
+	for i, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".evtx") {
+			continue
+		}
+		name := archive + "!/" + f.Name
+		// 压缩包中声明的大小不可信，解压时还会限制实际写入的大小
+		if f.UncompressedSize64 > uint64(remain) {
+			sources = append(sources, evtxSource{name: name, archive: archive,
+				err: fmt.Errorf("解压后大小 %d 字节，超过压缩包解压上限 %d 字节", f.UncompressedSize64, int64(maxEVTXArchiveSize))})
+			continue
+		}
+		// 压缩包内的路径不可信，解压时只保留文件名
+		local := filepath.Join(dir, fmt.Sprintf("%04d_%s", i, path.Base(f.Name)))
+		if err := extractZipFile(f, local, remain); err != nil {
+			os.Remove(local)
+			sources = append(sources, evtxSource{name: name, archive: archive, err: fmt.Errorf("解压失败: %v", err)})
+			continue
+		}
+		remain -= int64(f.UncompressedSize64)
+		sources = append(sources, evtxSource{name: name, path: local, archive: archive})
+	}
+	return sources, dir, nil
+}
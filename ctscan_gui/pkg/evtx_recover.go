@@ -0,0 +1,309 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/0xrawsec/golang-evtx/evtx"
+)
+
+// EVTX 中的签名
+const (
+	evtxChunkMagic  = "ElfChnk\x00"
+	evtxRecordMagic = "**\x00\x00"
+)
+
+const (
+	// evtxRecordStart 块头、字符串表与模板表之后第一条记录在块中的位置
+	evtxRecordStart = 512
+	// evtxFileHeaderSize 文件头所占的大小，正常文件的第一个块从这里开始
+	evtxFileHeaderSize = 4096
+	// evtxScanBufferSize 查找签名时每次读取的大小
+	evtxScanBufferSize = 1 << 20
+)
+
+// streamRecoveredEVTX 以恢复模式解析文件：不依赖文件头与块头中的记录链，
+// 在整个文件中查找块(ElfChnk)与记录(**\0\0)签名，解析所有校验通过的记录
+// 文件按块大小(64KB)划分为分段，分段数作为块数用于进度与断点续传，参数与 streamEVTXFile 相同
+// 正常解析得不到的记录(块的空闲空间、超出文件头块数的块、块头损坏的块、从磁盘中截取的片段)标记为恢复的记录
+func streamRecoveredEVTX(ctx context.Context, filePath string, from int, fn func(chunk, chunks int, events []EVTXEvent, chunkErr error) error) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	if info.Size() < evtx.EventHeaderSize {
+		return fmt.Errorf("文件格式错误：文件过小，没有可恢复的记录")
+	}
+
+	chunkStarts, err := scanEVTXSignature(ctx, f, info.Size(), evtxChunkMagic)
+	if err != nil {
+		return err
+	}
+	c := &evtxCarver{
+		file:   f,
+		size:   info.Size(),
+		starts: chunkStarts,
+		live:   evtxLiveRecords(filePath),
+		chunks: make(map[int64]*evtx.Chunk),
+	}
+
+	segments := int((info.Size() + evtx.ChunkSize - 1) / evtx.ChunkSize)
+	progress := progressFrom(ctx)
+	if from < segments {
+		progress.SetTotal(segments - from)
+	}
+	// next 为上一条记录结束的位置，跨越分段的记录不会在下一分段中重复查找
+	var next int64
+	buf := make([]byte, evtx.ChunkSize+len(evtxRecordMagic)-1)
+	for i := from; i < segments; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start := int64(i) * evtx.ChunkSize
+		end := start + evtx.ChunkSize
+		if end > info.Size() {
+			end = info.Size()
+		}
+		n, err := f.ReadAt(buf, start)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("读取文件失败: %v", err)
+		}
+		c.prune(start)
+
+		var events []EVTXEvent
+		bad := 0
+		pos := max(start, next)
+		for pos < end {
+			idx := bytes.Index(buf[pos-start:n], []byte(evtxRecordMagic))
+			if idx < 0 || pos+int64(idx) >= end {
+				break
+			}
+			offset := pos + int64(idx)
+			event, size, ok := c.record(offset)
+			switch {
+			case size == 0:
+				// 记录头校验失败，只是数据中恰好出现了签名
+				pos = offset + 1
+			case !ok:
+				bad++
+				pos = offset + 1
+			default:
+				event.Offset, event.Recovered = offset, !c.live[offset]
+				events = append(events, event)
+				pos = offset + int64(size)
+				next = pos
+			}
+		}
+
+		var chunkErr error
+		if bad > 0 {
+			chunkErr = fmt.Errorf("第 %d 个分段中 %d 条记录无法解析", i+1, bad)
+			progress.Fail(chunkErr)
+		}
+		if err := fn(i, segments, events, chunkErr); err != nil {
+			return err
+		}
+		progress.Step(fmt.Sprintf("分段 %d/%d", i+1, segments))
+	}
+	return nil
+}
+
+// scanEVTXSignature 返回文件中所有签名的偏移
+func scanEVTXSignature(ctx context.Context, f *os.File, size int64, magic string) ([]int64, error) {
+	var offsets []int64
+	overlap := int64(len(magic) - 1)
+	buf := make([]byte, evtxScanBufferSize+overlap)
+	for start := int64(0); start < size; start += evtxScanBufferSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := f.ReadAt(buf, start)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("读取文件失败: %v", err)
+		}
+		data := buf[:n]
+		for i := 0; ; {
+			idx := bytes.Index(data[i:], []byte(magic))
+			if idx < 0 || int64(i+idx) >= evtxScanBufferSize {
+				break
+			}
+			offsets = append(offsets, start+int64(i+idx))
+			i += idx + 1
+		}
+	}
+	return offsets, nil
+}
+
+// evtxLiveRecords 返回正常解析能得到的记录在文件中的偏移，文件头损坏时为空
+func evtxLiveRecords(filePath string) (live map[int64]bool) {
+	live = make(map[int64]bool)
+	defer func() {
+		recover()
+	}()
+	ef, err := evtx.Open(filePath)
+	if err != nil {
+		return live
+	}
+	defer ef.Close()
+	offsets, err := evtxChunkOffsets(&ef)
+	if err != nil {
+		return live
+	}
+	for _, offset := range offsets {
+		chunk, err := fetchEVTXChunk(&ef, offset)
+		if err != nil {
+			continue
+		}
+		for _, eo := range chunk.EventOffsets {
+			if eo <= chunk.Header.OffsetLastRec {
+				live[offset+int64(eo)] = true
+			}
+		}
+	}
+	return live
+}
+
+// fetchEVTXChunk 读取并解析块头、字符串表与模板表，与 parseEVTXChunk 中判断块是否可用的条件相同
+func fetchEVTXChunk(ef *evtx.File, offset int64) (chunk evtx.Chunk, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	chunk, err = ef.FetchChunk(offset)
+	if err == io.EOF {
+		err = nil
+	}
+	return chunk, err
+}
+
+// evtxCarver 恢复模式中解析单条记录
+type evtxCarver struct {
+	file   *os.File
+	size   int64
+	starts []int64               // 文件中所有块签名的偏移
+	live   map[int64]bool        // 正常解析能得到的记录
+	chunks map[int64]*evtx.Chunk // 按块起始位置缓存的块数据，记录中的模板与名称按块内偏移引用
+}
+
+// record 校验并解析 offset 处的记录
+// 记录头或记录末尾的长度副本校验失败时 size 为 0；记录头有效但无法解析时 ok 为 false
+func (c *evtxCarver) record(offset int64) (event EVTXEvent, size int32, ok bool) {
+	var header evtx.EventHeader
+	data := make([]byte, evtx.EventHeaderSize)
+	if _, err := c.file.ReadAt(data, offset); err != nil {
+		return EVTXEvent{}, 0, false
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return EVTXEvent{}, 0, false
+	}
+	if header.Validate() != nil || offset+int64(header.Size) > c.size {
+		return EVTXEvent{}, 0, false
+	}
+	tail := make([]byte, 4)
+	if _, err := c.file.ReadAt(tail, offset+int64(header.Size)-4); err != nil ||
+		int32(binary.LittleEndian.Uint32(tail)) != header.Size {
+		return EVTXEvent{}, 0, false
+	}
+
+	for _, base := range c.bases(offset) {
+		chunk := c.chunk(base)
+		event, err := parseCarvedRecord(chunk, offset-base, header)
+		if err == nil && carvedEventValid(event) {
+			return event, header.Size, true
+		}
+	}
+	return EVTXEvent{}, header.Size, false
+}
+
+// bases 返回记录可能所在的块的起始位置，按可信程度排序
+// 依次为记录之前 64KB 内的块签名、由记录中内联的模板定义推算的位置、按正常文件的块布局推算的位置
+func (c *evtxCarver) bases(offset int64) []int64 {
+	var bases []int64
+	add := func(base int64) {
+		if base < 0 || offset-base < evtxRecordStart || offset-base >= evtx.ChunkSize {
+			return
+		}
+		for _, b := range bases {
+			if b == base {
+				return
+			}
+		}
+		bases = append(bases, base)
+	}
+
+	if i := sort.Search(len(c.starts), func(i int) bool { return c.starts[i] > offset }); i > 0 {
+		add(c.starts[i-1])
+	}
+	// 记录内容以片段头(0x0f 01 01 00)与模板实例(0x0c)开始，模板第一次出现时内联在记录中，
+	// 其块内偏移就是紧随其后的位置，由此可以推算块的起始位置
+	data := make([]byte, 14)
+	if _, err := c.file.ReadAt(data, offset+evtx.EventHeaderSize); err == nil && data[0] == 0x0f && data[4] == 0x0c {
+		dataOffset := int64(binary.LittleEndian.Uint32(data[10:]))
+		add(offset + evtx.EventHeaderSize + int64(len(data)) - dataOffset)
+	}
+	if offset >= evtxFileHeaderSize {
+		add(offset - (offset-evtxFileHeaderSize)%evtx.ChunkSize)
+	}
+	return bases
+}
+
+// chunk 返回从 base 开始的块数据，超出文件末尾的部分为零
+func (c *evtxCarver) chunk(base int64) *evtx.Chunk {
+	if chunk, ok := c.chunks[base]; ok {
+		return chunk
+	}
+	chunk := evtx.NewChunk()
+	chunk.Offset = base
+	chunk.Data = make([]byte, evtx.ChunkSize)
+	c.file.ReadAt(chunk.Data, base)
+	c.chunks[base] = &chunk
+	return &chunk
+}
+
+// prune 丢弃不会再用到的块，之后的记录都在 start 之后，所在的块不会早于 start 之前 64KB
+func (c *evtxCarver) prune(start int64) {
+	for base := range c.chunks {
+		if base+evtx.ChunkSize <= start {
+			delete(c.chunks, base)
+		}
+	}
+}
+
+// parseCarvedRecord 按记录头解析块中 offset 处的记录，不检查块头中最后一条记录的位置
+func parseCarvedRecord(chunk *evtx.Chunk, offset int64, header evtx.EventHeader) (evt EVTXEvent, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	e := evtx.Event{Offset: offset, Header: header}
+	event, err := e.GoEvtxMap(chunk)
+	if err != nil {
+		return EVTXEvent{}, err
+	}
+	if event == nil {
+		return EVTXEvent{}, fmt.Errorf("记录内容无效")
+	}
+	return newEVTXEvent(event, header), nil
+}
+
+// carvedEventValid 排除按错误的块起始位置解析出的无意义内容
+func carvedEventValid(e EVTXEvent) bool {
+	if e.Provider == "" && e.Channel == "" && e.EventID == 0 {
+		return false
+	}
+	t, err := time.Parse(sessionTimeLayout, e.Time)
+	return err == nil && t.Year() >= 1990 && t.Year() <= 2100
+}
@@ -0,0 +1,217 @@
+package pkg
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// binXMLWriter 生成不使用模板的 BinXML 记录，名称内联在元素中，偏移相对于块的起始位置
+type binXMLWriter struct {
+	b     []byte
+	start int // b[0] 在块中的偏移
+}
+
+func (w *binXMLWriter) u8(v byte)    { w.b = append(w.b, v) }
+func (w *binXMLWriter) u16(v uint16) { w.b = binary.LittleEndian.AppendUint16(w.b, v) }
+func (w *binXMLWriter) u32(v uint32) { w.b = binary.LittleEndian.AppendUint32(w.b, v) }
+
+func (w *binXMLWriter) utf16(s string) {
+	for _, u := range utf16.Encode([]rune(s)) {
+		w.u16(u)
+	}
+}
+
+// open 元素开始标记(0x01)、数据大小、内联的名称与结束开始标记(0x02)
+func (w *binXMLWriter) open(name string) {
+	w.u8(0x01)
+	w.u32(0)
+	w.u32(uint32(w.start + len(w.b) + 4))
+	w.u32(0) // 前一个字符串的偏移
+	w.u16(0) // 哈希
+	w.u16(uint16(len(name)))
+	w.utf16(name)
+	w.u16(0)
+	w.u8(0x02)
+}
+
+// text 元素的文本值(0x05)
+func (w *binXMLWriter) text(s string) {
+	w.u8(0x05)
+	w.u8(0x01)
+	w.u16(uint16(len(s)))
+	w.utf16(s)
+}
+
+func (w *binXMLWriter) close() { w.u8(0x04) }
+
+// testEVTXRecord 生成位于块中 chunkOffset 处的一条记录，只有 System 下的 EventID 与 Channel
+func testEVTXRecord(chunkOffset int, id int64, eventID, channel string, t time.Time) []byte {
+	w := &binXMLWriter{start: chunkOffset + 24}
+	w.b = append(w.b, 0x0f, 0x01, 0x01, 0x00) // 片段头
+	w.open("Event")
+	w.open("System")
+	w.open("EventID")
+	w.text(eventID)
+	w.close()
+	w.open("Channel")
+	w.text(channel)
+	w.close()
+	w.close()
+	w.close()
+	w.u8(0x00) // 结束
+
+	size := 24 + len(w.b) + 4
+	size += (8 - size%8) % 8
+	rec := make([]byte, 0, size)
+	rec = append(rec, "**\x00\x00"...)
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(size))
+	rec = binary.LittleEndian.AppendUint64(rec, uint64(id))
+	rec = binary.LittleEndian.AppendUint64(rec, uint64((t.Unix()+11644473600)*10000000))
+	rec = append(rec, w.b...)
+	rec = append(rec, make([]byte, size-4-len(rec))...)
+	return binary.LittleEndian.AppendUint32(rec, uint32(size))
+}
+
+// putRecords 从块中的 evtxRecordStart 开始依次写入记录，返回下一条记录的块内偏移
+func putRecords(data []byte, base int, t time.Time, ids ...int64) int {
+	offset := evtxRecordStart
+	for _, id := range ids {
+		rec := testEVTXRecord(offset, id, "4624", "Security", t)
+		copy(data[base+offset:], rec)
+		offset += len(rec)
+	}
+	return offset
+}
+
+func recoverTestFile(t *testing.T, data []byte) ([]EVTXEvent, []error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "carved.bin")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var events []EVTXEvent
+	var errs []error
+	err := streamRecoveredEVTX(context.Background(), path, 0, func(chunk, chunks int, batch []EVTXEvent, chunkErr error) error {
+		events = append(events, batch...)
+		if chunkErr != nil {
+			errs = append(errs, chunkErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	return events, errs
+}
+
+func TestStreamRecoveredEVTX(t *testing.T) {
+	when := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		build    func() []byte
+		wantIDs  []int
+		wantErrs int
+	}{
+		{
+			name: "文件头与块头被清零时按块布局推算块的位置",
+			build: func() []byte {
+				data := make([]byte, evtxFileHeaderSize+0x10000)
+				putRecords(data, evtxFileHeaderSize, when, 1, 2, 3)
+				return data
+			},
+			wantIDs: []int{1, 2, 3},
+		},
+		{
+			name: "从磁盘截取的片段末尾的记录被截断",
+			build: func() []byte {
+				data := make([]byte, 0x10000)
+				copy(data, evtxChunkMagic)
+				end := putRecords(data, 0, when, 7, 8)
+				// 截掉最后一条记录的后半部分
+				return data[:end-20]
+			},
+			wantIDs: []int{7},
+		},
+		{
+			name: "记录之间被清零的部分不影响后面的记录",
+			build: func() []byte {
+				data := make([]byte, 0x10000)
+				copy(data, evtxChunkMagic)
+				end := putRecords(data, 0, when, 10, 11)
+				first := len(testEVTXRecord(evtxRecordStart, 10, "4624", "Security", when))
+				clear(data[evtxRecordStart : evtxRecordStart+first])
+				return data[:end]
+			},
+			wantIDs: []int{11},
+		},
+		{
+			name: "数据中偶然出现的签名被忽略",
+			build: func() []byte {
+				data := make([]byte, 0x10000)
+				copy(data, evtxChunkMagic)
+				end := putRecords(data, 0, when, 20)
+				// 大小超过块的签名与大小副本不一致的签名
+				copy(data[end:], "**\x00\x00\xff\xff\xff\x7f")
+				copy(data[end+64:], "**\x00\x00\x40\x00\x00\x00")
+				return data[:end+256]
+			},
+			wantIDs: []int{20},
+		},
+		{
+			name: "记录头有效但内容损坏时记为错误",
+			build: func() []byte {
+				data := make([]byte, 0x10000)
+				copy(data, evtxChunkMagic)
+				end := putRecords(data, 0, when, 30, 31)
+				// 破坏第一条记录的片段头
+				data[evtxRecordStart+24] = 0xee
+				return data[:end]
+			},
+			wantIDs:  []int{31},
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, errs := recoverTestFile(t, tt.build())
+			var ids []int
+			for _, e := range events {
+				ids = append(ids, e.EventRecordID)
+				if e.EventID != 4624 || e.Channel != "Security" || e.Time != "2024-06-01 10:00:00" {
+					t.Errorf("记录 %d 解析错误: EventID=%d Channel=%q Time=%q", e.EventRecordID, e.EventID, e.Channel, e.Time)
+				}
+				if !e.Recovered {
+					t.Errorf("记录 %d 应标记为恢复的记录", e.EventRecordID)
+				}
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("恢复的记录 %v，应为 %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("恢复的记录 %v，应为 %v", ids, tt.wantIDs)
+				}
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("错误 %v，应有 %d 个", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestStreamRecoveredEVTXTooSmall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "small.evtx")
+	if err := os.WriteFile(path, []byte("**\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := streamRecoveredEVTX(context.Background(), path, 0, func(int, int, []EVTXEvent, error) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "文件过小") {
+		t.Errorf("错误 %v 中应包含 文件过小", err)
+	}
+}
@@ -0,0 +1,634 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
+)
+
+// 支持的导出格式
+const (
+	ExportJSON  = "json"
+	ExportJSONL = "jsonl"
+	ExportCSV   = "csv"
+	ExportXLSX  = "xlsx"
+)
+
+// ExportFormats 所有支持的导出格式
+var ExportFormats = []string{ExportJSON, ExportJSONL, ExportCSV, ExportXLSX}
+
+// exportDataset 待导出的一组记录，Columns 决定输出的列顺序
+// 记录在写入时由 each 逐条读取，不一次性加载到内存
+type exportDataset struct {
+	Name    string
+	Columns []string
+	// Nested 可能包含嵌套对象的列，CSV 与 XLSX 需要先读取一遍记录确定展开后的子列
+	Nested []string
+	each   func(fn func(row map[string]any) error) error
+}
+
+var createTableRe = regexp.MustCompile(`(?i)CREATE TABLE IF NOT EXISTS\s+(\w+)`)
+
+// collectorTables 返回采集器对应的数据表
+func collectorTables(c Collector) []string {
+	var tables []string
+	for _, schema := range c.Schema() {
+		if m := createTableRe.FindStringSubmatch(schema); m != nil {
+			tables = append(tables, m[1])
+		}
+	}
+	return tables
+}
+
+// importedArtifactTables 不属于采集器、通过导入文件生成的数据，名称与会话中记录的采集项一致
+var importedArtifactTables = map[string][]string{
+	"evtx":     append([]string{"evtx_event", "evtx_file", "ps_script_block"}, evtxDerivedTables()...),
+	"timeline": {"timeline_event"},
+}
+
+// exportJSONColumns 以 JSON 文本保存的嵌套对象，导出时还原为对象，CSV 与 XLSX 中展开为子列
+var exportJSONColumns = map[string][]string{
+	"evtx_event": {"event_data", "user_data", "system_info"},
+}
+
+// resolveExportTables 将采集项名称或表名解析为数据表，names 为空时返回所有带会话的数据表
+func (a *App) resolveExportTables(names []string) ([]string, error) {
+	all, err := sessionTables(a.database())
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	var tables []string
+	for _, name := range names {
+		if c, ok := LookupCollector(name); ok {
+			for _, table := range collectorTables(c) {
+				if !containsString(tables, table) {
+					tables = append(tables, table)
+				}
+			}
+			continue
+		}
+		if imported, ok := importedArtifactTables[name]; ok {
+			for _, table := range imported {
+				if !containsString(tables, table) {
+					tables = append(tables, table)
+				}
+			}
+			continue
+		}
+		if !containsString(all, name) {
+			return nil, fmt.Errorf("未知的采集项或数据表: %s", name)
+		}
+		if !containsString(tables, name) {
+			tables = append(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+// sessionDataset 会话在数据表中的记录，写入时按ID顺序逐行读取
+func (a *App) sessionDataset(sessionID, table string) (exportDataset, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE session_id = ? ORDER BY id`, table)
+	rows, err := a.database().Query(query+` LIMIT 0`, sessionID)
+	if err != nil {
+		return exportDataset{}, fmt.Errorf("查询 %s 失败: %v", table, err)
+	}
+	columns, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return exportDataset{}, err
+	}
+
+	jsonColumns := exportJSONColumns[table]
+	ds := exportDataset{Name: table, Columns: columns, Nested: jsonColumns}
+	ds.each = func(fn func(row map[string]any) error) error {
+		rows, err := a.database().Query(query, sessionID)
+		if err != nil {
+			return fmt.Errorf("查询 %s 失败: %v", table, err)
+		}
+		defer rows.Close()
+
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				return err
+			}
+			record := make(map[string]any, len(columns))
+			for i, column := range columns {
+				record[column] = exportValue(values[i])
+				if containsString(jsonColumns, column) {
+					record[column] = exportJSONValue(record[column])
+				}
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+	return ds, nil
+}
+
+// exportValue 统一数据库值的类型，保留数字类型以便 JSON 输出
+// 时间按 RFC3339 输出并带上时区，EVTX 等导入的数据保存为 UTC，本机采集的数据保存为本地时间，导出时保持不变
+func exportValue(v any) any {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
+
+// exportJSONValue 将 JSON 文本还原为对象，空文本与无法解析的文本保持原样
+func exportJSONValue(v any) any {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "{") {
+		return v
+	}
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var m map[string]any
+	if err := decoder.Decode(&m); err != nil {
+		return v
+	}
+	return m
+}
+
+// structDataset 将分批读取的结构体转换为导出记录，列顺序与结构体字段顺序一致
+// batches 每读取一批记录调用一次 fn，CSV 与 XLSX 会读取两遍
+func structDataset[T any](name string, batches func(fn func(items []T) error) error) (exportDataset, error) {
+	ds := exportDataset{Name: name}
+	elem := reflect.TypeOf((*T)(nil)).Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return ds, fmt.Errorf("导出数据必须是结构体")
+	}
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		ds.Columns = append(ds.Columns, tag)
+	}
+
+	ds.Nested = ds.Columns
+	ds.each = func(fn func(row map[string]any) error) error {
+		return batches(func(items []T) error {
+			// 通过 JSON 转换得到与前端一致的字段名与取值
+			data, err := json.Marshal(items)
+			if err != nil {
+				return err
+			}
+			records := []map[string]any{}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&records); err != nil {
+				return err
+			}
+			for _, row := range records {
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return ds, nil
+}
+
+// flatHeader 读取一遍记录，返回嵌套对象展开为以点分隔的列后的表头，用于 CSV 与 XLSX 这类平面格式
+// 嵌套对象的子列插入在原列的位置，按键名排序，保证多次导出的列顺序一致
+func (ds exportDataset) flatHeader() ([]string, error) {
+	nested := make(map[string]map[string]bool)
+	if len(ds.Nested) > 0 {
+		err := ds.each(func(row map[string]any) error {
+			for _, column := range ds.Nested {
+				m, ok := row[column].(map[string]any)
+				if !ok {
+					continue
+				}
+				if nested[column] == nil {
+					nested[column] = make(map[string]bool)
+				}
+				flat := make(map[string]any)
+				flattenMap(column, m, flat)
+				for k := range flat {
+					nested[column][k] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var header []string
+	for _, column := range ds.Columns {
+		keys, ok := nested[column]
+		if !ok {
+			header = append(header, column)
+			continue
+		}
+		list := make([]string, 0, len(keys))
+		for k := range keys {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+		header = append(header, list...)
+	}
+	return header, nil
+}
+
+// flatRow 按 flatHeader 返回的表头输出一条记录
+func (ds exportDataset) flatRow(header []string, row map[string]any) []string {
+	flat := make(map[string]any, len(header))
+	for _, column := range ds.Columns {
+		if m, ok := row[column].(map[string]any); ok {
+			flattenMap(column, m, flat)
+		} else {
+			flat[column] = row[column]
+		}
+	}
+	line := make([]string, len(header))
+	for i, column := range header {
+		line[i] = exportString(flat[column])
+	}
+	return line
+}
+
+// flattenMap 递归展开嵌套对象
+func flattenMap(prefix string, m map[string]any, out map[string]any) {
+	for k, v := range m {
+		key := prefix + "." + k
+		if child, ok := v.(map[string]any); ok {
+			flattenMap(key, child, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// exportString 将值转换为单元格文本，数组等复杂值使用 JSON 表示
+func exportString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool, int, int32, int64, uint64, float32, float64:
+		return fmt.Sprint(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+// writeOrderedJSON 按列顺序输出一条 JSON 对象，嵌套对象保持原样
+func writeOrderedJSON(w io.Writer, columns []string, row map[string]any) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(row[column])
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONL(w io.Writer, ds exportDataset) error {
+	return ds.each(func(row map[string]any) error {
+		if err := writeOrderedJSON(w, ds.Columns, row); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	})
+}
+
+func writeJSON(w io.Writer, ds exportDataset) error {
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+	first := true
+	err := ds.each(func(row map[string]any) error {
+		if !first {
+			if _, err := io.WriteString(w, ",\n"); err != nil {
+				return err
+			}
+		}
+		first = false
+		return writeOrderedJSON(w, ds.Columns, row)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
+
+func writeCSV(w io.Writer, ds exportDataset) error {
+	// 写入 UTF-8 BOM，避免 Excel 打开中文乱码
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	header, err := ds.flatHeader()
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	err = ds.each(func(row map[string]any) error {
+		return cw.Write(ds.flatRow(header, row))
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeXLSX 将多个数据集写入同一个工作簿，每个数据集一个工作表
+func writeXLSX(path string, datasets []exportDataset) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, ds := range datasets {
+		sheet := xlsxSheetName(ds.Name)
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+		header, err := ds.flatHeader()
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow("A1", stringsToCells(header)); err != nil {
+			return err
+		}
+		r := 2
+		err = ds.each(func(row map[string]any) error {
+			cell, _ := excelize.CoordinatesToCellName(1, r)
+			r++
+			return sw.SetRow(cell, stringsToCells(ds.flatRow(header, row)))
+		})
+		if err != nil {
+			return err
+		}
+		if err := sw.Flush(); err != nil {
+			return err
+		}
+	}
+	return f.SaveAs(path)
+}
+
+// xlsxSheetName 工作表名最长 31 个字符且不能包含特殊字符
+func xlsxSheetName(name string) string {
+	name = strings.NewReplacer(":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "_", "]", "_").Replace(name)
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	return name
+}
+
+// stringsToCells Excel 单元格最多 32767 个字符，超出部分截断
+func stringsToCells(values []string) []any {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		if len([]rune(v)) > 32767 {
+			v = string([]rune(v)[:32767])
+		}
+		cells[i] = v
+	}
+	return cells
+}
+
+// writeDatasets 按格式写出数据集，返回生成的文件
+// xlsx 与单个数据集时 output 为文件，多个数据集时 output 为目录，每个数据集一个文件
+func writeDatasets(datasets []exportDataset, format, output string) ([]string, error) {
+	if !containsString(ExportFormats, format) {
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	if len(datasets) == 0 {
+		return nil, fmt.Errorf("没有可导出的数据")
+	}
+
+	if format == ExportXLSX {
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return nil, err
+		}
+		if err := writeXLSX(output, datasets); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", output, err)
+		}
+		return []string{output}, nil
+	}
+
+	paths := make([]string, 0, len(datasets))
+	for _, ds := range datasets {
+		path := output
+		if len(datasets) > 1 {
+			path = filepath.Join(output, ds.Name+"."+format)
+		}
+		if err := writeDatasetFile(path, ds, format); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %v", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeDatasetFile(path string, ds exportDataset, format string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 记录逐条写入，使用缓冲减少系统调用
+	w := bufio.NewWriter(f)
+	switch format {
+	case ExportJSON:
+		err = writeJSON(w, ds)
+	case ExportJSONL:
+		err = writeJSONL(w, ds)
+	case ExportCSV:
+		err = writeCSV(w, ds)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ExportSession 导出会话中指定采集项或数据表的记录，names 为空时导出全部
+// output 为空时保存在数据库所在目录
+func (a *App) ExportSession(sessionID string, names []string, format, output string) ([]string, error) {
+	tables, err := a.resolveExportTables(names)
+	if err != nil {
+		return nil, err
+	}
+	datasets := make([]exportDataset, 0, len(tables))
+	for _, table := range tables {
+		ds, err := a.sessionDataset(sessionID, table)
+		if err != nil {
+			return nil, err
+		}
+		datasets = append(datasets, ds)
+	}
+	if output == "" {
+		output = filepath.Join(filepath.Dir(a.databasePath()), defaultExportName(sessionID, tables, format))
+	}
+	return writeDatasets(datasets, format, output)
+}
+
+// defaultExportName 默认导出文件名，多个数据表的非 xlsx 导出为目录
+func defaultExportName(sessionID string, tables []string, format string) string {
+	name := fmt.Sprintf("ctscan-%s-%s", shortID(sessionID), time.Now().Format("20060102-150405"))
+	if len(tables) == 1 {
+		name += "-" + tables[0]
+	}
+	if format == ExportXLSX || len(tables) == 1 {
+		name += "." + format
+	}
+	return name
+}
+
+// exportSessionID 前端导出时使用的会话: 当前会话，没有当前会话时使用最近一次会话
+func (a *App) exportSessionID() (string, error) {
+	if session := a.CurrentScanSession(); session.ID != "" {
+		return session.ID, nil
+	}
+	return a.resolveSessionID("")
+}
+
+// ExportArtifact 导出当前会话中某个采集项的数据，弹窗选择保存位置
+func (a *App) ExportArtifact(collector, format string) ([]string, error) {
+	sessionID, err := a.exportSessionID()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := a.resolveExportTables([]string{collector})
+	if err != nil {
+		return nil, err
+	}
+	output, err := a.exportDialog(defaultExportName(sessionID, tables, format), format, len(tables) > 1)
+	if err != nil {
+		return nil, err
+	}
+	return a.ExportSession(sessionID, []string{collector}, format, output)
+}
+
+// ExportEVTXEvents 导出符合查询条件的所有EVTX事件(不分页)，弹窗选择保存位置，事件按导入顺序输出
+// 除通用格式外还支持 Windows 事件 XML(xml)与 ECS 批量导入格式(ndjson)
+func (a *App) ExportEVTXEvents(q EVTXQuery, format string) ([]string, error) {
+	if !containsString(EVTXExportFormats, format) {
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	events, err := a.evtxExportEvents(q)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("ctscan-evtx-%s.%s", time.Now().Format("20060102-150405"), format)
+	output, err := a.exportDialog(name, format, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEVTXEvents(output, events, format, evtxECSIndex); err != nil {
+		return nil, err
+	}
+	return []string{output}, nil
+}
+
+// exportDialog 弹出保存对话框，多个文件时选择目录
+func (a *App) exportDialog(defaultName, format string, multiple bool) (string, error) {
+	var output string
+	var err error
+	if multiple && format != ExportXLSX {
+		output, err = wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+			Title:            "选择导出目录",
+			DefaultDirectory: filepath.Dir(a.databasePath()),
+		})
+		if err == nil && output != "" {
+			output = filepath.Join(output, defaultName)
+		}
+	} else {
+		output, err = wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
+			Title:            "导出数据",
+			DefaultDirectory: filepath.Dir(a.databasePath()),
+			DefaultFilename:  defaultName,
+			Filters: []wailsruntime.FileFilter{
+				{DisplayName: strings.ToUpper(format), Pattern: "*." + format},
+			},
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		return "", fmt.Errorf("未选择保存位置")
+	}
+	return output, nil
+}
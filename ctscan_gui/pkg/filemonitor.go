@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"os"
 	"runtime"
 	"time"
@@ -250,3 +251,37 @@ func setPlatformFileInfo(info os.FileInfo, fileInfo *FileInfo) {
 	fileInfo.Owner = "SYSTEM"
 	fileInfo.Group = "Users"
 }
+
+// fileMonitorSchema 敏感文件监控表
+const fileMonitorSchema = `CREATE TABLE IF NOT EXISTS file_monitor (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	path TEXT,
+	file_exists BOOLEAN,
+	size INTEGER,
+	mode TEXT,
+	mod_time DATETIME,
+	create_time DATETIME,
+	access_time DATETIME,
+	change_time DATETIME,
+	is_dir BOOLEAN,
+	is_symlink BOOLEAN,
+	owner TEXT,
+	group_name TEXT,
+	permissions TEXT,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[FileInfo]{
+		name:      "files",
+		title:     "文件监控",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{fileMonitorSchema},
+		collect: func(ctx context.Context, a *App) ([]FileInfo, error) {
+			return a.GetSensitiveFileInfo(), nil
+		},
+		save: (*App).SaveFileMonitor,
+	})
+}
@@ -0,0 +1,724 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// systemd-journald 二进制日志文件，格式见 https://systemd.io/JOURNAL_FILE_FORMAT/
+// 所有整数为小端序，对象按 8 字节对齐
+
+const journalSignature = "LPKSHHRH"
+
+// 文件头 incompatible_flags，不认识的标志说明文件格式较新，无法正确读取
+const (
+	journalCompressedXZFlag   = 1 << 0
+	journalCompressedLZ4Flag  = 1 << 1
+	journalKeyedHashFlag      = 1 << 2
+	journalCompressedZSTDFlag = 1 << 3
+	journalCompactFlag        = 1 << 4 // 紧凑模式：条目中的数据对象偏移为 32 位
+	journalKnownFlags         = 1<<5 - 1
+)
+
+// 文件状态，ONLINE 表示正在写入或 journald 异常退出，文件末尾的对象可能不完整
+const (
+	journalStateOffline  = 0
+	journalStateOnline   = 1
+	journalStateArchived = 2
+)
+
+// 对象类型与对象的压缩标志
+const (
+	journalObjectData  = 1
+	journalObjectEntry = 3
+
+	journalObjectXZ   = 1 << 0
+	journalObjectLZ4  = 1 << 1
+	journalObjectZSTD = 1 << 2
+)
+
+// journalMaxDataSize 单个数据对象解压后的大小上限，避免损坏的文件申请过多内存
+const journalMaxDataSize = 64 << 20
+
+// journalDataCacheSize 数据对象缓存的条目数，主机名、单元名等字段在大量条目间共享
+const journalDataCacheSize = 200000
+
+// journalDirs 持久化与易失的日志目录，其下按 machine-id 分目录
+var journalDirs = []string{"var/log/journal", "run/log/journal"}
+
+// journalZstd 解压 zstd 数据对象，DecodeAll 可并发调用
+// 默认最多可申请 64GB 内存，与其他压缩格式一样限制为 journalMaxDataSize
+var journalZstd, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(journalMaxDataSize))
+
+// journalHeader 文件头中用到的字段
+type journalHeader struct {
+	incompatible uint32
+	state        byte
+	machineID    string
+	headerSize   uint64
+	arenaSize    uint64
+	nEntries     uint64
+	headRealtime uint64 // 微秒
+	tailRealtime uint64
+}
+
+// journalField 数据对象中的一个字段，原始内容为 NAME=value
+type journalField struct {
+	name  string
+	value string
+}
+
+// journalFile 一个打开的日志文件
+type journalFile struct {
+	path   string
+	f      *os.File
+	size   int64
+	header journalHeader
+	data   map[uint64]journalField
+}
+
+// journalEntry 一条日志记录
+type journalEntry struct {
+	Offset    int64 // 条目对象在文件中的偏移
+	Seqnum    uint64
+	Time      time.Time // 写入时间(__REALTIME_TIMESTAMP)
+	Monotonic uint64
+	BootID    string
+	XorHash   uint64 // 所有字段哈希的异或
+	Fields    map[string]string
+	File      string
+}
+
+// openJournalFile 打开日志文件并读取文件头
+func openJournalFile(path string) (*journalFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	buf := make([]byte, 208)
+	if _, err := io.ReadFull(f, buf); err != nil || string(buf[:8]) != journalSignature {
+		f.Close()
+		return nil, errors.New("不是 journal 日志文件")
+	}
+	le := binary.LittleEndian
+	h := journalHeader{
+		incompatible: le.Uint32(buf[12:]),
+		state:        buf[16],
+		machineID:    hex.EncodeToString(buf[40:56]),
+		headerSize:   le.Uint64(buf[88:]),
+		arenaSize:    le.Uint64(buf[96:]),
+		nEntries:     le.Uint64(buf[152:]),
+		headRealtime: le.Uint64(buf[184:]),
+		tailRealtime: le.Uint64(buf[192:]),
+	}
+	if h.incompatible&^journalKnownFlags != 0 {
+		f.Close()
+		return nil, fmt.Errorf("不支持的日志文件特性: %#x", h.incompatible&^journalKnownFlags)
+	}
+	if h.headerSize < 208 || int64(h.headerSize) > info.Size() {
+		f.Close()
+		return nil, fmt.Errorf("文件头大小无效: %d", h.headerSize)
+	}
+	return &journalFile{path: path, f: f, size: info.Size(), header: h, data: make(map[uint64]journalField)}, nil
+}
+
+func (j *journalFile) Close() error {
+	return j.f.Close()
+}
+
+func (j *journalFile) compact() bool {
+	return j.header.incompatible&journalCompactFlag != 0
+}
+
+// timeRange 文件中第一条与最后一条记录的时间，正在写入的文件最后一条记录的时间可能没有更新
+func (j *journalFile) timeRange() (head, tail time.Time) {
+	if j.header.headRealtime > 0 {
+		head = time.UnixMicro(int64(j.header.headRealtime))
+	}
+	if j.header.tailRealtime > 0 && j.header.state != journalStateOnline {
+		tail = time.UnixMicro(int64(j.header.tailRealtime))
+	}
+	return head, tail
+}
+
+// each 按文件中的顺序遍历所有条目，fn 返回 false 时停止
+// 直接顺序扫描对象而不是沿条目数组查找，未正常关闭的 ~ 文件与末尾不完整的文件也能读出完整的部分
+func (j *journalFile) each(ctx context.Context, fn func(journalEntry) bool) error {
+	start := int64(j.header.headerSize)
+	end := start + int64(j.header.arenaSize)
+	// 正在写入的文件 arena_size 可能还没有更新
+	if end > j.size || end <= start || j.header.state == journalStateOnline {
+		end = j.size
+	}
+	r := bufio.NewReaderSize(io.NewSectionReader(j.f, start, end-start), 256<<10)
+	hdr := make([]byte, 16)
+	var payload []byte
+	count := 0
+	for offset := start; offset+16 <= end; {
+		if count%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			break
+		}
+		typ, size := hdr[0], binary.LittleEndian.Uint64(hdr[8:])
+		// 大小无效或超出文件末尾的对象是未写完的部分，之后的内容无法定位
+		if size < 16 || size > uint64(end-offset) {
+			break
+		}
+		aligned := (size + 7) &^ 7
+		if typ == journalObjectEntry {
+			if cap(payload) < int(size-16) {
+				payload = make([]byte, size-16)
+			}
+			payload = payload[:size-16]
+			if _, err := io.ReadFull(r, payload); err != nil {
+				break
+			}
+			if _, err := r.Discard(int(aligned - size)); err != nil && offset+int64(aligned) < end {
+				break
+			}
+			count++
+			if e, ok := j.parseEntry(offset, payload); ok && !fn(e) {
+				return nil
+			}
+		} else if _, err := r.Discard(int(aligned - 16)); err != nil {
+			break
+		}
+		offset += int64(aligned)
+	}
+	return nil
+}
+
+// parseEntry 解析条目对象，payload 为对象头之后的内容
+func (j *journalFile) parseEntry(offset int64, payload []byte) (journalEntry, bool) {
+	if len(payload) < 48 {
+		return journalEntry{}, false
+	}
+	le := binary.LittleEndian
+	e := journalEntry{
+		Offset:    offset,
+		Seqnum:    le.Uint64(payload),
+		Time:      time.UnixMicro(int64(le.Uint64(payload[8:]))),
+		Monotonic: le.Uint64(payload[16:]),
+		BootID:    hex.EncodeToString(payload[24:40]),
+		XorHash:   le.Uint64(payload[40:]),
+		Fields:    make(map[string]string),
+		File:      j.path,
+	}
+	items := payload[48:]
+	itemSize := 16
+	if j.compact() {
+		itemSize = 4
+	}
+	for i := 0; i+itemSize <= len(items); i += itemSize {
+		var dataOffset uint64
+		if itemSize == 4 {
+			dataOffset = uint64(le.Uint32(items[i:]))
+		} else {
+			dataOffset = le.Uint64(items[i:])
+		}
+		field, err := j.field(dataOffset)
+		if err != nil {
+			continue
+		}
+		// 同名字段出现多次时保留第一个
+		if _, ok := e.Fields[field.name]; !ok {
+			e.Fields[field.name] = field.value
+		}
+	}
+	return e, true
+}
+
+// field 读取数据对象，结果缓存
+func (j *journalFile) field(offset uint64) (journalField, error) {
+	if f, ok := j.data[offset]; ok {
+		return f, nil
+	}
+	if offset < j.header.headerSize || offset%8 != 0 || int64(offset)+16 > j.size {
+		return journalField{}, errors.New("数据对象偏移无效")
+	}
+	hdr := make([]byte, 16)
+	if _, err := j.f.ReadAt(hdr, int64(offset)); err != nil {
+		return journalField{}, err
+	}
+	size := binary.LittleEndian.Uint64(hdr[8:])
+	payloadStart := uint64(64)
+	if j.compact() {
+		payloadStart = 72
+	}
+	if hdr[0] != journalObjectData || size < payloadStart || size > uint64(j.size)-offset || size > journalMaxDataSize {
+		return journalField{}, errors.New("数据对象无效")
+	}
+	buf := make([]byte, size-payloadStart)
+	if _, err := j.f.ReadAt(buf, int64(offset+payloadStart)); err != nil {
+		return journalField{}, err
+	}
+	data, err := journalDecompress(hdr[1], buf)
+	if err != nil {
+		return journalField{}, err
+	}
+	i := bytes.IndexByte(data, '=')
+	if i <= 0 {
+		return journalField{}, errors.New("数据对象内容无效")
+	}
+	f := journalField{name: string(data[:i]), value: string(data[i+1:])}
+	if len(j.data) >= journalDataCacheSize {
+		j.data = make(map[uint64]journalField)
+	}
+	j.data[offset] = f
+	return f, nil
+}
+
+// journalDecompress 按对象标志解压数据，LZ4 数据前 8 字节为解压后的大小
+func journalDecompress(flags byte, data []byte) ([]byte, error) {
+	switch {
+	case flags&journalObjectZSTD != 0:
+		out, err := journalZstd.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd 解压失败: %v", err)
+		}
+		return out, nil
+	case flags&journalObjectXZ != 0:
+		r, err := xz.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("xz 解压失败: %v", err)
+		}
+		out, err := io.ReadAll(io.LimitReader(r, journalMaxDataSize))
+		if err != nil {
+			return nil, fmt.Errorf("xz 解压失败: %v", err)
+		}
+		return out, nil
+	case flags&journalObjectLZ4 != 0:
+		if len(data) < 8 {
+			return nil, errors.New("lz4 数据无效")
+		}
+		size := binary.LittleEndian.Uint64(data)
+		if size > journalMaxDataSize {
+			return nil, fmt.Errorf("lz4 解压后大小无效: %d", size)
+		}
+		out := make([]byte, size)
+		n, err := lz4.UncompressBlock(data[8:], out)
+		if err != nil {
+			return nil, fmt.Errorf("lz4 解压失败: %v", err)
+		}
+		return out[:n], nil
+	}
+	return data, nil
+}
+
+// journalFiles root 下的所有日志文件，包括归档的 system@....journal 与未正常关闭的 .journal~，按修改时间从旧到新排序
+func journalFiles(root string) []string {
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []logFile
+	for _, dir := range journalDirs {
+		for _, pattern := range []string{"*.journal", "*.journal~", "*/*.journal", "*/*.journal~"} {
+			matches, _ := filepath.Glob(filepath.Join(root, dir, pattern))
+			for _, path := range matches {
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+				files = append(files, logFile{path, info.ModTime()})
+			}
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+// journalEntryKey 唯一标识一条记录，同一条记录可能同时存在于易失与持久化的日志中
+type journalEntryKey struct {
+	boot      string
+	realtime  int64
+	monotonic uint64
+	xorHash   uint64
+}
+
+// isRuntimeJournal 易失日志目录 /run/log/journal 中的文件
+func isRuntimeJournal(path string) bool {
+	return strings.Contains(filepath.ToSlash(path), "/"+journalDirs[1]+"/")
+}
+
+// eachJournalEntry 依次读取日志文件中的条目，skip 根据文件头跳过不需要的文件，无法读取的文件记录错误后跳过
+// 每个文件完成后推进一次进度，总数由调用方设置
+// journald 把易失日志转存到持久化目录后通常会删除原文件，没有删除时先读取易失日志，持久化日志中相同的记录跳过
+func eachJournalEntry(ctx context.Context, files []string, skip func(*journalFile) bool, fn func(journalEntry)) error {
+	progress := progressFrom(ctx)
+	files = append([]string{}, files...)
+	sort.SliceStable(files, func(i, j int) bool { return isRuntimeJournal(files[i]) && !isRuntimeJournal(files[j]) })
+	seen := make(map[journalEntryKey]bool)
+	for _, path := range files {
+		isRuntime := isRuntimeJournal(path)
+		j, err := openJournalFile(path)
+		if err != nil {
+			progress.Fail(fmt.Errorf("%s: %v", path, err))
+			progress.Step(filepath.Base(path))
+			continue
+		}
+		if skip == nil || !skip(j) {
+			err = j.each(ctx, func(e journalEntry) bool {
+				key := journalEntryKey{e.BootID, e.Time.UnixMicro(), e.Monotonic, e.XorHash}
+				if isRuntime {
+					seen[key] = true
+				} else if len(seen) > 0 && seen[key] {
+					return true
+				}
+				fn(e)
+				return true
+			})
+		}
+		j.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			progress.Fail(fmt.Errorf("%s: %v", path, err))
+		}
+		progress.Step(filepath.Base(path))
+	}
+	return nil
+}
+
+// journalInt 读取整数字段，不存在时为 -1
+func journalInt(fields map[string]string, name string) int {
+	n, err := strconv.Atoi(fields[name])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// journalIdentifier 程序名，与 journalctl 的输出一致优先使用 SYSLOG_IDENTIFIER
+func journalIdentifier(fields map[string]string) string {
+	return firstNonEmpty(fields["SYSLOG_IDENTIFIER"], fields["_COMM"])
+}
+
+// journalPID 进程ID，转发的 syslog 消息中 SYSLOG_PID 为原始进程
+func journalPID(fields map[string]string) int {
+	if pid := journalInt(fields, "SYSLOG_PID"); pid >= 0 {
+		return pid
+	}
+	return journalInt(fields, "_PID")
+}
+
+// journalPriorityNames syslog 级别
+var journalPriorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// JournalEntry 前端展示的一条 journal 记录
+type JournalEntry struct {
+	Time       string `json:"time"`
+	Hostname   string `json:"hostname"`
+	Unit       string `json:"unit"`
+	Identifier string `json:"identifier"`
+	PID        int    `json:"pid"`
+	Priority   int    `json:"priority"` // 0-7，没有级别时为 -1
+	Message    string `json:"message"`
+	BootID     string `json:"boot_id"`
+	File       string `json:"file"`
+	Offset     int64  `json:"offset"` // 条目在文件中的偏移，用于查看全部字段
+}
+
+// JournalQuery journal 查询条件，时间为本地时间
+type JournalQuery struct {
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Unit       string `json:"unit"`       // 匹配 _SYSTEMD_UNIT 或 _SYSTEMD_USER_UNIT
+	Identifier string `json:"identifier"` // 匹配 SYSLOG_IDENTIFIER 或 _COMM
+	Priority   string `json:"priority"`   // 只显示该级别及更严重的记录，为空时不过滤
+	Keyword    string `json:"keyword"`    // 不区分大小写匹配消息内容
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+}
+
+// JournalCount 单元名与记录数
+type JournalCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// JournalResult journal 查询结果，按时间从新到旧排序
+type JournalResult struct {
+	Total     int            `json:"total"`
+	Truncated bool           `json:"truncated"` // 匹配的记录超过上限，只保留最新的部分
+	Records   []JournalEntry `json:"records"`
+	Units     []JournalCount `json:"units"` // 除单元外其他条件匹配的记录按单元统计
+	Files     int            `json:"files"`
+}
+
+// journalQueryLimit 一次查询最多保留的记录数
+const journalQueryLimit = 200000
+
+// journalFilter 解析后的查询条件
+type journalFilter struct {
+	start, end time.Time
+	unit       string
+	identifier string
+	priority   int
+	keyword    string
+}
+
+func newJournalFilter(q JournalQuery) (journalFilter, error) {
+	f := journalFilter{
+		unit: q.Unit, identifier: q.Identifier, priority: -1,
+		keyword: strings.ToLower(strings.TrimSpace(q.Keyword)),
+	}
+	var err error
+	if q.Start != "" {
+		if f.start, err = time.ParseInLocation(sessionTimeLayout, q.Start, time.Local); err != nil {
+			return f, fmt.Errorf("开始时间格式错误: %v", err)
+		}
+	}
+	if q.End != "" {
+		if f.end, err = time.ParseInLocation(sessionTimeLayout, q.End, time.Local); err != nil {
+			return f, fmt.Errorf("结束时间格式错误: %v", err)
+		}
+	}
+	if q.Priority != "" {
+		if f.priority, err = strconv.Atoi(q.Priority); err != nil || f.priority < 0 || f.priority > 7 {
+			return f, fmt.Errorf("日志级别无效: %s", q.Priority)
+		}
+	}
+	return f, nil
+}
+
+// skipFile 文件的时间范围与查询的时间范围没有交集时跳过
+func (f journalFilter) skipFile(j *journalFile) bool {
+	head, tail := j.timeRange()
+	return (!f.end.IsZero() && !head.IsZero() && head.After(f.end)) ||
+		(!f.start.IsZero() && !tail.IsZero() && tail.Before(f.start))
+}
+
+// match 判断除单元以外的条件
+func (f journalFilter) match(e journalEntry) bool {
+	if (!f.start.IsZero() && e.Time.Before(f.start)) || (!f.end.IsZero() && e.Time.After(f.end)) {
+		return false
+	}
+	if f.identifier != "" && e.Fields["SYSLOG_IDENTIFIER"] != f.identifier && e.Fields["_COMM"] != f.identifier {
+		return false
+	}
+	if f.priority >= 0 {
+		if p := journalInt(e.Fields, "PRIORITY"); p < 0 || p > f.priority {
+			return false
+		}
+	}
+	return f.keyword == "" || strings.Contains(strings.ToLower(e.Fields["MESSAGE"]), f.keyword)
+}
+
+func journalUnit(fields map[string]string) string {
+	return firstNonEmpty(fields["_SYSTEMD_UNIT"], fields["_SYSTEMD_USER_UNIT"])
+}
+
+func newJournalEntry(e journalEntry) JournalEntry {
+	return JournalEntry{
+		Time:       e.Time.In(time.Local).Format("2006-01-02 15:04:05.000000"),
+		Hostname:   e.Fields["_HOSTNAME"],
+		Unit:       journalUnit(e.Fields),
+		Identifier: journalIdentifier(e.Fields),
+		PID:        journalPID(e.Fields),
+		Priority:   journalInt(e.Fields, "PRIORITY"),
+		Message:    strings.ToValidUTF8(e.Fields["MESSAGE"], "�"),
+		BootID:     e.BootID,
+		File:       e.File,
+		Offset:     e.Offset,
+	}
+}
+
+// queryJournal 读取 root 下的日志文件并按条件过滤
+func queryJournal(ctx context.Context, root string, q JournalQuery) (JournalResult, []JournalEntry, error) {
+	filter, err := newJournalFilter(q)
+	if err != nil {
+		return JournalResult{}, nil, err
+	}
+	files := journalFiles(root)
+	progressFrom(ctx).SetTotal(len(files))
+	units := make(map[string]int)
+	var records []JournalEntry
+	result := JournalResult{Files: len(files)}
+	// 超过上限时只保留最新的记录
+	trim := func() {
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Time > records[j].Time })
+		if len(records) > journalQueryLimit {
+			records = records[:journalQueryLimit]
+			result.Truncated = true
+		}
+	}
+	err = eachJournalEntry(ctx, files, filter.skipFile, func(e journalEntry) {
+		if !filter.match(e) {
+			return
+		}
+		unit := journalUnit(e.Fields)
+		units[unit]++
+		if filter.unit != "" && unit != filter.unit {
+			return
+		}
+		result.Total++
+		records = append(records, newJournalEntry(e))
+		if len(records) >= 2*journalQueryLimit {
+			trim()
+		}
+	})
+	if err != nil {
+		return JournalResult{}, nil, err
+	}
+	trim()
+	result.Units = make([]JournalCount, 0, len(units))
+	for name, count := range units {
+		if name != "" {
+			result.Units = append(result.Units, JournalCount{Name: name, Count: count})
+		}
+	}
+	sort.Slice(result.Units, func(i, j int) bool {
+		if result.Units[i].Count != result.Units[j].Count {
+			return result.Units[i].Count > result.Units[j].Count
+		}
+		return result.Units[i].Name < result.Units[j].Name
+	})
+	return result, records, nil
+}
+
+// journalCache 上一次查询的全部结果，翻页时不重新读取文件
+type journalCache struct {
+	key     string
+	result  JournalResult
+	records []JournalEntry
+}
+
+// QueryJournal 查询本机的 journal 日志，条件不变只翻页时使用上一次的结果
+func (a *App) QueryJournal(q JournalQuery) (JournalResult, error) {
+	page, pageSize := q.Page, q.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	q.Page, q.PageSize = 0, 0
+	key := fmt.Sprintf("%+v", q)
+
+	a.journalMu.Lock()
+	defer a.journalMu.Unlock()
+	if a.journal == nil || a.journal.key != key {
+		ctx, done := a.startTask(nil, "journal", "读取journal日志")
+		result, records, err := queryJournal(ctx, "/", q)
+		done(err)
+		if err != nil {
+			return JournalResult{}, taskError(err)
+		}
+		a.journal = &journalCache{key: key, result: result, records: records}
+	}
+
+	result := a.journal.result
+	start := (page - 1) * pageSize
+	result.Records = []JournalEntry{}
+	if start < len(a.journal.records) {
+		result.Records = a.journal.records[start:min(start+pageSize, len(a.journal.records))]
+	}
+	return result, nil
+}
+
+// RefreshJournal 清除查询缓存，下次查询时重新读取文件
+func (a *App) RefreshJournal() {
+	a.journalMu.Lock()
+	a.journal = nil
+	a.journalMu.Unlock()
+}
+
+// GetJournalEntryFields 读取一条记录的全部字段，offset 为条目在文件中的偏移
+func (a *App) GetJournalEntryFields(path string, offset int64) (map[string]string, error) {
+	if !isJournalFile(path) {
+		return nil, fmt.Errorf("不是 journal 日志文件: %s", path)
+	}
+	j, err := openJournalFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer j.Close()
+	hdr := make([]byte, 16)
+	if _, err := j.f.ReadAt(hdr, offset); err != nil {
+		return nil, fmt.Errorf("读取条目失败: %v", err)
+	}
+	size := binary.LittleEndian.Uint64(hdr[8:])
+	if hdr[0] != journalObjectEntry || size < 16+48 || size > uint64(j.size-offset) {
+		return nil, errors.New("条目对象无效")
+	}
+	payload := make([]byte, size-16)
+	if _, err := j.f.ReadAt(payload, offset+16); err != nil {
+		return nil, fmt.Errorf("读取条目失败: %v", err)
+	}
+	e, _ := j.parseEntry(offset, payload)
+	fields := make(map[string]string, len(e.Fields)+3)
+	for k, v := range e.Fields {
+		fields[k] = strings.ToValidUTF8(v, "�")
+	}
+	fields["__REALTIME_TIMESTAMP"] = strconv.FormatInt(e.Time.UnixMicro(), 10)
+	fields["__MONOTONIC_TIMESTAMP"] = strconv.FormatUint(e.Monotonic, 10)
+	fields["__SEQNUM"] = strconv.FormatUint(e.Seqnum, 10)
+	fields["_BOOT_ID"] = e.BootID
+	return fields, nil
+}
+
+// isJournalFile 只允许读取日志目录中的文件
+func isJournalFile(path string) bool {
+	if !strings.HasSuffix(path, ".journal") && !strings.HasSuffix(path, ".journal~") {
+		return false
+	}
+	for _, f := range journalFiles("/") {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// journalAuthIdentifiers 认证相关的程序，自身不使用 auth 设施记录日志的程序也能被提取
+var journalAuthIdentifiers = map[string]bool{
+	"sshd": true, "sshd-session": true, "sudo": true, "su": true, "login": true, "systemd-logind": true,
+}
+
+// journalAuthEntry 把 journal 中认证设施(auth=4、authpriv=10)或认证相关程序的记录转换为与认证日志相同的格式
+func journalAuthEntry(e journalEntry) (authLogEntry, bool) {
+	identifier := journalIdentifier(e.Fields)
+	facility := journalInt(e.Fields, "SYSLOG_FACILITY")
+	if facility != 4 && facility != 10 && !journalAuthIdentifiers[identifier] {
+		return authLogEntry{}, false
+	}
+	return authLogEntry{
+		Time:    e.Time,
+		Host:    e.Fields["_HOSTNAME"],
+		Program: identifier,
+		PID:     max(journalPID(e.Fields), 0),
+		Message: e.Fields["MESSAGE"],
+		File:    e.File,
+	}, true
+}
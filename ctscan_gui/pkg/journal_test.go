@@ -0,0 +1,354 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// testJournalHeaderSize 测试文件的文件头大小，与 systemd 252 写入的一致
+const testJournalHeaderSize = 272
+
+var testBootID = bytes.Repeat([]byte{0xab}, 16)
+
+// testJournal 按 JOURNAL_FILE_FORMAT 生成日志文件，对象依次追加在文件头之后
+type testJournal struct {
+	compact bool
+	buf     []byte
+	entries uint64
+	head    time.Time
+	tail    time.Time
+}
+
+func newTestJournal(compact bool) *testJournal {
+	return &testJournal{compact: compact, buf: make([]byte, testJournalHeaderSize)}
+}
+
+// object 追加一个对象并按 8 字节对齐，返回对象的偏移
+func (j *testJournal) object(typ, flags byte, payload []byte) uint64 {
+	offset := uint64(len(j.buf))
+	hdr := make([]byte, 16)
+	hdr[0], hdr[1] = typ, flags
+	binary.LittleEndian.PutUint64(hdr[8:], uint64(16+len(payload)))
+	j.buf = append(append(j.buf, hdr...), payload...)
+	j.buf = append(j.buf, make([]byte, (8-len(j.buf)%8)%8)...)
+	return offset
+}
+
+// data 追加数据对象，哈希与链表字段为 0，content 为 flags 对应的压缩内容
+func (j *testJournal) data(flags byte, content []byte) uint64 {
+	skip := 64 - 16
+	if j.compact {
+		skip = 72 - 16
+	}
+	return j.object(journalObjectData, flags, append(make([]byte, skip), content...))
+}
+
+func (j *testJournal) field(s string) uint64 {
+	return j.data(0, []byte(s))
+}
+
+// entry 追加条目对象，items 为数据对象的偏移
+func (j *testJournal) entry(seqnum uint64, t time.Time, items ...uint64) uint64 {
+	le := binary.LittleEndian
+	payload := make([]byte, 48)
+	le.PutUint64(payload, seqnum)
+	le.PutUint64(payload[8:], uint64(t.UnixMicro()))
+	le.PutUint64(payload[16:], seqnum*1000)
+	copy(payload[24:], testBootID)
+	le.PutUint64(payload[40:], seqnum)
+	for _, item := range items {
+		if j.compact {
+			payload = le.AppendUint32(payload, uint32(item))
+		} else {
+			payload = le.AppendUint64(le.AppendUint64(payload, item), 0)
+		}
+	}
+	j.entries++
+	if j.head.IsZero() {
+		j.head = t
+	}
+	j.tail = t
+	return j.object(journalObjectEntry, 0, payload)
+}
+
+// entryArray 追加条目数组对象，读取时应跳过
+func (j *testJournal) entryArray(entries ...uint64) uint64 {
+	payload := make([]byte, 8)
+	for _, e := range entries {
+		if j.compact {
+			payload = binary.LittleEndian.AppendUint32(payload, uint32(e))
+		} else {
+			payload = binary.LittleEndian.AppendUint64(payload, e)
+		}
+	}
+	return j.object(6, 0, payload)
+}
+
+// bytes 写入文件头后返回文件内容，arenaSize 为 -1 时按实际大小写入
+func (j *testJournal) bytes(state byte, arenaSize int64) []byte {
+	le := binary.LittleEndian
+	h := j.buf[:testJournalHeaderSize]
+	copy(h, journalSignature)
+	if j.compact {
+		le.PutUint32(h[12:], journalCompactFlag)
+	}
+	h[16] = state
+	copy(h[40:], bytes.Repeat([]byte{0x12}, 16))
+	le.PutUint64(h[88:], testJournalHeaderSize)
+	if arenaSize < 0 {
+		arenaSize = int64(len(j.buf) - testJournalHeaderSize)
+	}
+	le.PutUint64(h[96:], uint64(arenaSize))
+	le.PutUint64(h[152:], j.entries)
+	le.PutUint64(h[184:], uint64(j.head.UnixMicro()))
+	le.PutUint64(h[192:], uint64(j.tail.UnixMicro()))
+	return j.buf
+}
+
+func writeJournalFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readTestJournal(t *testing.T, path string) []journalEntry {
+	t.Helper()
+	j, err := openJournalFile(path)
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	defer j.Close()
+	var entries []journalEntry
+	if err := j.each(context.Background(), func(e journalEntry) bool {
+		entries = append(entries, e)
+		return true
+	}); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	return entries
+}
+
+func TestJournalFileEach(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	message := "Accepted publickey for root from 10.0.0.1 port 22 ssh2 " + strings.Repeat("x", 64)
+	zstdEnc, _ := zstd.NewWriter(nil)
+	compressZstd := zstdEnc.EncodeAll([]byte("MESSAGE="+message), nil)
+	zstdEnc.Close()
+	var xzBuf bytes.Buffer
+	xw, _ := xz.NewWriter(&xzBuf)
+	xw.Write([]byte("MESSAGE=" + message))
+	xw.Close()
+	lz4Src := []byte("MESSAGE=" + message)
+	lz4Buf := make([]byte, lz4.CompressBlockBound(len(lz4Src)))
+	n, _ := lz4.CompressBlock(lz4Src, lz4Buf, nil)
+	compressLZ4 := binary.LittleEndian.AppendUint64(nil, uint64(len(lz4Src)))
+	compressLZ4 = append(compressLZ4, lz4Buf[:n]...)
+
+	type want struct {
+		seqnum uint64
+		offset time.Duration // 与 base 的时间差
+		fields map[string]string
+	}
+	tests := []struct {
+		name  string
+		build func() []byte
+		want  []want
+	}{
+		{
+			name: "条目共享数据对象且跳过条目数组",
+			build: func() []byte {
+				j := newTestJournal(false)
+				host := j.field("_HOSTNAME=web01")
+				e1 := j.entry(1, base, host, j.field("MESSAGE=first"), j.field("PRIORITY=6"))
+				e2 := j.entry(2, base.Add(time.Minute), j.field("MESSAGE=second"), host)
+				j.entryArray(e1, e2)
+				return j.bytes(journalStateArchived, -1)
+			},
+			want: []want{
+				{1, 0, map[string]string{"_HOSTNAME": "web01", "MESSAGE": "first", "PRIORITY": "6"}},
+				{2, time.Minute, map[string]string{"_HOSTNAME": "web01", "MESSAGE": "second"}},
+			},
+		},
+		{
+			name: "紧凑模式的 32 位偏移",
+			build: func() []byte {
+				j := newTestJournal(true)
+				msg := j.field("MESSAGE=compact")
+				e := j.entry(7, base, msg, j.field("_PID=42"))
+				j.entryArray(e)
+				return j.bytes(journalStateOffline, -1)
+			},
+			want: []want{{7, 0, map[string]string{"MESSAGE": "compact", "_PID": "42"}}},
+		},
+		{
+			name: "zstd、xz 与 lz4 压缩的数据对象",
+			build: func() []byte {
+				j := newTestJournal(false)
+				j.entry(1, base, j.data(journalObjectZSTD, compressZstd))
+				j.entry(2, base, j.data(journalObjectXZ, xzBuf.Bytes()))
+				j.entry(3, base, j.data(journalObjectLZ4, compressLZ4))
+				return j.bytes(journalStateArchived, -1)
+			},
+			want: []want{
+				{1, 0, map[string]string{"MESSAGE": message}},
+				{2, 0, map[string]string{"MESSAGE": message}},
+				{3, 0, map[string]string{"MESSAGE": message}},
+			},
+		},
+		{
+			name: "同名字段保留第一个，无效的数据对象偏移被忽略",
+			build: func() []byte {
+				j := newTestJournal(false)
+				j.entry(1, base, j.field("MESSAGE=a"), j.field("MESSAGE=b"), 12345, 1<<40)
+				return j.bytes(journalStateArchived, -1)
+			},
+			want: []want{{1, 0, map[string]string{"MESSAGE": "a"}}},
+		},
+		{
+			name: "正在写入的文件 arena_size 未更新且末尾的对象不完整",
+			build: func() []byte {
+				j := newTestJournal(false)
+				j.entry(1, base, j.field("MESSAGE=a"))
+				arena := int64(len(j.buf) - testJournalHeaderSize)
+				j.entry(2, base.Add(time.Second), j.field("MESSAGE=b"))
+				j.entry(3, base.Add(2*time.Second), j.field("MESSAGE=c"))
+				data := j.bytes(journalStateOnline, arena)
+				return data[:len(data)-20]
+			},
+			want: []want{
+				{1, 0, map[string]string{"MESSAGE": "a"}},
+				{2, time.Second, map[string]string{"MESSAGE": "b"}},
+			},
+		},
+		{
+			name: "已关闭的文件只读取 arena_size 范围内的对象",
+			build: func() []byte {
+				j := newTestJournal(false)
+				j.entry(1, base, j.field("MESSAGE=a"))
+				arena := int64(len(j.buf) - testJournalHeaderSize)
+				j.entry(2, base, j.field("MESSAGE=b"))
+				return j.bytes(journalStateArchived, arena)
+			},
+			want: []want{{1, 0, map[string]string{"MESSAGE": "a"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeJournalFile(t, t.TempDir(), "system.journal", tt.build())
+			entries := readTestJournal(t, path)
+			if len(entries) != len(tt.want) {
+				t.Fatalf("读取到 %d 条记录，应为 %d 条", len(entries), len(tt.want))
+			}
+			for i, e := range entries {
+				w := tt.want[i]
+				if e.Seqnum != w.seqnum || !e.Time.Equal(base.Add(w.offset)) {
+					t.Errorf("第 %d 条记录 seqnum=%d time=%v", i+1, e.Seqnum, e.Time)
+				}
+				if e.BootID != strings.Repeat("ab", 16) || e.File != path {
+					t.Errorf("第 %d 条记录 boot=%s file=%s", i+1, e.BootID, e.File)
+				}
+				if len(e.Fields) != len(w.fields) {
+					t.Errorf("第 %d 条记录的字段 %v，应为 %v", i+1, e.Fields, w.fields)
+					continue
+				}
+				for k, v := range w.fields {
+					if e.Fields[k] != v {
+						t.Errorf("第 %d 条记录的 %s=%q，应为 %q", i+1, k, e.Fields[k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOpenJournalFile(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	valid := func() []byte {
+		j := newTestJournal(false)
+		j.entry(1, base, j.field("MESSAGE=a"))
+		j.entry(2, base.Add(time.Hour), j.field("MESSAGE=b"))
+		return j.bytes(journalStateArchived, -1)
+	}
+	tests := []struct {
+		name    string
+		modify  func([]byte) []byte
+		wantErr string
+	}{
+		{"有效的文件", func(b []byte) []byte { return b }, ""},
+		{"签名错误", func(b []byte) []byte { copy(b, "XXXXXXXX"); return b }, "不是 journal 日志文件"},
+		{"文件过短", func(b []byte) []byte { return b[:100] }, "不是 journal 日志文件"},
+		{"不认识的文件特性", func(b []byte) []byte { b[12] |= 1 << 5; return b }, "不支持的日志文件特性"},
+		{"文件头大小超出文件", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[88:], 1<<30); return b }, "文件头大小无效"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeJournalFile(t, t.TempDir(), "system.journal", tt.modify(valid()))
+			j, err := openJournalFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("错误 %v 中应包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("打开失败: %v", err)
+			}
+			defer j.Close()
+			if j.header.machineID != strings.Repeat("12", 16) || j.header.nEntries != 2 {
+				t.Errorf("文件头 %+v", j.header)
+			}
+			if head, tail := j.timeRange(); !head.Equal(base) || !tail.Equal(base.Add(time.Hour)) {
+				t.Errorf("时间范围 %v - %v", head, tail)
+			}
+		})
+	}
+}
+
+// 易失日志中的记录转存到持久化日志后，两处相同的记录只读取一次
+func TestEachJournalEntryDeduplicate(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	build := func(seqnums ...uint64) []byte {
+		j := newTestJournal(false)
+		for _, s := range seqnums {
+			j.entry(s, base.Add(time.Duration(s)*time.Second), j.field("MESSAGE=m"))
+		}
+		return j.bytes(journalStateArchived, -1)
+	}
+	root := t.TempDir()
+	persistent := writeJournalFile(t, filepath.Join(root, journalDirs[0], "m"), "system.journal", build(1, 2, 3))
+	runtime := writeJournalFile(t, filepath.Join(root, journalDirs[1], "m"), "system.journal", build(2, 3, 4))
+
+	var seqnums []uint64
+	err := eachJournalEntry(context.Background(), []string{persistent, runtime}, nil, func(e journalEntry) {
+		seqnums = append(seqnums, e.Seqnum)
+	})
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	// 先读取易失日志，持久化日志中只剩下 1
+	want := []uint64{2, 3, 4, 1}
+	if len(seqnums) != len(want) {
+		t.Fatalf("读取到 %v，应为 %v", seqnums, want)
+	}
+	for i := range want {
+		if seqnums[i] != want[i] {
+			t.Fatalf("读取到 %v，应为 %v", seqnums, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAuthLog 写入认证日志并设置修改时间，gz 为 true 时以 gzip 压缩
+func writeAuthLog(t *testing.T, lines []string, modTime time.Time, gz bool) string {
+	t.Helper()
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	name := "auth.log"
+	if gz {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(content)
+		w.Close()
+		content, name = buf.Bytes(), "auth.log.2.gz"
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadAuthLogFileYears(t *testing.T) {
+	local := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name    string
+		lines   []string
+		modTime time.Time
+		gz      bool
+		want    []time.Time
+	}{
+		{
+			name:    "同一年",
+			lines:   []string{"Mar  1 10:00:00 host sshd[1]: a", "Mar  2 10:00:00 host sshd[1]: b"},
+			modTime: local(2024, 3, 5, 0),
+			want:    []time.Time{local(2024, 3, 1, 10), local(2024, 3, 2, 10)},
+		},
+		{
+			name:    "跨年的记录",
+			lines:   []string{"Dec 31 23:00:00 host sshd[1]: a", "Jan  1 01:00:00 host sshd[1]: b"},
+			modTime: local(2024, 1, 2, 0),
+			want:    []time.Time{local(2023, 12, 31, 23), local(2024, 1, 1, 1)},
+		},
+		{
+			name:    "文件在新年之后修改但记录都在上一年",
+			lines:   []string{"Dec 30 10:00:00 host sshd[1]: a", "Dec 31 10:00:00 host sshd[1]: b"},
+			modTime: local(2024, 1, 3, 0),
+			want:    []time.Time{local(2023, 12, 30, 10), local(2023, 12, 31, 10)},
+		},
+		{
+			name:    "相差不到一天的时间倒序不视为跨年",
+			lines:   []string{"Jun  1 10:00:05 host sshd[1]: a", "Jun  1 10:00:00 host sshd[1]: b"},
+			modTime: local(2024, 6, 2, 0),
+			want:    []time.Time{time.Date(2024, 6, 1, 10, 0, 5, 0, time.Local), local(2024, 6, 1, 10)},
+		},
+		{
+			name: "带年份的记录作为之前记录的参照",
+			lines: []string{
+				"Dec 31 23:00:00 host sshd[1]: a",
+				"2022-01-01T01:00:00+00:00 host sshd[1]: b",
+			},
+			modTime: local(2024, 6, 1, 0),
+			want:    []time.Time{local(2021, 12, 31, 23), time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "gzip 压缩的轮转文件",
+			lines:   []string{"Nov 30 10:00:00 host sshd[1]: a", "Feb  1 10:00:00 host sshd[1]: b"},
+			modTime: local(2024, 2, 1, 12),
+			gz:      true,
+			want:    []time.Time{local(2023, 11, 30, 10), local(2024, 2, 1, 10)},
+		},
+		{
+			name:    "无法识别的行被跳过",
+			lines:   []string{"garbage", "", "Mar  1 10:00:00 host sshd[1]: a"},
+			modTime: local(2024, 3, 5, 0),
+			want:    []time.Time{local(2024, 3, 1, 10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeAuthLog(t, tt.lines, tt.modTime, tt.gz)
+			entries, err := readAuthLogFile(context.Background(), path)
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("解析出 %d 条记录，应为 %d 条", len(entries), len(tt.want))
+			}
+			for i, e := range entries {
+				if !e.Time.Equal(tt.want[i]) {
+					t.Errorf("第 %d 条记录的时间为 %v，应为 %v", i+1, e.Time, tt.want[i])
+				}
+				if e.File != path {
+					t.Errorf("第 %d 条记录的文件为 %q", i+1, e.File)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSyslogLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		hasYear bool
+		program string
+		pid     int
+		message string
+	}{
+		{"Jun  1 10:00:00 host sshd[123]: Accepted password for root", true, false, "sshd", 123, "Accepted password for root"},
+		{"Jun 11 10:00:00 host CRON[9]: pam_unix(cron:session): x", true, false, "CRON", 9, "pam_unix(cron:session): x"},
+		{"Jun  1 10:00:00 host sshd(pam_unix)[5]: session opened", true, false, "sshd(pam_unix)", 5, "session opened"},
+		{"Jun  1 10:00:00 host kernel: message", true, false, "kernel", 0, "message"},
+		{"2024-06-01T10:00:00.123456+08:00 host sudo: alice : TTY=pts/0", true, true, "sudo", 0, "alice : TTY=pts/0"},
+		{"Jun  1 10:00:00", false, false, "", 0, ""},
+		{"not a syslog line", false, false, "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			e, hasYear, ok := parseSyslogLine(tt.line)
+			if ok != tt.ok || hasYear != tt.hasYear {
+				t.Fatalf("ok=%v hasYear=%v，应为 %v %v", ok, hasYear, tt.ok, tt.hasYear)
+			}
+			if !ok {
+				return
+			}
+			if e.Host != "host" || e.Program != tt.program || e.PID != tt.pid || e.Message != tt.message {
+				t.Errorf("解析结果 %+v", e)
+			}
+		})
+	}
+}
+
+func TestLinuxAuthLogMessages(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	type line struct {
+		offset  time.Duration
+		program string
+		pid     int
+		message string
+	}
+	tests := []struct {
+		name        string
+		lines       []line
+		wantSuccess []string // 用户名
+		wantFailed  []string // 用户名
+		wantSudo    int
+		check       func(t *testing.T, l *linuxAuthLog)
+	}{
+		{
+			name:        "SSH 登录成功",
+			lines:       []line{{0, "sshd", 100, "Accepted publickey for alice from 10.0.0.1 port 50000 ssh2: RSA SHA256:x"}},
+			wantSuccess: []string{"alice"},
+			check: func(t *testing.T, l *linuxAuthLog) {
+				s := l.Success[0]
+				if s.IPAddress != "10.0.0.1" || s.Port != 50000 || s.Method != "publickey" || s.EventID != "100" {
+					t.Errorf("登录记录 %+v", s)
+				}
+			},
+		},
+		{
+			name: "message repeated 按次数记录",
+			lines: []line{
+				{0, "sshd", 200, "Failed password for root from 1.2.3.4 port 22 ssh2"},
+				{time.Second, "sshd", 200, "message repeated 3 times: [ Failed password for root from 1.2.3.4 port 22 ssh2]"},
+			},
+			wantFailed: []string{"root", "root", "root", "root"},
+		},
+		{
+			name:       "message repeated 中的不存在的用户",
+			lines:      []line{{0, "sshd", 201, "message repeated 2 times: [ Failed password for invalid user admin from 1.2.3.4 port 22 ssh2]"}},
+			wantFailed: []string{"admin", "admin"},
+			check: func(t *testing.T, l *linuxAuthLog) {
+				if l.Failed[0].Reason != "用户不存在" {
+					t.Errorf("原因为 %q", l.Failed[0].Reason)
+				}
+			},
+		},
+		{
+			name:  "与其他消息无关的 message repeated",
+			lines: []line{{0, "systemd", 1, "message repeated 5 times: [ Started Session]"}},
+		},
+		{
+			name:        "OpenSSH 9.8 的 sshd-session",
+			lines:       []line{{0, "sshd-session", 300, "Accepted password for bob from 10.0.0.2 port 40000 ssh2"}},
+			wantSuccess: []string{"bob"},
+		},
+		{
+			name: "PAM 认证失败",
+			lines: []line{{0, "su", 400,
+				"pam_unix(su:auth): authentication failure; logname=alice uid=1000 euid=0 tty=pts/0 ruser=alice rhost=  user=root"}},
+			wantFailed: []string{"root"},
+			check: func(t *testing.T, l *linuxAuthLog) {
+				if !strings.Contains(l.Failed[0].Reason, "发起用户 alice") || l.Failed[0].EventType != "切换用户失败" {
+					t.Errorf("登录失败记录 %+v", l.Failed[0])
+				}
+			},
+		},
+		{
+			name:  "sshd 的 PAM 认证失败由 Failed password 记录",
+			lines: []line{{0, "sshd", 500, "pam_unix(sshd:auth): authentication failure; logname= uid=0 euid=0 tty=ssh ruser= rhost=1.2.3.4  user=root"}},
+		},
+		{
+			name: "sudo 命令与被拒绝的命令",
+			lines: []line{
+				{0, "sudo", 600, "alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/cat /etc/shadow"},
+				{time.Second, "sudo", 601, "bob : user NOT in sudoers ; TTY=pts/1 ; PWD=/tmp ; USER=root ; COMMAND=/bin/sh -c 'id ; whoami'"},
+			},
+			wantSudo: 2,
+			check: func(t *testing.T, l *linuxAuthLog) {
+				if s := l.Sudo[0]; !s.Allowed || s.RunAs != "root" || s.Command != "/bin/cat /etc/shadow" || s.PWD != "/home/alice" {
+					t.Errorf("sudo 记录 %+v", s)
+				}
+				if s := l.Sudo[1]; s.Allowed || s.Reason != "user NOT in sudoers" || s.Command != "/bin/sh -c 'id ; whoami'" {
+					t.Errorf("被拒绝的 sudo 记录 %+v", s)
+				}
+			},
+		},
+		{
+			name: "systemd-logind 的新会话与之前的登录合并",
+			lines: []line{
+				{0, "sshd", 700, "Accepted password for carol from 10.0.0.3 port 1 ssh2"},
+				{2 * time.Second, "systemd-logind", 1, "New session 5 of user carol."},
+				{time.Minute, "systemd-logind", 1, "New session 6 of user carol."},
+			},
+			wantSuccess: []string{"carol", "carol"},
+		},
+		{
+			name: "cron 的 PAM 会话不是登录",
+			lines: []line{
+				{0, "CRON", 800, "pam_unix(cron:session): session opened for user root(uid=0) by (uid=0)"},
+				{time.Second, "login", 801, "pam_unix(login:session): session opened for user dave(uid=1001) by LOGIN(uid=0)"},
+			},
+			wantSuccess: []string{"dave"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &linuxAuthLog{sessions: make(map[string]time.Time)}
+			for _, ln := range tt.lines {
+				l.add(authLogEntry{Time: base.Add(ln.offset), Host: "host", Program: ln.program, PID: ln.pid, Message: ln.message, File: "auth.log"})
+			}
+			var success, failed []string
+			for _, s := range l.Success {
+				success = append(success, s.Username)
+			}
+			for _, f := range l.Failed {
+				failed = append(failed, f.Username)
+			}
+			if strings.Join(success, ",") != strings.Join(tt.wantSuccess, ",") {
+				t.Errorf("登录成功 %v，应为 %v", success, tt.wantSuccess)
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("登录失败 %v，应为 %v", failed, tt.wantFailed)
+			}
+			if len(l.Sudo) != tt.wantSudo {
+				t.Errorf("sudo 记录 %d 条，应为 %d 条", len(l.Sudo), tt.wantSudo)
+			}
+			if tt.check != nil && !t.Failed() {
+				tt.check(t, l)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package pkg
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -19,33 +21,34 @@ type LoginFailed struct {
 	Username  string `json:"username"`
 	IPAddress string `json:"ip_address"`
 	Reason    string `json:"reason"`
+	Port      int    `json:"port"`     // 来源端口，Linux SSH 登录
+	Method    string `json:"method"`   // 认证方式，如 password、publickey、pam
+	LogFile   string `json:"log_file"` // 记录所在的日志文件
 }
 
 func (a *App) GetLoginFailedRecords() []LoginFailed {
+	records, _ := a.getLoginFailedRecords(context.Background())
+	return records
+}
+
+func (a *App) getLoginFailedRecords(ctx context.Context) ([]LoginFailed, error) {
 	records := []LoginFailed{}
 	switch runtime.GOOS {
 	case "windows":
 		records = a.getWindowsLoginFailedRecords()
 	case "linux":
-		out, err := exec.Command("grep", "Failed password", "/var/log/auth.log").CombinedOutput()
+		auth, err := a.linuxAuthLogs(ctx, "login-failed")
 		if err != nil {
-			println("Linux 获取登录失败记录错误:", err.Error())
-			return records
+			return records, err
 		}
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			records = append(records, LoginFailed{Time: line[:15]})
+		if auth.Failed != nil {
+			records = auth.Failed
 		}
 	case "darwin":
-		println("开始获取 macOS 登录失败记录...")
 		// 使用最简单的查询方式，只获取最近的100条记录
 		out, err := exec.Command("log", "show", "--predicate", "eventMessage CONTAINS 'Failed'", "--last", "24h", "--limit", "10000", "--style", "json").CombinedOutput()
 		if err != nil {
-			println("查询失败:", err.Error())
-			return records
+			return records, fmt.Errorf("查询统一日志失败: %v", err)
 		}
 
 		// 解析 JSON 输出
@@ -55,12 +58,9 @@ func (a *App) GetLoginFailedRecords() []LoginFailed {
 		}
 
 		if err := json.Unmarshal(out, &logEntries); err != nil {
-			println("JSON 解析失败:", err.Error())
-			return records
+			return records, fmt.Errorf("解析统一日志失败: %v", err)
 		}
 
-		println("找到", len(logEntries), "条记录")
-
 		// 使用 map 直接去重
 		seen := make(map[string]bool)
 		for _, entry := range logEntries {
@@ -97,7 +97,7 @@ func (a *App) GetLoginFailedRecords() []LoginFailed {
 			}
 		}
 	}
-	return records
+	return records, nil
 }
 
 func (a *App) getWindowsLoginFailedRecords() []LoginFailed {
@@ -181,3 +181,33 @@ func (a *App) getWindowsLoginFailedRecords() []LoginFailed {
 
 	return records
 }
+
+// loginFailedSchema 登录失败记录表
+const loginFailedSchema = `CREATE TABLE IF NOT EXISTS login_failed (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	event_id TEXT,
+	event_type TEXT,
+	source TEXT,
+	username TEXT,
+	ip_address TEXT,
+	reason TEXT,
+	port INTEGER DEFAULT 0,
+	method TEXT DEFAULT '',
+	log_file TEXT DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[LoginFailed]{
+		name:      "login-failed",
+		title:     "登入失败",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{loginFailedSchema},
+		collect: func(ctx context.Context, a *App) ([]LoginFailed, error) {
+			return a.getLoginFailedRecords(ctx)
+		},
+		save: (*App).SaveLoginFailed,
+	})
+}
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"bufio"
+	"context"
 	"os/exec"
 	"regexp"
 	"runtime"
@@ -20,30 +21,34 @@ type LoginSuccess struct {
 	Source    string `json:"source"`
 	Username  string `json:"username"`
 	IPAddress string `json:"ip_address"`
+	Port      int    `json:"port"`     // 来源端口，Linux SSH 登录
+	Method    string `json:"method"`   // 认证方式，如 password、publickey、pam
+	LogFile   string `json:"log_file"` // 记录所在的日志文件
 }
 
 func (a *App) GetLoginSuccessRecords() []LoginSuccess {
+	records, _ := a.getLoginSuccessRecords(context.Background())
+	return records
+}
+
+func (a *App) getLoginSuccessRecords(ctx context.Context) ([]LoginSuccess, error) {
 	records := []LoginSuccess{}
 	switch runtime.GOOS {
 	case "windows":
 		records = a.getWindowsLoginSuccessRecords()
 	case "linux":
-		out, err := exec.Command("grep", "Accepted", "/var/log/auth.log").CombinedOutput()
+		auth, err := a.linuxAuthLogs(ctx, "login-success")
 		if err != nil {
-			return records
+			return records, err
 		}
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			records = append(records, LoginSuccess{Time: line[:15]})
+		if auth.Success != nil {
+			records = auth.Success
 		}
 	case "darwin":
 		cmd := exec.Command("last")
 		output, err := cmd.Output()
 		if err != nil {
-			return records
+			return records, nil
 		}
 
 		scanner := bufio.NewScanner(strings.NewReader(string(output)))
@@ -94,7 +99,7 @@ func (a *App) GetLoginSuccessRecords() []LoginSuccess {
 			}
 		}
 	}
-	return records
+	return records, nil
 }
 
 func (a *App) getWindowsLoginSuccessRecords() []LoginSuccess {
@@ -178,3 +183,32 @@ func (a *App) getWindowsLoginSuccessRecords() []LoginSuccess {
 
 	return records
 }
+
+// loginSuccessSchema 登录成功记录表
+const loginSuccessSchema = `CREATE TABLE IF NOT EXISTS login_success (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	event_id TEXT,
+	event_type TEXT,
+	source TEXT,
+	username TEXT,
+	ip_address TEXT,
+	port INTEGER DEFAULT 0,
+	method TEXT DEFAULT '',
+	log_file TEXT DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[LoginSuccess]{
+		name:      "login-success",
+		title:     "登入成功",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{loginSuccessSchema},
+		collect: func(ctx context.Context, a *App) ([]LoginSuccess, error) {
+			return a.getLoginSuccessRecords(ctx)
+		},
+		save: (*App).SaveLoginSuccess,
+	})
+}
@@ -0,0 +1,442 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// migration 一次数据库结构升级，version 必须严格递增
+// 已发布的升级步骤不能再修改，表结构变更只能追加新的升级步骤
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations 按版本排序的所有升级步骤
+var migrations = []migration{
+	{version: 1, description: "初始表结构", up: migrateInitialSchema},
+	{version: 2, description: "扫描会话，所有数据表增加 session_id 列", up: migrateScanSession},
+	{version: 3, description: "EVTX 事件表与文件导入进度", up: migrateEVTXEvent},
+	{version: 4, description: "EVTX 文件哈希、原始计算机名与损坏块", up: migrateEVTXProvenance},
+	{version: 5, description: "Sigma 规则命中表", up: migrateSigmaHit},
+	{version: 6, description: "Windows 安全事件结构化记录表", up: migrateWinEvent},
+	{version: 7, description: "PowerShell 脚本块表", up: migratePSScriptBlock},
+	{version: 8, description: "Sysmon 事件结构化记录表", up: migrateSysmonEvent},
+	{version: 9, description: "统一时间线表", up: migrateTimelineEvent},
+	{version: 10, description: "EVTX 恢复模式与记录偏移", up: migrateEVTXRecovery},
+	{version: 11, description: "登录记录的端口、认证方式与日志文件，sudo 命令表", up: migrateLoginDetail},
+	{version: 12, description: "wtmp 登录会话、btmp、lastlog 与登录日志篡改迹象表", up: migrateLoginLogs},
+	{version: 13, description: "auditd 命令执行记录与审计事件表", up: migrateAuditLog},
+	{version: 14, description: "Web 访问日志文件、可疑请求与高频来源表", up: migrateWebLog},
+	{version: 15, description: "Webshell 扫描结果表", up: migrateWebshell},
+}
+
+// schemaVersionSchema 数据库版本表
+const schemaVersionSchema = `CREATE TABLE IF NOT EXISTS schema_version (
+	version INTEGER PRIMARY KEY,
+	description TEXT,
+	applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// latestSchemaVersion 当前程序支持的最高数据库版本
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// currentSchemaVersion 读取数据库当前版本，未记录版本的数据库视为 0
+func currentSchemaVersion(db *sql.DB) (int, error) {
+	if _, err := db.Exec(schemaVersionSchema); err != nil {
+		return 0, fmt.Errorf("创建版本表失败: %v", err)
+	}
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %v", err)
+	}
+	return int(version.Int64), nil
+}
+
+// schemaVersion 只读地获取数据库版本，不会创建版本表
+func schemaVersion(db *sql.DB) (int, error) {
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %v", err)
+	}
+	if exists == 0 {
+		return 0, nil
+	}
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("读取数据库版本失败: %v", err)
+	}
+	return int(version.Int64), nil
+}
+
+// checkSchemaVersion 只读模式下检查数据库版本，版本不一致时无法升级，只能拒绝打开
+// 旧版本的表缺少会话等列，查询会失败，因此不按旧结构只读打开
+func checkSchemaVersion(db *sql.DB, dbPath string) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("数据库版本 v%d 高于当前程序支持的版本 v%d，请升级 CTScan 后再打开该数据库", current, latest)
+	}
+	if current < latest {
+		return fmt.Errorf("数据库版本 v%d 低于当前版本 v%d，只读模式下无法升级表结构。"+
+			"请不加 -readonly 以读写方式打开一次完成升级，升级前会自动备份到 %s；如需保持原文件不变，请先复制一份再打开副本",
+			current, latest, migrationBackupPath(dbPath, current))
+	}
+	return nil
+}
+
+// migrateDatabase 将数据库升级到最新版本
+// 数据库版本高于程序支持的版本时拒绝打开，避免旧版本程序破坏新数据
+func migrateDatabase(db *sql.DB, dbPath string) error {
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("数据库版本 v%d 高于当前程序支持的版本 v%d，请升级 CTScan 后再打开该数据库", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	// 已有数据的数据库在升级前先备份
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_version', 'sqlite_sequence')`).Scan(&tables); err != nil {
+		return fmt.Errorf("读取数据库表失败: %v", err)
+	}
+	if tables > 0 && dbPath != "" {
+		backupPath := migrationBackupPath(dbPath, current)
+		if _, err := db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+			return fmt.Errorf("升级前备份数据库失败: %v", err)
+		}
+		log.Printf("数据库已备份到: %s", backupPath)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		log.Printf("数据库升级: v%d -> v%d (%s)", current, m.version, m.description)
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("数据库升级到 v%d 失败: %v", m.version, err)
+		}
+		current = m.version
+	}
+	return nil
+}
+
+// migrationBackupPath 返回升级前备份的文件名，VACUUM INTO 不能写入已存在的文件
+// 上次升级失败或中断时留下的备份不删除，改用带时间的文件名
+func migrationBackupPath(dbPath string, version int) string {
+	backupPath := fmt.Sprintf("%s.v%d.bak", dbPath, version)
+	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+		return backupPath
+	}
+	stamp := time.Now().Format("20060102-150405")
+	backupPath = fmt.Sprintf("%s.v%d.%s.bak", dbPath, version, stamp)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			return backupPath
+		}
+		backupPath = fmt.Sprintf("%s.v%d.%s-%d.bak", dbPath, version, stamp, i)
+	}
+}
+
+// applyMigration 在事务中执行单个升级步骤并记录版本
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.description, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execAll 依次执行多条语句
+func execAll(tx *sql.Tx, statements ...string) error {
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn 为表添加列，列已存在时跳过
+// 早期版本的数据库可能已经包含部分列，因此升级步骤需要可重复执行
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	exists := false
+	for rows.Next() {
+		var cid int
+		var name, typ string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// migrateInitialSchema v1: 最初发布版本的表结构
+func migrateInitialSchema(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS user_info (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT,
+			uid TEXT,
+			gid TEXT,
+			home_dir TEXT,
+			name TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS system_info (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT,
+			os TEXT,
+			arch TEXT,
+			cpu_cores INTEGER,
+			kernel_version TEXT,
+			cpu_usage REAL,
+			total_memory INTEGER,
+			memory_usage REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS disk_info (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			system_info_id INTEGER,
+			mount_point TEXT,
+			total_size INTEGER,
+			used_size INTEGER,
+			free_size INTEGER,
+			usage REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (system_info_id) REFERENCES system_info(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS cron_task (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			line TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS file_monitor (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT,
+			file_exists BOOLEAN,
+			size INTEGER,
+			mode TEXT,
+			mod_time DATETIME,
+			create_time DATETIME,
+			access_time DATETIME,
+			change_time DATETIME,
+			is_dir BOOLEAN,
+			is_symlink BOOLEAN,
+			owner TEXT,
+			group_name TEXT,
+			permissions TEXT,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS login_failed (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time DATETIME,
+			event_id TEXT,
+			event_type TEXT,
+			source TEXT,
+			username TEXT,
+			ip_address TEXT,
+			reason TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS login_success (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time DATETIME,
+			event_id TEXT,
+			event_type TEXT,
+			source TEXT,
+			username TEXT,
+			ip_address TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS network_info (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT,
+			gateway TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS network_interface (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			network_info_id INTEGER,
+			name TEXT,
+			ip TEXT,
+			mac TEXT,
+			bytes_sent INTEGER,
+			bytes_recv INTEGER,
+			packets_sent INTEGER,
+			packets_recv INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (network_info_id) REFERENCES network_info(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS network_connection (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			proto TEXT,
+			local_addr TEXT,
+			remote_addr TEXT,
+			status TEXT,
+			pid INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS process_info (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pid INTEGER,
+			name TEXT,
+			ppid INTEGER,
+			parent_name TEXT,
+			create_time INTEGER,
+			exe TEXT,
+			file_ctime INTEGER,
+			file_mtime INTEGER,
+			md5 TEXT,
+			signature TEXT,
+			cpu_percent REAL,
+			mem_percent REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS rdp_login (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time DATETIME,
+			username TEXT,
+			ip TEXT,
+			status TEXT,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS shell_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time DATETIME,
+			command TEXT,
+			user TEXT,
+			shell TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS startup_item (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			path TEXT,
+			type TEXT,
+			enabled BOOLEAN,
+			last_mod_time DATETIME,
+			size INTEGER,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	)
+}
+
+// legacySessionTables 引入扫描会话之前的数据表及其对应的采集项
+var legacySessionTables = []struct{ table, collector string }{
+	{"user_info", "users"},
+	{"system_info", "sysinfo"},
+	{"disk_info", "sysinfo"},
+	{"cron_task", "cron"},
+	{"file_monitor", "files"},
+	{"login_failed", "login-failed"},
+	{"login_success", "login-success"},
+	{"network_info", "network"},
+	{"network_interface", "network"},
+	{"network_connection", "connections"},
+	{"process_info", "processes"},
+	{"rdp_login", "rdp"},
+	{"shell_history", "shell"},
+	{"startup_item", "startup"},
+}
+
+// migrateScanSession v2: 新增扫描会话表，所有数据表增加 session_id 列
+// 升级前已有的数据归入一个导入会话，否则按会话查询、对比、导出与删除时都看不到这些数据
+func migrateScanSession(tx *sql.Tx) error {
+	if err := execAll(tx, scanSessionSchema); err != nil {
+		return err
+	}
+	for _, t := range legacySessionTables {
+		if err := addColumn(tx, t.table, "session_id", "TEXT"); err != nil {
+			return err
+		}
+	}
+
+	var collectors []string
+	var first, last string // created_at 由 CURRENT_TIMESTAMP 生成，为 UTC 时间
+	for _, t := range legacySessionTables {
+		var count int
+		var minTime, maxTime sql.NullString
+		if err := tx.QueryRow(fmt.Sprintf(`SELECT COUNT(*), MIN(created_at), MAX(created_at) FROM %s WHERE session_id IS NULL`, t.table)).
+			Scan(&count, &minTime, &maxTime); err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		if !containsString(collectors, t.collector) {
+			collectors = append(collectors, t.collector)
+		}
+		if minTime.Valid && (first == "" || minTime.String < first) {
+			first = minTime.String
+		}
+		if maxTime.Valid && maxTime.String > last {
+			last = maxTime.String
+		}
+	}
+	if len(collectors) == 0 {
+		return nil
+	}
+
+	var hostname sql.NullString
+	tx.QueryRow(`SELECT hostname FROM system_info WHERE session_id IS NULL ORDER BY id DESC LIMIT 1`).Scan(&hostname)
+	sessionID := uuid.NewString()
+	if _, err := tx.Exec(`INSERT INTO scan_session (id, case_name, analyst, hostname, host_fingerprint, start_time, end_time, tool_version, collectors)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sessionID, "升级前的数据", "", hostname.String, "", nullableTime(first, time.UTC), nullableTime(last, time.UTC), "legacy", strings.Join(collectors, ",")); err != nil {
+		return fmt.Errorf("创建导入会话失败: %v", err)
+	}
+	for _, t := range legacySessionTables {
+		if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET session_id = ? WHERE session_id IS NULL`, t.table), sessionID); err != nil {
+			return fmt.Errorf("关联 %s 到导入会话失败: %v", t.table, err)
+		}
+	}
+	return nil
+}
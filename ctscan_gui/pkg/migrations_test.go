@@ -0,0 +1,293 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openRawDB 直接打开数据库，不执行升级
+func openRawDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createBaselineDB 创建最初发布版本(没有版本表)的数据库，并执行 setup 写入数据
+func createBaselineDB(t *testing.T, path string, setup ...string) {
+	t.Helper()
+	db := openRawDB(t, path)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateInitialSchema(tx); err != nil {
+		t.Fatalf("创建初始表结构失败: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+	}
+	db.Close()
+}
+
+// setSchemaVersion 将已升级的数据库的版本改为 version，模拟旧版本或新版本程序创建的数据库
+func setSchemaVersion(t *testing.T, path string, version int) {
+	t.Helper()
+	db := openRawDB(t, path)
+	if _, err := db.Exec(`DELETE FROM schema_version WHERE version > ?`, version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT OR IGNORE INTO schema_version (version, description) VALUES (?, 'test')`, version); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+}
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestMigrateBaselineDatabase(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          []string
+		rows           map[string]int
+		wantSession    bool
+		wantCollectors []string
+	}{
+		{
+			name: "空数据库",
+			rows: map[string]int{"process_info": 0},
+		},
+		{
+			name: "已有数据",
+			setup: []string{
+				`INSERT INTO system_info (hostname, os) VALUES ('web01', 'linux')`,
+				`INSERT INTO disk_info (system_info_id, mount_point) VALUES (1, '/')`,
+				`INSERT INTO process_info (pid, name) VALUES (1, 'init'), (42, 'sshd')`,
+				`INSERT INTO cron_task (line) VALUES ('* * * * * /tmp/x')`,
+			},
+			rows:           map[string]int{"system_info": 1, "disk_info": 1, "process_info": 2, "cron_task": 1, "user_info": 0},
+			wantSession:    true,
+			wantCollectors: []string{"sysinfo", "cron", "processes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ctscan.db")
+			createBaselineDB(t, path, tt.setup...)
+
+			db, err := InitSqliteDB(path, false)
+			if err != nil {
+				t.Fatalf("升级数据库失败: %v", err)
+			}
+			defer db.Close()
+
+			if v, err := schemaVersion(db); err != nil || v != latestSchemaVersion() {
+				t.Fatalf("升级后版本为 v%d (%v)，应为 v%d", v, err, latestSchemaVersion())
+			}
+			for table, want := range tt.rows {
+				if got := countRows(t, db, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, table)); got != want {
+					t.Errorf("%s 有 %d 行，应为 %d 行", table, got, want)
+				}
+			}
+
+			sessions := countRows(t, db, `SELECT COUNT(*) FROM scan_session`)
+			if !tt.wantSession {
+				if sessions != 0 {
+					t.Errorf("没有数据时不应创建会话，实际有 %d 个", sessions)
+				}
+				return
+			}
+			if sessions != 1 {
+				t.Fatalf("应创建 1 个导入会话，实际有 %d 个", sessions)
+			}
+			var id, hostname, collectors string
+			if err := db.QueryRow(`SELECT id, hostname, collectors FROM scan_session`).Scan(&id, &hostname, &collectors); err != nil {
+				t.Fatal(err)
+			}
+			if hostname != "web01" {
+				t.Errorf("会话主机名为 %q，应为 web01", hostname)
+			}
+			for _, c := range tt.wantCollectors {
+				if !containsString(strings.Split(collectors, ","), c) {
+					t.Errorf("会话采集项 %q 中缺少 %s", collectors, c)
+				}
+			}
+			for table := range tt.rows {
+				if n := countRows(t, db, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE session_id IS NULL OR session_id != ?`, table), id); n != 0 {
+					t.Errorf("%s 有 %d 行没有关联到导入会话", table, n)
+				}
+			}
+		})
+	}
+}
+
+func TestAddColumnIdempotent(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "列不存在", schema: `CREATE TABLE t (id INTEGER PRIMARY KEY)`},
+		{name: "列已存在", schema: `CREATE TABLE t (id INTEGER PRIMARY KEY, session_id TEXT)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openRawDB(t, filepath.Join(t.TempDir(), "t.db"))
+			if _, err := db.Exec(tt.schema); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2; i++ {
+				tx, err := db.Begin()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := addColumn(tx, "t", "session_id", "TEXT"); err != nil {
+					tx.Rollback()
+					t.Fatalf("第 %d 次添加列失败: %v", i+1, err)
+				}
+				if err := tx.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if n := countRows(t, db, `SELECT COUNT(*) FROM pragma_table_info('t') WHERE name = 'session_id'`); n != 1 {
+				t.Errorf("session_id 列出现 %d 次，应为 1 次", n)
+			}
+		})
+	}
+}
+
+func TestMigrateBackup(t *testing.T) {
+	const stale = "上次升级中断时留下的备份"
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T, path string)
+		wantBackups int // 升级前的备份数量，不包括已存在的旧备份
+	}{
+		{
+			name:    "新数据库不备份",
+			prepare: func(t *testing.T, path string) {},
+		},
+		{
+			name: "升级已有数据前备份",
+			prepare: func(t *testing.T, path string) {
+				createBaselineDB(t, path, `INSERT INTO process_info (pid, name) VALUES (1, 'init')`)
+			},
+			wantBackups: 1,
+		},
+		{
+			name: "已有旧备份时使用新文件名",
+			prepare: func(t *testing.T, path string) {
+				createBaselineDB(t, path, `INSERT INTO process_info (pid, name) VALUES (1, 'init')`)
+				if err := os.WriteFile(path+".v0.bak", []byte(stale), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantBackups: 1,
+		},
+		{
+			name: "已是最新版本不备份",
+			prepare: func(t *testing.T, path string) {
+				db, err := InitSqliteDB(path, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				db.Close()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ctscan.db")
+			tt.prepare(t, path)
+			db, err := InitSqliteDB(path, false)
+			if err != nil {
+				t.Fatalf("打开数据库失败: %v", err)
+			}
+			db.Close()
+
+			matches, _ := filepath.Glob(path + ".v*.bak")
+			var backups []string
+			for _, backup := range matches {
+				// 已存在的旧备份不应被覆盖或删除
+				if content, err := os.ReadFile(backup); err == nil && string(content) == stale {
+					continue
+				}
+				backups = append(backups, backup)
+			}
+			if len(backups) != tt.wantBackups {
+				t.Fatalf("生成了 %d 个备份 %v，应为 %d 个", len(backups), backups, tt.wantBackups)
+			}
+			for _, backup := range backups {
+				// 备份是升级前的数据库：保留原有数据，没有版本记录与会话列
+				bak := openRawDB(t, backup)
+				if n := countRows(t, bak, `SELECT COUNT(*) FROM process_info`); n != 1 {
+					t.Errorf("备份中 process_info 有 %d 行，应为 1 行", n)
+				}
+				if n := countRows(t, bak, `SELECT COUNT(*) FROM pragma_table_info('process_info') WHERE name = 'session_id'`); n != 0 {
+					t.Errorf("备份应为升级前的表结构")
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaVersionMismatch(t *testing.T) {
+	latest := latestSchemaVersion()
+	tests := []struct {
+		name     string
+		version  int
+		readOnly bool
+		wantErr  string // 为空时应能打开
+	}{
+		{name: "只读打开当前版本", version: latest, readOnly: true},
+		{name: "只读拒绝旧版本", version: latest - 1, readOnly: true, wantErr: "只读模式下无法升级"},
+		{name: "只读拒绝新版本", version: latest + 1, readOnly: true, wantErr: "高于当前程序支持的版本"},
+		{name: "读写升级旧版本", version: latest - 1},
+		{name: "读写拒绝新版本", version: latest + 1, wantErr: "高于当前程序支持的版本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ctscan.db")
+			db, err := InitSqliteDB(path, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db.Close()
+			setSchemaVersion(t, path, tt.version)
+
+			db, err = InitSqliteDB(path, tt.readOnly)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("应能打开数据库: %v", err)
+				}
+				db.Close()
+				return
+			}
+			if err == nil {
+				db.Close()
+				t.Fatalf("应拒绝打开 v%d 的数据库", tt.version)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 %q 中应包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
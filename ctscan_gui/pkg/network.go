@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -177,3 +178,70 @@ func (a *App) GetNetworkConnections() []NetworkConn {
 	}
 	return result
 }
+
+// networkInfoSchema 网络信息表
+const networkInfoSchema = `CREATE TABLE IF NOT EXISTS network_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	hostname TEXT,
+	gateway TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// networkInterfaceSchema 网络接口表
+const networkInterfaceSchema = `CREATE TABLE IF NOT EXISTS network_interface (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	network_info_id INTEGER,
+	name TEXT,
+	ip TEXT,
+	mac TEXT,
+	bytes_sent INTEGER,
+	bytes_recv INTEGER,
+	packets_sent INTEGER,
+	packets_recv INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (network_info_id) REFERENCES network_info(id)
+);`
+
+// networkConnectionSchema 网络连接表
+const networkConnectionSchema = `CREATE TABLE IF NOT EXISTS network_connection (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	proto TEXT,
+	local_addr TEXT,
+	remote_addr TEXT,
+	status TEXT,
+	pid INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[NetworkInfo]{
+		name:      "network",
+		title:     "网络信息",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{networkInfoSchema, networkInterfaceSchema},
+		collect: func(ctx context.Context, a *App) ([]NetworkInfo, error) {
+			return []NetworkInfo{a.GetNetworkInfo()}, nil
+		},
+		save: func(a *App, infos []NetworkInfo) error {
+			for _, info := range infos {
+				if err := a.SaveNetworkInfo(info); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	RegisterCollector(&sliceCollector[NetworkConn]{
+		name:      "connections",
+		title:     "网络连接",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{networkConnectionSchema},
+		collect: func(ctx context.Context, a *App) ([]NetworkConn, error) {
+			return a.GetNetworkConnections(), nil
+		},
+		save: (*App).SaveNetworkConnections,
+	})
+}
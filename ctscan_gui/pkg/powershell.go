@@ -0,0 +1,248 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// psDecodeDepth 解码后的内容继续解码的最大层数
+const psDecodeDepth = 5
+
+// psMaxDecoded 单个脚本块解码结果的最大长度，防止压缩炸弹
+const psMaxDecoded = 4 << 20
+
+// psLayer 解码得到的一层内容
+type psLayer struct {
+	method string
+	text   string
+}
+
+var (
+	// -EncodedCommand 可以缩写为 -e、-enc 等任意前缀，也可以使用 -ec 或 /e
+	psEncodedArg = regexp.MustCompile(`(?i)(?:^|[\s"'])[-/–]([a-z]+)\s+['"]?([A-Za-z0-9+/]{8,}={0,2})`)
+	psFromBase64 = regexp.MustCompile(`(?i)FromBase64String\s*\(\s*['"]([A-Za-z0-9+/=\s]{8,})['"]\s*\)`)
+	// [char]73+[char]69+[char]88
+	psCharConcat = regexp.MustCompile(`(?i)(?:\[char\]\s*\(?\s*(?:0x[0-9a-f]+|\d+)\s*\)?\s*\+?\s*){3,}`)
+	psCharItem   = regexp.MustCompile(`(?i)\[char\]\s*\(?\s*(0x[0-9a-f]+|\d+)`)
+	// [char[]](73,69,88) 与 (73,69,88) | %{[char]$_}
+	psCharArray = regexp.MustCompile(`(?i)\[char\[\]\]\s*\(\s*((?:(?:0x[0-9a-f]+|\d+)\s*,\s*){3,}(?:0x[0-9a-f]+|\d+))\s*\)`)
+	psCharPipe  = regexp.MustCompile(`(?i)\(\s*((?:(?:0x[0-9a-f]+|\d+)\s*,\s*){3,}(?:0x[0-9a-f]+|\d+))\s*\)\s*\|\s*(?:%|foreach(?:-object)?)\s*\{\s*\[char\]`)
+	psDeflate   = regexp.MustCompile(`(?i)DeflateStream`)
+)
+
+// decodePowerShell 逐层解码脚本中的编码命令、Base64、压缩流与字符码拼接
+func decodePowerShell(script string) []psLayer {
+	var layers []psLayer
+	seen := map[string]bool{script: true}
+	size := 0
+	var walk func(text string, depth int)
+	walk = func(text string, depth int) {
+		if depth >= psDecodeDepth {
+			return
+		}
+		for _, l := range decodePowerShellOnce(text) {
+			if seen[l.text] || size+len(l.text) > psMaxDecoded {
+				continue
+			}
+			seen[l.text] = true
+			size += len(l.text)
+			layers = append(layers, l)
+			walk(l.text, depth+1)
+		}
+	}
+	walk(script, 0)
+	return layers
+}
+
+// decodePowerShellOnce 只解码一层
+func decodePowerShellOnce(text string) []psLayer {
+	var layers []psLayer
+	for _, m := range psEncodedArg.FindAllStringSubmatch(text, -1) {
+		flag := strings.ToLower(m[1])
+		if flag != "ec" && !strings.HasPrefix("encodedcommand", flag) {
+			continue
+		}
+		data, err := decodeBase64(m[2])
+		if err != nil {
+			continue
+		}
+		if s, ok := psText(data, true); ok {
+			layers = append(layers, psLayer{"EncodedCommand", s})
+		}
+	}
+	deflate := psDeflate.MatchString(text)
+	for _, m := range psFromBase64.FindAllStringSubmatch(text, -1) {
+		data, err := decodeBase64(m[1])
+		if err != nil {
+			continue
+		}
+		method := "FromBase64String"
+		if out, ok := psDecompress(data, deflate); ok {
+			data = out
+			method += " + 解压"
+		}
+		if s, ok := psText(data, false); ok {
+			layers = append(layers, psLayer{method, s})
+		}
+	}
+	for _, m := range psCharConcat.FindAllString(text, -1) {
+		var codes []string
+		for _, item := range psCharItem.FindAllStringSubmatch(m, -1) {
+			codes = append(codes, item[1])
+		}
+		if s, ok := psCharCodes(codes); ok {
+			layers = append(layers, psLayer{"字符码拼接", s})
+		}
+	}
+	for _, re := range []*regexp.Regexp{psCharArray, psCharPipe} {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			if s, ok := psCharCodes(strings.Split(m[1], ",")); ok {
+				layers = append(layers, psLayer{"字符码拼接", s})
+			}
+		}
+	}
+	return layers
+}
+
+// decodeBase64 解码 Base64，忽略空白字符与缺失的填充
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimRight(s, "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// psDecompress 解压 gzip 流，脚本中使用 DeflateStream 时按原始 deflate 解压
+func psDecompress(data []byte, deflate bool) ([]byte, bool) {
+	var r io.Reader
+	switch {
+	case len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, false
+		}
+		r = zr
+	case deflate:
+		r = flate.NewReader(bytes.NewReader(data))
+	default:
+		return nil, false
+	}
+	out, err := io.ReadAll(io.LimitReader(r, psMaxDecoded))
+	if err != nil && len(out) == 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// psText 将解码后的字节转换为文本，-EncodedCommand 固定为 UTF-16LE，其余按内容判断
+func psText(data []byte, utf16le bool) (string, bool) {
+	if !utf16le && len(data) >= 2 && data[1] == 0 && data[0] != 0 {
+		utf16le = true
+	}
+	var s string
+	if utf16le {
+		if len(data)%2 == 1 {
+			data = data[:len(data)-1]
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+		}
+		s = string(utf16.Decode(units))
+	} else {
+		if !utf8.Valid(data) {
+			return "", false
+		}
+		s = string(data)
+	}
+	s = strings.TrimPrefix(s, "\ufeff")
+	return s, psPrintable(s)
+}
+
+// psPrintable 判断解码结果是否为文本，二进制数据(如 shellcode)不作为脚本显示
+func psPrintable(s string) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	total, printable := 0, 0
+	for _, r := range s {
+		total++
+		if unicode.IsPrint(r) || r == '\n' || r == '\r' || r == '\t' {
+			printable++
+		}
+	}
+	return printable*10 >= total*9
+}
+
+// psCharCodes 将字符码转换为文本
+func psCharCodes(codes []string) (string, bool) {
+	var b strings.Builder
+	for _, c := range codes {
+		n, err := strconv.ParseInt(strings.TrimSpace(c), 0, 32)
+		if err != nil || n <= 0 || n > unicode.MaxRune {
+			return "", false
+		}
+		b.WriteRune(rune(n))
+	}
+	return b.String(), psPrintable(b.String())
+}
+
+// psIndicator 可疑关键字，score 为命中后增加的分值
+type psIndicator struct {
+	name  string
+	score int
+	re    *regexp.Regexp
+}
+
+var psIndicators = []psIndicator{
+	{"AMSI 绕过", 5, regexp.MustCompile(`(?i)AmsiUtils|amsiInitFailed|AmsiScanBuffer|amsiContext|amsi\.dll`)},
+	{"Mimikatz", 5, regexp.MustCompile(`(?i)Invoke-Mimikatz|mimikatz|sekurlsa::|lsadump::|kerberos::(?:golden|ptt)`)},
+	{"攻击框架", 4, regexp.MustCompile(`(?i)Invoke-(?:Shellcode|Kerberoast|DllInjection|ReflectivePEInjection|TokenManipulation|SMBExec|WMIExec|PowerShellTcp|Empire)|PowerSploit|PowerView|Get-GPPPassword|Nishang`)},
+	{"Shellcode 注入", 4, regexp.MustCompile(`(?i)VirtualAlloc|WriteProcessMemory|CreateRemoteThread|GetDelegateForFunctionPointer`)},
+	{"反弹 Shell", 4, regexp.MustCompile(`(?i)Net\.Sockets\.TCPClient|Net\.Sockets\.Socket`)},
+	{"关闭 Defender", 4, regexp.MustCompile(`(?i)Set-MpPreference\s+[^\n]*-Disable|Add-MpPreference\s+[^\n]*-Exclusion`)},
+	{"远程下载", 3, regexp.MustCompile(`(?i)\.Download(?:String|File|Data)(?:Async)?\s*\(|Invoke-WebRequest|Invoke-RestMethod|Start-BitsTransfer|\biwr\s+http`)},
+	{"反射加载程序集", 3, regexp.MustCompile(`(?i)Reflection\.Assembly\]::Load|\[Reflection\.Assembly\]::Load`)},
+	{"清除痕迹", 3, regexp.MustCompile(`(?i)Clear-EventLog|wevtutil(?:\.exe)?\s+cl\b|Remove-Item[^\n]*ConsoleHost_history|Set-PSReadlineOption\s+[^\n]*-HistorySaveStyle\s+SaveNothing`)},
+	{"动态执行(IEX)", 2, regexp.MustCompile(`(?i)\b(?:IEX|Invoke-Expression)\b`)},
+	{"Net.WebClient", 1, regexp.MustCompile(`(?i)Net\.WebClient`)},
+	{"隐藏执行", 1, regexp.MustCompile(`(?i)-w(?:indowstyle)?\s+h(?:idden)?\b|-(?:ep|executionpolicy)\s+bypass|-nop\b`)},
+}
+
+// psMatchPreview 命中内容最多显示的字符数
+const psMatchPreview = 80
+
+// scorePowerShell 在原始脚本与解码结果中查找可疑关键字，返回命中的说明与分值
+func scorePowerShell(texts ...string) ([]string, int) {
+	var found []string
+	score := 0
+	for _, ind := range psIndicators {
+		for _, text := range texts {
+			m := ind.re.FindString(text)
+			if m == "" {
+				continue
+			}
+			if r := []rune(m); len(r) > psMatchPreview {
+				m = string(r[:psMatchPreview]) + "..."
+			}
+			found = append(found, fmt.Sprintf("%s: %s", ind.name, strings.TrimSpace(m)))
+			score += ind.score
+			break
+		}
+	}
+	return found, score
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"io"
@@ -48,8 +49,8 @@ func getFileTimes(path string) (ctime, mtime int64) {
 	return 0, mtime // macOS/Linux下ctime无法直接获取，返回0
 }
 
-func getSignature(path string) string {
-	out, err := exec.Command("codesign", "-dv", "--verbose=4", path).CombinedOutput()
+func getSignature(ctx context.Context, path string) string {
+	out, err := exec.CommandContext(ctx, "codesign", "-dv", "--verbose=4", path).CombinedOutput()
 	if err != nil {
 		return ""
 	}
@@ -62,13 +63,30 @@ func getSignature(path string) string {
 	return ""
 }
 
-func (a *App) GetAllProcesses() []ProcInfo {
-	procs, err := process.Processes()
+// GetAllProcesses 获取所有进程，可通过 CancelTask("processes") 取消
+func (a *App) GetAllProcesses() ([]ProcInfo, error) {
+	ctx, done := a.startTask(nil, "processes", "进程排查")
+	result, err := collectProcesses(ctx)
+	done(err)
 	if err != nil {
-		return nil
+		return nil, taskError(err)
 	}
+	return result, nil
+}
+
+// collectProcesses 获取进程信息，计算哈希与校验签名较慢，每处理一个进程检查一次是否取消
+func collectProcesses(ctx context.Context) ([]ProcInfo, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress := progressFrom(ctx)
+	progress.SetTotal(len(procs))
 	var result []ProcInfo
 	for _, p := range procs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
 		name, _ := p.Name()
 		exe, _ := p.Exe()
 		ctime, _ := p.CreateTime()
@@ -83,7 +101,7 @@ func (a *App) GetAllProcesses() []ProcInfo {
 		}
 		fileCtime, fileMtime := getFileTimes(exe)
 		md5sum := getFileMD5(exe)
-		signature := getSignature(exe)
+		signature := getSignature(ctx, exe)
 		result = append(result, ProcInfo{
 			PID:        p.Pid,
 			Name:       name,
@@ -98,6 +116,39 @@ func (a *App) GetAllProcesses() []ProcInfo {
 			CPUPercent: cpuPercent,
 			MemPercent: float64(memPercent),
 		})
+		progress.Step(name)
 	}
-	return result
+	return result, nil
+}
+
+// processInfoSchema 进程信息表
+const processInfoSchema = `CREATE TABLE IF NOT EXISTS process_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	pid INTEGER,
+	name TEXT,
+	ppid INTEGER,
+	parent_name TEXT,
+	create_time INTEGER,
+	exe TEXT,
+	file_ctime INTEGER,
+	file_mtime INTEGER,
+	md5 TEXT,
+	signature TEXT,
+	cpu_percent REAL,
+	mem_percent REAL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[ProcInfo]{
+		name:      "processes",
+		title:     "进程排查",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{processInfoSchema},
+		collect: func(ctx context.Context, a *App) ([]ProcInfo, error) {
+			return collectProcesses(ctx)
+		},
+		save: (*App).SaveProcessInfo,
+	})
 }
@@ -0,0 +1,272 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// progressEvent 前端监听的进度事件名
+const progressEvent = "progress"
+
+// progressInterval 两次进度事件之间的最小间隔，避免频繁推送拖慢前端
+const progressInterval = 100 * time.Millisecond
+
+// 任务状态
+const (
+	TaskRunning  = "running"
+	TaskDone     = "done"
+	TaskCanceled = "canceled"
+	TaskFailed   = "failed"
+)
+
+// errTaskCanceled 任务被取消时返回给前端的错误
+var errTaskCanceled = errors.New("任务已取消")
+
+// taskError 将 context 取消错误转换为 errTaskCanceled，便于前端识别
+func taskError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return errTaskCanceled
+	}
+	return err
+}
+
+// Progress 长时间运行任务的进度
+type Progress struct {
+	Task      string `json:"task"` // 任务名，采集器名称或 evtx
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`   // 为 0 表示总数未知
+	Current   string `json:"current"` // 当前处理的对象
+	Errors    int    `json:"errors"`
+	LastError string `json:"last_error"`
+	Elapsed   int64  `json:"elapsed"` // 已耗时，毫秒
+}
+
+// progressReporter 记录任务进度并按间隔推送
+// 方法允许在 nil 上调用，采集代码无需关心是否有人在监听进度
+type progressReporter struct {
+	mu     sync.Mutex
+	p      Progress
+	start  time.Time
+	last   time.Time
+	emitMu sync.Mutex // 逐个推送，避免较早的快照在结束状态之后送达
+	emit   func(Progress)
+}
+
+type progressKey struct{}
+
+// withProgress 将进度记录器放入 context，供采集代码通过 progressFrom 取出
+func withProgress(ctx context.Context, r *progressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, r)
+}
+
+// progressFrom 取出 context 中的进度记录器，没有时返回 nil
+func progressFrom(ctx context.Context) *progressReporter {
+	r, _ := ctx.Value(progressKey{}).(*progressReporter)
+	return r
+}
+
+// SetTotal 设置任务总数
+func (r *progressReporter) SetTotal(total int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.p.Total = total
+	r.mu.Unlock()
+	r.flush(true)
+}
+
+// Step 完成一项，current 为刚处理完的对象
+func (r *progressReporter) Step(current string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.p.Done++
+	r.p.Current = current
+	r.mu.Unlock()
+	r.flush(false)
+}
+
+// Fail 记录一个不影响整体任务的错误
+func (r *progressReporter) Fail(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.mu.Lock()
+	r.p.Errors++
+	r.p.LastError = err.Error()
+	r.mu.Unlock()
+	r.flush(false)
+}
+
+// finish 根据任务返回的错误设置最终状态并推送
+func (r *progressReporter) finish(err error) {
+	r.mu.Lock()
+	switch {
+	case err == nil:
+		r.p.State = TaskDone
+	case errors.Is(err, context.Canceled):
+		r.p.State = TaskCanceled
+	default:
+		r.p.State = TaskFailed
+		r.p.LastError = err.Error()
+	}
+	r.mu.Unlock()
+	r.flush(true)
+}
+
+func (r *progressReporter) snapshot() Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.p
+	p.Elapsed = time.Since(r.start).Milliseconds()
+	return p
+}
+
+// flush 推送进度，force 为 false 时受 progressInterval 限制
+func (r *progressReporter) flush(force bool) {
+	if r.emit == nil {
+		return
+	}
+	r.mu.Lock()
+	now := time.Now()
+	if !force && now.Sub(r.last) < progressInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.last = now
+	r.mu.Unlock()
+	r.emitMu.Lock()
+	defer r.emitMu.Unlock()
+	r.emit(r.snapshot())
+}
+
+// runningTask 正在运行的任务
+type runningTask struct {
+	cancel context.CancelFunc
+}
+
+// startTask 注册一个可取消的任务，返回带进度记录器的 context 与结束函数
+// 同名任务正在运行时会先取消旧任务
+func (a *App) startTask(parent context.Context, name, title string) (context.Context, func(error)) {
+	if parent == nil {
+		parent = a.ctx
+	}
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	r := &progressReporter{
+		p:     Progress{Task: name, Title: title, State: TaskRunning},
+		start: time.Now(),
+		emit:  a.emitProgress,
+	}
+
+	task := &runningTask{cancel: cancel}
+	a.tasksMu.Lock()
+	if a.tasks == nil {
+		a.tasks = make(map[string]*runningTask)
+	}
+	if old, ok := a.tasks[name]; ok {
+		old.cancel()
+	}
+	a.tasks[name] = task
+	a.tasksMu.Unlock()
+	r.flush(true)
+
+	return withProgress(ctx, r), func(err error) {
+		a.tasksMu.Lock()
+		// 任务可能已被同名的新任务替换
+		if a.tasks[name] == task {
+			delete(a.tasks, name)
+		}
+		a.tasksMu.Unlock()
+		cancel()
+		r.finish(err)
+	}
+}
+
+// emitProgress 推送进度：图形界面通过 Wails 事件，命令行通过 onProgress
+func (a *App) emitProgress(p Progress) {
+	if a.onProgress != nil {
+		a.onProgress(p)
+		return
+	}
+	if a.ctx != nil {
+		wailsruntime.EventsEmit(a.ctx, progressEvent, p)
+	}
+}
+
+// CancelTask 取消正在运行的任务，任务不存在时返回 false
+func (a *App) CancelTask(name string) bool {
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+	task, ok := a.tasks[name]
+	if ok {
+		task.cancel()
+	}
+	return ok
+}
+
+// newStderrProgress 命令行模式下的进度输出
+// stderr 为终端时在同一行刷新，否则(如重定向到文件)每隔几秒输出一行
+// 返回的函数由所有任务共用，可能被并发调用
+func newStderrProgress() func(Progress) {
+	tty := false
+	if info, err := os.Stderr.Stat(); err == nil {
+		tty = info.Mode()&os.ModeCharDevice != 0
+	}
+	var (
+		mu      sync.Mutex
+		task    string
+		last    time.Time
+		printed bool
+	)
+	return func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		line := fmt.Sprintf("[%s] %d", p.Title, p.Done)
+		if p.Total > 0 {
+			line = fmt.Sprintf("[%s] %d/%d %3d%%", p.Title, p.Done, p.Total, p.Done*100/p.Total)
+		}
+		if p.Errors > 0 {
+			line += fmt.Sprintf(" 错误 %d", p.Errors)
+		}
+		if !tty {
+			// 运行较快的任务不输出进度，只有耗时较长或被取消的任务才输出
+			if p.Task != task {
+				task, last, printed = p.Task, time.Now(), false
+			}
+			switch {
+			case p.State == TaskRunning && time.Since(last) < 5*time.Second:
+				return
+			case p.State == TaskCanceled:
+				line += " 已取消"
+			case p.State != TaskRunning && !printed:
+				return
+			}
+			last, printed = time.Now(), true
+			fmt.Fprintln(os.Stderr, line)
+			return
+		}
+		if p.State != TaskRunning {
+			// 结束时清除进度行，由调用方输出汇总
+			fmt.Fprint(os.Stderr, "\r\033[K")
+			return
+		}
+		current := []rune(p.Current)
+		if len(current) > 50 {
+			current = append([]rune("..."), current[len(current)-47:]...)
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[K%s %s", line, string(current))
+	}
+}
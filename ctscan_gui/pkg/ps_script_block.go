@@ -0,0 +1,400 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const psScriptBlockSchema = `CREATE TABLE IF NOT EXISTS ps_script_block (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	computer TEXT,
+	script_block_id TEXT,
+	path TEXT,
+	user_id TEXT,
+	first_time DATETIME,
+	last_time DATETIME,
+	message_total INTEGER,
+	message_count INTEGER,
+	complete INTEGER,
+	warning INTEGER,
+	parts TEXT,
+	script TEXT,
+	decoded TEXT,
+	obfuscation TEXT,
+	indicators TEXT,
+	score INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+)`
+
+var psScriptBlockIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_ps_script_block_score ON ps_script_block (session_id, score)`,
+}
+
+// migratePSScriptBlock 新增 PowerShell 脚本块表
+func migratePSScriptBlock(tx *sql.Tx) error {
+	return execAll(tx, append([]string{psScriptBlockSchema}, psScriptBlockIndexes...)...)
+}
+
+// psScriptBlockEventID PowerShell 脚本块日志，大脚本会拆分为多个事件
+const psScriptBlockEventID = 4104
+
+// ScriptBlockPart 组成脚本块的一个 4104 事件
+type ScriptBlockPart struct {
+	SourceFile    string `json:"source_file"`
+	RecordID      int    `json:"record_id"`
+	MessageNumber int    `json:"message_number"`
+	Time          string `json:"time"` // UTC
+}
+
+// ScriptBlock 由 4104 事件按 ScriptBlockId 与 MessageNumber 拼接出的完整脚本
+type ScriptBlock struct {
+	ID            int64             `json:"id"`
+	SessionID     string            `json:"session_id"`
+	Computer      string            `json:"computer"`
+	ScriptBlockID string            `json:"script_block_id"`
+	Path          string            `json:"path"` // 脚本文件路径，交互执行时为空
+	UserID        string            `json:"user_id"`
+	FirstTime     string            `json:"first_time"` // UTC
+	LastTime      string            `json:"last_time"`
+	MessageTotal  int               `json:"message_total"`
+	MessageCount  int               `json:"message_count"`
+	Complete      bool              `json:"complete"` // 所有分段都已找到
+	Warning       bool              `json:"warning"`  // PowerShell 自身判断为可疑时记录为警告级别
+	Parts         []ScriptBlockPart `json:"parts"`
+	Script        string            `json:"script"`
+	Decoded       string            `json:"decoded"`     // 逐层解码的结果
+	Obfuscation   []string          `json:"obfuscation"` // 使用的编码方式
+	Indicators    []string          `json:"indicators"`  // 命中的可疑关键字
+	Score         int               `json:"score"`
+}
+
+// ScriptBlockQuery 脚本块的查询条件
+type ScriptBlockQuery struct {
+	SessionID  string `json:"session_id"`
+	Computer   string `json:"computer"`
+	Keyword    string `json:"keyword"`
+	Suspicious bool   `json:"suspicious"` // 只显示命中可疑关键字的脚本块
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+}
+
+// ScriptBlockPage 一页脚本块
+type ScriptBlockPage struct {
+	SessionID string        `json:"session_id"`
+	Total     int           `json:"total"`
+	Page      int           `json:"page"`
+	PageSize  int           `json:"page_size"`
+	Blocks    []ScriptBlock `json:"blocks"`
+}
+
+// ScriptBlockRebuildResult 重新拼接脚本块的结果
+type ScriptBlockRebuildResult struct {
+	SessionID  string `json:"session_id"`
+	Events     int    `json:"events"`
+	Blocks     int    `json:"blocks"`
+	Suspicious int    `json:"suspicious"`
+	Incomplete int    `json:"incomplete"`
+}
+
+// psFragment 一个 4104 事件中的脚本分段
+type psFragment struct {
+	part  ScriptBlockPart
+	total int
+	text  string
+	path  string
+	user  string
+	warn  bool
+}
+
+// isScriptBlockEvent 判断是否为 Windows PowerShell 或 PowerShell 7 的脚本块日志
+func isScriptBlockEvent(e *EVTXEvent) bool {
+	return e.EventID == psScriptBlockEventID && strings.Contains(strings.ToLower(e.Provider), "powershell")
+}
+
+// psFragmentOf 读取事件中的脚本分段
+func psFragmentOf(e *EVTXEvent) (key string, f psFragment, ok bool) {
+	id := winEventData(e, "ScriptBlockId")
+	if id == "" {
+		return "", psFragment{}, false
+	}
+	number, _ := strconv.Atoi(winEventData(e, "MessageNumber"))
+	total, _ := strconv.Atoi(winEventData(e, "MessageTotal"))
+	if number < 1 {
+		number = 1
+	}
+	if total < number {
+		total = number
+	}
+	// ScriptBlockText 中的换行与缩进需要保留，不能使用 winEventData
+	var text string
+	if v, found := sigmaLookup(e.EventData, "ScriptBlockText"); found {
+		text = strings.Join(sigmaStrings(v), "")
+	}
+	f = psFragment{
+		part:  ScriptBlockPart{SourceFile: e.SourceFile, RecordID: e.EventRecordID, MessageNumber: number, Time: e.Time},
+		total: total,
+		text:  text,
+		path:  winEventData(e, "Path"),
+		user:  e.UserID,
+		warn:  e.Level == getEventLevel(3),
+	}
+	return e.Computer + "\x00" + strings.ToLower(id), f, true
+}
+
+// assembleScriptBlocks 按 ScriptBlockId 分组，按 MessageNumber 拼接，重复导入的同一分段只取一次
+func assembleScriptBlocks(groups map[string][]psFragment) []ScriptBlock {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	blocks := make([]ScriptBlock, 0, len(keys))
+	for _, key := range keys {
+		fragments := groups[key]
+		sort.SliceStable(fragments, func(i, j int) bool {
+			return fragments[i].part.MessageNumber < fragments[j].part.MessageNumber
+		})
+		computer, id, _ := strings.Cut(key, "\x00")
+		b := ScriptBlock{Computer: computer, ScriptBlockID: id, Parts: []ScriptBlockPart{}}
+		var script strings.Builder
+		seen := map[int]bool{}
+		for _, f := range fragments {
+			if f.total > b.MessageTotal {
+				b.MessageTotal = f.total
+			}
+			if b.Path == "" {
+				b.Path = f.path
+			}
+			if b.UserID == "" {
+				b.UserID = f.user
+			}
+			b.Warning = b.Warning || f.warn
+			if b.FirstTime == "" || f.part.Time < b.FirstTime {
+				b.FirstTime = f.part.Time
+			}
+			if f.part.Time > b.LastTime {
+				b.LastTime = f.part.Time
+			}
+			b.Parts = append(b.Parts, f.part)
+			if seen[f.part.MessageNumber] {
+				continue
+			}
+			seen[f.part.MessageNumber] = true
+			script.WriteString(f.text)
+		}
+		b.MessageCount = len(seen)
+		b.Complete = b.MessageCount == b.MessageTotal
+		b.Script = script.String()
+		analyzeScriptBlock(&b)
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// analyzeScriptBlock 解码脚本并查找可疑关键字
+func analyzeScriptBlock(b *ScriptBlock) {
+	layers := decodePowerShell(b.Script)
+	b.Obfuscation = []string{}
+	var decoded []string
+	for _, l := range layers {
+		if !containsString(b.Obfuscation, l.method) {
+			b.Obfuscation = append(b.Obfuscation, l.method)
+		}
+		decoded = append(decoded, "# ---- "+l.method+" ----\n"+l.text)
+	}
+	b.Decoded = strings.Join(decoded, "\n\n")
+
+	texts := []string{b.Script}
+	for _, l := range layers {
+		texts = append(texts, l.text)
+	}
+	b.Indicators, b.Score = scorePowerShell(texts...)
+	if b.Indicators == nil {
+		b.Indicators = []string{}
+	}
+	// 编码本身不一定可疑，只在命中关键字时加分
+	if b.Score > 0 {
+		b.Score += len(b.Obfuscation)
+	}
+	if b.Warning {
+		b.Indicators = append(b.Indicators, "PowerShell 记录为警告级别")
+		b.Score += 2
+	}
+}
+
+// rebuildScriptBlocks 读取会话中的全部 4104 事件重新拼接脚本块，完成后在一个事务中替换原有记录
+func (a *App) rebuildScriptBlocks(ctx context.Context, sessionID string) (ScriptBlockRebuildResult, error) {
+	result := ScriptBlockRebuildResult{SessionID: sessionID}
+	progress := progressFrom(ctx)
+	var total int
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM evtx_event WHERE session_id = ? AND event_id = ?`,
+		sessionID, psScriptBlockEventID).Scan(&total); err != nil {
+		return result, fmt.Errorf("查询EVTX事件失败: %v", err)
+	}
+	progress.SetTotal((total + evtxBatchSize - 1) / evtxBatchSize)
+
+	groups := map[string][]psFragment{}
+	for offset := 0; offset < total; offset += evtxBatchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		events, err := a.queryEVTXEvents(`session_id = ? AND event_id = ? ORDER BY id LIMIT ? OFFSET ?`,
+			sessionID, psScriptBlockEventID, evtxBatchSize, offset)
+		if err != nil {
+			return result, err
+		}
+		for i := range events {
+			if !isScriptBlockEvent(&events[i]) {
+				continue
+			}
+			if key, f, ok := psFragmentOf(&events[i]); ok {
+				groups[key] = append(groups[key], f)
+				result.Events++
+			}
+		}
+		progress.Step(fmt.Sprintf("%d 个脚本块", len(groups)))
+	}
+	blocks := assembleScriptBlocks(groups)
+
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+	tx, err := a.database().Begin()
+	if err != nil {
+		return result, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM ps_script_block WHERE session_id = ?`, sessionID); err != nil {
+		return result, fmt.Errorf("清除旧的脚本块失败: %v", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO ps_script_block (session_id, computer, script_block_id, path, user_id,
+		first_time, last_time, message_total, message_count, complete, warning, parts, script, decoded,
+		obfuscation, indicators, score) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return result, fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+	for _, b := range blocks {
+		first, _ := time.Parse(sessionTimeLayout, b.FirstTime)
+		last, _ := time.Parse(sessionTimeLayout, b.LastTime)
+		parts, _ := json.Marshal(b.Parts)
+		obfuscation, _ := json.Marshal(b.Obfuscation)
+		indicators, _ := json.Marshal(b.Indicators)
+		if _, err := stmt.Exec(sessionID, b.Computer, b.ScriptBlockID, b.Path, b.UserID, first, last,
+			b.MessageTotal, b.MessageCount, b.Complete, b.Warning, string(parts), b.Script, b.Decoded,
+			string(obfuscation), string(indicators), b.Score); err != nil {
+			return result, fmt.Errorf("保存脚本块失败: %v", err)
+		}
+		if b.Score > 0 {
+			result.Suspicious++
+		}
+		if !b.Complete {
+			result.Incomplete++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("提交事务失败: %v", err)
+	}
+	result.Blocks = len(blocks)
+	return result, nil
+}
+
+// RebuildScriptBlocks 重新拼接会话中的 PowerShell 脚本块，导入EVTX文件后会自动运行
+// 可通过 CancelTask("powershell") 取消，取消时不修改原有记录
+func (a *App) RebuildScriptBlocks(sessionID string) (ScriptBlockRebuildResult, error) {
+	if a.isReadOnly() {
+		return ScriptBlockRebuildResult{}, errReadOnly
+	}
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return ScriptBlockRebuildResult{}, err
+	}
+	ctx, done := a.startTask(nil, "powershell", "拼接PowerShell脚本块")
+	result, err := a.rebuildScriptBlocks(ctx, sessionID)
+	done(err)
+	return result, taskError(err)
+}
+
+func (q ScriptBlockQuery) where(sessionID string) (string, []any) {
+	conds := []string{"session_id = ?"}
+	args := []any{sessionID}
+	if q.Computer != "" {
+		conds = append(conds, "computer = ?")
+		args = append(args, q.Computer)
+	}
+	if q.Suspicious {
+		conds = append(conds, "score > 0")
+	}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		conds = append(conds, "(script LIKE ? OR decoded LIKE ? OR path LIKE ? OR script_block_id LIKE ? OR indicators LIKE ?)")
+		args = append(args, like, like, like, like, like)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// QueryScriptBlocks 分页查询脚本块，按可疑分值从高到低、时间先后排序
+func (a *App) QueryScriptBlocks(q ScriptBlockQuery) (ScriptBlockPage, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return ScriptBlockPage{}, err
+	}
+	page := ScriptBlockPage{SessionID: sessionID, Page: q.Page, PageSize: q.PageSize}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize < 1 {
+		page.PageSize = evtxDefaultPageSize
+	}
+	if page.PageSize > evtxMaxPageSize {
+		page.PageSize = evtxMaxPageSize
+	}
+
+	where, args := q.where(sessionID)
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM ps_script_block WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return ScriptBlockPage{}, fmt.Errorf("查询PowerShell脚本块失败: %v", err)
+	}
+	args = append(args, page.PageSize, (page.Page-1)*page.PageSize)
+	rows, err := a.database().Query(`SELECT id, session_id, computer, script_block_id, path, user_id, first_time, last_time,
+		message_total, message_count, complete, warning, parts, script, decoded, obfuscation, indicators, score
+		FROM ps_script_block WHERE `+where+` ORDER BY score DESC, first_time, id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return ScriptBlockPage{}, fmt.Errorf("查询PowerShell脚本块失败: %v", err)
+	}
+	defer rows.Close()
+
+	page.Blocks = []ScriptBlock{}
+	for rows.Next() {
+		var (
+			b                              ScriptBlock
+			first, last                    sql.NullTime
+			parts, obfuscation, indicators sql.NullString
+		)
+		if err := rows.Scan(&b.ID, &b.SessionID, &b.Computer, &b.ScriptBlockID, &b.Path, &b.UserID, &first, &last,
+			&b.MessageTotal, &b.MessageCount, &b.Complete, &b.Warning, &parts, &b.Script, &b.Decoded,
+			&obfuscation, &indicators, &b.Score); err != nil {
+			return ScriptBlockPage{}, fmt.Errorf("读取PowerShell脚本块失败: %v", err)
+		}
+		if first.Valid {
+			b.FirstTime = first.Time.UTC().Format(sessionTimeLayout)
+		}
+		if last.Valid {
+			b.LastTime = last.Time.UTC().Format(sessionTimeLayout)
+		}
+		b.Parts = []ScriptBlockPart{}
+		if parts.String != "" {
+			json.Unmarshal([]byte(parts.String), &b.Parts)
+		}
+		b.Obfuscation = jsonStrings(obfuscation.String)
+		b.Indicators = jsonStrings(indicators.String)
+		page.Blocks = append(page.Blocks, b)
+	}
+	return page, rows.Err()
+}
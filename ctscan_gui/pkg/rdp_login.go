@@ -1,8 +1,10 @@
 package pkg
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
 	"regexp"
@@ -86,7 +88,7 @@ func getWindowsRDPLogs() []RDPLoginInfo {
 		}
 
 		logs = append(logs, RDPLoginInfo{
-			Time:        t.Format("2006-01-02 15:04:05"),
+			Time:        formatLocalTime(t),
 			Username:    username,
 			IP:          ip,
 			Status:      status,
@@ -97,8 +99,17 @@ func getWindowsRDPLogs() []RDPLoginInfo {
 	return logs
 }
 
-// 获取Linux/macOS系统的RDP日志
+// 获取Linux/macOS系统的RDP日志，Linux 下还读取 journal 中 xrdp 的记录
 func getUnixRDPLogs() []RDPLoginInfo {
+	logs := getUnixRDPFileLogs()
+	if runtime.GOOS == "linux" {
+		logs = append(logs, getJournalRDPLogs()...)
+	}
+	return logs
+}
+
+// getUnixRDPFileLogs 从文本日志文件中过滤RDP相关的记录
+func getUnixRDPFileLogs() []RDPLoginInfo {
 	var logs []RDPLoginInfo
 
 	// 根据操作系统选择对应的日志文件
@@ -133,72 +144,131 @@ func getUnixRDPLogs() []RDPLoginInfo {
 
 	// 如果没有找到日志文件，直接返回
 	if logFile == "" {
-		fmt.Printf("未找到RDP日志文件\n")
 		return logs
 	}
 
-	fmt.Printf("正在检查日志文件: %s\n", logFile)
-
 	// 使用 grep 命令直接过滤出包含 RDP 相关内容的行
 	cmd := exec.Command("grep", "-i", "-E", "(xrdp|rdp|RemoteDesktop)", logFile)
 	output, err := cmd.Output()
 	if err != nil {
 		// grep 在没有找到匹配项时会返回错误，这是正常的
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			fmt.Printf("未找到RDP相关日志记录\n")
 			return logs
 		}
-		fmt.Printf("执行grep命令失败: %v\n", err)
+		log.Printf("读取RDP日志 %s 失败: %v", logFile, err)
 		return logs
 	}
 
 	// 匹配RDP相关的日志行
 	lines := strings.Split(string(output), "\n")
-	fmt.Printf("找到 %d 行相关日志\n", len(lines))
 
+	// 日志中的时间没有年份，与认证日志一样按文件的修改时间补全
+	var entries []authLogEntry
+	var noYear []bool
 	for _, line := range lines {
 		if line == "" {
 			continue
 		}
 
 		// 提取时间
-		timeRegex := regexp.MustCompile(`(\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})`)
-		timeMatch := timeRegex.FindStringSubmatch(line)
+		timeMatch := rdpTimeRegex.FindStringSubmatch(line)
 		if len(timeMatch) < 2 {
 			continue
 		}
-
-		// 提取用户名
-		userRegex := regexp.MustCompile(`user\s+(\w+)`)
-		userMatch := userRegex.FindStringSubmatch(line)
-		username := "unknown"
-		if len(userMatch) >= 2 {
-			username = userMatch[1]
+		t, err := time.ParseInLocation(syslogTimeLayout, timeMatch[1], time.Local)
+		if err != nil {
+			continue
 		}
+		entries = append(entries, authLogEntry{Time: t, Message: line})
+		noYear = append(noYear, true)
+	}
+	modTime := time.Now()
+	if info, err := os.Stat(logFile); err == nil {
+		modTime = info.ModTime()
+	}
+	inferSyslogYears(entries, noYear, modTime)
+	for _, e := range entries {
+		logs = append(logs, parseRDPLogLine(formatLocalTime(e.Time), e.Message))
+	}
+	return logs
+}
 
-		// 提取IP地址
-		ipRegex := regexp.MustCompile(`from\s+([\d\.]+)`)
-		ipMatch := ipRegex.FindStringSubmatch(line)
-		ip := "unknown"
-		if len(ipMatch) >= 2 {
-			ip = ipMatch[1]
-		}
+// RDP相关日志中的时间、用户名与IP地址
+// xrdp-sesman 的记录形如 "++ created session (access granted): username alice, ip 10.0.0.1:53000" 与 "AUTHFAIL: user=alice ip=10.0.0.1"
+var (
+	rdpTimeRegex = regexp.MustCompile(`(\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})`)
+	rdpUserRegex = regexp.MustCompile(`user(?:name)?[\s=]+(\w+)`)
+	rdpIPRegex   = regexp.MustCompile(`(?:from|ip)[\s=]+([\d\.]+)`)
+)
 
-		// 判断登录状态
-		status := "失败"
-		if strings.Contains(line, "successful") || strings.Contains(line, "Accepted") || strings.Contains(line, "connected") {
-			status = "成功"
-		}
+// parseRDPLogLine 从一行日志中提取用户名、IP与登录状态
+func parseRDPLogLine(timeText, line string) RDPLoginInfo {
+	// 提取用户名
+	username := "unknown"
+	if m := rdpUserRegex.FindStringSubmatch(line); len(m) >= 2 {
+		username = m[1]
+	}
 
-		logs = append(logs, RDPLoginInfo{
-			Time:        timeMatch[1],
-			Username:    username,
-			IP:          ip,
-			Status:      status,
-			Description: fmt.Sprintf("用户 %s 从 %s 尝试登录", username, ip),
-		})
+	// 提取IP地址
+	ip := "unknown"
+	if m := rdpIPRegex.FindStringSubmatch(line); len(m) >= 2 {
+		ip = m[1]
+	}
+
+	// 判断登录状态
+	status := "失败"
+	if strings.Contains(line, "successful") || strings.Contains(line, "Accepted") || strings.Contains(line, "connected") ||
+		strings.Contains(line, "access granted") {
+		status = "成功"
 	}
 
-	fmt.Printf("成功解析 %d 条RDP登录记录\n", len(logs))
+	return RDPLoginInfo{
+		Time:        timeText,
+		Username:    username,
+		IP:          ip,
+		Status:      status,
+		Description: fmt.Sprintf("用户 %s 从 %s 尝试登录", username, ip),
+	}
+}
+
+// rdpJournalMessageRe journal 中 xrdp 与登录相关的消息，xrdp 的其他运行日志不作为登录记录
+// PAM 的记录(pam_unix(xrdp-sesman:auth) 等)已写入认证日志，由登录成功与失败的采集项处理
+var rdpJournalMessageRe = regexp.MustCompile(`(?i)access granted|AUTHFAIL|login (failed|successful)|connected client`)
+
+// getJournalRDPLogs 读取 journal 中 xrdp、xrdp-sesman 记录的登录
+func getJournalRDPLogs() []RDPLoginInfo {
+	var logs []RDPLoginInfo
+	eachJournalEntry(context.Background(), journalFiles("/"), nil, func(e journalEntry) {
+		msg := e.Fields["MESSAGE"]
+		if !strings.HasPrefix(journalIdentifier(e.Fields), "xrdp") || !rdpJournalMessageRe.MatchString(msg) {
+			return
+		}
+		logs = append(logs, parseRDPLogLine(formatLocalTime(e.Time), msg))
+	})
 	return logs
 }
+
+// rdpLoginSchema RDP登录表
+const rdpLoginSchema = `CREATE TABLE IF NOT EXISTS rdp_login (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	username TEXT,
+	ip TEXT,
+	status TEXT,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[RDPLoginInfo]{
+		name:      "rdp",
+		title:     "RDP登入",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{rdpLoginSchema},
+		collect: func(ctx context.Context, a *App) ([]RDPLoginInfo, error) {
+			return a.GetRDPLoginLogs(), nil
+		},
+		save: (*App).SaveRDPLogin,
+	})
+}
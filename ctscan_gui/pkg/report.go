@@ -0,0 +1,465 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+//go:embed templates/report.html
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateText))
+
+// reportTable 报告中的一个表格
+type reportTable struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// reportSection 报告中的一个章节
+type reportSection struct {
+	ID     string
+	Title  string
+	Note   string // 章节说明
+	Alert  bool   // 章节中有需要关注的内容
+	Tables []reportTable
+}
+
+// Count 章节中的记录总数
+func (s reportSection) Count() int {
+	n := 0
+	for _, t := range s.Tables {
+		n += len(t.Rows)
+	}
+	return n
+}
+
+// reportData 报告模板数据
+type reportData struct {
+	Session     ScanSession
+	GeneratedAt string
+	DBPath      string
+	DBHash      string
+	Sections    []reportSection
+}
+
+// reportBuilder 按顺序生成报告章节
+type reportBuilder struct {
+	db        *sql.DB
+	sessionID string
+	err       error
+}
+
+// table 执行查询并生成表格，查询结果的列数需要与 columns 一致
+func (b *reportBuilder) table(title string, columns []string, query string, args ...any) reportTable {
+	t := reportTable{Title: title, Columns: columns}
+	if b.err != nil {
+		return t
+	}
+	rows, err := queryStrings(b.db, query, append([]any{b.sessionID}, args...)...)
+	if err != nil {
+		b.err = fmt.Errorf("生成报告表格 %s 失败: %v", title, err)
+		return t
+	}
+	t.Rows = rows
+	return t
+}
+
+// utcTime 以 UTC 输出 DATETIME 列
+// 驱动会把 DATETIME 列读取为 time.Time，formatDBValue 再转换为本地时间，标注为 UTC 的列需要在查询中转换为文本
+func utcTime(column string) string {
+	return "strftime('%Y-%m-%d %H:%M:%S', " + column + ")"
+}
+
+// queryStrings 执行查询并将所有值转换为字符串
+func queryStrings(db *sql.DB, query string, args ...any) ([][]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	result := [][]string{}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = formatDBValue(v)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// suspiciousDirs 恶意程序常用的落地目录
+var suspiciousDirs = []string{
+	"/tmp/", "/var/tmp/", "/dev/shm/", "/run/user/",
+	`\temp\`, `\appdata\`, `\users\public\`, `\downloads\`, `\$recycle.bin\`,
+}
+
+// processFlags 返回进程的可疑特征
+func processFlags(name, exe, md5, signature, goos string, cpu float64) []string {
+	var flags []string
+	lower := strings.ToLower(exe)
+	for _, dir := range suspiciousDirs {
+		if strings.Contains(lower, dir) {
+			flags = append(flags, "位于临时/用户目录")
+			break
+		}
+	}
+	if strings.HasSuffix(exe, " (deleted)") {
+		flags = append(flags, "程序文件已删除")
+	} else if exe != "" && md5 == "" {
+		flags = append(flags, "无法读取程序文件")
+	}
+	if exe != "" && name != "" {
+		base := strings.TrimSuffix(filepath.Base(strings.ReplaceAll(exe, `\`, "/")), " (deleted)")
+		lowerBase, lowerName := strings.ToLower(base), strings.ToLower(name)
+		// Linux 进程名最长 15 个字符，只比较前缀
+		if !strings.HasPrefix(lowerBase, lowerName) && !strings.HasPrefix(lowerName, lowerBase) {
+			flags = append(flags, "进程名与程序文件不一致")
+		}
+	}
+	if goos == "darwin" && exe != "" && signature == "" {
+		flags = append(flags, "无签名")
+	}
+	if cpu >= 80 {
+		flags = append(flags, "CPU占用过高")
+	}
+	return flags
+}
+
+// flaggedProcesses 筛选出有可疑特征的进程
+func (b *reportBuilder) flaggedProcesses(goos string) reportTable {
+	t := reportTable{
+		Title:   "可疑进程",
+		Columns: []string{"PID", "进程名", "父进程", "程序路径", "MD5", "签名", "可疑特征"},
+		Rows:    [][]string{},
+	}
+	if b.err != nil {
+		return t
+	}
+	rows, err := b.db.Query(`
+	SELECT pid, name, parent_name, exe, md5, signature, cpu_percent
+	FROM process_info WHERE session_id = ? ORDER BY pid`, b.sessionID)
+	if err != nil {
+		b.err = fmt.Errorf("查询进程失败: %v", err)
+		return t
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pid sql.NullInt64
+		var name, parent, exe, md5, signature sql.NullString
+		var cpu sql.NullFloat64
+		if err := rows.Scan(&pid, &name, &parent, &exe, &md5, &signature, &cpu); err != nil {
+			b.err = err
+			return t
+		}
+		flags := processFlags(name.String, exe.String, md5.String, signature.String, goos, cpu.Float64)
+		if len(flags) == 0 {
+			continue
+		}
+		t.Rows = append(t.Rows, []string{
+			fmt.Sprint(pid.Int64), name.String, parent.String, exe.String,
+			md5.String, signature.String, strings.Join(flags, "、"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		b.err = err
+	}
+	return t
+}
+
+// buildReport 读取会话数据生成报告内容
+func (a *App) buildReport(sessionID string) (reportData, error) {
+	session, err := a.getScanSession(sessionID)
+	if err != nil {
+		return reportData{}, err
+	}
+	dbHash, err := fileSHA256(a.databasePath())
+	if err != nil {
+		return reportData{}, fmt.Errorf("计算数据库哈希失败: %v", err)
+	}
+
+	b := &reportBuilder{db: a.database(), sessionID: sessionID}
+	var goos string
+	a.database().QueryRow(`SELECT os FROM system_info WHERE session_id = ? ORDER BY id DESC LIMIT 1`, sessionID).Scan(&goos)
+
+	sections := []reportSection{
+		{
+			ID:    "host",
+			Title: "主机概况",
+			Tables: []reportTable{
+				b.table("系统信息", []string{"主机名", "操作系统", "架构", "内核版本", "CPU核数", "CPU使用率", "内存", "内存使用率"}, `
+				SELECT hostname, os, arch, kernel_version, cpu_cores,
+					printf('%.1f%%', cpu_usage), printf('%.1f GB', total_memory / 1073741824.0), printf('%.1f%%', memory_usage)
+				FROM system_info WHERE session_id = ? ORDER BY id`),
+				b.table("磁盘", []string{"挂载点", "总容量", "已用", "可用", "使用率"}, `
+				SELECT mount_point, printf('%.1f GB', total_size / 1073741824.0), printf('%.1f GB', used_size / 1073741824.0),
+					printf('%.1f GB', free_size / 1073741824.0), printf('%.1f%%', usage)
+				FROM disk_info WHERE session_id = ? ORDER BY mount_point`),
+			},
+		},
+		{
+			ID:    "users",
+			Title: "用户",
+			Tables: []reportTable{
+				b.table("系统用户", []string{"用户名", "UID", "GID", "主目录", "全名"}, `
+				SELECT username, uid, gid, home_dir, name FROM user_info WHERE session_id = ? ORDER BY username`),
+			},
+		},
+		{
+			ID:     "processes",
+			Title:  "进程",
+			Note:   "可疑特征为启发式判断，需要结合实际业务人工确认",
+			Tables: []reportTable{b.flaggedProcesses(goos)},
+		},
+		{
+			ID:    "connections",
+			Title: "网络连接",
+			Tables: []reportTable{
+				b.table("监听端口", []string{"协议", "本地地址", "PID", "进程"}, `
+				SELECT c.proto, c.local_addr, c.pid, COALESCE(p.name, '')
+				FROM network_connection c
+				LEFT JOIN process_info p ON p.session_id = c.session_id AND p.pid = c.pid
+				WHERE c.session_id = ? AND c.status = 'LISTEN'
+				GROUP BY c.id ORDER BY c.local_addr`),
+				b.table("已建立连接", []string{"协议", "本地地址", "远程地址", "PID", "进程"}, `
+				SELECT c.proto, c.local_addr, c.remote_addr, c.pid, COALESCE(p.name, '')
+				FROM network_connection c
+				LEFT JOIN process_info p ON p.session_id = c.session_id AND p.pid = c.pid
+				WHERE c.session_id = ? AND c.status = 'ESTABLISHED'
+				GROUP BY c.id ORDER BY c.remote_addr`),
+			},
+		},
+		{
+			ID:    "startup",
+			Title: "开机启动项",
+			Tables: []reportTable{
+				b.table("启动项", []string{"名称", "路径", "类型", "启用", "修改时间", "描述"}, `
+				SELECT name, path, type, CASE WHEN enabled THEN '是' ELSE '否' END, last_mod_time, description
+				FROM startup_item WHERE session_id = ? ORDER BY last_mod_time DESC`),
+			},
+		},
+		{
+			ID:    "cron",
+			Title: "任务计划",
+			Tables: []reportTable{
+				b.table("任务计划", []string{"内容"}, `SELECT line FROM cron_task WHERE session_id = ? ORDER BY id`),
+			},
+		},
+		{
+			ID:    "logins",
+			Title: "登录记录",
+			Tables: []reportTable{
+				b.table("登录成功", []string{"时间", "事件ID", "类型", "来源", "用户名", "IP", "端口", "认证方式"}, `
+				SELECT time, event_id, event_type, source, username, ip_address, CASE WHEN port > 0 THEN port ELSE '' END, method
+				FROM login_success WHERE session_id = ? ORDER BY time DESC`),
+				b.table("登录失败", []string{"时间", "事件ID", "类型", "来源", "用户名", "IP", "端口", "认证方式", "原因"}, `
+				SELECT time, event_id, event_type, source, username, ip_address, CASE WHEN port > 0 THEN port ELSE '' END, method, reason
+				FROM login_failed WHERE session_id = ? ORDER BY time DESC`),
+				b.table("sudo 命令", []string{"时间", "用户", "目标用户", "终端", "目录", "命令", "结果"}, `
+				SELECT time, user, run_as, tty, pwd, command, CASE WHEN allowed THEN '执行' ELSE '拒绝: ' || reason END
+				FROM sudo_command WHERE session_id = ? ORDER BY time DESC`),
+				b.table("登录会话(wtmp)", []string{"用户", "终端", "来源", "登录时间", "登出时间", "时长(秒)", "状态"}, `
+				SELECT user, tty, host, login_time, COALESCE(logout_time, ''), duration, status
+				FROM login_session WHERE session_id = ? ORDER BY login_time DESC`),
+				b.table("登录失败(btmp)", []string{"时间", "用户", "终端", "来源"}, `
+				SELECT time, user, tty, host FROM btmp_record WHERE session_id = ? ORDER BY time DESC`),
+				b.table("最后登录(lastlog)", []string{"UID", "用户", "时间", "终端", "来源"}, `
+				SELECT CASE WHEN uid >= 0 THEN uid ELSE '' END, user, time, tty, host
+				FROM lastlog_entry WHERE session_id = ? ORDER BY time DESC`),
+				b.table("登录日志篡改迹象", []string{"文件", "偏移", "类型", "说明"}, `
+				SELECT log_file, CASE WHEN offset >= 0 THEN offset ELSE '' END, kind, detail
+				FROM login_log_anomaly WHERE session_id = ? ORDER BY log_file, offset`),
+			},
+		},
+		{
+			ID:    "audit",
+			Title: "审计日志",
+			Note:  "命令执行记录由 auditd 的 EXECVE 记录还原，删除 .bash_history 不会影响这些记录；登录用户(auid)在 su/sudo 之后保持不变",
+			Tables: []reportTable{
+				b.table("命令执行(auditd)", []string{"时间", "登录用户", "执行身份", "终端", "目录", "命令", "结果"}, `
+				SELECT time, user, run_as, tty, cwd, command, success
+				FROM audit_command WHERE session_id = ? ORDER BY time DESC`),
+				b.table("登录、认证与账户变更", []string{"时间", "类型", "用户", "登录用户", "程序", "终端", "地址", "结果", "说明"}, `
+				SELECT time, type, user, operator, exe, terminal, addr, result, detail
+				FROM audit_event WHERE session_id = ? AND category != '文件监控' ORDER BY time DESC`),
+				b.table("监控文件访问", []string{"时间", "规则", "系统调用", "文件", "用户", "登录用户", "程序", "结果"}, `
+				SELECT time, key, syscall, path, user, operator, exe, result
+				FROM audit_event WHERE session_id = ? AND category = '文件监控' ORDER BY time DESC`),
+			},
+		},
+		{
+			ID:    "weblog",
+			Title: "Web访问日志",
+			Note:  "同一来源对同一路径命中同一规则的请求合并为一条；仅POST访问、先404后200的脚本可能是上传的 webshell",
+			Tables: []reportTable{
+				b.table("可疑请求", []string{"级别", "分类", "规则", "来源", "路径", "请求", "命中内容", "次数", "2xx次数", "首次", "末次"}, `
+				SELECT level, category, rule, ip, path, sample, match, count, ok_count, first_time, last_time
+				FROM web_log_finding WHERE session_id = ? ORDER BY `+sigmaLevelOrder+`, count DESC`),
+				b.table("高频来源", []string{"IP", "请求数", "错误数", "POST数", "路径数", "首次", "末次", "User-Agent"}, `
+				SELECT ip, requests, errors, posts, paths, first_time, last_time, user_agent
+				FROM web_log_talker WHERE session_id = ? ORDER BY requests DESC`),
+				b.table("访问日志文件", []string{"文件", "格式", "请求数", "无法解析", "起始时间", "结束时间"}, `
+				SELECT path, format, requests, skipped, COALESCE(first_time, ''), COALESCE(last_time, '')
+				FROM web_log_file WHERE session_id = ? ORDER BY first_time`),
+			},
+		},
+		{
+			ID:    "webshell",
+			Title: "Webshell扫描",
+			Note:  "按静态特征为 Web 根目录中的脚本打分，80 分及以上为高危；比同目录文件新一周以上的脚本可能是之后上传的",
+			Tables: []reportTable{
+				b.table("可疑脚本", []string{"级别", "得分", "家族", "文件", "命中特征", "大小", "MD5", "修改时间", "元数据变更时间"}, `
+				SELECT level, score, family, path, indicators, size, md5, COALESCE(mod_time, ''), COALESCE(change_time, '')
+				FROM webshell_file WHERE session_id = ? ORDER BY score DESC, path`),
+			},
+		},
+		{
+			ID:    "evtx",
+			Title: "日志重点事件",
+			Note:  "登录失败 5 次及以上的来源视为疑似暴力破解，Sigma 规则命中、PowerShell 脚本块、账户、服务、计划任务、日志清除与 Sysmon 注入类记录来自导入的EVTX文件",
+			Tables: []reportTable{
+				b.table("Sigma 规则命中", []string{"级别", "规则", "命中次数", "涉及主机", "首次(UTC)", "最近(UTC)", "标签"}, `
+				SELECT level, MAX(title), COUNT(*), COUNT(DISTINCT computer), `+utcTime("MIN(time)")+`, `+utcTime("MAX(time)")+`, MAX(tags)
+				FROM sigma_hit WHERE session_id = ? GROUP BY rule_id, level ORDER BY `+sigmaLevelOrder+`, COUNT(*) DESC`),
+				b.table("可疑 PowerShell 脚本块", []string{"首次(UTC)", "计算机", "分值", "可疑特征", "编码方式", "脚本路径", "脚本开头"}, `
+				SELECT `+utcTime("first_time")+`, computer, score, indicators, obfuscation, path, substr(script, 1, 200)
+				FROM ps_script_block WHERE session_id = ? AND score > 0 ORDER BY score DESC, first_time`),
+				b.table("日志清除", []string{"时间(UTC)", "计算机", "日志", "操作账户", "备份路径"}, `
+				SELECT `+utcTime("time")+`, computer, log, subject, backup_path
+				FROM win_log_clear WHERE session_id = ? ORDER BY time`),
+				b.table("账户变更", []string{"时间(UTC)", "计算机", "操作", "账户", "安全组", "操作账户"}, `
+				SELECT `+utcTime("time")+`, computer, action, CASE WHEN account != '' THEN account ELSE account_sid END, group_name, subject
+				FROM win_account_change WHERE session_id = ? ORDER BY time`),
+				b.table("服务安装", []string{"时间(UTC)", "计算机", "服务名", "映像路径", "启动类型", "运行账户"}, `
+				SELECT `+utcTime("time")+`, computer, service_name, image_path, start_type, account
+				FROM win_service_install WHERE session_id = ? ORDER BY time`),
+				b.table("计划任务", []string{"时间(UTC)", "计算机", "操作", "任务", "命令", "参数", "操作账户"}, `
+				SELECT `+utcTime("time")+`, computer, action, task_name, command, arguments, subject
+				FROM win_scheduled_task WHERE session_id = ? ORDER BY time`),
+				b.table("Sysmon 远程线程与 LSASS 访问", []string{"时间(UTC)", "计算机", "类型", "源进程", "目标进程", "访问权限/起始地址"}, `
+				SELECT `+utcTime("time")+`, computer, '远程线程', source_image, target_image, start_address
+				FROM sysmon_remote_thread WHERE session_id = ?
+				UNION ALL
+				SELECT `+utcTime("time")+`, computer, '进程访问', source_image, target_image, granted_access
+				FROM sysmon_process_access WHERE session_id = ? AND lower(target_image) LIKE '%\lsass.exe'
+				ORDER BY 1`, b.sessionID),
+				b.table("疑似暴力破解", []string{"来源IP", "失败次数", "涉及用户", "首次", "最近"}, `
+				SELECT ip_address, COUNT(*), GROUP_CONCAT(DISTINCT username), MIN(time), MAX(time)
+				FROM login_failed WHERE session_id = ? AND ip_address != ''
+				GROUP BY ip_address HAVING COUNT(*) >= 5 ORDER BY COUNT(*) DESC`),
+				b.table("RDP 登录", []string{"时间", "用户名", "IP", "状态", "描述"}, `
+				SELECT time, username, ip, status, description
+				FROM rdp_login WHERE session_id = ? ORDER BY time DESC`),
+			},
+		},
+	}
+	if b.err != nil {
+		return reportData{}, b.err
+	}
+
+	for i := range sections {
+		switch sections[i].ID {
+		case "processes", "webshell", "evtx":
+			sections[i].Alert = sections[i].Count() > 0
+		}
+	}
+
+	return reportData{
+		Session:     session,
+		GeneratedAt: time.Now().Format(sessionTimeLayout),
+		DBPath:      a.databasePath(),
+		DBHash:      dbHash,
+		Sections:    sections,
+	}, nil
+}
+
+// GenerateReport 生成会话的离线 HTML 报告，返回报告路径
+// output 为空时保存在数据库所在目录
+func (a *App) GenerateReport(sessionID, output string) (string, error) {
+	data, err := a.buildReport(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		output = filepath.Join(filepath.Dir(a.databasePath()), defaultReportName(data.Session))
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("生成报告失败: %v", err)
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("写入报告失败: %v", err)
+	}
+	return output, nil
+}
+
+// ExportReport 弹窗选择保存位置并生成报告
+func (a *App) ExportReport(sessionID string) (string, error) {
+	session, err := a.getScanSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+	output, err := wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
+		Title:            "保存报告",
+		DefaultDirectory: filepath.Dir(a.databasePath()),
+		DefaultFilename:  defaultReportName(session),
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: "HTML 报告", Pattern: "*.html"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		return "", fmt.Errorf("未选择保存位置")
+	}
+	return a.GenerateReport(sessionID, output)
+}
+
+func defaultReportName(session ScanSession) string {
+	return fmt.Sprintf("ctscan-report-%s-%s.html", shortID(session.ID), time.Now().Format("20060102-150405"))
+}
+
+// fileSHA256 计算文件的 SHA-256
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
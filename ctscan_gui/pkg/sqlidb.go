@@ -3,9 +3,11 @@ package pkg
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // 导入 SQLite 驱动程序
@@ -38,255 +40,86 @@ func getDesktopPath() (string, error) {
 	}
 }
 
-func InitSqliteDB() (*sql.DB, error) {
-	desktopPath, err := getDesktopPath()
-	if err != nil {
-		return nil, err
-	}
-
-	dbPath := filepath.Join(desktopPath, "ctscan.db")
-
-	// 检查数据库文件是否存在
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		// 创建数据库文件
-		file, err := os.Create(dbPath)
-		if err != nil {
-			return nil, fmt.Errorf("创建数据库文件失败: %v", err)
+// InitSqliteDB 打开数据库并升级到最新结构
+// 只读模式下数据库必须已存在且版本与程序一致
+func InitSqliteDB(dbPath string, readOnly bool) (*sql.DB, error) {
+	dsn := sqliteDSN(dbPath, readOnly)
+	if !readOnly {
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+			return nil, fmt.Errorf("创建数据库目录失败: %v", err)
+		}
+		// 检查数据库文件是否存在
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			// 创建数据库文件
+			file, err := os.Create(dbPath)
+			if err != nil {
+				return nil, fmt.Errorf("创建数据库文件失败: %v", err)
+			}
+			file.Close()
 		}
-		file.Close()
 	}
 
 	// 打开数据库连接
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("打开数据库连接失败: %v", err)
 	}
 
 	// 测试数据库连接
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("数据库连接测试失败: %v", err)
 	}
 
+	if readOnly {
+		if err := checkSchemaVersion(db, dbPath); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	// 升级数据库结构
+	if err := migrateDatabase(db, dbPath); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// 创建表
 	if err := createTables(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("创建表失败: %v", err)
 	}
 
 	return db, nil
 }
 
+// sqliteDSN 打开 SQLite 文件的 URI
+// 案例名常出现在路径中，其中的 ?、#、% 需要转义，否则驱动会把 ? 之后的部分当作参数，打开错误的文件
+func sqliteDSN(path string, readOnly bool) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	p := filepath.ToSlash(path)
+	// Windows 路径 C:/cases 写作 file:///C:/cases
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "file", Path: p}
+	if readOnly {
+		u.RawQuery = "mode=ro"
+	}
+	return u.String()
+}
+
+// createTables 创建采集器声明的数据表
+// 内置表由 migrations 创建和升级，这里只会为新增的采集器补建表
 func createTables(db *sql.DB) error {
-	// 创建用户信息表
-	createUserInfoTable := `
-	CREATE TABLE IF NOT EXISTS user_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		username TEXT,
-		uid TEXT,
-		gid TEXT,
-		home_dir TEXT,
-		name TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建系统信息表
-	createSystemInfoTable := `
-	CREATE TABLE IF NOT EXISTS system_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		hostname TEXT,
-		os TEXT,
-		arch TEXT,
-		cpu_cores INTEGER,
-		kernel_version TEXT,
-		cpu_usage REAL,
-		total_memory INTEGER,
-		memory_usage REAL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建磁盘信息表
-	createDiskInfoTable := `
-	CREATE TABLE IF NOT EXISTS disk_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		system_info_id INTEGER,
-		mount_point TEXT,
-		total_size INTEGER,
-		used_size INTEGER,
-		free_size INTEGER,
-		usage REAL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (system_info_id) REFERENCES system_info(id)
-	);`
-
-	// 创建定时任务表
-	createCronTaskTable := `
-	CREATE TABLE IF NOT EXISTS cron_task (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		line TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建敏感文件监控表
-	createFileMonitorTable := `
-	CREATE TABLE IF NOT EXISTS file_monitor (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		path TEXT,
-		file_exists BOOLEAN,
-		size INTEGER,
-		mode TEXT,
-		mod_time DATETIME,
-		create_time DATETIME,
-		access_time DATETIME,
-		change_time DATETIME,
-		is_dir BOOLEAN,
-		is_symlink BOOLEAN,
-		owner TEXT,
-		group_name TEXT,
-		permissions TEXT,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建登录失败记录表
-	createLoginFailedTable := `
-	CREATE TABLE IF NOT EXISTS login_failed (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		time DATETIME,
-		event_id TEXT,
-		event_type TEXT,
-		source TEXT,
-		username TEXT,
-		ip_address TEXT,
-		reason TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建登录成功记录表
-	createLoginSuccessTable := `
-	CREATE TABLE IF NOT EXISTS login_success (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		time DATETIME,
-		event_id TEXT,
-		event_type TEXT,
-		source TEXT,
-		username TEXT,
-		ip_address TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建网络信息表
-	createNetworkInfoTable := `
-	CREATE TABLE IF NOT EXISTS network_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		hostname TEXT,
-		gateway TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建网络接口表
-	createNetworkInterfaceTable := `
-	CREATE TABLE IF NOT EXISTS network_interface (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		network_info_id INTEGER,
-		name TEXT,
-		ip TEXT,
-		mac TEXT,
-		bytes_sent INTEGER,
-		bytes_recv INTEGER,
-		packets_sent INTEGER,
-		packets_recv INTEGER,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (network_info_id) REFERENCES network_info(id)
-	);`
-
-	// 创建网络连接表
-	createNetworkConnectionTable := `
-	CREATE TABLE IF NOT EXISTS network_connection (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		proto TEXT,
-		local_addr TEXT,
-		remote_addr TEXT,
-		status TEXT,
-		pid INTEGER,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建进程信息表
-	createProcessInfoTable := `
-	CREATE TABLE IF NOT EXISTS process_info (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		pid INTEGER,
-		name TEXT,
-		ppid INTEGER,
-		parent_name TEXT,
-		create_time INTEGER,
-		exe TEXT,
-		file_ctime INTEGER,
-		file_mtime INTEGER,
-		md5 TEXT,
-		signature TEXT,
-		cpu_percent REAL,
-		mem_percent REAL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建RDP登录表
-	createRDPLoginTable := `
-	CREATE TABLE IF NOT EXISTS rdp_login (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		time DATETIME,
-		username TEXT,
-		ip TEXT,
-		status TEXT,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建Shell历史记录表
-	createShellHistoryTable := `
-	CREATE TABLE IF NOT EXISTS shell_history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		time DATETIME,
-		command TEXT,
-		user TEXT,
-		shell TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 创建启动项表
-	createStartupItemTable := `
-	CREATE TABLE IF NOT EXISTS startup_item (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT,
-		path TEXT,
-		type TEXT,
-		enabled BOOLEAN,
-		last_mod_time DATETIME,
-		size INTEGER,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// 执行创建表的SQL语句
-	tables := []string{
-		createUserInfoTable,
-		createSystemInfoTable,
-		createDiskInfoTable,
-		createCronTaskTable,
-		createFileMonitorTable,
-		createLoginFailedTable,
-		createLoginSuccessTable,
-		createNetworkInfoTable,
-		createNetworkInterfaceTable,
-		createNetworkConnectionTable,
-		createProcessInfoTable,
-		createRDPLoginTable,
-		createShellHistoryTable,
-		createStartupItemTable,
-	}
-
-	for _, table := range tables {
-		if _, err := db.Exec(table); err != nil {
-			return fmt.Errorf("创建表失败: %v", err)
+	for _, c := range Collectors() {
+		for _, table := range c.Schema() {
+			if _, err := db.Exec(table); err != nil {
+				return fmt.Errorf("创建表失败: %v", err)
+			}
 		}
 	}
 
@@ -295,11 +128,17 @@ func createTables(db *sql.DB) error {
 
 // SaveUserInfo 保存用户信息到数据库
 func (a *App) SaveUserInfo(userInfo UserInfo) error {
+	sessionID, err := a.sessionFor("users")
+	if err != nil {
+		return err
+	}
+
 	query := `
-	INSERT INTO user_info (username, uid, gid, home_dir, name)
-	VALUES (?, ?, ?, ?, ?)`
+	INSERT INTO user_info (session_id, username, uid, gid, home_dir, name)
+	VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := a.db.Exec(query,
+	_, err = a.database().Exec(query,
+		sessionID,
 		userInfo.Username,
 		userInfo.Uid,
 		userInfo.Gid,
@@ -311,8 +150,13 @@ func (a *App) SaveUserInfo(userInfo UserInfo) error {
 
 // SaveSystemInfo 保存系统信息到数据库
 func (a *App) SaveSystemInfo(sysInfo SystemInfo) error {
+	sessionID, err := a.sessionFor("sysinfo")
+	if err != nil {
+		return err
+	}
+
 	// 开始事务
-	tx, err := a.db.Begin()
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -321,11 +165,12 @@ func (a *App) SaveSystemInfo(sysInfo SystemInfo) error {
 	// 插入系统信息
 	systemQuery := `
 	INSERT INTO system_info (
-		hostname, os, arch, cpu_cores, kernel_version,
+		session_id, hostname, os, arch, cpu_cores, kernel_version,
 		cpu_usage, total_memory, memory_usage
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	result, err := tx.Exec(systemQuery,
+		sessionID,
 		sysInfo.Hostname,
 		sysInfo.OS,
 		sysInfo.Arch,
@@ -348,12 +193,13 @@ func (a *App) SaveSystemInfo(sysInfo SystemInfo) error {
 	// 插入磁盘信息
 	diskQuery := `
 	INSERT INTO disk_info (
-		system_info_id, mount_point, total_size,
+		session_id, system_info_id, mount_point, total_size,
 		used_size, free_size, usage
-	) VALUES (?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
 	for _, disk := range sysInfo.Disks {
 		_, err = tx.Exec(diskQuery,
+			sessionID,
 			systemInfoID,
 			disk.MountPoint,
 			disk.TotalSize,
@@ -372,15 +218,20 @@ func (a *App) SaveSystemInfo(sysInfo SystemInfo) error {
 
 // SaveCronTasks 保存定时任务到数据库
 func (a *App) SaveCronTasks(tasks []CronTask) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("cron")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	query := `INSERT INTO cron_task (line) VALUES (?)`
+	query := `INSERT INTO cron_task (session_id, line) VALUES (?, ?)`
 	for _, task := range tasks {
-		_, err = tx.Exec(query, task.Line)
+		_, err = tx.Exec(query, sessionID, task.Line)
 		if err != nil {
 			return err
 		}
@@ -391,7 +242,12 @@ func (a *App) SaveCronTasks(tasks []CronTask) error {
 
 // SaveFileMonitor 保存文件监控信息到数据库
 func (a *App) SaveFileMonitor(files []FileInfo) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("files")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -399,13 +255,14 @@ func (a *App) SaveFileMonitor(files []FileInfo) error {
 
 	query := `
 	INSERT INTO file_monitor (
-		path, file_exists, size, mode, mod_time,
+		session_id, path, file_exists, size, mode, mod_time,
 		create_time, access_time, change_time, is_dir,
 		is_symlink, owner, group_name, permissions, description
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, file := range files {
 		_, err = tx.Exec(query,
+			sessionID,
 			file.Path,
 			file.Exists,
 			file.Size,
@@ -431,7 +288,12 @@ func (a *App) SaveFileMonitor(files []FileInfo) error {
 
 // SaveLoginFailed 保存登录失败记录到数据库
 func (a *App) SaveLoginFailed(records []LoginFailed) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("login-failed")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -439,25 +301,23 @@ func (a *App) SaveLoginFailed(records []LoginFailed) error {
 
 	query := `
 	INSERT INTO login_failed (
-		time, event_id, event_type, source,
-		username, ip_address, reason
-	) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		session_id, time, event_id, event_type, source,
+		username, ip_address, reason, port, method, log_file
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, record := range records {
-		// 解析时间字符串
-		timeValue, err := time.Parse("2006-01-02 15:04:05", record.Time)
-		if err != nil {
-			timeValue = time.Now() // 如果解析失败，使用当前时间
-		}
-
-		_, err = tx.Exec(query,
-			timeValue,
+		_, err := tx.Exec(query,
+			sessionID,
+			nullableEventTime(record.Time, time.Local),
 			record.EventID,
 			record.EventType,
 			record.Source,
 			record.Username,
 			record.IPAddress,
 			record.Reason,
+			record.Port,
+			record.Method,
+			record.LogFile,
 		)
 		if err != nil {
 			return err
@@ -469,7 +329,12 @@ func (a *App) SaveLoginFailed(records []LoginFailed) error {
 
 // SaveLoginSuccess 保存登录成功记录到数据库
 func (a *App) SaveLoginSuccess(records []LoginSuccess) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("login-success")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -477,23 +342,63 @@ func (a *App) SaveLoginSuccess(records []LoginSuccess) error {
 
 	query := `
 	INSERT INTO login_success (
-		time, event_id, event_type, source,
-		username, ip_address
-	) VALUES (?, ?, ?, ?, ?, ?)`
+		session_id, time, event_id, event_type, source,
+		username, ip_address, port, method, log_file
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, record := range records {
-		timeValue, err := time.Parse("2006-01-02 15:04:05", record.Time)
-		if err != nil {
-			timeValue = time.Now()
-		}
-
-		_, err = tx.Exec(query,
-			timeValue,
+		_, err := tx.Exec(query,
+			sessionID,
+			nullableEventTime(record.Time, time.Local),
 			record.EventID,
 			record.EventType,
 			record.Source,
 			record.Username,
 			record.IPAddress,
+			record.Port,
+			record.Method,
+			record.LogFile,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveSudoCommands 保存 sudo 命令记录到数据库
+func (a *App) SaveSudoCommands(records []SudoCommand) error {
+	sessionID, err := a.sessionFor("sudo")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO sudo_command (
+		session_id, time, user, run_as, tty, pwd,
+		command, allowed, reason, host, log_file
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for _, record := range records {
+		_, err := tx.Exec(query,
+			sessionID,
+			nullableTime(record.Time, time.Local),
+			record.User,
+			record.RunAs,
+			record.TTY,
+			record.PWD,
+			record.Command,
+			record.Allowed,
+			record.Reason,
+			record.Host,
+			record.LogFile,
 		)
 		if err != nil {
 			return err
@@ -503,9 +408,143 @@ func (a *App) SaveLoginSuccess(records []LoginSuccess) error {
 	return tx.Commit()
 }
 
+// SaveLoginLogs 保存二进制登录日志的解析结果
+func (a *App) SaveLoginLogs(logs LoginLogs) error {
+	sessionID, err := a.sessionFor("login-sessions")
+	if err != nil {
+		return err
+	}
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range logs.Sessions {
+		if _, err := tx.Exec(`INSERT INTO login_session (session_id, user, tty, host, ip, pid, login_time, logout_time, duration, status, log_file)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, s.User, s.TTY, s.Host, s.IP, s.PID, nullableTime(s.LoginTime, time.Local), nullableTime(s.LogoutTime, time.Local), s.Duration, s.Status, s.LogFile); err != nil {
+			return fmt.Errorf("保存登录会话失败: %v", err)
+		}
+	}
+	for _, r := range logs.Failed {
+		if _, err := tx.Exec(`INSERT INTO btmp_record (session_id, time, user, tty, host, ip, pid, log_file) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, nullableTime(r.Time, time.Local), r.User, r.TTY, r.Host, r.IP, r.PID, r.LogFile); err != nil {
+			return fmt.Errorf("保存 btmp 记录失败: %v", err)
+		}
+	}
+	for _, l := range logs.Lastlog {
+		if _, err := tx.Exec(`INSERT INTO lastlog_entry (session_id, uid, user, time, tty, host, log_file) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, l.UID, l.User, nullableTime(l.Time, time.Local), l.TTY, l.Host, l.LogFile); err != nil {
+			return fmt.Errorf("保存 lastlog 记录失败: %v", err)
+		}
+	}
+	for _, an := range logs.Anomalies {
+		if _, err := tx.Exec(`INSERT INTO login_log_anomaly (session_id, log_file, offset, kind, detail) VALUES (?, ?, ?, ?, ?)`,
+			sessionID, an.LogFile, an.Offset, an.Kind, an.Detail); err != nil {
+			return fmt.Errorf("保存篡改迹象失败: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveAuditLog 保存 auditd 日志的解析结果
+func (a *App) SaveAuditLog(l AuditLog) error {
+	sessionID, err := a.sessionFor("audit")
+	if err != nil {
+		return err
+	}
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range l.Commands {
+		if _, err := tx.Exec(`INSERT INTO audit_command (session_id, time, serial, user, auid, run_as, uid, tty, audit_session, pid, ppid, exe, cwd, command, key, success, host, log_file)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, nullableTime(c.Time, time.Local), c.Serial, c.User, c.AUID, c.RunAs, c.UID, c.TTY, c.Session, c.PID, c.PPID,
+			c.Exe, c.CWD, c.Command, c.Key, c.Success, c.Host, c.LogFile); err != nil {
+			return fmt.Errorf("保存审计命令记录失败: %v", err)
+		}
+	}
+	for _, e := range l.Events {
+		if _, err := tx.Exec(`INSERT INTO audit_event (session_id, time, serial, type, category, user, operator, exe, terminal, addr, result, key, syscall, path, detail, host, log_file)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, nullableTime(e.Time, time.Local), e.Serial, e.Type, e.Category, e.User, e.Operator, e.Exe, e.Terminal, e.Addr,
+			e.Result, e.Key, e.Syscall, e.Path, e.Detail, e.Host, e.LogFile); err != nil {
+			return fmt.Errorf("保存审计事件失败: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveWebLogAnalysis 保存 Web 访问日志的分析结果
+func (a *App) SaveWebLogAnalysis(result WebLogAnalysis) error {
+	sessionID, err := a.sessionFor("weblog")
+	if err != nil {
+		return err
+	}
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range result.Files {
+		if _, err := tx.Exec(`INSERT INTO web_log_file (session_id, path, format, requests, skipped, first_time, last_time) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, f.Path, f.Format, f.Requests, f.Skipped, nullableTime(f.FirstTime, time.Local), nullableTime(f.LastTime, time.Local)); err != nil {
+			return fmt.Errorf("保存访问日志文件失败: %v", err)
+		}
+	}
+	for _, f := range result.Findings {
+		if _, err := tx.Exec(`INSERT INTO web_log_finding (session_id, level, category, rule, ip, method, path, sample, match, status, ok_count, count, first_time, last_time, user_agent, log_file)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, f.Level, f.Category, f.Rule, f.IP, f.Method, f.Path, f.Sample, f.Match, f.Status, f.OKCount, f.Count,
+			nullableTime(f.FirstTime, time.Local), nullableTime(f.LastTime, time.Local), f.UserAgent, f.LogFile); err != nil {
+			return fmt.Errorf("保存可疑请求失败: %v", err)
+		}
+	}
+	for _, t := range result.Talkers {
+		if _, err := tx.Exec(`INSERT INTO web_log_talker (session_id, ip, requests, errors, posts, paths, first_time, last_time, user_agent) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, t.IP, t.Requests, t.Errors, t.Posts, t.Paths, nullableTime(t.FirstTime, time.Local), nullableTime(t.LastTime, time.Local), t.UserAgent); err != nil {
+			return fmt.Errorf("保存高频来源失败: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveWebshellScan 保存 Webshell 扫描结果
+func (a *App) SaveWebshellScan(result WebshellScan) error {
+	sessionID, err := a.sessionFor("webshell")
+	if err != nil {
+		return err
+	}
+	tx, err := a.database().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range result.Files {
+		if _, err := tx.Exec(`INSERT INTO webshell_file (session_id, path, root, score, level, family, indicators, size, md5, sha256, entropy, longest_line, mod_time, access_time, change_time, create_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, f.Path, f.Root, f.Score, f.Level, f.Family, strings.Join(f.Indicators, "、"), f.Size, f.MD5, f.SHA256, f.Entropy, f.LongestLine,
+			nullableTime(f.ModTime, time.Local), nullableTime(f.AccessTime, time.Local), nullableTime(f.ChangeTime, time.Local), nullableTime(f.CreateTime, time.Local)); err != nil {
+			return fmt.Errorf("保存Webshell扫描结果失败: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
 // SaveNetworkInfo 保存网络信息到数据库
 func (a *App) SaveNetworkInfo(info NetworkInfo) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("network")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -513,10 +552,10 @@ func (a *App) SaveNetworkInfo(info NetworkInfo) error {
 
 	// 插入网络基本信息
 	networkQuery := `
-	INSERT INTO network_info (hostname, gateway)
-	VALUES (?, ?)`
+	INSERT INTO network_info (session_id, hostname, gateway)
+	VALUES (?, ?, ?)`
 
-	result, err := tx.Exec(networkQuery, info.Hostname, info.Gateway)
+	result, err := tx.Exec(networkQuery, sessionID, info.Hostname, info.Gateway)
 	if err != nil {
 		return err
 	}
@@ -529,9 +568,9 @@ func (a *App) SaveNetworkInfo(info NetworkInfo) error {
 	// 插入网络接口信息
 	interfaceQuery := `
 	INSERT INTO network_interface (
-		network_info_id, name, ip, mac,
+		session_id, network_info_id, name, ip, mac,
 		bytes_sent, bytes_recv, packets_sent, packets_recv
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for i, iface := range info.Interfaces {
 		var ip, mac string
@@ -551,6 +590,7 @@ func (a *App) SaveNetworkInfo(info NetworkInfo) error {
 		}
 
 		_, err = tx.Exec(interfaceQuery,
+			sessionID,
 			networkInfoID,
 			iface,
 			ip,
@@ -570,7 +610,12 @@ func (a *App) SaveNetworkInfo(info NetworkInfo) error {
 
 // SaveNetworkConnections 保存网络连接到数据库
 func (a *App) SaveNetworkConnections(conns []NetworkConn) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("connections")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -578,11 +623,12 @@ func (a *App) SaveNetworkConnections(conns []NetworkConn) error {
 
 	query := `
 	INSERT INTO network_connection (
-		proto, local_addr, remote_addr, status, pid
-	) VALUES (?, ?, ?, ?, ?)`
+		session_id, proto, local_addr, remote_addr, status, pid
+	) VALUES (?, ?, ?, ?, ?, ?)`
 
 	for _, conn := range conns {
 		_, err = tx.Exec(query,
+			sessionID,
 			conn.Proto,
 			conn.LocalAddr,
 			conn.RemoteAddr,
@@ -599,7 +645,12 @@ func (a *App) SaveNetworkConnections(conns []NetworkConn) error {
 
 // SaveProcessInfo 保存进程信息到数据库
 func (a *App) SaveProcessInfo(procs []ProcInfo) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("processes")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -607,13 +658,14 @@ func (a *App) SaveProcessInfo(procs []ProcInfo) error {
 
 	query := `
 	INSERT INTO process_info (
-		pid, name, ppid, parent_name, create_time,
+		session_id, pid, name, ppid, parent_name, create_time,
 		exe, file_ctime, file_mtime, md5, signature,
 		cpu_percent, mem_percent
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, proc := range procs {
 		_, err = tx.Exec(query,
+			sessionID,
 			proc.PID,
 			proc.Name,
 			proc.PPID,
@@ -637,7 +689,12 @@ func (a *App) SaveProcessInfo(procs []ProcInfo) error {
 
 // SaveRDPLogin 保存RDP登录信息到数据库
 func (a *App) SaveRDPLogin(logs []RDPLoginInfo) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("rdp")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -645,17 +702,13 @@ func (a *App) SaveRDPLogin(logs []RDPLoginInfo) error {
 
 	query := `
 	INSERT INTO rdp_login (
-		time, username, ip, status, description
-	) VALUES (?, ?, ?, ?, ?)`
+		session_id, time, username, ip, status, description
+	) VALUES (?, ?, ?, ?, ?, ?)`
 
 	for _, log := range logs {
-		timeValue, err := time.Parse("2006-01-02 15:04:05", log.Time)
-		if err != nil {
-			timeValue = time.Now()
-		}
-
-		_, err = tx.Exec(query,
-			timeValue,
+		_, err := tx.Exec(query,
+			sessionID,
+			nullableTime(log.Time, time.Local),
 			log.Username,
 			log.IP,
 			log.Status,
@@ -671,7 +724,12 @@ func (a *App) SaveRDPLogin(logs []RDPLoginInfo) error {
 
 // SaveShellHistory 保存Shell历史记录到数据库
 func (a *App) SaveShellHistory(records []ShellHistory) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("shell")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -679,17 +737,13 @@ func (a *App) SaveShellHistory(records []ShellHistory) error {
 
 	query := `
 	INSERT INTO shell_history (
-		time, command, user, shell
-	) VALUES (?, ?, ?, ?)`
+		session_id, time, command, user, shell
+	) VALUES (?, ?, ?, ?, ?)`
 
 	for _, record := range records {
-		timeValue, err := time.Parse("2006-01-02 15:04:05", record.Time)
-		if err != nil {
-			timeValue = time.Now()
-		}
-
-		_, err = tx.Exec(query,
-			timeValue,
+		_, err := tx.Exec(query,
+			sessionID,
+			nullableTime(record.Time, time.Local),
 			record.Command,
 			record.User,
 			record.Shell,
@@ -704,7 +758,12 @@ func (a *App) SaveShellHistory(records []ShellHistory) error {
 
 // SaveStartupItems 保存启动项到数据库
 func (a *App) SaveStartupItems(items []StartupItem) error {
-	tx, err := a.db.Begin()
+	sessionID, err := a.sessionFor("startup")
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.database().Begin()
 	if err != nil {
 		return err
 	}
@@ -712,12 +771,13 @@ func (a *App) SaveStartupItems(items []StartupItem) error {
 
 	query := `
 	INSERT INTO startup_item (
-		name, path, type, enabled, last_mod_time,
+		session_id, name, path, type, enabled, last_mod_time,
 		size, description
-	) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, item := range items {
 		_, err = tx.Exec(query,
+			sessionID,
 			item.Name,
 			item.Path,
 			item.Type,
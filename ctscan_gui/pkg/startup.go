@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"encoding/xml"
 	"io/ioutil"
 	"os"
@@ -204,3 +205,30 @@ func (a *App) getLinuxStartupItems() []StartupItem {
 
 	return items
 }
+
+// startupItemSchema 启动项表
+const startupItemSchema = `CREATE TABLE IF NOT EXISTS startup_item (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	name TEXT,
+	path TEXT,
+	type TEXT,
+	enabled BOOLEAN,
+	last_mod_time DATETIME,
+	size INTEGER,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[StartupItem]{
+		name:      "startup",
+		title:     "开机启动项",
+		platforms: []string{"darwin", "windows", "linux"},
+		schema:    []string{startupItemSchema},
+		collect: func(ctx context.Context, a *App) ([]StartupItem, error) {
+			return a.GetStartupItems(), nil
+		},
+		save: (*App).SaveStartupItems,
+	})
+}
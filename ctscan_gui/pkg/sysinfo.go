@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"os"
 	"runtime"
 
@@ -82,3 +83,51 @@ func (a *App) GetSystemInfo() SystemInfo {
 		Disks:         disks,
 	}
 }
+
+// systemInfoSchema 系统信息表
+const systemInfoSchema = `CREATE TABLE IF NOT EXISTS system_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	hostname TEXT,
+	os TEXT,
+	arch TEXT,
+	cpu_cores INTEGER,
+	kernel_version TEXT,
+	cpu_usage REAL,
+	total_memory INTEGER,
+	memory_usage REAL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// diskInfoSchema 磁盘信息表
+const diskInfoSchema = `CREATE TABLE IF NOT EXISTS disk_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	system_info_id INTEGER,
+	mount_point TEXT,
+	total_size INTEGER,
+	used_size INTEGER,
+	free_size INTEGER,
+	usage REAL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (system_info_id) REFERENCES system_info(id)
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[SystemInfo]{
+		name:   "sysinfo",
+		title:  "系统基本信息",
+		schema: []string{systemInfoSchema, diskInfoSchema},
+		collect: func(ctx context.Context, a *App) ([]SystemInfo, error) {
+			return []SystemInfo{a.GetSystemInfo()}, nil
+		},
+		save: func(a *App, infos []SystemInfo) error {
+			for _, info := range infos {
+				if err := a.SaveSystemInfo(info); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,538 @@
+package pkg
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// sysmonEventExtractors Sysmon 事件，进程相关的记录都保存进程GUID，用于关联同一进程的活动
+var sysmonEventExtractors = []winEventExtractor{
+	sysmonProcessTable,
+	sysmonNetworkTable,
+	sysmonImageLoadTable,
+	sysmonRemoteThreadTable,
+	sysmonProcessAccessTable,
+	sysmonFileCreateTable,
+	sysmonRegistryTable,
+	sysmonDNSTable,
+	sysmonFileDeleteTable,
+}
+
+// migrateSysmonEvent 新增 Sysmon 事件的结构化记录表
+func migrateSysmonEvent(tx *sql.Tx) error {
+	return execAll(tx, winEventSchemas(sysmonEventExtractors)...)
+}
+
+const sysmonProvider = "Microsoft-Windows-Sysmon"
+
+// isSysmon 判断是否为 Sysmon 产生的指定事件
+func isSysmon(e *EVTXEvent, ids ...int) bool {
+	if !strings.EqualFold(e.Provider, sysmonProvider) {
+		return false
+	}
+	for _, id := range ids {
+		if e.EventID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sysmonInt 解析 Sysmon 中十进制的进程ID与端口
+func sysmonInt(e *EVTXEvent, field string) int64 {
+	n, _ := strconv.ParseInt(winEventData(e, field), 10, 64)
+	return n
+}
+
+// sysmonBool 解析 Sysmon 中 true/false 形式的字段
+func sysmonBool(e *EVTXEvent, field string) bool {
+	return strings.EqualFold(winEventData(e, field), "true")
+}
+
+// SysmonProcess 进程创建(Sysmon 1)
+type SysmonProcess struct {
+	WinEvent
+	ProcessGUID       string `json:"process_guid"`
+	ProcessID         int64  `json:"process_id"`
+	Image             string `json:"image"`
+	CommandLine       string `json:"command_line"`
+	CurrentDirectory  string `json:"current_directory"`
+	OriginalFileName  string `json:"original_file_name"`
+	User              string `json:"user"`
+	LogonID           string `json:"logon_id"`
+	IntegrityLevel    string `json:"integrity_level"`
+	Hashes            string `json:"hashes"`
+	ParentProcessGUID string `json:"parent_process_guid"`
+	ParentProcessID   int64  `json:"parent_process_id"`
+	ParentImage       string `json:"parent_image"`
+	ParentCommandLine string `json:"parent_command_line"`
+}
+
+var sysmonProcessTable = &winEventTable[SysmonProcess, *SysmonProcess]{
+	name:  "sysmon_process",
+	title: "Sysmon进程创建",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "command_line TEXT",
+		"current_directory TEXT", "original_file_name TEXT", "user TEXT", "logon_id TEXT", "integrity_level TEXT",
+		"hashes TEXT", "parent_process_guid TEXT", "parent_process_id INTEGER", "parent_image TEXT", "parent_command_line TEXT"},
+	keyword: []string{"image", "command_line", "user", "hashes", "parent_image", "parent_command_line"},
+	process: []string{"process_guid", "parent_process_guid"},
+	extract: func(e *EVTXEvent) (SysmonProcess, bool) {
+		if !isSysmon(e, 1) {
+			return SysmonProcess{}, false
+		}
+		return SysmonProcess{
+			ProcessGUID:       winEventData(e, "ProcessGuid"),
+			ProcessID:         sysmonInt(e, "ProcessId"),
+			Image:             winEventData(e, "Image"),
+			CommandLine:       winEventData(e, "CommandLine"),
+			CurrentDirectory:  winEventData(e, "CurrentDirectory"),
+			OriginalFileName:  winEventData(e, "OriginalFileName"),
+			User:              winEventData(e, "User"),
+			LogonID:           winEventData(e, "LogonId"),
+			IntegrityLevel:    winEventData(e, "IntegrityLevel"),
+			Hashes:            winEventData(e, "Hashes"),
+			ParentProcessGUID: winEventData(e, "ParentProcessGuid"),
+			ParentProcessID:   sysmonInt(e, "ParentProcessId"),
+			ParentImage:       winEventData(e, "ParentImage"),
+			ParentCommandLine: winEventData(e, "ParentCommandLine"),
+		}, true
+	},
+	fields: func(r *SysmonProcess) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.CommandLine, &r.CurrentDirectory, &r.OriginalFileName,
+			&r.User, &r.LogonID, &r.IntegrityLevel, &r.Hashes, &r.ParentProcessGUID, &r.ParentProcessID,
+			&r.ParentImage, &r.ParentCommandLine}
+	},
+}
+
+// SysmonNetwork 网络连接(Sysmon 3)
+type SysmonNetwork struct {
+	WinEvent
+	ProcessGUID         string `json:"process_guid"`
+	ProcessID           int64  `json:"process_id"`
+	Image               string `json:"image"`
+	User                string `json:"user"`
+	Protocol            string `json:"protocol"`
+	Initiated           bool   `json:"initiated"` // 由本机发起的连接
+	SourceIP            string `json:"source_ip"`
+	SourcePort          int64  `json:"source_port"`
+	SourceHostname      string `json:"source_hostname"`
+	DestinationIP       string `json:"destination_ip"`
+	DestinationPort     int64  `json:"destination_port"`
+	DestinationHostname string `json:"destination_hostname"`
+}
+
+var sysmonNetworkTable = &winEventTable[SysmonNetwork, *SysmonNetwork]{
+	name:  "sysmon_network",
+	title: "Sysmon网络连接",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "user TEXT", "protocol TEXT",
+		"initiated INTEGER", "source_ip TEXT", "source_port INTEGER", "source_hostname TEXT",
+		"destination_ip TEXT", "destination_port INTEGER", "destination_hostname TEXT"},
+	keyword: []string{"image", "user", "source_ip", "source_hostname", "destination_ip", "destination_hostname"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonNetwork, bool) {
+		if !isSysmon(e, 3) {
+			return SysmonNetwork{}, false
+		}
+		return SysmonNetwork{
+			ProcessGUID:         winEventData(e, "ProcessGuid"),
+			ProcessID:           sysmonInt(e, "ProcessId"),
+			Image:               winEventData(e, "Image"),
+			User:                winEventData(e, "User"),
+			Protocol:            winEventData(e, "Protocol"),
+			Initiated:           sysmonBool(e, "Initiated"),
+			SourceIP:            winEventData(e, "SourceIp"),
+			SourcePort:          sysmonInt(e, "SourcePort"),
+			SourceHostname:      winEventData(e, "SourceHostname"),
+			DestinationIP:       winEventData(e, "DestinationIp"),
+			DestinationPort:     sysmonInt(e, "DestinationPort"),
+			DestinationHostname: winEventData(e, "DestinationHostname"),
+		}, true
+	},
+	fields: func(r *SysmonNetwork) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.User, &r.Protocol, &r.Initiated, &r.SourceIP,
+			&r.SourcePort, &r.SourceHostname, &r.DestinationIP, &r.DestinationPort, &r.DestinationHostname}
+	},
+}
+
+// SysmonImageLoad 模块加载(Sysmon 7)
+type SysmonImageLoad struct {
+	WinEvent
+	ProcessGUID     string `json:"process_guid"`
+	ProcessID       int64  `json:"process_id"`
+	Image           string `json:"image"`
+	ImageLoaded     string `json:"image_loaded"`
+	Signed          bool   `json:"signed"`
+	Signature       string `json:"signature"`
+	SignatureStatus string `json:"signature_status"`
+	Hashes          string `json:"hashes"`
+}
+
+var sysmonImageLoadTable = &winEventTable[SysmonImageLoad, *SysmonImageLoad]{
+	name:  "sysmon_image_load",
+	title: "Sysmon模块加载",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "image_loaded TEXT", "signed INTEGER",
+		"signature TEXT", "signature_status TEXT", "hashes TEXT"},
+	keyword: []string{"image", "image_loaded", "signature", "hashes"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonImageLoad, bool) {
+		if !isSysmon(e, 7) {
+			return SysmonImageLoad{}, false
+		}
+		return SysmonImageLoad{
+			ProcessGUID:     winEventData(e, "ProcessGuid"),
+			ProcessID:       sysmonInt(e, "ProcessId"),
+			Image:           winEventData(e, "Image"),
+			ImageLoaded:     winEventData(e, "ImageLoaded"),
+			Signed:          sysmonBool(e, "Signed"),
+			Signature:       winEventData(e, "Signature"),
+			SignatureStatus: winEventData(e, "SignatureStatus"),
+			Hashes:          winEventData(e, "Hashes"),
+		}, true
+	},
+	fields: func(r *SysmonImageLoad) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.ImageLoaded, &r.Signed, &r.Signature,
+			&r.SignatureStatus, &r.Hashes}
+	},
+}
+
+// SysmonRemoteThread 创建远程线程(Sysmon 8)，常见于进程注入
+type SysmonRemoteThread struct {
+	WinEvent
+	SourceProcessGUID string `json:"source_process_guid"`
+	SourceProcessID   int64  `json:"source_process_id"`
+	SourceImage       string `json:"source_image"`
+	TargetProcessGUID string `json:"target_process_guid"`
+	TargetProcessID   int64  `json:"target_process_id"`
+	TargetImage       string `json:"target_image"`
+	StartAddress      string `json:"start_address"`
+	StartModule       string `json:"start_module"`
+	StartFunction     string `json:"start_function"`
+}
+
+var sysmonRemoteThreadTable = &winEventTable[SysmonRemoteThread, *SysmonRemoteThread]{
+	name:  "sysmon_remote_thread",
+	title: "Sysmon远程线程",
+	columns: []string{"source_process_guid TEXT", "source_process_id INTEGER", "source_image TEXT",
+		"target_process_guid TEXT", "target_process_id INTEGER", "target_image TEXT", "start_address TEXT",
+		"start_module TEXT", "start_function TEXT"},
+	keyword: []string{"source_image", "target_image", "start_module", "start_function"},
+	process: []string{"source_process_guid", "target_process_guid"},
+	extract: func(e *EVTXEvent) (SysmonRemoteThread, bool) {
+		if !isSysmon(e, 8) {
+			return SysmonRemoteThread{}, false
+		}
+		return SysmonRemoteThread{
+			SourceProcessGUID: winEventData(e, "SourceProcessGuid"),
+			SourceProcessID:   sysmonInt(e, "SourceProcessId"),
+			SourceImage:       winEventData(e, "SourceImage"),
+			TargetProcessGUID: winEventData(e, "TargetProcessGuid"),
+			TargetProcessID:   sysmonInt(e, "TargetProcessId"),
+			TargetImage:       winEventData(e, "TargetImage"),
+			StartAddress:      winEventData(e, "StartAddress"),
+			StartModule:       winEventData(e, "StartModule"),
+			StartFunction:     winEventData(e, "StartFunction"),
+		}, true
+	},
+	fields: func(r *SysmonRemoteThread) []any {
+		return []any{&r.SourceProcessGUID, &r.SourceProcessID, &r.SourceImage, &r.TargetProcessGUID,
+			&r.TargetProcessID, &r.TargetImage, &r.StartAddress, &r.StartModule, &r.StartFunction}
+	},
+}
+
+// SysmonProcessAccess 打开其他进程(Sysmon 10)，如读取 lsass 内存
+type SysmonProcessAccess struct {
+	WinEvent
+	SourceProcessGUID string `json:"source_process_guid"`
+	SourceProcessID   int64  `json:"source_process_id"`
+	SourceImage       string `json:"source_image"`
+	TargetProcessGUID string `json:"target_process_guid"`
+	TargetProcessID   int64  `json:"target_process_id"`
+	TargetImage       string `json:"target_image"`
+	GrantedAccess     string `json:"granted_access"`
+	CallTrace         string `json:"call_trace"`
+}
+
+var sysmonProcessAccessTable = &winEventTable[SysmonProcessAccess, *SysmonProcessAccess]{
+	name:  "sysmon_process_access",
+	title: "Sysmon进程访问",
+	columns: []string{"source_process_guid TEXT", "source_process_id INTEGER", "source_image TEXT",
+		"target_process_guid TEXT", "target_process_id INTEGER", "target_image TEXT", "granted_access TEXT", "call_trace TEXT"},
+	keyword: []string{"source_image", "target_image", "granted_access", "call_trace"},
+	process: []string{"source_process_guid", "target_process_guid"},
+	extract: func(e *EVTXEvent) (SysmonProcessAccess, bool) {
+		if !isSysmon(e, 10) {
+			return SysmonProcessAccess{}, false
+		}
+		return SysmonProcessAccess{
+			SourceProcessGUID: winEventData(e, "SourceProcessGUID"),
+			SourceProcessID:   sysmonInt(e, "SourceProcessId"),
+			SourceImage:       winEventData(e, "SourceImage"),
+			TargetProcessGUID: winEventData(e, "TargetProcessGUID"),
+			TargetProcessID:   sysmonInt(e, "TargetProcessId"),
+			TargetImage:       winEventData(e, "TargetImage"),
+			GrantedAccess:     winEventData(e, "GrantedAccess"),
+			CallTrace:         winEventData(e, "CallTrace"),
+		}, true
+	},
+	fields: func(r *SysmonProcessAccess) []any {
+		return []any{&r.SourceProcessGUID, &r.SourceProcessID, &r.SourceImage, &r.TargetProcessGUID,
+			&r.TargetProcessID, &r.TargetImage, &r.GrantedAccess, &r.CallTrace}
+	},
+}
+
+// SysmonFileCreate 文件创建(Sysmon 11)
+type SysmonFileCreate struct {
+	WinEvent
+	ProcessGUID     string `json:"process_guid"`
+	ProcessID       int64  `json:"process_id"`
+	Image           string `json:"image"`
+	TargetFilename  string `json:"target_filename"`
+	CreationUtcTime string `json:"creation_utc_time"` // 文件自身记录的创建时间，与事件时间不一致时可能被篡改
+}
+
+var sysmonFileCreateTable = &winEventTable[SysmonFileCreate, *SysmonFileCreate]{
+	name:    "sysmon_file_create",
+	title:   "Sysmon文件创建",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "target_filename TEXT", "creation_utc_time TEXT"},
+	keyword: []string{"image", "target_filename"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonFileCreate, bool) {
+		if !isSysmon(e, 11) {
+			return SysmonFileCreate{}, false
+		}
+		return SysmonFileCreate{
+			ProcessGUID:     winEventData(e, "ProcessGuid"),
+			ProcessID:       sysmonInt(e, "ProcessId"),
+			Image:           winEventData(e, "Image"),
+			TargetFilename:  winEventData(e, "TargetFilename"),
+			CreationUtcTime: winEventData(e, "CreationUtcTime"),
+		}, true
+	},
+	fields: func(r *SysmonFileCreate) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.TargetFilename, &r.CreationUtcTime}
+	},
+}
+
+// SysmonRegistry 注册表项创建删除(12)、值设置(13)与重命名(14)
+type SysmonRegistry struct {
+	WinEvent
+	Action       string `json:"action"` // CreateKey/DeleteKey/SetValue/RenameKey 等
+	ProcessGUID  string `json:"process_guid"`
+	ProcessID    int64  `json:"process_id"`
+	Image        string `json:"image"`
+	TargetObject string `json:"target_object"`
+	Details      string `json:"details"`  // 设置的值
+	NewName      string `json:"new_name"` // 重命名后的名称
+}
+
+var sysmonRegistryTable = &winEventTable[SysmonRegistry, *SysmonRegistry]{
+	name:  "sysmon_registry",
+	title: "Sysmon注册表",
+	columns: []string{"action TEXT", "process_guid TEXT", "process_id INTEGER", "image TEXT", "target_object TEXT",
+		"details TEXT", "new_name TEXT"},
+	keyword: []string{"action", "image", "target_object", "details", "new_name"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonRegistry, bool) {
+		if !isSysmon(e, 12, 13, 14) {
+			return SysmonRegistry{}, false
+		}
+		return SysmonRegistry{
+			Action:       winEventData(e, "EventType"),
+			ProcessGUID:  winEventData(e, "ProcessGuid"),
+			ProcessID:    sysmonInt(e, "ProcessId"),
+			Image:        winEventData(e, "Image"),
+			TargetObject: winEventData(e, "TargetObject"),
+			Details:      winEventData(e, "Details"),
+			NewName:      winEventData(e, "NewName"),
+		}, true
+	},
+	fields: func(r *SysmonRegistry) []any {
+		return []any{&r.Action, &r.ProcessGUID, &r.ProcessID, &r.Image, &r.TargetObject, &r.Details, &r.NewName}
+	},
+}
+
+// SysmonDNS DNS 查询(Sysmon 22)
+type SysmonDNS struct {
+	WinEvent
+	ProcessGUID  string `json:"process_guid"`
+	ProcessID    int64  `json:"process_id"`
+	Image        string `json:"image"`
+	QueryName    string `json:"query_name"`
+	QueryStatus  string `json:"query_status"`
+	QueryResults string `json:"query_results"`
+}
+
+var sysmonDNSTable = &winEventTable[SysmonDNS, *SysmonDNS]{
+	name:  "sysmon_dns",
+	title: "Sysmon DNS查询",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "query_name TEXT", "query_status TEXT",
+		"query_results TEXT"},
+	keyword: []string{"image", "query_name", "query_results"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonDNS, bool) {
+		if !isSysmon(e, 22) {
+			return SysmonDNS{}, false
+		}
+		return SysmonDNS{
+			ProcessGUID:  winEventData(e, "ProcessGuid"),
+			ProcessID:    sysmonInt(e, "ProcessId"),
+			Image:        winEventData(e, "Image"),
+			QueryName:    winEventData(e, "QueryName"),
+			QueryStatus:  winEventData(e, "QueryStatus"),
+			QueryResults: winEventData(e, "QueryResults"),
+		}, true
+	},
+	fields: func(r *SysmonDNS) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.QueryName, &r.QueryStatus, &r.QueryResults}
+	},
+}
+
+// SysmonFileDelete 文件删除，23 会归档被删除的文件，26 只记录
+type SysmonFileDelete struct {
+	WinEvent
+	ProcessGUID    string `json:"process_guid"`
+	ProcessID      int64  `json:"process_id"`
+	Image          string `json:"image"`
+	User           string `json:"user"`
+	TargetFilename string `json:"target_filename"`
+	Hashes         string `json:"hashes"`
+	IsExecutable   bool   `json:"is_executable"`
+	Archived       bool   `json:"archived"`
+}
+
+var sysmonFileDeleteTable = &winEventTable[SysmonFileDelete, *SysmonFileDelete]{
+	name:  "sysmon_file_delete",
+	title: "Sysmon文件删除",
+	columns: []string{"process_guid TEXT", "process_id INTEGER", "image TEXT", "user TEXT", "target_filename TEXT",
+		"hashes TEXT", "is_executable INTEGER", "archived INTEGER"},
+	keyword: []string{"image", "user", "target_filename", "hashes"},
+	process: []string{"process_guid"},
+	extract: func(e *EVTXEvent) (SysmonFileDelete, bool) {
+		if !isSysmon(e, 23, 26) {
+			return SysmonFileDelete{}, false
+		}
+		return SysmonFileDelete{
+			ProcessGUID:    winEventData(e, "ProcessGuid"),
+			ProcessID:      sysmonInt(e, "ProcessId"),
+			Image:          winEventData(e, "Image"),
+			User:           winEventData(e, "User"),
+			TargetFilename: winEventData(e, "TargetFilename"),
+			Hashes:         winEventData(e, "Hashes"),
+			IsExecutable:   sysmonBool(e, "IsExecutable"),
+			Archived:       sysmonBool(e, "Archived"),
+		}, true
+	},
+	fields: func(r *SysmonFileDelete) []any {
+		return []any{&r.ProcessGUID, &r.ProcessID, &r.Image, &r.User, &r.TargetFilename, &r.Hashes,
+			&r.IsExecutable, &r.Archived}
+	},
+}
+
+// SysmonProcessPage 一页 Sysmon 进程创建记录
+type SysmonProcessPage struct {
+	WinEventPageInfo
+	Records []SysmonProcess `json:"records"`
+}
+
+// SysmonNetworkPage 一页 Sysmon 网络连接记录
+type SysmonNetworkPage struct {
+	WinEventPageInfo
+	Records []SysmonNetwork `json:"records"`
+}
+
+// SysmonImageLoadPage 一页 Sysmon 模块加载记录
+type SysmonImageLoadPage struct {
+	WinEventPageInfo
+	Records []SysmonImageLoad `json:"records"`
+}
+
+// SysmonRemoteThreadPage 一页 Sysmon 远程线程记录
+type SysmonRemoteThreadPage struct {
+	WinEventPageInfo
+	Records []SysmonRemoteThread `json:"records"`
+}
+
+// SysmonProcessAccessPage 一页 Sysmon 进程访问记录
+type SysmonProcessAccessPage struct {
+	WinEventPageInfo
+	Records []SysmonProcessAccess `json:"records"`
+}
+
+// SysmonFileCreatePage 一页 Sysmon 文件创建记录
+type SysmonFileCreatePage struct {
+	WinEventPageInfo
+	Records []SysmonFileCreate `json:"records"`
+}
+
+// SysmonRegistryPage 一页 Sysmon 注册表记录
+type SysmonRegistryPage struct {
+	WinEventPageInfo
+	Records []SysmonRegistry `json:"records"`
+}
+
+// SysmonDNSPage 一页 Sysmon DNS 查询记录
+type SysmonDNSPage struct {
+	WinEventPageInfo
+	Records []SysmonDNS `json:"records"`
+}
+
+// SysmonFileDeletePage 一页 Sysmon 文件删除记录
+type SysmonFileDeletePage struct {
+	WinEventPageInfo
+	Records []SysmonFileDelete `json:"records"`
+}
+
+// QuerySysmonProcesses 分页查询 Sysmon 进程创建记录
+func (a *App) QuerySysmonProcesses(q WinEventQuery) (SysmonProcessPage, error) {
+	records, info, err := sysmonProcessTable.query(a, q)
+	return SysmonProcessPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonNetwork 分页查询 Sysmon 网络连接记录
+func (a *App) QuerySysmonNetwork(q WinEventQuery) (SysmonNetworkPage, error) {
+	records, info, err := sysmonNetworkTable.query(a, q)
+	return SysmonNetworkPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonImageLoads 分页查询 Sysmon 模块加载记录
+func (a *App) QuerySysmonImageLoads(q WinEventQuery) (SysmonImageLoadPage, error) {
+	records, info, err := sysmonImageLoadTable.query(a, q)
+	return SysmonImageLoadPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonRemoteThreads 分页查询 Sysmon 远程线程记录
+func (a *App) QuerySysmonRemoteThreads(q WinEventQuery) (SysmonRemoteThreadPage, error) {
+	records, info, err := sysmonRemoteThreadTable.query(a, q)
+	return SysmonRemoteThreadPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonProcessAccess 分页查询 Sysmon 进程访问记录
+func (a *App) QuerySysmonProcessAccess(q WinEventQuery) (SysmonProcessAccessPage, error) {
+	records, info, err := sysmonProcessAccessTable.query(a, q)
+	return SysmonProcessAccessPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonFileCreates 分页查询 Sysmon 文件创建记录
+func (a *App) QuerySysmonFileCreates(q WinEventQuery) (SysmonFileCreatePage, error) {
+	records, info, err := sysmonFileCreateTable.query(a, q)
+	return SysmonFileCreatePage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonRegistry 分页查询 Sysmon 注册表记录
+func (a *App) QuerySysmonRegistry(q WinEventQuery) (SysmonRegistryPage, error) {
+	records, info, err := sysmonRegistryTable.query(a, q)
+	return SysmonRegistryPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonDNS 分页查询 Sysmon DNS 查询记录
+func (a *App) QuerySysmonDNS(q WinEventQuery) (SysmonDNSPage, error) {
+	records, info, err := sysmonDNSTable.query(a, q)
+	return SysmonDNSPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySysmonFileDeletes 分页查询 Sysmon 文件删除记录
+func (a *App) QuerySysmonFileDeletes(q WinEventQuery) (SysmonFileDeletePage, error) {
+	records, info, err := sysmonFileDeleteTable.query(a, q)
+	return SysmonFileDeletePage{WinEventPageInfo: info, Records: records}, err
+}
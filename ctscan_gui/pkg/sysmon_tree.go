@@ -0,0 +1,356 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sysmonTreeLimit 进程树最多包含的进程创建记录数
+const sysmonTreeLimit = 10000
+
+// sysmonAncestorDepth 向上查找时间范围外父进程的最大层数
+const sysmonAncestorDepth = 32
+
+// sysmonSummaryLimit 网络活动汇总最多返回的条数
+const sysmonSummaryLimit = 1000
+
+// SysmonProcessNode 历史进程树中的一个进程
+type SysmonProcessNode struct {
+	ProcessGUID       string `json:"process_guid"`
+	ProcessID         int64  `json:"process_id"`
+	Image             string `json:"image"`
+	CommandLine       string `json:"command_line"`
+	User              string `json:"user"`
+	IntegrityLevel    string `json:"integrity_level"`
+	Time              string `json:"time"` // UTC，推断的进程为空
+	Computer          string `json:"computer"`
+	ParentProcessGUID string `json:"parent_process_guid"`
+	Connections       int    `json:"connections"`  // 时间范围内的网络连接数
+	DNSQueries        int    `json:"dns_queries"`  // 时间范围内的 DNS 查询数
+	FileCreates       int    `json:"file_creates"` // 时间范围内创建的文件数
+	// OutOfRange 在时间范围之前创建，为显示完整的进程链而加入
+	OutOfRange bool `json:"out_of_range"`
+	// Inferred 没有该进程的创建记录，根据子进程中的父进程信息推断
+	Inferred bool                 `json:"inferred"`
+	Children []*SysmonProcessNode `json:"children,omitempty"`
+}
+
+// SysmonProcessTree 按时间范围还原的进程树
+type SysmonProcessTree struct {
+	SessionID string               `json:"session_id"`
+	Processes int                  `json:"processes"` // 时间范围内的进程创建记录数
+	Truncated bool                 `json:"truncated"` // 超过 sysmonTreeLimit 时只包含最早的部分
+	Roots     []*SysmonProcessNode `json:"roots"`
+}
+
+// scanSysmonNode 读取进程创建记录对应的节点，同时返回其中的父进程信息
+func scanSysmonNode(rows *sql.Rows) (*SysmonProcessNode, string, error) {
+	var (
+		n                   SysmonProcessNode
+		created             sql.NullTime
+		parentImage, parent string
+		parentID            int64
+	)
+	if err := rows.Scan(&n.ProcessGUID, &n.ProcessID, &n.Image, &n.CommandLine, &n.User, &n.IntegrityLevel,
+		&created, &n.Computer, &n.ParentProcessGUID, &parentID, &parentImage, &parent); err != nil {
+		return nil, "", fmt.Errorf("读取Sysmon进程创建记录失败: %v", err)
+	}
+	if created.Valid {
+		n.Time = created.Time.UTC().Format(sessionTimeLayout)
+	}
+	return &n, strings.Join([]string{fmt.Sprint(parentID), parentImage, parent}, "\x00"), nil
+}
+
+const sysmonNodeColumns = `process_guid, process_id, image, command_line, user, integrity_level, time, computer,
+	parent_process_guid, parent_process_id, parent_image, parent_command_line`
+
+// GetSysmonProcessTree 根据 Sysmon 进程创建记录还原时间范围内的进程树
+// 时间范围之前创建的父进程同样加入树中，没有创建记录的父进程根据子进程推断
+func (a *App) GetSysmonProcessTree(q WinEventQuery) (SysmonProcessTree, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return SysmonProcessTree{}, err
+	}
+	tree := SysmonProcessTree{SessionID: sessionID, Roots: []*SysmonProcessNode{}}
+	where, args, err := sysmonProcessTable.where(sessionID, q)
+	if err != nil {
+		return tree, err
+	}
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM sysmon_process WHERE `+where, args...).Scan(&tree.Processes); err != nil {
+		return tree, fmt.Errorf("查询Sysmon进程创建记录失败: %v", err)
+	}
+	tree.Truncated = tree.Processes > sysmonTreeLimit
+
+	rows, err := a.database().Query(`SELECT `+sysmonNodeColumns+` FROM sysmon_process WHERE `+where+`
+		ORDER BY time, source_file, record_id LIMIT ?`, append(args, sysmonTreeLimit)...)
+	if err != nil {
+		return tree, fmt.Errorf("查询Sysmon进程创建记录失败: %v", err)
+	}
+	nodes := map[string]*SysmonProcessNode{}
+	parents := map[string]string{} // 子进程记录中的父进程信息，用于推断
+	var order []*SysmonProcessNode
+	for rows.Next() {
+		n, parent, err := scanSysmonNode(rows)
+		if err != nil {
+			rows.Close()
+			return tree, err
+		}
+		// 同一进程只有一条创建记录，重复导入的不同文件中可能出现多次
+		if _, ok := nodes[n.ProcessGUID]; ok || n.ProcessGUID == "" {
+			continue
+		}
+		nodes[n.ProcessGUID] = n
+		parents[n.ProcessGUID] = parent
+		order = append(order, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return tree, fmt.Errorf("读取Sysmon进程创建记录失败: %v", err)
+	}
+
+	if err := a.sysmonAncestors(sessionID, nodes, parents, &order); err != nil {
+		return tree, err
+	}
+	if err := a.sysmonActivityCounts(sessionID, q, nodes); err != nil {
+		return tree, err
+	}
+
+	for _, n := range order {
+		if p, ok := nodes[n.ParentProcessGUID]; ok && p != n {
+			p.Children = append(p.Children, n)
+		} else {
+			tree.Roots = append(tree.Roots, n)
+		}
+	}
+	// 推断的进程没有时间，排在同级进程之前
+	sort.SliceStable(tree.Roots, func(i, j int) bool { return tree.Roots[i].Time < tree.Roots[j].Time })
+	return tree, nil
+}
+
+// sysmonAncestors 补全不在结果中的父进程，先查找时间范围外的创建记录，找不到时根据子进程推断
+func (a *App) sysmonAncestors(sessionID string, nodes map[string]*SysmonProcessNode, parents map[string]string, order *[]*SysmonProcessNode) error {
+	pending := *order
+	for depth := 0; depth < sysmonAncestorDepth && len(pending) > 0; depth++ {
+		var missing []*SysmonProcessNode
+		for _, n := range pending {
+			if guid := n.ParentProcessGUID; guid != "" && nodes[guid] == nil {
+				missing = append(missing, n)
+			}
+		}
+		pending = nil
+		for _, child := range missing {
+			guid := child.ParentProcessGUID
+			if nodes[guid] != nil {
+				continue
+			}
+			rows, err := a.database().Query(`SELECT `+sysmonNodeColumns+` FROM sysmon_process
+				WHERE session_id = ? AND process_guid = ? ORDER BY time LIMIT 1`, sessionID, guid)
+			if err != nil {
+				return fmt.Errorf("查询Sysmon父进程失败: %v", err)
+			}
+			var found *SysmonProcessNode
+			if rows.Next() {
+				n, parent, err := scanSysmonNode(rows)
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				n.OutOfRange = true
+				found = n
+				parents[guid] = parent
+			}
+			rows.Close()
+			if found == nil {
+				found = inferSysmonParent(child, parents[child.ProcessGUID])
+			} else {
+				pending = append(pending, found)
+			}
+			nodes[guid] = found
+			*order = append(*order, found)
+		}
+	}
+	return nil
+}
+
+// inferSysmonParent 根据子进程记录中的父进程ID、映像与命令行构造父进程
+func inferSysmonParent(child *SysmonProcessNode, parent string) *SysmonProcessNode {
+	n := &SysmonProcessNode{
+		ProcessGUID: child.ParentProcessGUID,
+		Computer:    child.Computer,
+		Inferred:    true,
+	}
+	if f := strings.SplitN(parent, "\x00", 3); len(f) == 3 {
+		fmt.Sscan(f[0], &n.ProcessID)
+		n.Image, n.CommandLine = f[1], f[2]
+	}
+	return n
+}
+
+// sysmonActivityCounts 统计时间范围内每个进程的网络连接、DNS 查询与文件创建次数
+func (a *App) sysmonActivityCounts(sessionID string, q WinEventQuery, nodes map[string]*SysmonProcessNode) error {
+	q.Keyword, q.ProcessGUID = "", ""
+	counters := []struct {
+		table interface {
+			tableName() string
+			tableTitle() string
+			where(string, WinEventQuery) (string, []any, error)
+		}
+		count func(n *SysmonProcessNode) *int
+	}{
+		{sysmonNetworkTable, func(n *SysmonProcessNode) *int { return &n.Connections }},
+		{sysmonDNSTable, func(n *SysmonProcessNode) *int { return &n.DNSQueries }},
+		{sysmonFileCreateTable, func(n *SysmonProcessNode) *int { return &n.FileCreates }},
+	}
+	for _, c := range counters {
+		where, args, err := c.table.where(sessionID, q)
+		if err != nil {
+			return err
+		}
+		rows, err := a.database().Query(`SELECT process_guid, COUNT(*) FROM `+c.table.tableName()+` WHERE `+where+`
+			GROUP BY process_guid`, args...)
+		if err != nil {
+			return fmt.Errorf("统计%s记录失败: %v", c.table.tableTitle(), err)
+		}
+		for rows.Next() {
+			var (
+				guid  string
+				count int
+			)
+			if err := rows.Scan(&guid, &count); err != nil {
+				rows.Close()
+				return fmt.Errorf("统计%s记录失败: %v", c.table.tableTitle(), err)
+			}
+			if n, ok := nodes[guid]; ok {
+				*c.count(n) = count
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("统计%s记录失败: %v", c.table.tableTitle(), err)
+		}
+	}
+	return nil
+}
+
+// SysmonConnectionSummary 相同进程映像、协议与目标的网络连接汇总
+type SysmonConnectionSummary struct {
+	Image               string `json:"image"`
+	Protocol            string `json:"protocol"`
+	Initiated           bool   `json:"initiated"`
+	DestinationIP       string `json:"destination_ip"`
+	DestinationPort     int64  `json:"destination_port"`
+	DestinationHostname string `json:"destination_hostname"`
+	Count               int    `json:"count"`
+	Processes           int    `json:"processes"` // 不同进程GUID的数量
+	Computers           int    `json:"computers"`
+	FirstSeen           string `json:"first_seen"`
+	LastSeen            string `json:"last_seen"`
+}
+
+// SysmonDNSSummary 相同域名的 DNS 查询汇总
+type SysmonDNSSummary struct {
+	QueryName string   `json:"query_name"`
+	Count     int      `json:"count"`
+	Images    []string `json:"images"`
+	Results   string   `json:"results"` // 最近一次查询的结果
+	FirstSeen string   `json:"first_seen"`
+	LastSeen  string   `json:"last_seen"`
+}
+
+// SysmonNetworkActivity 时间范围内的网络连接与 DNS 查询汇总
+type SysmonNetworkActivity struct {
+	SessionID   string                    `json:"session_id"`
+	Connections []SysmonConnectionSummary `json:"connections"`
+	DNS         []SysmonDNSSummary        `json:"dns"`
+}
+
+// GetSysmonNetworkActivity 汇总时间范围内的 Sysmon 网络连接与 DNS 查询，按次数排序
+func (a *App) GetSysmonNetworkActivity(q WinEventQuery) (SysmonNetworkActivity, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return SysmonNetworkActivity{}, err
+	}
+	activity := SysmonNetworkActivity{
+		SessionID:   sessionID,
+		Connections: []SysmonConnectionSummary{},
+		DNS:         []SysmonDNSSummary{},
+	}
+	if activity.Connections, err = a.sysmonConnectionSummary(sessionID, q); err != nil {
+		return activity, err
+	}
+	activity.DNS, err = a.sysmonDNSSummary(sessionID, q)
+	return activity, err
+}
+
+func (a *App) sysmonConnectionSummary(sessionID string, q WinEventQuery) ([]SysmonConnectionSummary, error) {
+	where, args, err := sysmonNetworkTable.where(sessionID, q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.database().Query(`SELECT image, protocol, initiated, destination_ip, destination_port,
+			MAX(destination_hostname), COUNT(*), COUNT(DISTINCT process_guid), COUNT(DISTINCT computer), MIN(time), MAX(time)
+		FROM sysmon_network WHERE `+where+`
+		GROUP BY image, protocol, initiated, destination_ip, destination_port
+		ORDER BY COUNT(*) DESC, MIN(time) LIMIT ?`, append(args, sysmonSummaryLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("汇总Sysmon网络连接失败: %v", err)
+	}
+	defer rows.Close()
+
+	summaries := []SysmonConnectionSummary{}
+	for rows.Next() {
+		var (
+			s           SysmonConnectionSummary
+			hostname    sql.NullString
+			first, last sql.NullString
+		)
+		if err := rows.Scan(&s.Image, &s.Protocol, &s.Initiated, &s.DestinationIP, &s.DestinationPort, &hostname,
+			&s.Count, &s.Processes, &s.Computers, &first, &last); err != nil {
+			return nil, fmt.Errorf("读取Sysmon网络连接汇总失败: %v", err)
+		}
+		s.DestinationHostname = hostname.String
+		s.FirstSeen = formatUTCText(first.String)
+		s.LastSeen = formatUTCText(last.String)
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+func (a *App) sysmonDNSSummary(sessionID string, q WinEventQuery) ([]SysmonDNSSummary, error) {
+	where, args, err := sysmonDNSTable.where(sessionID, q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.database().Query(`SELECT query_name, COUNT(*), GROUP_CONCAT(DISTINCT image), MIN(time), MAX(time),
+			(SELECT d.query_results FROM sysmon_dns d WHERE d.session_id = sysmon_dns.session_id
+				AND d.query_name = sysmon_dns.query_name ORDER BY d.time DESC LIMIT 1)
+		FROM sysmon_dns WHERE `+where+`
+		GROUP BY query_name ORDER BY COUNT(*) DESC, MIN(time) LIMIT ?`, append(args, sysmonSummaryLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("汇总Sysmon DNS查询失败: %v", err)
+	}
+	defer rows.Close()
+
+	summaries := []SysmonDNSSummary{}
+	for rows.Next() {
+		var (
+			s               SysmonDNSSummary
+			images, results sql.NullString
+			first, last     sql.NullString
+		)
+		if err := rows.Scan(&s.QueryName, &s.Count, &images, &first, &last, &results); err != nil {
+			return nil, fmt.Errorf("读取Sysmon DNS查询汇总失败: %v", err)
+		}
+		s.Images = []string{}
+		if images.String != "" {
+			s.Images = strings.Split(images.String, ",")
+		}
+		s.Results = results.String
+		s.FirstSeen = formatUTCText(first.String)
+		s.LastSeen = formatUTCText(last.String)
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
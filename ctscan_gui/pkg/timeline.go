@@ -0,0 +1,756 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timelineEventSchema 统一时间线表，所有时间为 UTC，ref_table 与 ref_id 指向原始记录
+const timelineEventSchema = `CREATE TABLE IF NOT EXISTS timeline_event (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	source TEXT,
+	source_type TEXT,
+	time_type TEXT,
+	macb TEXT,
+	host TEXT,
+	user TEXT,
+	summary TEXT,
+	description TEXT,
+	filename TEXT,
+	ref_table TEXT,
+	ref_id INTEGER,
+	raw_time TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+)`
+
+// migrateTimelineEvent 新增统一时间线表
+func migrateTimelineEvent(tx *sql.Tx) error {
+	return execAll(tx,
+		timelineEventSchema,
+		`CREATE INDEX IF NOT EXISTS idx_timeline_event_time ON timeline_event (session_id, time)`,
+		`CREATE INDEX IF NOT EXISTS idx_timeline_event_source ON timeline_event (session_id, source)`,
+	)
+}
+
+// TimelineEvent 时间线中的一个事件
+type TimelineEvent struct {
+	ID          int64  `json:"id"`
+	Time        string `json:"time"` // UTC
+	Source      string `json:"source"`
+	SourceType  string `json:"source_type"`
+	TimeType    string `json:"time_type"` // 时间的含义，如进程启动、文件修改
+	MACB        string `json:"macb"`      // 文件时间对应的 修改/访问/元数据变更/创建，其他事件为 ....
+	Host        string `json:"host"`
+	User        string `json:"user"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Filename    string `json:"filename"`
+	RefTable    string `json:"ref_table"`
+	RefID       int64  `json:"ref_id"`
+	RawTime     string `json:"raw_time"` // 原始记录中的时间文本
+}
+
+// timelineContext 生成时间线时需要的会话信息
+type timelineContext struct {
+	host string
+	// loc 采集主机的时区，原始记录中没有时区的本地时间按该时区转换
+	loc *time.Location
+	// ref 会话开始时间，没有年份的 syslog 时间以此推断年份
+	ref time.Time
+}
+
+// timelineSource 一类原始记录，query 的第一列为记录ID，events 收到的是其余各列
+// 时间列需要 CAST 为文本，否则 go-sqlite3 会把无法识别的时间转换为零值
+type timelineSource struct {
+	name   string
+	title  string
+	table  string
+	query  string
+	events func(c *timelineContext, v []string) []TimelineEvent
+}
+
+// timelineSources 时间线的所有来源，同时决定统计的顺序
+var timelineSources = []timelineSource{
+	{
+		name:  "PROCESS",
+		title: "进程",
+		table: "process_info",
+		query: `SELECT id, pid, name, ppid, parent_name, create_time, exe, file_mtime, md5 FROM process_info`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			var events []TimelineEvent
+			// gopsutil 返回的进程启动时间为毫秒
+			if t, ok := c.parse(v[4]); ok {
+				events = append(events, TimelineEvent{
+					Time: formatTimeline(t), RawTime: v[4], TimeType: "进程启动", MACB: "....",
+					Summary:     fmt.Sprintf("进程启动 %s (PID %s)", v[1], v[0]),
+					Description: fmt.Sprintf("PID %s  父进程 %s (PID %s)  %s  MD5 %s", v[0], v[3], v[2], v[5], v[7]),
+					Filename:    v[5],
+				})
+			}
+			if t, ok := c.parse(v[6]); ok && v[5] != "" {
+				events = append(events, TimelineEvent{
+					Time: formatTimeline(t), RawTime: v[6], TimeType: "程序文件修改", MACB: "M...",
+					Summary:     "进程程序文件修改 " + v[5],
+					Description: fmt.Sprintf("进程 %s (PID %s) 的程序文件  MD5 %s", v[1], v[0], v[7]),
+					Filename:    v[5],
+				})
+			}
+			return events
+		},
+	},
+	{
+		name:  "FILE",
+		title: "文件",
+		table: "file_monitor",
+		query: `SELECT id, path, CAST(mod_time AS TEXT), CAST(access_time AS TEXT), CAST(change_time AS TEXT),
+			CAST(create_time AS TEXT), size, owner, permissions FROM file_monitor WHERE file_exists`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.fileTimes(v[0], fmt.Sprintf("大小 %s  属主 %s  权限 %s", v[5], v[6], v[7]), v[1], v[2], v[3], v[4])
+		},
+	},
+	{
+		name:  "STARTUP",
+		title: "启动项",
+		table: "startup_item",
+		query: `SELECT id, name, path, type, enabled, CAST(last_mod_time AS TEXT) FROM startup_item`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			t, ok := c.parse(v[4])
+			if !ok {
+				return nil
+			}
+			return []TimelineEvent{{
+				Time: formatTimeline(t), RawTime: v[4], TimeType: "启动项修改", MACB: "M...",
+				Summary:     fmt.Sprintf("启动项修改 %s", v[0]),
+				Description: fmt.Sprintf("%s  类型 %s  启用 %s", v[1], v[2], v[3]),
+				Filename:    v[1],
+			}}
+		},
+	},
+	{
+		name:  "LOGIN",
+		title: "登录成功",
+		table: "login_success",
+		query: `SELECT id, CAST(time AS TEXT), event_id, event_type, source, username, ip_address, port, method FROM login_success`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "登录成功", v[4], fmt.Sprintf("登录成功 %s %s", v[4], v[5]),
+				fmt.Sprintf("事件 %s %s  来源 %s  用户 %s  IP %s%s", v[1], v[2], v[3], v[4], v[5], loginDetail(v[6], v[7])))
+		},
+	},
+	{
+		name:  "LOGIN",
+		title: "登录失败",
+		table: "login_failed",
+		query: `SELECT id, CAST(time AS TEXT), event_id, event_type, source, username, ip_address, reason, port, method FROM login_failed`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "登录失败", v[4], fmt.Sprintf("登录失败 %s %s", v[4], v[5]),
+				fmt.Sprintf("事件 %s %s  来源 %s  用户 %s  IP %s%s  %s", v[1], v[2], v[3], v[4], v[5], loginDetail(v[7], v[8]), v[6]))
+		},
+	},
+	{
+		name:  "LOGIN",
+		title: "登录会话",
+		table: "login_session",
+		query: `SELECT id, CAST(login_time AS TEXT), CAST(logout_time AS TEXT), user, tty, host, duration, status FROM login_session`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			// wtmp 中的 reboot 会话为系统启动与关机
+			start, end := "登录", "登出"
+			if v[2] == "reboot" {
+				start, end = "系统启动", "系统关机"
+			}
+			description := fmt.Sprintf("用户 %s  终端 %s  来源 %s  时长 %s 秒  %s", v[2], v[3], v[4], v[5], v[6])
+			events := c.single(v[0], start, v[2], fmt.Sprintf("%s %s %s %s", start, v[2], v[3], v[4]), description)
+			return append(events, c.single(v[1], end, v[2], fmt.Sprintf("%s %s %s (%s)", end, v[2], v[3], v[6]), description)...)
+		},
+	},
+	{
+		name:  "LOGIN",
+		title: "btmp登录失败",
+		table: "btmp_record",
+		query: `SELECT id, CAST(time AS TEXT), user, tty, host FROM btmp_record`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "登录失败", v[1], fmt.Sprintf("登录失败 %s %s", v[1], v[3]),
+				fmt.Sprintf("btmp  用户 %s  终端 %s  来源 %s", v[1], v[2], v[3]))
+		},
+	},
+	{
+		name:  "AUDIT",
+		title: "auditd命令执行",
+		table: "audit_command",
+		query: `SELECT id, CAST(time AS TEXT), user, run_as, tty, cwd, command, success FROM audit_command`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "命令执行", v[1], fmt.Sprintf("%s(%s): %s", v[1], v[2], v[5]),
+				fmt.Sprintf("auditd  用户 %s  身份 %s  终端 %s  目录 %s  结果 %s  %s", v[1], v[2], v[3], v[4], v[6], v[5]))
+		},
+	},
+	{
+		name:  "AUDIT",
+		title: "auditd审计事件",
+		table: "audit_event",
+		query: `SELECT id, CAST(time AS TEXT), category, type, user, operator, exe, addr, result, key, path, detail FROM audit_event`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			// 文件监控事件以规则名与文件为摘要
+			summary := fmt.Sprintf("%s %s %s %s %s", v[1], v[2], v[3], v[6], v[7])
+			if v[9] != "" {
+				summary = fmt.Sprintf("%s %s %s (%s)", v[1], v[8], v[9], v[5])
+			}
+			return c.single(v[0], v[1], v[3], summary,
+				fmt.Sprintf("%s  用户 %s  登录用户 %s  程序 %s  地址 %s  结果 %s  规则 %s  文件 %s  %s", v[2], v[3], v[4], v[5], v[6], v[7], v[8], v[9], v[10]))
+		},
+	},
+	{
+		// 合并后的命中记录只有首次与末次时间
+		name:  "WEB",
+		title: "Web可疑请求",
+		table: "web_log_finding",
+		query: `SELECT id, CAST(first_time AS TEXT), CAST(last_time AS TEXT), level, category, rule, ip, path, sample, count, status FROM web_log_finding`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			description := fmt.Sprintf("%s %s  级别 %s  来源 %s  次数 %s  状态 %s  %s", v[3], v[4], v[2], v[5], v[8], v[9], v[7])
+			events := c.single(v[0], "Web请求首次出现", "", fmt.Sprintf("%s %s %s", v[3], v[5], v[6]), description)
+			if v[1] != v[0] {
+				events = append(events, c.single(v[1], "Web请求末次出现", "", fmt.Sprintf("%s %s %s", v[3], v[5], v[6]), description)...)
+			}
+			return events
+		},
+	},
+	{
+		name:  "WEBSHELL",
+		title: "Webshell",
+		table: "webshell_file",
+		query: `SELECT id, path, CAST(mod_time AS TEXT), CAST(access_time AS TEXT), CAST(change_time AS TEXT),
+			CAST(create_time AS TEXT), score, family, indicators, md5 FROM webshell_file`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			description := "可疑脚本 得分 " + v[5]
+			if v[6] != "" {
+				description += " " + v[6]
+			}
+			description += fmt.Sprintf("  %s  MD5 %s", v[7], v[8])
+			return c.fileTimes(v[0], description, v[1], v[2], v[3], v[4])
+		},
+	},
+	{
+		name:  "RDP",
+		title: "RDP登录",
+		table: "rdp_login",
+		query: `SELECT id, CAST(time AS TEXT), username, ip, status, description FROM rdp_login`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "RDP登录", v[1], fmt.Sprintf("RDP登录%s %s %s", v[3], v[1], v[2]), v[4])
+		},
+	},
+	{
+		// 只有 zsh 的历史记录带有执行时间，其他 shell 记录的是采集时间
+		name:  "SHELL",
+		title: "命令记录",
+		table: "shell_history",
+		query: `SELECT id, CAST(time AS TEXT), command, user, shell FROM shell_history WHERE shell = 'zsh'`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			return c.single(v[0], "命令执行", v[2], fmt.Sprintf("%s: %s", v[3], v[1]), v[1])
+		},
+	},
+	{
+		name:  "SUDO",
+		title: "sudo命令",
+		table: "sudo_command",
+		query: `SELECT id, CAST(time AS TEXT), user, run_as, command, allowed, reason, pwd, tty FROM sudo_command`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			summary := fmt.Sprintf("sudo %s -> %s: %s", v[1], v[2], v[3])
+			if v[4] != "1" {
+				summary = fmt.Sprintf("sudo 被拒绝 %s: %s (%s)", v[1], v[3], v[5])
+			}
+			return c.single(v[0], "sudo命令", v[1], summary, fmt.Sprintf("目录 %s  终端 %s  %s", v[6], v[7], v[3]))
+		},
+	},
+	{
+		name:  "EVTX",
+		title: "EVTX事件",
+		table: "evtx_event",
+		query: `SELECT id, CAST(time AS TEXT), event_id, provider, channel, computer, user_id, source_file, record_id,
+			event_data, user_data, recovered, record_offset FROM evtx_event`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			events := c.single(v[0], "事件记录", v[5], fmt.Sprintf("%s %s", v[3], v[1]), evtxDescription(v[8], v[9]))
+			record := "记录 " + v[7]
+			if v[10] == "1" {
+				offset, _ := strconv.ParseInt(v[11], 10, 64)
+				record = fmt.Sprintf("恢复的记录 %s (偏移 0x%x)", v[7], offset)
+			}
+			for i := range events {
+				events[i].SourceType = v[2]
+				events[i].Host = v[4]
+				events[i].Filename = v[6]
+				events[i].Description = fmt.Sprintf("%s  %s", record, events[i].Description)
+			}
+			return events
+		},
+	},
+	{
+		name:  "SIGMA",
+		title: "Sigma告警",
+		table: "sigma_hit",
+		query: `SELECT id, CAST(time AS TEXT), level, title, rule_id, event_id, channel, computer, source_file, record_id,
+			description FROM sigma_hit`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			events := c.single(v[0], "规则命中", "", fmt.Sprintf("[%s] %s", v[1], v[2]),
+				fmt.Sprintf("规则 %s  %s 事件 %s  记录 %s  %s", v[3], v[5], v[4], v[8], v[9]))
+			for i := range events {
+				events[i].Host = v[6]
+				events[i].Filename = v[7]
+			}
+			return events
+		},
+	},
+	{
+		name:  "POWERSHELL",
+		title: "PowerShell脚本",
+		table: "ps_script_block",
+		query: `SELECT id, CAST(first_time AS TEXT), computer, script_block_id, path, user_id, score, indicators,
+			substr(script, 1, 200) FROM ps_script_block`,
+		events: func(c *timelineContext, v []string) []TimelineEvent {
+			events := c.single(v[0], "脚本执行", v[4], fmt.Sprintf("PowerShell脚本块 %s 分值 %s", v[2], v[5]),
+				fmt.Sprintf("可疑特征 %s  %s", v[6], strings.Join(strings.Fields(v[7]), " ")))
+			for i := range events {
+				events[i].Host = v[1]
+				events[i].Filename = v[3]
+			}
+			return events
+		},
+	},
+}
+
+// timelineDescriptionLimit 事件描述的最大长度
+const timelineDescriptionLimit = 500
+
+// evtxDescription 将事件数据压缩为一行 键=值 文本
+func evtxDescription(eventData, userData string) string {
+	var parts []string
+	for _, s := range []string{eventData, userData} {
+		m := map[string]any{}
+		flattenMap("", jsonMap(s), m)
+		for _, k := range sortedKeys(m) {
+			if v := exportString(m[k]); v != "" && v != "-" {
+				parts = append(parts, strings.TrimPrefix(k, ".")+"="+v)
+			}
+		}
+	}
+	return truncateRunes(strings.Join(parts, " "), timelineDescriptionLimit)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncateRunes 按字符截断，超出时以 ... 结尾
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
+
+// single 生成只有一个时间的事件，描述中的换行合并为一行
+func (c *timelineContext) single(raw, timeType, user, summary, description string) []TimelineEvent {
+	t, ok := c.parse(raw)
+	if !ok {
+		return nil
+	}
+	return []TimelineEvent{{
+		Time: formatTimeline(t), RawTime: raw, TimeType: timeType, MACB: "....",
+		User: user, Summary: summary,
+		Description: truncateRunes(strings.Join(strings.Fields(description), " "), timelineDescriptionLimit),
+	}}
+}
+
+// fileTimes 文件的修改、访问、元数据变更与创建时间，相同的时间合并为一个事件
+func (c *timelineContext) fileTimes(path, description string, raws ...string) []TimelineEvent {
+	names := []string{"修改", "访问", "元数据变更", "创建"}
+	var events []TimelineEvent
+	index := map[string]int{}
+	for i, raw := range raws {
+		t, ok := c.parse(raw)
+		if !ok {
+			continue
+		}
+		key := formatTimeline(t)
+		j, ok := index[key]
+		if !ok {
+			j = len(events)
+			index[key] = j
+			events = append(events, TimelineEvent{
+				Time: key, RawTime: raw, MACB: "....", Filename: path, Description: description,
+			})
+		}
+		e := &events[j]
+		e.MACB = e.MACB[:i] + "MACB"[i:i+1] + e.MACB[i+1:]
+		if e.TimeType != "" {
+			e.TimeType += "/"
+		}
+		e.TimeType += names[i]
+	}
+	for i := range events {
+		events[i].Summary = fmt.Sprintf("文件%s %s", events[i].TimeType, path)
+	}
+	return events
+}
+
+var (
+	// WMI 返回的时间，如 20240601100000.000000+480，时区为分钟
+	cimTimeRe = regexp.MustCompile(`^(\d{14})\.\d{6}([+-])(\d{3})$`)
+	digitsRe  = regexp.MustCompile(`^\d+$`)
+)
+
+// parseCIMTime 解析 WMI 时间，保留其中的时区偏移
+func parseCIMTime(s string) (time.Time, bool) {
+	m := cimTimeRe.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	minutes, _ := strconv.Atoi(m[3])
+	if m[2] == "-" {
+		minutes = -minutes
+	}
+	t, err := time.ParseInLocation("20060102150405", m[1], time.FixedZone("", minutes*60))
+	return t, err == nil
+}
+
+// 带时区的时间格式，go-sqlite3 保存 time.Time 时使用第二种格式
+var zonedTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999Z07:00",
+}
+
+// 没有时区的本地时间格式
+var localTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+}
+
+// syslogTimeLayout 没有年份的 syslog 时间
+const syslogTimeLayout = "Jan _2 15:04:05"
+
+// parse 将各采集项中不同格式的时间转换为 UTC，无法识别或为空的时间返回 false
+func (c *timelineContext) parse(raw string) (time.Time, bool) {
+	s := strings.TrimSpace(raw)
+	if s == "" || s == "0" {
+		return time.Time{}, false
+	}
+	t, ok := c.parseTime(s)
+	// 1971 年以前的时间视为未设置
+	if !ok || t.Year() < 1971 {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+func (c *timelineContext) parseTime(s string) (time.Time, bool) {
+	if t, ok := parseCIMTime(s); ok {
+		return t, true
+	}
+	if digitsRe.MatchString(s) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// 超过 10^11 的数值为毫秒
+		if n > 1e11 {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	}
+	for _, layout := range zonedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, c.loc); err == nil {
+			return t, true
+		}
+	}
+	if len(s) >= len(syslogTimeLayout) {
+		if t, err := time.ParseInLocation(syslogTimeLayout, s[:len(syslogTimeLayout)], c.loc); err == nil {
+			// 晚于会话开始时间的日志属于上一年
+			ref := c.ref.In(c.loc)
+			t = time.Date(ref.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, c.loc)
+			if t.After(ref.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// loginDetail Linux 登录记录中的端口与认证方式
+func loginDetail(port, method string) string {
+	var s string
+	if port != "" && port != "0" {
+		s += "  端口 " + port
+	}
+	if method != "" {
+		s += "  方式 " + method
+	}
+	return s
+}
+
+func formatTimeline(t time.Time) string {
+	return t.UTC().Format(sessionTimeLayout)
+}
+
+// newTimelineContext 读取会话的主机名与开始时间，开始时间中的时区即采集主机的时区
+func (a *App) newTimelineContext(sessionID string) (*timelineContext, error) {
+	var host, start sql.NullString
+	err := a.database().QueryRow(`SELECT hostname, CAST(start_time AS TEXT) FROM scan_session WHERE id = ?`, sessionID).Scan(&host, &start)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("会话不存在: %s", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取会话失败: %v", err)
+	}
+	c := &timelineContext{host: host.String, loc: time.Local, ref: time.Now()}
+	for _, layout := range zonedTimeLayouts {
+		if t, err := time.Parse(layout, start.String); err == nil {
+			_, offset := t.Zone()
+			c.loc, c.ref = time.FixedZone("", offset), t
+			break
+		}
+	}
+	return c, nil
+}
+
+// TimelineSourceCount 时间线中每个来源的事件数量
+type TimelineSourceCount struct {
+	Source string `json:"source"`
+	Count  int    `json:"count"`
+}
+
+// TimelineBuildResult 生成时间线的结果
+type TimelineBuildResult struct {
+	SessionID string                `json:"session_id"`
+	Events    int                   `json:"events"`
+	Skipped   int                   `json:"skipped"` // 时间为空或无法识别的记录数
+	Counts    []TimelineSourceCount `json:"counts"`
+}
+
+// BuildTimeline 将会话中所有采集项与导入日志的时间统一为 UTC 并生成时间线，替换该会话原有的时间线
+// 可通过 CancelTask("timeline") 取消
+func (a *App) BuildTimeline(sessionID string) (TimelineBuildResult, error) {
+	if a.isReadOnly() {
+		return TimelineBuildResult{}, errReadOnly
+	}
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return TimelineBuildResult{}, err
+	}
+	ctx, done := a.startTask(nil, "timeline", "生成时间线")
+	result, err := a.buildTimeline(ctx, sessionID)
+	done(err)
+	return result, taskError(err)
+}
+
+// buildTimeline 逐批读取各来源并写入临时会话ID下，全部完成后在一个事务中替换时间线，取消时不修改原有的时间线
+func (a *App) buildTimeline(ctx context.Context, sessionID string) (TimelineBuildResult, error) {
+	result := TimelineBuildResult{SessionID: sessionID}
+	c, err := a.newTimelineContext(sessionID)
+	if err != nil {
+		return result, err
+	}
+	// 清除上次中断时留下的事件
+	staging := timelineStagingID(sessionID)
+	if err := a.deleteTimeline(staging); err != nil {
+		return result, err
+	}
+	replaced := false
+	defer func() {
+		if !replaced {
+			a.deleteTimeline(staging)
+		}
+	}()
+
+	progress := progressFrom(ctx)
+	progress.SetTotal(len(timelineSources))
+	for _, src := range timelineSources {
+		found, skipped, err := a.readTimelineSource(ctx, c, sessionID, src, func(events []TimelineEvent) error {
+			return a.saveTimelineEvents(staging, events)
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Events += found
+		result.Skipped += skipped
+		progress.Step(fmt.Sprintf("%s %d 个事件", src.title, found))
+	}
+
+	a.evtxMu.Lock()
+	err = func() error {
+		tx, err := a.database().Begin()
+		if err != nil {
+			return fmt.Errorf("开始事务失败: %v", err)
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(`DELETE FROM timeline_event WHERE session_id = ?`, sessionID); err != nil {
+			return fmt.Errorf("清除旧的时间线失败: %v", err)
+		}
+		if _, err := tx.Exec(`UPDATE timeline_event SET session_id = ? WHERE session_id = ?`, sessionID, staging); err != nil {
+			return fmt.Errorf("保存时间线失败: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("提交事务失败: %v", err)
+		}
+		return nil
+	}()
+	a.evtxMu.Unlock()
+	if err != nil {
+		return result, err
+	}
+	replaced = true
+	result.Counts, err = a.timelineCounts(sessionID)
+	return result, err
+}
+
+// timelineStagingID 生成过程中的事件保存在该会话ID下，查询与导出时不可见
+func timelineStagingID(sessionID string) string {
+	return sessionID + ":building"
+}
+
+// saveTimelineEvents 在一个事务中保存一批时间线事件，每批单独加锁，不阻塞同时进行的EVTX导入
+func (a *App) saveTimelineEvents(sessionID string, events []TimelineEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+	tx, err := a.database().Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO timeline_event (session_id, time, source, source_type, time_type, macb, host,
+		user, summary, description, filename, ref_table, ref_id, raw_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+	for _, e := range events {
+		created, _ := time.Parse(sessionTimeLayout, e.Time)
+		if _, err := stmt.Exec(sessionID, created, e.Source, e.SourceType, e.TimeType, e.MACB, e.Host, e.User,
+			e.Summary, e.Description, e.Filename, e.RefTable, e.RefID, e.RawTime); err != nil {
+			return fmt.Errorf("保存时间线失败: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+func (a *App) deleteTimeline(sessionID string) error {
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+	if _, err := a.database().Exec(`DELETE FROM timeline_event WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("清除时间线失败: %v", err)
+	}
+	return nil
+}
+
+// readTimelineSource 按记录ID分批读取一类原始记录，转换为时间线事件后按批交给 save
+// 返回生成的事件数与没有可用时间的记录数
+func (a *App) readTimelineSource(ctx context.Context, c *timelineContext, sessionID string, src timelineSource,
+	save func(events []TimelineEvent) error) (int, int, error) {
+	query := src.query + ` WHERE session_id = ?`
+	if strings.Contains(src.query, " WHERE ") {
+		query = src.query + ` AND session_id = ?`
+	}
+	total, skipped := 0, 0
+	for from := int64(0); ; {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		rows, err := a.database().Query(query+` AND id > ? ORDER BY id LIMIT ?`, sessionID, from, evtxBatchSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("读取%s失败: %v", src.title, err)
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("读取%s失败: %v", src.title, err)
+		}
+		var events []TimelineEvent
+		n := 0
+		for rows.Next() {
+			var id int64
+			values := make([]sql.NullString, len(columns)-1)
+			dest := []any{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return 0, 0, fmt.Errorf("读取%s失败: %v", src.title, err)
+			}
+			v := make([]string, len(values))
+			for i, value := range values {
+				v[i] = value.String
+			}
+			found := src.events(c, v)
+			if len(found) == 0 {
+				skipped++
+			}
+			for _, e := range found {
+				e.Source = src.name
+				if e.SourceType == "" {
+					e.SourceType = src.title
+				}
+				if e.Host == "" {
+					e.Host = c.host
+				}
+				e.RefTable, e.RefID = src.table, id
+				events = append(events, e)
+			}
+			from = id
+			n++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, 0, fmt.Errorf("读取%s失败: %v", src.title, err)
+		}
+		if err := save(events); err != nil {
+			return 0, 0, err
+		}
+		total += len(events)
+		if n < evtxBatchSize {
+			return total, skipped, nil
+		}
+	}
+}
+
+func (a *App) timelineCounts(sessionID string) ([]TimelineSourceCount, error) {
+	rows, err := a.database().Query(`SELECT source, COUNT(*) FROM timeline_event WHERE session_id = ? GROUP BY source ORDER BY COUNT(*) DESC`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("统计时间线失败: %v", err)
+	}
+	defer rows.Close()
+	counts := []TimelineSourceCount{}
+	for rows.Next() {
+		var c TimelineSourceCount
+		if err := rows.Scan(&c.Source, &c.Count); err != nil {
+			return nil, fmt.Errorf("统计时间线失败: %v", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,327 @@
+package pkg
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimelineQuery 时间线的查询条件，时间为 UTC
+type TimelineQuery struct {
+	SessionID string   `json:"session_id"`
+	Start     string   `json:"start"`
+	End       string   `json:"end"`
+	Sources   []string `json:"sources"` // 为空时查询所有来源
+	Host      string   `json:"host"`
+	Keyword   string   `json:"keyword"`
+	Page      int      `json:"page"`
+	PageSize  int      `json:"page_size"`
+}
+
+// TimelinePage 一页时间线事件
+type TimelinePage struct {
+	SessionID string          `json:"session_id"`
+	Total     int             `json:"total"`
+	Page      int             `json:"page"`
+	PageSize  int             `json:"page_size"`
+	Events    []TimelineEvent `json:"events"`
+}
+
+// where 生成查询条件
+func (q TimelineQuery) where(sessionID string) (string, []any, error) {
+	conds := []string{"session_id = ?"}
+	args := []any{sessionID}
+	if q.Start != "" {
+		start, err := time.Parse(sessionTimeLayout, q.Start)
+		if err != nil {
+			return "", nil, fmt.Errorf("开始时间格式错误: %s", q.Start)
+		}
+		conds = append(conds, "time >= ?")
+		args = append(args, start)
+	}
+	if q.End != "" {
+		end, err := time.Parse(sessionTimeLayout, q.End)
+		if err != nil {
+			return "", nil, fmt.Errorf("结束时间格式错误: %s", q.End)
+		}
+		conds = append(conds, "time < ?")
+		args = append(args, end.Add(time.Second))
+	}
+	if len(q.Sources) > 0 {
+		conds = append(conds, "source IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(q.Sources)), ", ")+")")
+		for _, s := range q.Sources {
+			args = append(args, s)
+		}
+	}
+	if q.Host != "" {
+		conds = append(conds, "host = ?")
+		args = append(args, q.Host)
+	}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		conds = append(conds, "(summary LIKE ? OR description LIKE ? OR user LIKE ? OR filename LIKE ?)")
+		args = append(args, like, like, like, like)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+const timelineEventColumns = `id, time, source, source_type, time_type, macb, host, user, summary, description, filename,
+	ref_table, ref_id, raw_time`
+
+// QueryTimeline 分页查询时间线，按时间排序
+func (a *App) QueryTimeline(q TimelineQuery) (TimelinePage, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+	page := TimelinePage{SessionID: sessionID, Page: q.Page, PageSize: q.PageSize, Events: []TimelineEvent{}}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize < 1 {
+		page.PageSize = evtxDefaultPageSize
+	}
+	if page.PageSize > evtxMaxPageSize {
+		page.PageSize = evtxMaxPageSize
+	}
+	where, args, err := q.where(sessionID)
+	if err != nil {
+		return page, err
+	}
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM timeline_event WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return page, fmt.Errorf("查询时间线失败: %v", err)
+	}
+	page.Events, err = a.queryTimelineEvents(where+` ORDER BY time, id LIMIT ? OFFSET ?`,
+		append(args, page.PageSize, (page.Page-1)*page.PageSize)...)
+	return page, err
+}
+
+func (a *App) queryTimelineEvents(where string, args ...any) ([]TimelineEvent, error) {
+	rows, err := a.database().Query(`SELECT `+timelineEventColumns+` FROM timeline_event WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询时间线失败: %v", err)
+	}
+	defer rows.Close()
+
+	events := []TimelineEvent{}
+	for rows.Next() {
+		var (
+			e       TimelineEvent
+			created sql.NullTime
+		)
+		if err := rows.Scan(&e.ID, &created, &e.Source, &e.SourceType, &e.TimeType, &e.MACB, &e.Host, &e.User,
+			&e.Summary, &e.Description, &e.Filename, &e.RefTable, &e.RefID, &e.RawTime); err != nil {
+			return nil, fmt.Errorf("读取时间线失败: %v", err)
+		}
+		if created.Valid {
+			e.Time = created.Time.UTC().Format(sessionTimeLayout)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// TimelineBucket 直方图中的一个时间段
+type TimelineBucket struct {
+	Start string `json:"start"` // UTC
+	End   string `json:"end"`
+	Count int    `json:"count"`
+}
+
+// TimelineHistogram 时间线的事件分布，用于缩放时间范围
+type TimelineHistogram struct {
+	SessionID string                `json:"session_id"`
+	Start     string                `json:"start"`
+	End       string                `json:"end"`
+	Seconds   int64                 `json:"seconds"` // 每个时间段的秒数
+	Buckets   []TimelineBucket      `json:"buckets"`
+	Sources   []TimelineSourceCount `json:"sources"` // 时间范围内每个来源的事件数，不受来源筛选影响
+	Hosts     []string              `json:"hosts"`
+}
+
+// timelineBuckets 直方图的最大时间段数
+const timelineBuckets = 60
+
+// timelineBucketSizes 可选的时间段长度，选择使时间段数不超过 timelineBuckets 的最小值
+var timelineBucketSizes = []time.Duration{
+	time.Second, 10 * time.Second, time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour,
+	30 * 24 * time.Hour, 90 * 24 * time.Hour, 365 * 24 * time.Hour,
+}
+
+// GetTimelineHistogram 统计符合条件的事件在时间上的分布
+func (a *App) GetTimelineHistogram(q TimelineQuery) (TimelineHistogram, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return TimelineHistogram{}, err
+	}
+	h := TimelineHistogram{SessionID: sessionID, Buckets: []TimelineBucket{}, Hosts: []string{}}
+	where, args, err := q.where(sessionID)
+	if err != nil {
+		return h, err
+	}
+	var first, last sql.NullString
+	if err := a.database().QueryRow(`SELECT MIN(time), MAX(time) FROM timeline_event WHERE `+where, args...).Scan(&first, &last); err != nil {
+		return h, fmt.Errorf("查询时间线失败: %v", err)
+	}
+
+	if first.Valid {
+		start, _ := time.Parse(sessionTimeLayout, formatUTCText(first.String))
+		end, _ := time.Parse(sessionTimeLayout, formatUTCText(last.String))
+		size := timelineBucketSizes[len(timelineBucketSizes)-1]
+		for _, s := range timelineBucketSizes {
+			if end.Sub(start)/s < timelineBuckets {
+				size = s
+				break
+			}
+		}
+		start = start.Truncate(size)
+		h.Seconds = int64(size / time.Second)
+		rows, err := a.database().Query(`SELECT (CAST(strftime('%s', time) AS INTEGER) - ?) / ?, COUNT(*) FROM timeline_event
+			WHERE `+where+` GROUP BY 1 ORDER BY 1`, append([]any{start.Unix(), h.Seconds}, args...)...)
+		if err != nil {
+			return h, fmt.Errorf("统计时间线失败: %v", err)
+		}
+		counts := map[int64]int{}
+		var maxIndex int64
+		for rows.Next() {
+			var index int64
+			var count int
+			if err := rows.Scan(&index, &count); err != nil {
+				rows.Close()
+				return h, fmt.Errorf("统计时间线失败: %v", err)
+			}
+			counts[index] = count
+			if index > maxIndex {
+				maxIndex = index
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return h, fmt.Errorf("统计时间线失败: %v", err)
+		}
+		// 没有事件的时间段同样返回，保证直方图的横轴连续
+		for i := int64(0); i <= maxIndex; i++ {
+			from := start.Add(time.Duration(i) * size)
+			h.Buckets = append(h.Buckets, TimelineBucket{
+				Start: from.Format(sessionTimeLayout),
+				End:   from.Add(size - time.Second).Format(sessionTimeLayout),
+				Count: counts[i],
+			})
+		}
+		h.Start = h.Buckets[0].Start
+		h.End = h.Buckets[len(h.Buckets)-1].End
+	}
+
+	all := q
+	all.Sources = nil
+	where, args, _ = all.where(sessionID)
+	rows, err := a.database().Query(`SELECT source, COUNT(*) FROM timeline_event WHERE `+where+` GROUP BY source ORDER BY COUNT(*) DESC`, args...)
+	if err != nil {
+		return h, fmt.Errorf("统计时间线失败: %v", err)
+	}
+	defer rows.Close()
+	h.Sources = []TimelineSourceCount{}
+	for rows.Next() {
+		var c TimelineSourceCount
+		if err := rows.Scan(&c.Source, &c.Count); err != nil {
+			return h, fmt.Errorf("统计时间线失败: %v", err)
+		}
+		h.Sources = append(h.Sources, c)
+	}
+	if err := rows.Err(); err != nil {
+		return h, fmt.Errorf("统计时间线失败: %v", err)
+	}
+	hosts, err := a.database().Query(`SELECT DISTINCT host FROM timeline_event WHERE session_id = ? AND host != '' ORDER BY host`, sessionID)
+	if err != nil {
+		return h, fmt.Errorf("统计时间线失败: %v", err)
+	}
+	defer hosts.Close()
+	for hosts.Next() {
+		var host string
+		if err := hosts.Scan(&host); err != nil {
+			return h, fmt.Errorf("统计时间线失败: %v", err)
+		}
+		h.Hosts = append(h.Hosts, host)
+	}
+	return h, hosts.Err()
+}
+
+// l2tcsvColumns plaso l2tcsv 格式的列
+var l2tcsvColumns = []string{"date", "time", "timezone", "MACB", "source", "sourcetype", "type", "user", "host",
+	"short", "desc", "version", "filename", "inode", "notes", "format", "extra"}
+
+// writeL2TCSV 以 plaso l2tcsv 格式写出时间线，日期为 MM/DD/YYYY，时区固定为 UTC
+func writeL2TCSV(w io.Writer, events []TimelineEvent) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(l2tcsvColumns); err != nil {
+		return err
+	}
+	dash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	for _, e := range events {
+		t, err := time.Parse(sessionTimeLayout, e.Time)
+		if err != nil {
+			continue
+		}
+		record := []string{
+			t.Format("01/02/2006"), t.Format("15:04:05"), "UTC", e.MACB, e.Source, e.SourceType, e.TimeType,
+			dash(e.User), dash(e.Host), e.Summary, dash(e.Description), "2", dash(e.Filename), "-", "-",
+			"ctscan/" + e.RefTable, fmt.Sprintf("ref_table: %s; ref_id: %d; raw_time: %s", e.RefTable, e.RefID, e.RawTime),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportTimeline 将符合条件的全部时间线事件(不分页)写出为 l2tcsv 文件
+func (a *App) exportTimeline(q TimelineQuery, output string) (int, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	where, args, err := q.where(sessionID)
+	if err != nil {
+		return 0, err
+	}
+	events, err := a.queryTimelineEvents(where+` ORDER BY time, id`, args...)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, fmt.Errorf("没有符合条件的时间线事件，请先生成时间线")
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return 0, fmt.Errorf("创建文件失败: %v", err)
+	}
+	if err := writeL2TCSV(f, events); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("写入文件失败: %v", err)
+	}
+	return len(events), f.Close()
+}
+
+// ExportTimeline 以 plaso l2tcsv 格式导出符合条件的时间线，弹窗选择保存位置
+func (a *App) ExportTimeline(q TimelineQuery) (string, error) {
+	name := fmt.Sprintf("ctscan-timeline-%s.csv", time.Now().Format("20060102-150405"))
+	output, err := a.exportDialog(name, ExportCSV, false)
+	if err != nil {
+		return "", err
+	}
+	if _, err := a.exportTimeline(q, output); err != nil {
+		return "", err
+	}
+	return output, nil
+}
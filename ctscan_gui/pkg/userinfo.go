@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"os"
 	"os/user"
 	"runtime"
@@ -142,3 +143,35 @@ func (a *App) getWindowsUsers() []SystemUser {
 	}
 	return users
 }
+
+// userInfoSchema 用户信息表
+const userInfoSchema = `CREATE TABLE IF NOT EXISTS user_info (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	username TEXT,
+	uid TEXT,
+	gid TEXT,
+	home_dir TEXT,
+	name TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+func init() {
+	RegisterCollector(&sliceCollector[SystemUser]{
+		name:      "users",
+		title:     "用户信息",
+		platforms: []string{"windows", "linux", "darwin"},
+		schema:    []string{userInfoSchema},
+		collect: func(ctx context.Context, a *App) ([]SystemUser, error) {
+			return a.GetAllUsers(), nil
+		},
+		save: func(a *App, users []SystemUser) error {
+			for _, u := range users {
+				if err := a.SaveUserInfo(UserInfo(u)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,823 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// utmp 记录类型(ut_type)
+const (
+	utEmpty        = 0
+	utRunLevel     = 1
+	utBootTime     = 2
+	utNewTime      = 3 // macOS 中 3 为 OLD_TIME、4 为 NEW_TIME，都表示系统时间被修改
+	utOldTime      = 4
+	utInitProcess  = 5
+	utLoginProcess = 6
+	utUserProcess  = 7
+	utDeadProcess  = 8
+	utAccounting   = 9
+	utSignature    = 10 // macOS utmpx 文件的第一条记录
+	utShutdownTime = 11 // macOS
+)
+
+var utmpTypeNames = []string{
+	"EMPTY", "RUN_LVL", "BOOT_TIME", "NEW_TIME", "OLD_TIME", "INIT_PROCESS",
+	"LOGIN_PROCESS", "USER_PROCESS", "DEAD_PROCESS", "ACCOUNTING", "SIGNATURE", "SHUTDOWN_TIME",
+}
+
+// utmpLayout 不同平台 utmp 结构中各字段的偏移与长度
+type utmpLayout struct {
+	name                      string
+	size                      int
+	typ, pid, line, user      int
+	lineLen, userLen, hostLen int
+	host                      int
+	sec, secLen               int
+	addr                      int // IPv4/IPv6 地址，没有该字段时为 -1
+}
+
+// utmpLayouts 支持的 utmp 结构
+// Linux x86_64 与 i386 为了兼容，时间与会话ID都是 32 位，记录大小为 384 字节；
+// 没有该兼容处理的 64 位平台(如部分 aarch64、ppc64)时间为 64 位，记录大小为 400 字节；
+// macOS 的 utmpx 为 640 字节，用户名在前且没有地址字段
+var utmpLayouts = []utmpLayout{
+	{name: "linux", size: 384, typ: 0, pid: 4, line: 8, lineLen: 32, user: 44, userLen: 32, host: 76, hostLen: 256, sec: 340, secLen: 4, addr: 348},
+	{name: "linux64", size: 400, typ: 0, pid: 4, line: 8, lineLen: 32, user: 44, userLen: 32, host: 76, hostLen: 256, sec: 344, secLen: 8, addr: 360},
+	{name: "utmpx", size: 640, typ: 296, pid: 292, line: 260, lineLen: 32, user: 0, userLen: 256, host: 320, hostLen: 256, sec: 304, secLen: 8, addr: -1},
+}
+
+// utmpEntry 解析后的一条 utmp 记录
+type utmpEntry struct {
+	Type   int
+	PID    int
+	Line   string
+	User   string
+	Host   string
+	IP     string
+	Time   time.Time // 时间为 0 时为零值
+	File   string
+	Offset int64
+}
+
+func (e utmpEntry) typeName() string {
+	if e.Type >= 0 && e.Type < len(utmpTypeNames) {
+		return utmpTypeNames[e.Type]
+	}
+	return strconv.Itoa(e.Type)
+}
+
+func (l utmpLayout) int(rec []byte, offset, size int) int64 {
+	switch size {
+	case 2:
+		return int64(int16(binary.LittleEndian.Uint16(rec[offset:])))
+	case 4:
+		return int64(int32(binary.LittleEndian.Uint32(rec[offset:])))
+	default:
+		return int64(binary.LittleEndian.Uint64(rec[offset:]))
+	}
+}
+
+func (l utmpLayout) typeOf(rec []byte) int {
+	// macOS 的 ut_type 为 short，Linux 为 short 加 2 字节填充
+	return int(l.int(rec, l.typ, 2))
+}
+
+// parse 解析一条记录，字段中出现不可打印的字符时 ok 为 false
+func (l utmpLayout) parse(rec []byte) (e utmpEntry, ok bool) {
+	e.Type = l.typeOf(rec)
+	e.PID = int(l.int(rec, l.pid, 4))
+	var okLine, okUser, okHost bool
+	e.Line, okLine = utmpString(rec[l.line : l.line+l.lineLen])
+	e.User, okUser = utmpString(rec[l.user : l.user+l.userLen])
+	e.Host, okHost = utmpString(rec[l.host : l.host+l.hostLen])
+	if sec := l.int(rec, l.sec, l.secLen); sec > 0 {
+		e.Time = time.Unix(sec, 0)
+	}
+	if l.addr >= 0 {
+		e.IP = utmpIP(rec[l.addr : l.addr+16])
+	}
+	return e, okLine && okUser && okHost
+}
+
+// valid 判断记录是否符合该结构，用于识别文件使用的结构
+func (l utmpLayout) valid(rec []byte, now time.Time) bool {
+	e, ok := l.parse(rec)
+	if !ok || e.Type < utEmpty || e.Type > utShutdownTime {
+		return false
+	}
+	if !e.Time.IsZero() && (e.Time.Year() < 1980 || e.Time.After(now.AddDate(1, 0, 0))) {
+		return false
+	}
+	return e.Type != utEmpty || e.Time.IsZero()
+}
+
+// utmpString 读取以 0 结尾的字符串
+func utmpString(b []byte) (string, bool) {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	if !utf8.Valid(b) {
+		return "", false
+	}
+	for _, r := range string(b) {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+	return string(b), true
+}
+
+// utmpIP ut_addr_v6，IPv4 地址只占前 4 个字节
+func utmpIP(b []byte) string {
+	if isZero(b) {
+		return ""
+	}
+	if isZero(b[4:]) {
+		return net.IP(b[:4]).String()
+	}
+	return net.IP(b).String()
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// detectUtmpLayout 按前若干条记录中有效记录的数量选择结构，数量相同时选择文件大小能被整除的结构
+func detectUtmpLayout(head []byte, size int64) utmpLayout {
+	now := time.Now()
+	best, bestScore := utmpLayouts[0], -1<<31
+	for _, l := range utmpLayouts {
+		score := 0
+		for off := 0; off+l.size <= len(head) && off < 64*l.size; off += l.size {
+			rec := head[off : off+l.size]
+			switch {
+			case isZero(rec):
+			case !l.valid(rec, now):
+				score -= 2
+			case l.typeOf(rec) != utEmpty:
+				// 空记录在不同结构下都可能有效，只统计非空的记录
+				score += 2
+			}
+		}
+		if size > 0 && size%int64(l.size) == 0 {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best
+}
+
+// LoginLogAnomaly 登录日志中的篡改迹象
+type LoginLogAnomaly struct {
+	LogFile string `json:"log_file"`
+	Offset  int64  `json:"offset"` // 记录在文件中的偏移，文件级别的问题为 -1
+	Kind    string `json:"kind"`
+	Detail  string `json:"detail"`
+}
+
+// 篡改迹象的类型
+const (
+	anomalyMisaligned = "大小未对齐"
+	anomalyZeroed     = "记录被清零"
+	anomalyBackwards  = "时间倒序"
+	anomalyBadRecord  = "记录无效"
+	anomalyFuture     = "时间晚于当前"
+	anomalyEmptyFile  = "文件为空"
+	anomalyMissing    = "wtmp 缺少登录记录"
+)
+
+// utmpBackwardsTolerance 后一条记录的时间早于前一条超过该值时视为时间倒序，容忍写入顺序与时钟微调造成的误差
+const utmpBackwardsTolerance = time.Minute
+
+// readUtmpFile 读取 utmp/wtmp/btmp 文件，同时检查篡改迹象
+// checkZero 为 false 时不检查清零的记录，/run/utmp 中的空槽位可能为 0
+func readUtmpFile(ctx context.Context, path string, checkZero bool) ([]utmpEntry, []LoginLogAnomaly, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+
+	br := bufio.NewReaderSize(f, 64*640)
+	var r io.Reader = br
+	size := info.Size()
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("解压文件失败: %v", err)
+		}
+		defer gz.Close()
+		br = bufio.NewReaderSize(gz, 64*640)
+		r, size = br, 0
+	}
+
+	var anomalies []LoginLogAnomaly
+	flag := func(offset int64, kind, format string, args ...any) {
+		anomalies = append(anomalies, LoginLogAnomaly{LogFile: path, Offset: offset, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+	head, _ := br.Peek(64 * 640)
+	if len(head) == 0 {
+		return nil, nil, nil
+	}
+	layout := detectUtmpLayout(head, size)
+
+	var entries []utmpEntry
+	var prev utmpEntry
+	zeroStart, zeroCount := int64(-1), 0
+	now := time.Now()
+	rec := make([]byte, layout.size)
+	var offset int64
+	for ; ; offset += int64(layout.size) {
+		if len(entries)%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		n, err := io.ReadFull(r, rec)
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			flag(offset, anomalyMisaligned, "文件大小不是记录大小 %d 的整数倍，末尾有 %d 字节不完整的记录", layout.size, n)
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取文件失败: %v", err)
+		}
+
+		if isZero(rec) {
+			if zeroCount == 0 {
+				zeroStart = offset
+			}
+			zeroCount++
+			continue
+		}
+		if zeroCount > 0 && checkZero {
+			flag(zeroStart, anomalyZeroed, "连续 %d 条记录全部为 0", zeroCount)
+		}
+		zeroCount = 0
+
+		e, ok := layout.parse(rec)
+		e.File, e.Offset = path, offset
+		if !ok || e.Type < utEmpty || e.Type > utShutdownTime {
+			flag(offset, anomalyBadRecord, "记录类型 %d 或字段内容无效", e.Type)
+			continue
+		}
+		if e.Time.After(now.Add(24 * time.Hour)) {
+			flag(offset, anomalyFuture, "%s %s 的时间 %s 晚于当前时间", e.typeName(), e.User, formatLocalTime(e.Time))
+		}
+		// 修改系统时间时写入的 NEW_TIME/OLD_TIME 记录前后时间不连续是正常的
+		if !e.Time.IsZero() && !prev.Time.IsZero() && e.Time.Before(prev.Time.Add(-utmpBackwardsTolerance)) &&
+			!utmpClockChange(e.Type) && !utmpClockChange(prev.Type) {
+			flag(offset, anomalyBackwards, "%s %s 的时间 %s 早于前一条记录的 %s", e.typeName(), e.User,
+				formatLocalTime(e.Time), formatLocalTime(prev.Time))
+		}
+		if !e.Time.IsZero() {
+			prev = e
+		}
+		entries = append(entries, e)
+	}
+	if zeroCount > 0 && checkZero {
+		flag(zeroStart, anomalyZeroed, "文件末尾连续 %d 条记录全部为 0", zeroCount)
+	}
+	return entries, anomalies, nil
+}
+
+func utmpClockChange(t int) bool {
+	return t == utNewTime || t == utOldTime
+}
+
+// LoginSession 由 wtmp 还原的登录会话
+type LoginSession struct {
+	User       string `json:"user"`
+	TTY        string `json:"tty"`
+	Host       string `json:"host"`
+	IP         string `json:"ip"`
+	PID        int    `json:"pid"`
+	LoginTime  string `json:"login_time"`
+	LogoutTime string `json:"logout_time"`
+	Duration   int64  `json:"duration"` // 秒，没有登出时间时为 0
+	Status     string `json:"status"`
+	LogFile    string `json:"log_file"`
+}
+
+// 会话状态
+const (
+	sessionLoggedOut = "已登出"
+	sessionActive    = "仍在登录"
+	sessionNoLogout  = "无登出记录"
+	sessionShutdown  = "关机"
+	sessionCrash     = "异常中断"
+	sessionBoot      = "系统启动"
+)
+
+// buildLoginSessions 按 last 命令的方式把登录与登出记录配对为会话
+// active 为当前 utmp 中仍在登录的终端，最后没有登出记录的会话如果仍在其中则为仍在登录
+func buildLoginSessions(entries []utmpEntry, active map[string]bool) []LoginSession {
+	var sessions []LoginSession
+	var open = make(map[string]int) // 终端 -> 会话下标
+	boot := -1
+	login := make(map[int]time.Time)
+
+	closeSession := func(i int, t time.Time, status string) {
+		sessions[i].Status = status
+		if !t.IsZero() {
+			sessions[i].LogoutTime = formatLocalTime(t)
+			if d := t.Sub(login[i]); d > 0 {
+				sessions[i].Duration = int64(d / time.Second)
+			}
+		}
+	}
+	closeAll := func(t time.Time, status string) {
+		for line, i := range open {
+			closeSession(i, t, status)
+			delete(open, line)
+		}
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.Type == utUserProcess && e.User != "":
+			if i, ok := open[e.Line]; ok {
+				closeSession(i, time.Time{}, sessionNoLogout)
+			}
+			sessions = append(sessions, LoginSession{
+				User: e.User, TTY: e.Line, Host: e.Host, IP: e.IP, PID: e.PID,
+				LoginTime: formatLocalTime(e.Time), Status: sessionActive, LogFile: e.File,
+			})
+			open[e.Line] = len(sessions) - 1
+			login[len(sessions)-1] = e.Time
+		case e.Type == utDeadProcess:
+			if i, ok := open[e.Line]; ok {
+				closeSession(i, e.Time, sessionLoggedOut)
+				delete(open, e.Line)
+			}
+		case e.Type == utBootTime:
+			closeAll(e.Time, sessionCrash)
+			// 上一次启动没有关机记录，说明系统异常重启
+			if boot >= 0 && sessions[boot].Status == sessionBoot {
+				closeSession(boot, e.Time, sessionCrash)
+			}
+			sessions = append(sessions, LoginSession{
+				User: "reboot", TTY: "system boot", Host: e.Host,
+				LoginTime: formatLocalTime(e.Time), Status: sessionBoot, LogFile: e.File,
+			})
+			boot = len(sessions) - 1
+			login[boot] = e.Time
+		case e.Type == utShutdownTime || (e.Type == utRunLevel && e.User == "shutdown"):
+			closeAll(e.Time, sessionShutdown)
+			if boot >= 0 && sessions[boot].Status == sessionBoot {
+				closeSession(boot, e.Time, sessionShutdown)
+			}
+		}
+	}
+	for line, i := range open {
+		if !active[line] {
+			sessions[i].Status = sessionNoLogout
+		}
+	}
+	return sessions
+}
+
+// UtmpRecord btmp 中的登录失败记录
+type UtmpRecord struct {
+	Time    string `json:"time"`
+	User    string `json:"user"`
+	TTY     string `json:"tty"`
+	Host    string `json:"host"`
+	IP      string `json:"ip"`
+	PID     int    `json:"pid"`
+	LogFile string `json:"log_file"`
+}
+
+// LastlogEntry 每个用户最后一次登录的时间、终端与来源
+type LastlogEntry struct {
+	UID     int    `json:"uid"`
+	User    string `json:"user"`
+	Time    string `json:"time"`
+	TTY     string `json:"tty"`
+	Host    string `json:"host"`
+	LogFile string `json:"log_file"`
+}
+
+// LoginLogs 从二进制登录日志中解析的全部结果
+type LoginLogs struct {
+	Sessions  []LoginSession    `json:"sessions"`
+	Failed    []UtmpRecord      `json:"failed"`
+	Lastlog   []LastlogEntry    `json:"lastlog"`
+	Anomalies []LoginLogAnomaly `json:"anomalies"`
+}
+
+func (l LoginLogs) recordCount() int {
+	return len(l.Sessions) + len(l.Failed) + len(l.Lastlog) + len(l.Anomalies)
+}
+
+// wtmp/btmp 轮转后的文件名，如 wtmp.1、wtmp-20240601、btmp.1.gz
+var utmpRotatedRe = regexp.MustCompile(`^(wtmp|btmp)(\.\d+|-\d{8})?(\.gz)?$`)
+
+// utmpRotatedFiles 返回 dir 中名为 name 的日志及其轮转文件，按修改时间从旧到新排序
+func utmpRotatedFiles(dir, name string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, name+"*"))
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []logFile
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		m := utmpRotatedRe.FindStringSubmatch(filepath.Base(path))
+		if err != nil || !info.Mode().IsRegular() || m == nil || m[1] != name {
+			continue
+		}
+		files = append(files, logFile{path, info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+// loginLogPaths root 下的登录日志文件，root 为 / 时是本机，也可以是挂载的磁盘镜像
+type loginLogPaths struct {
+	wtmp, btmp []string
+	utmp       string
+	lastlog    string
+	lastlog2   string
+	passwd     string
+}
+
+func newLoginLogPaths(root string) loginLogPaths {
+	p := loginLogPaths{
+		wtmp:     utmpRotatedFiles(filepath.Join(root, "var/log"), "wtmp"),
+		btmp:     utmpRotatedFiles(filepath.Join(root, "var/log"), "btmp"),
+		lastlog:  filepath.Join(root, "var/log/lastlog"),
+		lastlog2: filepath.Join(root, "var/lib/lastlog/lastlog2.db"),
+		passwd:   filepath.Join(root, "etc/passwd"),
+	}
+	for _, utmp := range []string{"run/utmp", "var/run/utmp", "var/run/utmpx"} {
+		if info, err := os.Stat(filepath.Join(root, utmp)); err == nil && info.Mode().IsRegular() {
+			p.utmp = filepath.Join(root, utmp)
+			break
+		}
+	}
+	return p
+}
+
+// files 存在的所有文件，用于证据包
+func (p loginLogPaths) files() []string {
+	files := append(append([]string{}, p.wtmp...), p.btmp...)
+	for _, path := range []string{p.utmp, p.lastlog, p.lastlog2} {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// readLoginLogs 解析 root 下的 wtmp、btmp、utmp 与 lastlog，无法读取的文件记录错误后跳过
+func readLoginLogs(ctx context.Context, root string) (LoginLogs, error) {
+	paths := newLoginLogPaths(root)
+	logs := LoginLogs{Sessions: []LoginSession{}, Failed: []UtmpRecord{}, Lastlog: []LastlogEntry{}, Anomalies: []LoginLogAnomaly{}}
+	progress := progressFrom(ctx)
+	progress.SetTotal(len(paths.wtmp) + len(paths.btmp) + 2)
+	fail := func(path string, err error) {
+		progress.Fail(fmt.Errorf("%s: %v", path, err))
+	}
+
+	var wtmp []utmpEntry
+	for _, path := range paths.wtmp {
+		entries, anomalies, err := readUtmpFile(ctx, path, true)
+		if ctx.Err() != nil {
+			return LoginLogs{}, ctx.Err()
+		}
+		if err != nil {
+			fail(path, err)
+		}
+		wtmp = append(wtmp, entries...)
+		logs.Anomalies = append(logs.Anomalies, anomalies...)
+		progress.Step(filepath.Base(path))
+	}
+
+	// btmp 与刚轮转的 wtmp 为空是正常的，wtmp 及其轮转文件全部为空时才可能被清空
+	if len(paths.wtmp) > 0 && len(wtmp) == 0 {
+		logs.Anomalies = append(logs.Anomalies, LoginLogAnomaly{
+			LogFile: paths.wtmp[len(paths.wtmp)-1], Offset: -1, Kind: anomalyEmptyFile, Detail: "wtmp 及其轮转文件中没有任何记录，可能被清空",
+		})
+	}
+
+	active := make(map[string]bool)
+	if paths.utmp != "" {
+		entries, _, err := readUtmpFile(ctx, paths.utmp, false)
+		if err != nil {
+			fail(paths.utmp, err)
+		}
+		for _, e := range entries {
+			if e.Type == utUserProcess && e.User != "" {
+				active[e.Line] = true
+			}
+		}
+	}
+	progress.Step("utmp")
+	logs.Sessions = append(logs.Sessions, buildLoginSessions(wtmp, active)...)
+
+	for _, path := range paths.btmp {
+		entries, anomalies, err := readUtmpFile(ctx, path, true)
+		if ctx.Err() != nil {
+			return LoginLogs{}, ctx.Err()
+		}
+		if err != nil {
+			fail(path, err)
+		}
+		for _, e := range entries {
+			logs.Failed = append(logs.Failed, UtmpRecord{
+				Time: formatLocalTime(e.Time), User: e.User, TTY: e.Line, Host: e.Host, IP: e.IP, PID: e.PID, LogFile: e.File,
+			})
+		}
+		logs.Anomalies = append(logs.Anomalies, anomalies...)
+		progress.Step(filepath.Base(path))
+	}
+
+	lastlog, err := readLastlog(paths)
+	if err != nil {
+		fail(paths.lastlog, err)
+	}
+	logs.Lastlog = append(logs.Lastlog, lastlog...)
+	logs.Anomalies = append(logs.Anomalies, checkLastlogAgainstWtmp(lastlog, wtmp)...)
+	progress.Step("lastlog")
+	return logs, nil
+}
+
+// lastlogRecordSize lastlog 记录的大小：x86_64 与 32 位平台的时间为 32 位，其他 64 位平台为 64 位
+func lastlogRecordSize(size int64) int {
+	switch {
+	case size%292 == 0 && size%296 != 0:
+		return 292
+	case size%296 == 0 && size%292 != 0:
+		return 296
+	case runtime.GOARCH == "amd64" || runtime.GOARCH == "386" || runtime.GOARCH == "arm":
+		return 292
+	default:
+		return 296
+	}
+}
+
+// readLastlog 读取 lastlog，较新的发行版使用 lastlog2 的 SQLite 数据库
+// lastlog 是以 UID 为下标的稀疏文件，nfsnobody 等大 UID 会使文件的表观大小达到上百 GB，因此只按 passwd 中的用户读取
+func readLastlog(paths loginLogPaths) ([]LastlogEntry, error) {
+	var entries []LastlogEntry
+	if _, err := os.Stat(paths.lastlog2); err == nil {
+		list, err := readLastlog2(paths.lastlog2)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, list...)
+	}
+
+	f, err := os.Open(paths.lastlog)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return entries, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return entries, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	size := lastlogRecordSize(info.Size())
+	timeLen := size - 32 - 256
+	rec := make([]byte, size)
+	for _, u := range readPasswdUsers(paths.passwd) {
+		offset := int64(u.uid) * int64(size)
+		if offset+int64(size) > info.Size() {
+			continue
+		}
+		if _, err := f.ReadAt(rec, offset); err != nil {
+			continue
+		}
+		var sec int64
+		if timeLen == 4 {
+			sec = int64(int32(binary.LittleEndian.Uint32(rec)))
+		} else {
+			sec = int64(binary.LittleEndian.Uint64(rec))
+		}
+		if sec <= 0 {
+			continue
+		}
+		line, _ := utmpString(rec[timeLen : timeLen+32])
+		host, _ := utmpString(rec[timeLen+32:])
+		entries = append(entries, LastlogEntry{
+			UID: u.uid, User: u.name, Time: formatLocalTime(time.Unix(sec, 0)), TTY: line, Host: host, LogFile: paths.lastlog,
+		})
+	}
+	return entries, nil
+}
+
+// readLastlog2 读取 util-linux lastlog2 的数据库
+func readLastlog2(path string) ([]LastlogEntry, error) {
+	db, err := sql.Open("sqlite3", sqliteDSN(path, true))
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT Name, Time, COALESCE(TTY, ''), COALESCE(RemoteHost, '') FROM Lastlog2 WHERE Time > 0`)
+	if err != nil {
+		return nil, fmt.Errorf("读取 lastlog2 失败: %v", err)
+	}
+	defer rows.Close()
+	var entries []LastlogEntry
+	for rows.Next() {
+		var e LastlogEntry
+		var sec int64
+		if err := rows.Scan(&e.User, &sec, &e.TTY, &e.Host); err != nil {
+			return nil, err
+		}
+		e.UID, e.Time, e.LogFile = -1, formatLocalTime(time.Unix(sec, 0)), path
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+type passwdUser struct {
+	name string
+	uid  int
+}
+
+// readPasswdUsers 读取 passwd 中的用户名与 UID
+func readPasswdUsers(path string) []passwdUser {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var users []passwdUser
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if uid, err := strconv.Atoi(fields[2]); err == nil && uid >= 0 {
+			users = append(users, passwdUser{name: fields[0], uid: uid})
+		}
+	}
+	return users
+}
+
+// checkLastlogAgainstWtmp lastlog 中的最后一次登录在 wtmp 覆盖的时间范围内却没有对应的登录记录，说明 wtmp 中的记录可能被删除
+func checkLastlogAgainstWtmp(lastlog []LastlogEntry, wtmp []utmpEntry) []LoginLogAnomaly {
+	var first time.Time
+	logins := make(map[string][]time.Time)
+	for _, e := range wtmp {
+		if e.Time.IsZero() {
+			continue
+		}
+		if first.IsZero() || e.Time.Before(first) {
+			first = e.Time
+		}
+		if e.Type == utUserProcess {
+			logins[e.User] = append(logins[e.User], e.Time)
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+	var anomalies []LoginLogAnomaly
+	for _, l := range lastlog {
+		t, err := time.ParseInLocation(sessionTimeLayout, l.Time, time.Local)
+		if err != nil || t.Before(first) {
+			continue
+		}
+		found := false
+		for _, login := range logins[l.User] {
+			if d := login.Sub(t); d > -5*time.Second && d < 5*time.Second {
+				found = true
+				break
+			}
+		}
+		if !found {
+			anomalies = append(anomalies, LoginLogAnomaly{
+				LogFile: l.LogFile, Offset: -1, Kind: anomalyMissing,
+				Detail: fmt.Sprintf("%s 最后一次登录 %s (%s %s) 在 wtmp 中没有对应的记录，可能被删除", l.User, l.Time, l.TTY, l.Host),
+			})
+		}
+	}
+	return anomalies
+}
+
+// GetLoginLogs 解析本机的 wtmp、btmp、utmp 与 lastlog
+func (a *App) GetLoginLogs() LoginLogs {
+	logs, _ := readLoginLogs(context.Background(), "/")
+	return logs
+}
+
+// loginSessionSchema 等为二进制登录日志的数据表
+const (
+	loginSessionSchema = `CREATE TABLE IF NOT EXISTS login_session (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	user TEXT,
+	tty TEXT,
+	host TEXT,
+	ip TEXT,
+	pid INTEGER,
+	login_time DATETIME,
+	logout_time DATETIME,
+	duration INTEGER,
+	status TEXT,
+	log_file TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+	btmpRecordSchema = `CREATE TABLE IF NOT EXISTS btmp_record (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	time DATETIME,
+	user TEXT,
+	tty TEXT,
+	host TEXT,
+	ip TEXT,
+	pid INTEGER,
+	log_file TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+	lastlogEntrySchema = `CREATE TABLE IF NOT EXISTS lastlog_entry (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	uid INTEGER,
+	user TEXT,
+	time DATETIME,
+	tty TEXT,
+	host TEXT,
+	log_file TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+	loginLogAnomalySchema = `CREATE TABLE IF NOT EXISTS login_log_anomaly (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	log_file TEXT,
+	offset INTEGER,
+	kind TEXT,
+	detail TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+)
+
+// migrateLoginLogs v12: wtmp 登录会话、btmp 登录失败、lastlog 与登录日志篡改迹象表
+func migrateLoginLogs(tx *sql.Tx) error {
+	return execAll(tx, loginSessionSchema, btmpRecordSchema, lastlogEntrySchema, loginLogAnomalySchema)
+}
+
+// macOS 没有 wtmp，只能从 utmpx 读到当前的登录，因此只在 Linux 上采集，macOS 的登录记录由 login-success 读取
+func init() {
+	RegisterCollector(&sliceCollector[LoginLogs]{
+		name:      "login-sessions",
+		title:     "登录会话",
+		platforms: []string{"linux"},
+		schema:    []string{loginSessionSchema, btmpRecordSchema, lastlogEntrySchema, loginLogAnomalySchema},
+		collect: func(ctx context.Context, a *App) ([]LoginLogs, error) {
+			logs, err := readLoginLogs(ctx, "/")
+			if err != nil {
+				return nil, err
+			}
+			return []LoginLogs{logs}, nil
+		},
+		save: func(a *App, logs []LoginLogs) error {
+			for _, l := range logs {
+				if err := a.SaveLoginLogs(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		count: sumRecords[LoginLogs],
+	})
+}
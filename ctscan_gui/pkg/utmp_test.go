@@ -0,0 +1,286 @@
+package pkg
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testUtmp 按结构生成记录的参数
+type testUtmp struct {
+	typ              int
+	pid              int
+	line, user, host string
+	time             time.Time
+	ip               string
+}
+
+func testUtmpRecord(l utmpLayout, u testUtmp) []byte {
+	rec := make([]byte, l.size)
+	binary.LittleEndian.PutUint16(rec[l.typ:], uint16(u.typ))
+	binary.LittleEndian.PutUint32(rec[l.pid:], uint32(u.pid))
+	copy(rec[l.line:l.line+l.lineLen], u.line)
+	copy(rec[l.user:l.user+l.userLen], u.user)
+	copy(rec[l.host:l.host+l.hostLen], u.host)
+	if !u.time.IsZero() {
+		if l.secLen == 4 {
+			binary.LittleEndian.PutUint32(rec[l.sec:], uint32(u.time.Unix()))
+		} else {
+			binary.LittleEndian.PutUint64(rec[l.sec:], uint64(u.time.Unix()))
+		}
+	}
+	if ip := net.ParseIP(u.ip); ip != nil && l.addr >= 0 {
+		if v4 := ip.To4(); v4 != nil {
+			copy(rec[l.addr:], v4)
+		} else {
+			copy(rec[l.addr:], ip)
+		}
+	}
+	return rec
+}
+
+func utmpLayoutByName(t *testing.T, name string) utmpLayout {
+	t.Helper()
+	for _, l := range utmpLayouts {
+		if l.name == name {
+			return l
+		}
+	}
+	t.Fatalf("没有结构 %s", name)
+	return utmpLayout{}
+}
+
+func writeUtmpFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wtmp")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadUtmpFileLayouts(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	records := []testUtmp{
+		{typ: utBootTime, line: "~", user: "reboot", host: "6.1.0-21-amd64", time: base},
+		{typ: utUserProcess, pid: 1234, line: "pts/0", user: "alice", host: "10.0.0.5", time: base.Add(time.Minute), ip: "10.0.0.5"},
+		{typ: utUserProcess, pid: 1300, line: "pts/1", user: "bob", host: "fe80::1", time: base.Add(2 * time.Minute), ip: "fe80::1"},
+		{typ: utDeadProcess, pid: 1234, line: "pts/0", time: base.Add(time.Hour)},
+	}
+	for _, name := range []string{"linux", "linux64"} {
+		t.Run(name, func(t *testing.T) {
+			layout := utmpLayoutByName(t, name)
+			var data []byte
+			for _, r := range records {
+				data = append(data, testUtmpRecord(layout, r)...)
+			}
+			if got := detectUtmpLayout(data, int64(len(data))); got.name != name {
+				t.Fatalf("识别的结构为 %s，应为 %s", got.name, name)
+			}
+
+			path := writeUtmpFile(t, data)
+			entries, anomalies, err := readUtmpFile(context.Background(), path, true)
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if len(anomalies) != 0 {
+				t.Errorf("不应有篡改迹象: %+v", anomalies)
+			}
+			if len(entries) != len(records) {
+				t.Fatalf("解析出 %d 条记录，应为 %d 条", len(entries), len(records))
+			}
+			for i, e := range entries {
+				r := records[i]
+				if e.Type != r.typ || e.PID != r.pid || e.Line != r.line || e.User != r.user || e.Host != r.host || e.IP != r.ip {
+					t.Errorf("第 %d 条记录 %+v", i+1, e)
+				}
+				if !e.Time.Equal(r.time) || e.Offset != int64(i*layout.size) || e.File != path {
+					t.Errorf("第 %d 条记录的时间 %v 偏移 %d", i+1, e.Time, e.Offset)
+				}
+			}
+		})
+	}
+}
+
+func TestReadUtmpFileAnomalies(t *testing.T) {
+	layout := utmpLayoutByName(t, "linux")
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	login := func(user string, t time.Time) []byte {
+		return testUtmpRecord(layout, testUtmp{typ: utUserProcess, pid: 1, line: "pts/0", user: user, time: t})
+	}
+	join := func(recs ...[]byte) []byte {
+		var data []byte
+		for _, r := range recs {
+			data = append(data, r...)
+		}
+		return data
+	}
+	zero := make([]byte, layout.size)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		checkZero   bool
+		wantEntries int
+		wantKinds   []string
+	}{
+		{
+			name:        "中间的记录被清零",
+			data:        join(login("a", base), zero, zero, login("b", base.Add(time.Hour))),
+			checkZero:   true,
+			wantEntries: 2,
+			wantKinds:   []string{anomalyZeroed},
+		},
+		{
+			name:        "utmp 中的空槽位不检查清零",
+			data:        join(login("a", base), zero, login("b", base.Add(time.Hour)), zero),
+			wantEntries: 2,
+		},
+		{
+			name:        "文件末尾被清零",
+			data:        join(login("a", base), zero),
+			checkZero:   true,
+			wantEntries: 1,
+			wantKinds:   []string{anomalyZeroed},
+		},
+		{
+			name:        "时间倒序",
+			data:        join(login("a", base.Add(time.Hour)), login("b", base)),
+			wantEntries: 2,
+			wantKinds:   []string{anomalyBackwards},
+		},
+		{
+			name:        "容忍范围内的时间倒序",
+			data:        join(login("a", base.Add(30*time.Second)), login("b", base)),
+			wantEntries: 2,
+		},
+		{
+			name: "修改系统时间的记录前后不连续",
+			data: join(
+				login("a", base.Add(time.Hour)),
+				testUtmpRecord(layout, testUtmp{typ: utOldTime, line: "|", time: base.Add(time.Hour)}),
+				testUtmpRecord(layout, testUtmp{typ: utNewTime, line: "{", time: base}),
+				login("b", base.Add(time.Minute)),
+			),
+			wantEntries: 4,
+		},
+		{
+			name:        "末尾有不完整的记录",
+			data:        join(login("a", base), login("b", base.Add(time.Minute)))[:2*layout.size-100],
+			wantEntries: 1,
+			wantKinds:   []string{anomalyMisaligned},
+		},
+		{
+			name:        "时间晚于当前",
+			data:        join(login("a", base), login("b", time.Now().Add(72*time.Hour))),
+			wantEntries: 2,
+			wantKinds:   []string{anomalyFuture},
+		},
+		{
+			name: "记录类型无效",
+			data: join(
+				login("a", base),
+				testUtmpRecord(layout, testUtmp{typ: 99, line: "pts/0", user: "x", time: base.Add(time.Minute)}),
+				login("b", base.Add(2*time.Minute)),
+			),
+			wantEntries: 2,
+			wantKinds:   []string{anomalyBadRecord},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, anomalies, err := readUtmpFile(context.Background(), writeUtmpFile(t, tt.data), tt.checkZero)
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if len(entries) != tt.wantEntries {
+				t.Errorf("解析出 %d 条记录，应为 %d 条", len(entries), tt.wantEntries)
+			}
+			var kinds []string
+			for _, a := range anomalies {
+				kinds = append(kinds, a.Kind)
+			}
+			if len(kinds) != len(tt.wantKinds) {
+				t.Fatalf("篡改迹象 %v，应为 %v", anomalies, tt.wantKinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.wantKinds[i] {
+					t.Fatalf("篡改迹象 %v，应为 %v", kinds, tt.wantKinds)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildLoginSessions(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	login := func(user, line string, minutes int) utmpEntry {
+		return utmpEntry{Type: utUserProcess, User: user, Line: line, Time: at(minutes)}
+	}
+	logout := func(line string, minutes int) utmpEntry {
+		return utmpEntry{Type: utDeadProcess, Line: line, Time: at(minutes)}
+	}
+	boot := func(minutes int) utmpEntry {
+		return utmpEntry{Type: utBootTime, User: "reboot", Line: "~", Time: at(minutes)}
+	}
+	shutdown := func(minutes int) utmpEntry {
+		return utmpEntry{Type: utRunLevel, User: "shutdown", Line: "~~", Time: at(minutes)}
+	}
+
+	type want struct {
+		user, status string
+		duration     int64
+	}
+	tests := []struct {
+		name    string
+		entries []utmpEntry
+		active  map[string]bool
+		want    []want
+	}{
+		{
+			name:    "登录与登出配对",
+			entries: []utmpEntry{login("alice", "pts/0", 0), logout("pts/0", 30)},
+			want:    []want{{"alice", sessionLoggedOut, 1800}},
+		},
+		{
+			name:    "同一终端再次登录时前一个会话没有登出",
+			entries: []utmpEntry{login("alice", "pts/0", 0), login("bob", "pts/0", 5), logout("pts/0", 10)},
+			want:    []want{{"alice", sessionNoLogout, 0}, {"bob", sessionLoggedOut, 300}},
+		},
+		{
+			name:    "仍在 utmp 中的会话",
+			entries: []utmpEntry{login("alice", "pts/0", 0), login("bob", "pts/1", 1)},
+			active:  map[string]bool{"pts/1": true},
+			want:    []want{{"alice", sessionNoLogout, 0}, {"bob", sessionActive, 0}},
+		},
+		{
+			name:    "关机结束会话与启动记录",
+			entries: []utmpEntry{boot(0), login("alice", "tty1", 1), shutdown(61)},
+			want:    []want{{"reboot", sessionShutdown, 3660}, {"alice", sessionShutdown, 3600}},
+		},
+		{
+			name:    "没有关机记录的再次启动",
+			entries: []utmpEntry{boot(0), login("alice", "tty1", 1), boot(11)},
+			want:    []want{{"reboot", sessionCrash, 660}, {"alice", sessionCrash, 600}, {"reboot", sessionBoot, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := buildLoginSessions(tt.entries, tt.active)
+			if len(sessions) != len(tt.want) {
+				t.Fatalf("得到 %d 个会话 %+v，应为 %d 个", len(sessions), sessions, len(tt.want))
+			}
+			for i, s := range sessions {
+				w := tt.want[i]
+				if s.User != w.user || s.Status != w.status || s.Duration != w.duration {
+					t.Errorf("第 %d 个会话 %s/%s/%d，应为 %s/%s/%d", i+1, s.User, s.Status, s.Duration, w.user, w.status, w.duration)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,873 @@
+package pkg
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Web 访问日志：nginx、Apache 的 combined/common 格式，Tomcat 默认的 common 格式，以及 IIS 的 W3C 扩展格式，
+// 包括轮转与 gzip 压缩的文件。日志位置由常见的默认目录与 nginx/Apache 配置中的 access_log、CustomLog 确定。
+
+// 访问日志格式
+const (
+	webLogCombined = "combined"
+	webLogCommon   = "common"
+	webLogW3C      = "w3c"
+)
+
+// webLogPatterns 常见的访问日志位置，相对于根目录
+var webLogPatterns = []string{
+	"var/log/nginx/*access*",
+	"var/log/apache2/*access*",
+	"var/log/httpd/*access*",
+	"usr/local/nginx/logs/*access*",
+	"usr/local/openresty/nginx/logs/*access*",
+	"usr/local/apache2/logs/*access*",
+	"www/wwwlogs/*.log*",
+	"var/log/tomcat*/localhost_access_log*",
+	"opt/tomcat*/logs/localhost_access_log*",
+	"usr/local/tomcat*/logs/localhost_access_log*",
+	"usr/share/tomcat*/logs/localhost_access_log*",
+	// macOS Homebrew 与系统自带的 Apache
+	"usr/local/var/log/nginx/*access*",
+	"opt/homebrew/var/log/nginx/*access*",
+	"usr/local/var/log/httpd/*access*",
+	"opt/homebrew/var/log/httpd/*access*",
+	"private/var/log/apache2/*access*",
+	// Windows IIS、Tomcat 与常见的集成环境
+	"inetpub/logs/LogFiles/*/*.log",
+	"Windows/System32/LogFiles/W3SVC*/*.log",
+	"Program Files/Apache Software Foundation/Tomcat*/logs/localhost_access_log*",
+	"nginx*/logs/*access*",
+	"xampp/apache/logs/*access*",
+}
+
+// webServerConfigPatterns nginx 与 Apache 的配置文件
+var webServerConfigPatterns = []string{
+	"etc/nginx/nginx.conf",
+	"etc/nginx/conf.d/*.conf",
+	"etc/nginx/sites-enabled/*",
+	"usr/local/nginx/conf/nginx.conf",
+	"usr/local/nginx/conf/vhost/*.conf",
+	"usr/local/openresty/nginx/conf/nginx.conf",
+	"www/server/panel/vhost/nginx/*.conf",
+	"etc/apache2/apache2.conf",
+	"etc/apache2/sites-enabled/*",
+	"etc/httpd/conf/httpd.conf",
+	"etc/httpd/conf.d/*.conf",
+	"usr/local/apache2/conf/httpd.conf",
+	"usr/local/etc/nginx/nginx.conf",
+	"opt/homebrew/etc/nginx/nginx.conf",
+	"opt/homebrew/etc/nginx/servers/*",
+	"usr/local/etc/httpd/httpd.conf",
+	"private/etc/apache2/httpd.conf",
+}
+
+// webLogConfigRe 配置中的访问日志路径，如 "access_log /var/log/nginx/a.log main;"、"CustomLog ${APACHE_LOG_DIR}/access.log combined"
+var webLogConfigRe = regexp.MustCompile(`(?im)^\s*(?:access_log|CustomLog)\s+"?([^"\s;]+)`)
+
+// webLogRotatedRe 轮转后的文件名后缀，如 .1、-20240601、.2024-06-01、.gz
+var webLogRotatedRe = regexp.MustCompile(`^(\.\d+|-\d{8}|[-.]\d{4}-\d{2}-\d{2})?(\.log|\.txt)?(\.gz)?$`)
+
+// webLogCombinedRe combined 与 common 格式，后者没有 Referer 与 User-Agent
+var webLogCombinedRe = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}|-) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+const webLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// 分析结果的数量限制
+const (
+	webLogMaxFindings = 20000
+	webLogTopTalkers  = 20
+	webLogMaxSample   = 500
+	webLogMaxPaths    = 10000 // 每个 IP 统计的不同路径数
+)
+
+// webScriptExts 服务端脚本扩展名，用于识别新出现的脚本与 webshell
+var webScriptExts = map[string]bool{
+	".php": true, ".php3": true, ".php4": true, ".php5": true, ".phtml": true, ".pht": true,
+	".jsp": true, ".jspx": true, ".jspf": true, ".asp": true, ".aspx": true, ".ashx": true, ".asmx": true,
+	".asa": true, ".cer": true, ".cdx": true, ".cfm": true, ".cgi": true, ".pl": true, ".py": true,
+}
+
+// webLogRule 请求特征规则，ua 为 true 时匹配 User-Agent，否则匹配解码后的 URI
+type webLogRule struct {
+	category string
+	name     string
+	level    string
+	ua       bool
+	re       *regexp.Regexp
+}
+
+// 特征分类
+const (
+	webCategoryScanner   = "扫描器"
+	webCategorySQLi      = "SQL注入"
+	webCategoryXSS       = "XSS"
+	webCategoryTraversal = "路径遍历"
+	webCategoryCommand   = "命令注入"
+	webCategoryNewScript = "新脚本文件"
+	webCategoryWebshell  = "疑似Webshell"
+	webCategoryTalker    = "高频访问"
+)
+
+var webLogRules = []webLogRule{
+	{webCategoryScanner, "扫描器UA", "medium", true, regexp.MustCompile(`(?i)sqlmap|nikto|nmap|masscan|zgrab|nuclei|acunetix|wvs|netsparker|appscan|dirbuster|dirsearch|gobuster|feroxbuster|ffuf|wfuzz|burp|w3af|openvas|nessus|whatweb|wpscan|jaeles|xray|goby|fscan|zmeu|morfeus|commix|webinspect|arachni|skipfish|havij|pangolin|jorgee|l9explore|censysinspect|httpx`)},
+	{webCategoryScanner, "脚本工具UA", "low", true, regexp.MustCompile(`(?i)^(python-requests|python-urllib|go-http-client|curl/|wget/|libwww-perl|okhttp|java/|aiohttp|httpclient|winhttp)`)},
+	{webCategorySQLi, "UNION查询", "high", false, regexp.MustCompile(`(?i)union(\s|/\*.*?\*/|\+)+(all(\s|/\*.*?\*/|\+)+)?select`)},
+	{webCategorySQLi, "延时/报错注入", "high", false, regexp.MustCompile(`(?i)\b(sleep|benchmark|pg_sleep|extractvalue|updatexml|load_file)\s*\(|waitfor\s+delay|into\s+(out|dump)file`)},
+	{webCategorySQLi, "SQL语句", "high", false, regexp.MustCompile(`(?i)information_schema|mysql\.user|sys\.(objects|columns)|\bselect\b.+\bfrom\b|'\s*(and|or)\s+'?\d+'?\s*=\s*'?\d+|\b(and|or)\s+\d+\s*=\s*\d+(\s|--|#|$)`)},
+	{webCategoryXSS, "XSS", "medium", false, regexp.MustCompile(`(?i)<\s*/?\s*script|javascript\s*:|<[^>]*\bon(error|load|mouseover|focus|click)\s*=|<\s*(svg|iframe|img|body)\b|\b(alert|prompt|confirm)\s*\(|document\.(cookie|domain)|string\.fromcharcode`)},
+	{webCategoryTraversal, "目录穿越", "high", false, regexp.MustCompile(`\.\.[/\\]`)},
+	{webCategoryTraversal, "敏感文件探测", "medium", false, regexp.MustCompile(`(?i)/etc/(passwd|shadow|hosts)|win\.ini|boot\.ini|web-inf/web\.xml|/\.git/(config|head)|/\.svn/|/\.env\b|/\.htaccess|/proc/self/`)},
+	{webCategoryCommand, "命令注入", "high", false, regexp.MustCompile("(?i)(?:[;|`]|&&)\\s*(cat|id|whoami|uname|ls|wget|curl|nc|ncat|bash|sh|ping|powershell|cmd|echo|chmod|rm|net|ipconfig|ifconfig)(\\s|$|[;|&`])|\\$\\([^)]+\\)|/bin/(ba)?sh|cmd(\\.exe)?\\s*/c|powershell(\\.exe)?\\s+-")},
+	{webCategoryCommand, "代码执行", "high", false, regexp.MustCompile(`(?i)\b(system|exec|passthru|shell_exec|popen|proc_open|assert|eval|call_user_func)\s*\(|%\{\s*\(?#|\bognl\b|class\.module\.classloader|invokefunction|\\think\\app|allow_url_include|auto_prepend_file|php://input|data://text`)},
+	{webCategoryCommand, "JNDI/Shellshock", "high", false, regexp.MustCompile(`(?i)\$\{\s*(jndi|\$\{|lower|upper|env):|\(\)\s*\{\s*:;?\s*\}`)},
+	{webCategoryCommand, "JNDI/Shellshock", "high", true, regexp.MustCompile(`(?i)\$\{\s*(jndi|\$\{|lower|upper|env):|\(\)\s*\{\s*:;?\s*\}`)},
+}
+
+// webRequest 解析后的一条访问记录
+type webRequest struct {
+	Time      time.Time
+	IP        string
+	User      string
+	Method    string
+	Path      string
+	Query     string
+	Status    int
+	Size      int64
+	Referer   string
+	UserAgent string
+	Raw       string // 请求行
+}
+
+// WebLogFile 读取的访问日志文件
+type WebLogFile struct {
+	Path      string `json:"path"`
+	Format    string `json:"format"`
+	Requests  int    `json:"requests"`
+	Skipped   int    `json:"skipped"` // 无法解析的行
+	FirstTime string `json:"first_time"`
+	LastTime  string `json:"last_time"`
+}
+
+// WebLogFinding 同一来源对同一路径命中同一规则的请求合并为一条
+type WebLogFinding struct {
+	Level     string `json:"level"`
+	Category  string `json:"category"`
+	Rule      string `json:"rule"`
+	IP        string `json:"ip"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Sample    string `json:"sample"` // 第一次命中的请求
+	Match     string `json:"match"`  // 命中的内容
+	Status    int    `json:"status"`
+	OKCount   int    `json:"ok_count"` // 返回 2xx 的次数
+	Count     int    `json:"count"`
+	FirstTime string `json:"first_time"`
+	LastTime  string `json:"last_time"`
+	UserAgent string `json:"user_agent"`
+	LogFile   string `json:"log_file"`
+}
+
+// WebLogTalker 请求数最多的来源 IP
+type WebLogTalker struct {
+	IP        string `json:"ip"`
+	Requests  int    `json:"requests"`
+	Errors    int    `json:"errors"` // 4xx 与 5xx
+	Posts     int    `json:"posts"`
+	Paths     int    `json:"paths"`
+	FirstTime string `json:"first_time"`
+	LastTime  string `json:"last_time"`
+	UserAgent string `json:"user_agent"`
+}
+
+// WebLogAnalysis 访问日志的分析结果
+type WebLogAnalysis struct {
+	Files     []WebLogFile    `json:"files"`
+	Findings  []WebLogFinding `json:"findings"`
+	Talkers   []WebLogTalker  `json:"talkers"`
+	Requests  int             `json:"requests"`
+	Truncated bool            `json:"truncated"` // 命中过多，只保留了前 webLogMaxFindings 条
+}
+
+func (r WebLogAnalysis) recordCount() int {
+	return len(r.Findings) + len(r.Talkers)
+}
+
+// webConfigLogPaths 从 nginx/Apache 配置中读取访问日志路径，相对路径按配置目录的上一级补全
+func webConfigLogPaths(root string) []string {
+	var paths []string
+	for _, config := range webServerConfigFiles(root) {
+		data, err := os.ReadFile(config)
+		if err != nil {
+			continue
+		}
+		for _, m := range webLogConfigRe.FindAllStringSubmatch(string(data), -1) {
+			p := strings.ReplaceAll(m[1], "${APACHE_LOG_DIR}", "/var/log/apache2")
+			if p == "off" || strings.ContainsAny(p, "$|") || strings.HasPrefix(p, "syslog:") {
+				continue
+			}
+			if filepath.IsAbs(p) {
+				p = filepath.Join(root, p)
+			} else {
+				p = filepath.Join(filepath.Dir(filepath.Dir(config)), p)
+			}
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// webServerConfigFiles root 下存在的 nginx 与 Apache 配置文件
+func webServerConfigFiles(root string) []string {
+	var files []string
+	for _, pattern := range webServerConfigPatterns {
+		matches, _ := filepath.Glob(filepath.Join(root, pattern))
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.Mode().IsRegular() {
+				files = append(files, m)
+			}
+		}
+	}
+	return files
+}
+
+// webLogRoot 本机的根目录，Windows 上为系统盘
+func webLogRoot() string {
+	if runtime.GOOS == "windows" {
+		return firstNonEmpty(os.Getenv("SystemDrive"), "C:") + `\`
+	}
+	return "/"
+}
+
+// webLogFiles 按修改时间从旧到新返回 root 下的访问日志及其轮转文件
+func webLogFiles(root string) []string {
+	candidates := make(map[string]bool)
+	for _, pattern := range webLogPatterns {
+		matches, _ := filepath.Glob(filepath.Join(root, pattern))
+		for _, m := range matches {
+			candidates[m] = true
+		}
+	}
+	// 配置中的日志及其轮转文件
+	for _, p := range webConfigLogPaths(root) {
+		matches, _ := filepath.Glob(p + "*")
+		for _, m := range matches {
+			if webLogRotatedRe.MatchString(strings.TrimPrefix(m, p)) {
+				candidates[m] = true
+			}
+		}
+	}
+
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []logFile
+	seen := make(map[string]bool)
+	for path := range candidates {
+		name := strings.ToLower(filepath.Base(path))
+		if strings.Contains(name, "error") || strings.HasSuffix(name, ".bz2") || strings.HasSuffix(name, ".xz") || strings.HasSuffix(name, ".zst") {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// 同一文件可能通过符号链接出现多次，如 /etc/httpd/logs 指向 /var/log/httpd
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			resolved = path
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		files = append(files, logFile{path, info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.Before(files[j].modTime)
+		}
+		return files[i].path < files[j].path
+	})
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+// splitWebRequestLine 拆分请求行 "GET /a?b=1 HTTP/1.1"，无法识别的请求行(如 TLS 握手)整体作为路径
+func splitWebRequestLine(line string) (method, path, query string) {
+	parts := strings.Fields(line)
+	target := line
+	if len(parts) >= 2 && len(parts) <= 3 {
+		method, target = parts[0], parts[1]
+	}
+	if i := strings.IndexByte(target, '?'); i >= 0 {
+		return method, target[:i], target[i+1:]
+	}
+	return method, target, ""
+}
+
+// unescapeWebLogField 还原 nginx 对引号内特殊字符的转义，如 \x22、\"
+func unescapeWebLogField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	if v, err := strconv.Unquote(`"` + s + `"`); err == nil {
+		return v
+	}
+	return s
+}
+
+// parseCombinedLine 解析 combined/common 格式的一行
+func parseCombinedLine(line string) (webRequest, string, bool) {
+	m := webLogCombinedRe.FindStringSubmatch(line)
+	if m == nil {
+		return webRequest{}, "", false
+	}
+	t, err := time.Parse(webLogTimeLayout, m[3])
+	if err != nil {
+		return webRequest{}, "", false
+	}
+	r := webRequest{Time: t, IP: m[1], User: strings.Trim(m[2], "-"), Raw: unescapeWebLogField(m[4])}
+	r.Method, r.Path, r.Query = splitWebRequestLine(r.Raw)
+	r.Status, _ = strconv.Atoi(m[5])
+	r.Size, _ = strconv.ParseInt(m[6], 10, 64)
+	format := webLogCommon
+	if m[7] != "" || m[8] != "" {
+		format = webLogCombined
+		r.Referer, r.UserAgent = unescapeWebLogField(m[7]), unescapeWebLogField(m[8])
+	}
+	return r, format, true
+}
+
+// w3cFields IIS W3C 日志中由 #Fields 指定的列
+type w3cFields map[string]int
+
+func (f w3cFields) get(values []string, name string) string {
+	i, ok := f[name]
+	if !ok || i >= len(values) || values[i] == "-" {
+		return ""
+	}
+	return values[i]
+}
+
+// parseW3CLine 解析 IIS W3C 格式的一行，时间为 UTC，User-Agent 中的空格记录为 +
+func parseW3CLine(fields w3cFields, line string) (webRequest, bool) {
+	values := strings.Split(line, " ")
+	t, err := time.Parse("2006-01-02 15:04:05", fields.get(values, "date")+" "+fields.get(values, "time"))
+	if err != nil {
+		return webRequest{}, false
+	}
+	r := webRequest{
+		Time: t, IP: fields.get(values, "c-ip"), User: fields.get(values, "cs-username"),
+		Method: fields.get(values, "cs-method"), Path: fields.get(values, "cs-uri-stem"), Query: fields.get(values, "cs-uri-query"),
+		Referer: fields.get(values, "cs(referer)"), UserAgent: strings.ReplaceAll(fields.get(values, "cs(user-agent)"), "+", " "),
+	}
+	r.Status, _ = strconv.Atoi(fields.get(values, "sc-status"))
+	r.Size, _ = strconv.ParseInt(fields.get(values, "sc-bytes"), 10, 64)
+	r.Raw = r.Method + " " + r.Path
+	if r.Query != "" {
+		r.Raw += "?" + r.Query
+	}
+	return r, true
+}
+
+// maxWebLogLine 单行日志保留的最大长度，攻击载荷可能使一行非常长，超出的部分丢弃后继续读取后面的记录
+const maxWebLogLine = 1024 * 1024
+
+// readWebLogLine 读取一行，不包括换行符，超过 maxWebLogLine 的部分被截断
+func readWebLogLine(br *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			if len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+		if room := maxWebLogLine - len(line); room > 0 {
+			line = append(line, chunk[:min(len(chunk), room)]...)
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// readWebLogFile 读取一个访问日志文件，格式按内容识别，gzip 压缩的文件按文件头识别
+func readWebLogFile(ctx context.Context, path string, fn func(r webRequest)) (WebLogFile, error) {
+	file := WebLogFile{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return file, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return file, fmt.Errorf("解压文件失败: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var first, last time.Time
+	var w3c w3cFields
+	lr := bufio.NewReader(r)
+	for n := 0; ; n++ {
+		if n%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return file, err
+			}
+		}
+		raw, err := readWebLogLine(lr)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return file, fmt.Errorf("读取文件失败: %v", err)
+		}
+		line := strings.TrimRight(raw, "\r")
+		if line == "" {
+			continue
+		}
+		// W3C 日志的 #Fields 可能在文件中多次出现，以最近一次为准
+		if strings.HasPrefix(line, "#") {
+			if rest, ok := strings.CutPrefix(line, "#Fields:"); ok {
+				w3c = make(w3cFields)
+				for i, name := range strings.Fields(rest) {
+					w3c[strings.ToLower(name)] = i
+				}
+				file.Format = webLogW3C
+			}
+			continue
+		}
+		var req webRequest
+		var ok bool
+		if w3c != nil {
+			req, ok = parseW3CLine(w3c, line)
+		} else {
+			var format string
+			if req, format, ok = parseCombinedLine(line); ok && file.Format != webLogCombined {
+				file.Format = format
+			}
+		}
+		if !ok {
+			file.Skipped++
+			continue
+		}
+		file.Requests++
+		if first.IsZero() || req.Time.Before(first) {
+			first = req.Time
+		}
+		if req.Time.After(last) {
+			last = req.Time
+		}
+		fn(req)
+	}
+	if !first.IsZero() {
+		file.FirstTime, file.LastTime = formatLocalTime(first), formatLocalTime(last)
+	}
+	return file, nil
+}
+
+// webDecode URL 解码两次以还原双重编码的攻击载荷，非法的编码保持原样
+func webDecode(s string) string {
+	for i := 0; i < 2 && strings.ContainsAny(s, "%+"); i++ {
+		d, err := url.QueryUnescape(s)
+		if err != nil {
+			d = webLenientUnescape(s)
+		}
+		if d == s {
+			break
+		}
+		s = d
+	}
+	return s
+}
+
+// webLenientUnescape 只解码合法的 %XX，其余字符保留
+func webLenientUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		if s[i] == '+' {
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// webScriptStats 对一个脚本路径的访问统计，用于识别新出现的脚本与只接受 POST 的 webshell
+type webScriptStats struct {
+	path      string
+	first     time.Time
+	firstOK   time.Time // 第一次返回 2xx 的时间
+	last      time.Time
+	notFound  time.Time // 第一次返回 404 的时间，早于 firstOK 说明文件是之后出现的
+	gets      int
+	posts     int
+	ok        int
+	total     int
+	ips       []string // 最多记录 4 个，超过 3 个来源时不再视为 webshell
+	userAgent string
+	status    int
+	file      string
+}
+
+type webTalkerStats struct {
+	WebLogTalker
+	first, last time.Time
+	paths       map[string]bool
+}
+
+// webLogAnalyzer 逐条分析访问记录
+type webLogAnalyzer struct {
+	result   WebLogAnalysis
+	findings map[string]*WebLogFinding
+	order    []string
+	firstOf  map[string]time.Time // 命中记录的首次与末次时间，用于比较
+	lastOf   map[string]time.Time
+	scripts  map[string]*webScriptStats
+	talkers  map[string]*webTalkerStats
+	first    time.Time
+}
+
+func newWebLogAnalyzer() *webLogAnalyzer {
+	return &webLogAnalyzer{
+		findings: make(map[string]*WebLogFinding),
+		firstOf:  make(map[string]time.Time),
+		lastOf:   make(map[string]time.Time),
+		scripts:  make(map[string]*webScriptStats),
+		talkers:  make(map[string]*webTalkerStats),
+	}
+}
+
+// addFinding 合并同一来源、路径与规则的命中
+func (a *webLogAnalyzer) addFinding(rule webLogRule, r webRequest, match, file string) {
+	key := rule.category + "\x00" + rule.name + "\x00" + r.IP + "\x00" + r.Path
+	if rule.ua {
+		key = rule.category + "\x00" + rule.name + "\x00" + r.IP + "\x00" + r.UserAgent
+	}
+	f, ok := a.findings[key]
+	if !ok {
+		if len(a.findings) >= webLogMaxFindings {
+			a.result.Truncated = true
+			return
+		}
+		f = &WebLogFinding{
+			Level: rule.level, Category: rule.category, Rule: rule.name, IP: r.IP, Method: r.Method, Path: r.Path,
+			Sample: truncateRunes(r.Raw, webLogMaxSample), Match: truncateRunes(match, 200), Status: r.Status,
+			UserAgent: r.UserAgent, LogFile: file,
+		}
+		a.findings[key] = f
+		a.order = append(a.order, key)
+	}
+	f.Count++
+	if r.Status >= 200 && r.Status < 300 {
+		f.OKCount++
+	}
+	if first, ok := a.firstOf[key]; !ok || r.Time.Before(first) {
+		a.firstOf[key] = r.Time
+	}
+	if r.Time.After(a.lastOf[key]) {
+		a.lastOf[key] = r.Time
+	}
+}
+
+func (a *webLogAnalyzer) add(r webRequest, file string) {
+	a.result.Requests++
+	if a.first.IsZero() || r.Time.Before(a.first) {
+		a.first = r.Time
+	}
+
+	uri := strings.ToLower(webDecode(r.Path + "?" + r.Query))
+	matched := make(map[string]bool)
+	for _, rule := range webLogRules {
+		if matched[rule.category] {
+			continue
+		}
+		target := uri
+		if rule.ua {
+			target = r.UserAgent
+		}
+		if m := rule.re.FindString(target); m != "" {
+			matched[rule.category] = true
+			a.addFinding(rule, r, m, file)
+		}
+	}
+
+	t := a.talkers[r.IP]
+	if t == nil {
+		t = &webTalkerStats{WebLogTalker: WebLogTalker{IP: r.IP}, first: r.Time, paths: make(map[string]bool)}
+		a.talkers[r.IP] = t
+	}
+	t.Requests++
+	if r.Status >= 400 {
+		t.Errors++
+	}
+	if r.Method == "POST" {
+		t.Posts++
+	}
+	if len(t.paths) < webLogMaxPaths {
+		t.paths[r.Path] = true
+	}
+	if r.Time.Before(t.first) {
+		t.first = r.Time
+	}
+	if r.Time.After(t.last) {
+		t.last = r.Time
+	}
+	if r.UserAgent != "" {
+		t.UserAgent = r.UserAgent
+	}
+
+	if !webScriptExts[strings.ToLower(path.Ext(r.Path))] {
+		return
+	}
+	s := a.scripts[r.Path]
+	if s == nil {
+		s = &webScriptStats{path: r.Path, first: r.Time, file: file}
+		a.scripts[r.Path] = s
+	}
+	s.total++
+	if r.Time.Before(s.first) {
+		s.first = r.Time
+	}
+	if r.Time.After(s.last) {
+		s.last = r.Time
+	}
+	switch r.Method {
+	case "GET", "HEAD":
+		s.gets++
+	case "POST":
+		s.posts++
+	}
+	switch {
+	case r.Status >= 200 && r.Status < 300:
+		s.ok++
+		if s.firstOK.IsZero() || r.Time.Before(s.firstOK) {
+			s.firstOK, s.userAgent, s.status = r.Time, r.UserAgent, r.Status
+		}
+	case r.Status == 404:
+		if s.notFound.IsZero() || r.Time.Before(s.notFound) {
+			s.notFound = r.Time
+		}
+	}
+	if len(s.ips) <= 3 && !containsString(s.ips, r.IP) {
+		s.ips = append(s.ips, r.IP)
+	}
+}
+
+// finish 汇总脚本访问与来源统计
+func (a *webLogAnalyzer) finish() WebLogAnalysis {
+	for _, s := range a.scripts {
+		if s.ok == 0 || len(s.ips) > 3 {
+			continue
+		}
+		ips := strings.Join(s.ips, ",")
+		rule := webLogRule{}
+		detail := ""
+		switch {
+		// webshell 管理工具只用 POST 传递命令，正常页面很少只有 POST 访问
+		case s.posts >= 3 && s.gets == 0:
+			rule = webLogRule{category: webCategoryWebshell, name: "仅POST访问的脚本", level: "high"}
+			detail = fmt.Sprintf("POST %d 次，没有 GET 请求，来源 %s", s.posts, ips)
+		case !s.notFound.IsZero() && s.notFound.Before(s.firstOK):
+			rule = webLogRule{category: webCategoryNewScript, name: "先404后200", level: "high"}
+			detail = fmt.Sprintf("%s 之前返回 404，之后开始返回 2xx，文件可能是新上传的", formatLocalTime(s.firstOK))
+		// 日志开始一天之后才出现并且只有少数来源访问的脚本
+		case s.firstOK.Sub(a.first) >= 24*time.Hour && s.first.Equal(s.firstOK):
+			rule = webLogRule{category: webCategoryNewScript, name: "新出现的脚本", level: "medium"}
+			detail = fmt.Sprintf("%s 第一次被访问，来源 %s", formatLocalTime(s.first), ips)
+		default:
+			continue
+		}
+		key := rule.category + "\x00" + s.path
+		a.findings[key] = &WebLogFinding{
+			Level: rule.level, Category: rule.category, Rule: rule.name, IP: ips, Path: s.path,
+			Sample: s.path, Match: detail, Status: s.status, OKCount: s.ok, Count: s.total, UserAgent: s.userAgent, LogFile: s.file,
+		}
+		a.firstOf[key], a.lastOf[key] = s.first, s.last
+		a.order = append(a.order, key)
+	}
+
+	talkers := make([]*webTalkerStats, 0, len(a.talkers))
+	for _, t := range a.talkers {
+		t.Paths = len(t.paths)
+		t.FirstTime, t.LastTime = formatLocalTime(t.first), formatLocalTime(t.last)
+		talkers = append(talkers, t)
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		if talkers[i].Requests != talkers[j].Requests {
+			return talkers[i].Requests > talkers[j].Requests
+		}
+		return talkers[i].IP < talkers[j].IP
+	})
+	for i, t := range talkers {
+		if i >= webLogTopTalkers {
+			break
+		}
+		a.result.Talkers = append(a.result.Talkers, t.WebLogTalker)
+		// 大量请求且多数返回错误，通常是目录或口令爆破
+		if t.Requests >= 100 && t.Errors*2 > t.Requests {
+			key := webCategoryTalker + "\x00" + t.IP
+			a.findings[key] = &WebLogFinding{
+				Level: "low", Category: webCategoryTalker, Rule: "大量错误请求", IP: t.IP, Sample: fmt.Sprintf("%d 个不同路径", t.Paths),
+				Match: fmt.Sprintf("%d 次请求中 %d 次返回 4xx/5xx", t.Requests, t.Errors), Count: t.Requests, UserAgent: t.UserAgent,
+			}
+			a.firstOf[key], a.lastOf[key] = t.first, t.last
+			a.order = append(a.order, key)
+		}
+	}
+
+	for _, key := range a.order {
+		f := a.findings[key]
+		f.FirstTime, f.LastTime = formatLocalTime(a.firstOf[key]), formatLocalTime(a.lastOf[key])
+		a.result.Findings = append(a.result.Findings, *f)
+	}
+	sort.SliceStable(a.result.Findings, func(i, j int) bool {
+		fi, fj := a.result.Findings[i], a.result.Findings[j]
+		if sigmaLevelRank[fi.Level] != sigmaLevelRank[fj.Level] {
+			return sigmaLevelRank[fi.Level] < sigmaLevelRank[fj.Level]
+		}
+		return fi.FirstTime < fj.FirstTime
+	})
+	return a.result
+}
+
+// analyzeWebLogs 分析指定的访问日志文件，无法读取的文件记录错误后跳过
+func analyzeWebLogs(ctx context.Context, files []string) (WebLogAnalysis, error) {
+	a := newWebLogAnalyzer()
+	a.result = WebLogAnalysis{Files: []WebLogFile{}, Findings: []WebLogFinding{}, Talkers: []WebLogTalker{}}
+	progress := progressFrom(ctx)
+	progress.SetTotal(len(files))
+	for _, path := range files {
+		file, err := readWebLogFile(ctx, path, func(r webRequest) { a.add(r, path) })
+		if ctx.Err() != nil {
+			return WebLogAnalysis{}, ctx.Err()
+		}
+		if err != nil {
+			progress.Fail(fmt.Errorf("%s: %v", path, err))
+		}
+		if file.Format != "" || err == nil {
+			a.result.Files = append(a.result.Files, file)
+		}
+		progress.Step(filepath.Base(path))
+	}
+	return a.finish(), nil
+}
+
+// GetWebLogAnalysis 分析本机的 Web 访问日志
+func (a *App) GetWebLogAnalysis() WebLogAnalysis {
+	result, _ := analyzeWebLogs(context.Background(), webLogFiles(webLogRoot()))
+	return result
+}
+
+// webLogFileSchema 等为 Web 访问日志分析的数据表
+const (
+	webLogFileSchema = `CREATE TABLE IF NOT EXISTS web_log_file (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	path TEXT,
+	format TEXT,
+	requests INTEGER,
+	skipped INTEGER,
+	first_time DATETIME,
+	last_time DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+	webLogFindingSchema = `CREATE TABLE IF NOT EXISTS web_log_finding (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	level TEXT,
+	category TEXT,
+	rule TEXT,
+	ip TEXT,
+	method TEXT,
+	path TEXT,
+	sample TEXT,
+	match TEXT,
+	status INTEGER,
+	ok_count INTEGER,
+	count INTEGER,
+	first_time DATETIME,
+	last_time DATETIME,
+	user_agent TEXT,
+	log_file TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+	webLogTalkerSchema = `CREATE TABLE IF NOT EXISTS web_log_talker (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	ip TEXT,
+	requests INTEGER,
+	errors INTEGER,
+	posts INTEGER,
+	paths INTEGER,
+	first_time DATETIME,
+	last_time DATETIME,
+	user_agent TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+)
+
+// migrateWebLog v14: Web 访问日志文件、可疑请求与高频来源表
+func migrateWebLog(tx *sql.Tx) error {
+	return execAll(tx, webLogFileSchema, webLogFindingSchema, webLogTalkerSchema)
+}
+
+func init() {
+	RegisterCollector(&sliceCollector[WebLogAnalysis]{
+		name:      "weblog",
+		title:     "Web访问日志",
+		platforms: []string{"linux", "darwin", "windows"},
+		schema:    []string{webLogFileSchema, webLogFindingSchema, webLogTalkerSchema},
+		collect: func(ctx context.Context, a *App) ([]WebLogAnalysis, error) {
+			result, err := analyzeWebLogs(ctx, webLogFiles(webLogRoot()))
+			if err != nil {
+				return nil, err
+			}
+			return []WebLogAnalysis{result}, nil
+		},
+		save: func(a *App, results []WebLogAnalysis) error {
+			for _, r := range results {
+				if err := a.SaveWebLogAnalysis(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		count: sumRecords[WebLogAnalysis],
+	})
+}
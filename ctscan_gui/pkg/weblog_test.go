@@ -0,0 +1,321 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeWebLog(t *testing.T, name, content string, gz bool) string {
+	t.Helper()
+	data := []byte(content)
+	if gz {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+		data = buf.Bytes()
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadWebLogFile(t *testing.T) {
+	combined := `10.0.0.1 - admin [01/Jun/2024:10:00:00 +0800] "GET /index.php?id=1 HTTP/1.1" 200 512 "http://a/" "Mozilla/5.0 (X11)"` + "\n" +
+		`10.0.0.2 - - [01/Jun/2024:10:00:05 +0800] "POST /login HTTP/1.1" 302 - "-" "curl/8.0"` + "\n"
+	w3c := "#Software: Microsoft Internet Information Services 10.0\r\n" +
+		"#Fields: date time s-ip cs-method cs-uri-stem cs-uri-query s-port cs-username c-ip cs(User-Agent) cs(Referer) sc-status sc-substatus sc-win32-status sc-bytes\r\n" +
+		"2024-06-01 02:00:00 10.0.0.9 GET /default.aspx a=1 80 - 192.168.1.5 Mozilla/5.0+(Windows+NT+10.0) - 200 0 0 1024\r\n" +
+		"#Fields: date time c-ip cs-method cs-uri-stem sc-status\r\n" +
+		"2024-06-01 02:00:10 192.168.1.6 POST /upload.aspx 500\r\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		gz          bool
+		wantFormat  string
+		wantSkipped int
+		want        []webRequest
+	}{
+		{
+			name:       "combined 格式",
+			content:    combined,
+			wantFormat: webLogCombined,
+			want: []webRequest{
+				{Time: time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), IP: "10.0.0.1", User: "admin", Method: "GET", Path: "/index.php", Query: "id=1",
+					Status: 200, Size: 512, Referer: "http://a/", UserAgent: "Mozilla/5.0 (X11)", Raw: "GET /index.php?id=1 HTTP/1.1"},
+				{Time: time.Date(2024, 6, 1, 2, 0, 5, 0, time.UTC), IP: "10.0.0.2", Method: "POST", Path: "/login",
+					Status: 302, Referer: "-", UserAgent: "curl/8.0", Raw: "POST /login HTTP/1.1"},
+			},
+		},
+		{
+			name:       "common 格式与 nginx 的转义",
+			content:    `1.2.3.4 - - [01/Jun/2024:10:00:00 +0000] "GET /?q=\x22test\x22 HTTP/1.1" 404 0` + "\n",
+			wantFormat: webLogCommon,
+			want: []webRequest{
+				{Time: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), IP: "1.2.3.4", Method: "GET", Path: "/", Query: `q="test"`,
+					Status: 404, Raw: `GET /?q="test" HTTP/1.1`},
+			},
+		},
+		{
+			name:       "gzip 压缩的轮转文件",
+			content:    combined,
+			gz:         true,
+			wantFormat: webLogCombined,
+			want: []webRequest{
+				{Time: time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), IP: "10.0.0.1", User: "admin", Method: "GET", Path: "/index.php", Query: "id=1",
+					Status: 200, Size: 512, Referer: "http://a/", UserAgent: "Mozilla/5.0 (X11)", Raw: "GET /index.php?id=1 HTTP/1.1"},
+				{Time: time.Date(2024, 6, 1, 2, 0, 5, 0, time.UTC), IP: "10.0.0.2", Method: "POST", Path: "/login",
+					Status: 302, Referer: "-", UserAgent: "curl/8.0", Raw: "POST /login HTTP/1.1"},
+			},
+		},
+		{
+			name:       "IIS W3C 格式，#Fields 重新定义列",
+			content:    w3c,
+			wantFormat: webLogW3C,
+			want: []webRequest{
+				{Time: time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), IP: "192.168.1.5", Method: "GET", Path: "/default.aspx", Query: "a=1",
+					Status: 200, Size: 1024, UserAgent: "Mozilla/5.0 (Windows NT 10.0)", Raw: "GET /default.aspx?a=1"},
+				{Time: time.Date(2024, 6, 1, 2, 0, 10, 0, time.UTC), IP: "192.168.1.6", Method: "POST", Path: "/upload.aspx",
+					Status: 500, Raw: "POST /upload.aspx"},
+			},
+		},
+		{
+			name: "超长的行被截断后跳过，不影响后面的记录",
+			content: `1.2.3.4 - - [01/Jun/2024:10:00:00 +0000] "GET /a HTTP/1.1" 200 1` + "\n" +
+				`1.2.3.4 - - [01/Jun/2024:10:00:01 +0000] "GET /?x=` + strings.Repeat("A", 2*maxWebLogLine) + ` HTTP/1.1" 200 1` + "\n" +
+				`1.2.3.4 - - [01/Jun/2024:10:00:02 +0000] "GET /b HTTP/1.1" 200 1`,
+			wantFormat:  webLogCommon,
+			wantSkipped: 1,
+			want: []webRequest{
+				{Time: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), IP: "1.2.3.4", Method: "GET", Path: "/a", Status: 200, Size: 1, Raw: "GET /a HTTP/1.1"},
+				{Time: time.Date(2024, 6, 1, 10, 0, 2, 0, time.UTC), IP: "1.2.3.4", Method: "GET", Path: "/b", Status: 200, Size: 1, Raw: "GET /b HTTP/1.1"},
+			},
+		},
+		{
+			name:        "无法识别的行",
+			content:     "garbage\n\n1.2.3.4 - - [bad time] \"GET / HTTP/1.1\" 200 1\n",
+			wantSkipped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWebLog(t, "access.log", tt.content, tt.gz)
+			var got []webRequest
+			file, err := readWebLogFile(context.Background(), path, func(r webRequest) { got = append(got, r) })
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if file.Format != tt.wantFormat || file.Requests != len(tt.want) || file.Skipped != tt.wantSkipped {
+				t.Errorf("文件 %+v，应为格式 %s、%d 条记录、跳过 %d 行", file, tt.wantFormat, len(tt.want), tt.wantSkipped)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("读取到 %d 条记录，应为 %d 条", len(got), len(tt.want))
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if !g.Time.Equal(w.Time) {
+					t.Errorf("第 %d 条记录的时间 %v，应为 %v", i+1, g.Time, w.Time)
+				}
+				g.Time, w.Time = time.Time{}, time.Time{}
+				if g != w {
+					t.Errorf("第 %d 条记录\n%+v\n应为\n%+v", i+1, g, w)
+				}
+			}
+		})
+	}
+}
+
+// TestReadWebLogLine 超长的行截断为 maxWebLogLine，之后的行正常读取
+func TestReadWebLogLine(t *testing.T) {
+	long := strings.Repeat("x", maxWebLogLine+100)
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"普通的行", "a\nb\n", []string{"a", "b"}},
+		{"最后一行没有换行符", "a\nb", []string{"a", "b"}},
+		{"超长的行", long + "\nnext\n", []string{long[:maxWebLogLine], "next"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWebLog(t, "access.log", tt.input, false)
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			br := bufio.NewReader(f)
+			var got []string
+			for {
+				line, err := readWebLogLine(br)
+				if err != nil {
+					break
+				}
+				got = append(got, line)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("读取到 %d 行，应为 %d 行", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("第 %d 行长度 %d，应为 %d", i+1, len(got[i]), len(tt.want[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestWebLogAnalyzer(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	req := func(minutes int, ip, method, target string, status int) webRequest {
+		method, p, q := splitWebRequestLine(method + " " + target + " HTTP/1.1")
+		return webRequest{Time: base.Add(time.Duration(minutes) * time.Minute), IP: ip, Method: method, Path: p, Query: q,
+			Status: status, UserAgent: "Mozilla/5.0", Raw: method + " " + target + " HTTP/1.1"}
+	}
+	// 日志开始的基准请求
+	start := req(0, "10.0.0.1", "GET", "/index.html", 200)
+
+	type want struct {
+		rule  string
+		path  string
+		count int
+	}
+	tests := []struct {
+		name     string
+		requests []webRequest
+		want     []want
+	}{
+		{
+			name: "先404后200",
+			requests: []webRequest{
+				start,
+				req(10, "6.6.6.6", "GET", "/upload/x.php", 404),
+				req(20, "6.6.6.6", "GET", "/upload/x.php", 200),
+			},
+			want: []want{{"先404后200", "/upload/x.php", 2}},
+		},
+		{
+			name: "先200后404的脚本不是新上传的",
+			requests: []webRequest{
+				start,
+				req(10, "6.6.6.6", "GET", "/old.php", 200),
+				req(20, "6.6.6.6", "GET", "/old.php", 404),
+			},
+		},
+		{
+			name: "仅POST访问的脚本",
+			requests: []webRequest{
+				start,
+				req(10, "6.6.6.6", "POST", "/images/shell.jsp", 200),
+				req(11, "6.6.6.6", "POST", "/images/shell.jsp", 200),
+				req(12, "6.6.6.7", "POST", "/images/shell.jsp", 200),
+			},
+			want: []want{{"仅POST访问的脚本", "/images/shell.jsp", 3}},
+		},
+		{
+			name: "仅POST优先于先404后200",
+			requests: []webRequest{
+				start,
+				req(5, "6.6.6.6", "POST", "/s.php", 404),
+				req(10, "6.6.6.6", "POST", "/s.php", 200),
+				req(11, "6.6.6.6", "POST", "/s.php", 200),
+			},
+			want: []want{{"仅POST访问的脚本", "/s.php", 3}},
+		},
+		{
+			name: "有 GET 请求的表单不是 webshell",
+			requests: []webRequest{
+				start,
+				req(10, "6.6.6.6", "GET", "/login.php", 200),
+				req(11, "6.6.6.6", "POST", "/login.php", 200),
+				req(12, "6.6.6.6", "POST", "/login.php", 200),
+				req(13, "6.6.6.6", "POST", "/login.php", 200),
+			},
+		},
+		{
+			name: "超过 3 个来源访问的脚本不是 webshell",
+			requests: []webRequest{
+				start,
+				req(10, "1.1.1.1", "POST", "/api.php", 200),
+				req(11, "2.2.2.2", "POST", "/api.php", 200),
+				req(12, "3.3.3.3", "POST", "/api.php", 200),
+				req(13, "4.4.4.4", "POST", "/api.php", 200),
+			},
+		},
+		{
+			name: "日志开始一天之后出现的脚本",
+			requests: []webRequest{
+				start,
+				req(25*60, "6.6.6.6", "GET", "/new.aspx", 200),
+			},
+			want: []want{{"新出现的脚本", "/new.aspx", 1}},
+		},
+		{
+			name: "同一来源对同一路径的特征命中合并",
+			requests: []webRequest{
+				req(0, "6.6.6.6", "GET", "/item?id=1%2520union%2520select%25201,2", 200),
+				req(1, "6.6.6.6", "GET", "/item?id=2+UNION+ALL+SELECT+3", 500),
+				req(2, "6.6.6.6", "GET", "/../../etc/passwd", 400),
+			},
+			want: []want{{"UNION查询", "/item", 2}, {"目录穿越", "/../../etc/passwd", 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newWebLogAnalyzer()
+			for _, r := range tt.requests {
+				a.add(r, "access.log")
+			}
+			result := a.finish()
+			var got, wantStr []string
+			for _, f := range result.Findings {
+				got = append(got, fmt.Sprintf("%s %s %d", f.Rule, f.Path, f.Count))
+			}
+			for _, w := range tt.want {
+				wantStr = append(wantStr, fmt.Sprintf("%s %s %d", w.rule, w.path, w.count))
+			}
+			if strings.Join(got, "; ") != strings.Join(wantStr, "; ") {
+				t.Errorf("命中 %q，应为 %q", got, wantStr)
+			}
+		})
+	}
+}
+
+func TestWebLogAnalyzerTalkers(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	a := newWebLogAnalyzer()
+	for i := 0; i < 120; i++ {
+		status := 404
+		if i%4 == 0 {
+			status = 200
+		}
+		a.add(webRequest{Time: base.Add(time.Duration(i) * time.Second), IP: "6.6.6.6", Method: "GET",
+			Path: fmt.Sprintf("/dir%d/", i), Status: status, UserAgent: "gobuster/3.6"}, "access.log")
+	}
+	a.add(webRequest{Time: base, IP: "10.0.0.1", Method: "POST", Path: "/", Status: 200}, "access.log")
+	result := a.finish()
+
+	if len(result.Talkers) != 2 || result.Talkers[0].IP != "6.6.6.6" || result.Talkers[0].Requests != 120 ||
+		result.Talkers[0].Errors != 90 || result.Talkers[0].Paths != 120 || result.Talkers[1].Posts != 1 {
+		t.Errorf("来源统计 %+v", result.Talkers)
+	}
+	rules := make(map[string]int)
+	for _, f := range result.Findings {
+		rules[f.Rule] = f.Count
+	}
+	if rules["大量错误请求"] != 120 || rules["扫描器UA"] != 120 {
+		t.Errorf("命中 %v", rules)
+	}
+}
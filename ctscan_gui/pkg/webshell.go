@@ -0,0 +1,605 @@
+package pkg
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Webshell 扫描：遍历 Web 根目录中的脚本文件，按静态特征打分。
+// Web 根目录来自 nginx/Apache 配置中的 root、DocumentRoot，IIS 的 applicationHost.config，以及常见的默认目录。
+
+// webRootPatterns 常见的 Web 根目录，相对于根目录
+var webRootPatterns = []string{
+	"var/www",
+	"srv/www",
+	"srv/http",
+	"usr/share/nginx/html",
+	"usr/local/nginx/html",
+	"usr/local/openresty/nginx/html",
+	"usr/local/apache2/htdocs",
+	"www/wwwroot",
+	"home/wwwroot",
+	"data/wwwroot",
+	"var/lib/tomcat*/webapps",
+	"opt/tomcat*/webapps",
+	"usr/local/tomcat*/webapps",
+	"usr/share/tomcat*/webapps",
+	"Library/WebServer/Documents",
+	"inetpub/wwwroot",
+	"xampp/htdocs",
+	"phpStudy/WWW",
+	"phpstudy_pro/WWW",
+	"Program Files/Apache Software Foundation/Tomcat*/webapps",
+}
+
+// webRootConfigRe nginx 的 root 与 Apache 的 DocumentRoot
+var webRootConfigRe = regexp.MustCompile(`(?im)(?:^|[{;])\s*(?:root|DocumentRoot)\s+"?([^"\s;]+)`)
+
+// iisPhysicalPathRe applicationHost.config 中站点与虚拟目录的物理路径
+var iisPhysicalPathRe = regexp.MustCompile(`(?i)physicalPath="([^"]+)"`)
+
+const (
+	webshellMaxFileSize = 5 << 20 // 超过该大小的文件不扫描
+	webshellMinScore    = 30      // 低于该分数的文件不输出
+)
+
+// webshellImageExts 可能被嵌入脚本后通过文件包含执行的图片与文本
+var webshellImageExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true, ".ico": true, ".txt": true,
+}
+
+// 脚本语言，决定使用哪些规则
+const (
+	webshellLangPHP = "php"
+	webshellLangJSP = "jsp"
+	webshellLangASP = "asp"
+)
+
+var webshellLangs = map[string]string{
+	".php": webshellLangPHP, ".php3": webshellLangPHP, ".php4": webshellLangPHP, ".php5": webshellLangPHP,
+	".phtml": webshellLangPHP, ".pht": webshellLangPHP, ".inc": webshellLangPHP,
+	".jsp": webshellLangJSP, ".jspx": webshellLangJSP, ".jspf": webshellLangJSP,
+	".asp": webshellLangASP, ".aspx": webshellLangASP, ".ashx": webshellLangASP, ".asmx": webshellLangASP,
+	".asa": webshellLangASP, ".cer": webshellLangASP, ".cdx": webshellLangASP,
+}
+
+// webshellRule 静态特征，all 中的正则都匹配时命中；lang 为空时适用于所有脚本，family 不为空时为已知的 webshell 家族特征
+type webshellRule struct {
+	name   string
+	weight int
+	lang   string
+	family string
+	all    []*regexp.Regexp
+}
+
+func newWebshellRule(name string, weight int, lang string, patterns ...string) webshellRule {
+	r := webshellRule{name: name, weight: weight, lang: lang}
+	for _, p := range patterns {
+		r.all = append(r.all, regexp.MustCompile(p))
+	}
+	return r
+}
+
+func webshellFamilyRule(family, lang, pattern string) webshellRule {
+	r := newWebshellRule(family+"特征", 80, lang, pattern)
+	r.family = family
+	return r
+}
+
+// Webshell 家族
+const (
+	webshellChopper  = "中国菜刀"
+	webshellBehinder = "冰蝎"
+	webshellGodzilla = "哥斯拉"
+	webshellAntSword = "蚁剑"
+)
+
+var webshellRules = []webshellRule{
+	// 已知家族
+	webshellFamilyRule(webshellChopper, webshellLangASP, `(?i)eval\s*\(\s*Request\.Item\[[^\]]+\]\s*,\s*"unsafe"\s*\)|<%\s*eval\s+request\s*\(\s*"[^"]*"\s*\)\s*%>`),
+	webshellFamilyRule(webshellBehinder, "", `(?i)e45e329feb5d925b|session\.putValue\s*\(\s*"u"|class\s+U\s+extends\s+ClassLoader|__invoke\s*\(\s*\$p\s*\)\s*\{\s*eval\s*\(\s*\$p|CreateInstance\s*\(\s*"U"\s*\)|\$_SESSION\[\s*['"]k['"]\s*\]\s*=\s*\$key`),
+	webshellFamilyRule(webshellGodzilla, "", `(?i)\$payloadName\s*=|md5\s*\(\s*\$pass\s*\.\s*\$key\s*\)|String\s+xc\s*=\s*"[0-9a-f]{16}"|md5\s*\(\s*pass\s*\+\s*xc\s*\)|string\s+key\s*=\s*"[0-9a-f]{16}"\s*;\s*string\s+pass\s*=|session\.(get|set)Attribute\s*\(\s*"payload"|Session\s*\[\s*"payload"\s*\]`),
+	webshellFamilyRule(webshellAntSword, "", `(?i)\$ant\s*=\s*(base64_decode|str_rot13|create_function)|\$_(POST|REQUEST)\[\s*['"]ant['"]\s*\]|antsword`),
+
+	// PHP
+	newWebshellRule("eval执行请求参数", 60, webshellLangPHP, `(?i)\b(eval|assert)\s*\(\s*@?(\$_(POST|GET|REQUEST|COOKIE|SERVER)\b|\$\{\s*['"]_(POST|GET|REQUEST))`),
+	newWebshellRule("eval解码链", 40, webshellLangPHP, `(?i)\b(eval|assert)\s*\(\s*@?(base64_decode|gzinflate|gzuncompress|gzdecode|str_rot13|strrev|hex2bin|convert_uudecode|rawurldecode|urldecode)\s*\(`),
+	newWebshellRule("命令执行请求参数", 50, webshellLangPHP, `(?i)\b(system|exec|shell_exec|passthru|popen|proc_open|pcntl_exec)\s*\(\s*@?\$_(POST|GET|REQUEST|COOKIE)`),
+	newWebshellRule("请求参数作为函数调用", 40, webshellLangPHP, `(?i)\$_(POST|GET|REQUEST|COOKIE)\s*\[[^\]]*\]\s*\(|@?\$\w+\s*\(\s*@?\$_(POST|GET|REQUEST|COOKIE)\s*\[`),
+	newWebshellRule("preg_replace /e", 40, webshellLangPHP, `(?i)preg_replace\s*\(\s*['"][^'"]*/[a-z]*e[a-z]*['"]`),
+	newWebshellRule("create_function", 20, webshellLangPHP, `(?i)\bcreate_function\s*\(`),
+	newWebshellRule("回调执行", 30, webshellLangPHP, `(?i)\b(call_user_func(_array)?|array_map|array_filter|usort|uasort|array_walk|register_shutdown_function)\s*\(\s*(@?\$_(POST|GET|REQUEST|COOKIE)|['"](assert|eval|system)['"])`),
+	newWebshellRule("拼接危险函数名", 40, webshellLangPHP, `(?i)['"]as['"]\s*\.\s*['"]sert['"]|['"]ass['"]\s*\.\s*['"]ert['"]|['"]e['"]\s*\.\s*['"]val['"]|['"]sys['"]\s*\.\s*['"]tem['"]|strrev\s*\(\s*['"](tressa|lave|metsys)['"]`),
+	newWebshellRule("chr拼接字符串", 20, webshellLangPHP, `(?i)(chr\s*\(\s*\d+\s*\)\s*\.\s*){4,}`),
+	newWebshellRule("写入请求内容", 30, webshellLangPHP, `(?i)\b(file_put_contents|fwrite|fputs)\s*\([^;]*\$_(POST|GET|REQUEST)`),
+	newWebshellRule("解码函数", 10, webshellLangPHP, `(?i)\b(gzinflate|gzuncompress|str_rot13|convert_uudecode)\s*\(`),
+
+	// JSP
+	newWebshellRule("Runtime.exec执行请求参数", 60, webshellLangJSP, `Runtime\.getRuntime\(\)\s*\.exec\s*\(`, `request\.getParameter\s*\(`),
+	newWebshellRule("Runtime.exec", 20, webshellLangJSP, `Runtime\.getRuntime\(\)\s*\.exec\s*\(`),
+	newWebshellRule("ProcessBuilder", 20, webshellLangJSP, `new\s+ProcessBuilder\s*\(`),
+	newWebshellRule("自定义类加载", 40, webshellLangJSP, `defineClass\s*\(`, `ClassLoader`),
+	newWebshellRule("AES解密请求体", 30, webshellLangJSP, `Cipher\.getInstance\s*\(\s*"AES`, `request\.(getReader|getInputStream)\s*\(`),
+	newWebshellRule("脚本引擎", 20, webshellLangJSP, `ScriptEngineManager|getEngineByName\s*\(`),
+	newWebshellRule("反射调用", 10, webshellLangJSP, `\.getMethod\s*\([^)]*\)\s*\.invoke\s*\(`),
+
+	// ASP/ASPX
+	newWebshellRule("eval执行请求参数", 60, webshellLangASP, `(?i)\b(eval|execute|executeglobal)\s*\(?\s*request\s*[.(\[]`),
+	newWebshellRule("Assembly.Load", 40, webshellLangASP, `(?i)Assembly\.Load\s*\(`),
+	newWebshellRule("执行系统命令", 30, webshellLangASP, `(?i)Process\.Start\s*\(|new\s+ProcessStartInfo|WScript\.Shell|Shell\.Application`),
+	newWebshellRule("读取原始请求", 10, webshellLangASP, `(?i)Request\.BinaryRead`),
+
+	// 所有脚本
+	newWebshellRule("Base64编码的危险函数", 30, "", `YXNzZXJ0|ZXZhbC|c3lzdGVt|c2hlbGxfZXhlYw|cGFzc3RocnU|QGV2YWwo`),
+	newWebshellRule("十六进制/八进制编码字符串", 15, "", `(\\x[0-9a-fA-F]{2}){8,}|(\\[0-7]{3}){8,}`),
+}
+
+// webshellEmbeddedRe 图片与文本中嵌入的脚本
+var webshellEmbeddedRe = regexp.MustCompile(`(?i)<\?php|<%@?\s*(page|eval|execute)|<jsp:|<script\s+runat\s*=\s*"?server`)
+
+// webshellConfigRe .htaccess 与 .user.ini 中让其他扩展名按 PHP 执行或自动包含文件的配置
+var webshellConfigRe = regexp.MustCompile(`(?i)(AddType|AddHandler|SetHandler)\s+application/x-httpd-php|auto_(pre|ap)pend_file`)
+
+// WebshellFile 得分达到阈值的可疑文件
+type WebshellFile struct {
+	Path        string   `json:"path"`
+	Root        string   `json:"root"`
+	Score       int      `json:"score"`
+	Level       string   `json:"level"`
+	Family      string   `json:"family"`
+	Indicators  []string `json:"indicators"`
+	Size        int64    `json:"size"`
+	MD5         string   `json:"md5"`
+	SHA256      string   `json:"sha256"`
+	Entropy     float64  `json:"entropy"`
+	LongestLine int      `json:"longest_line"`
+	ModTime     string   `json:"mod_time"`
+	AccessTime  string   `json:"access_time"`
+	ChangeTime  string   `json:"change_time"`
+	CreateTime  string   `json:"create_time"`
+}
+
+// WebshellScan Webshell 扫描结果
+type WebshellScan struct {
+	Roots   []string       `json:"roots"`
+	Scanned int            `json:"scanned"`
+	Files   []WebshellFile `json:"files"`
+}
+
+func (r WebshellScan) recordCount() int {
+	return len(r.Files)
+}
+
+// webshellFileTimes 文件的访问、元数据变更与创建时间，平台不提供的时间为零值
+// os.FileInfo.Sys() 在各平台的结构不同，按字段名读取以免为每个平台单独实现
+func webshellFileTimes(info os.FileInfo) (atime, ctime, btime time.Time) {
+	v := reflect.Indirect(reflect.ValueOf(info.Sys()))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	// Linux 为 Atim/Ctim，macOS 为 Atimespec/Ctimespec/Birthtimespec
+	timespec := func(names ...string) time.Time {
+		for _, name := range names {
+			f := v.FieldByName(name)
+			if !f.IsValid() || f.Kind() != reflect.Struct {
+				continue
+			}
+			sec, nsec := f.FieldByName("Sec"), f.FieldByName("Nsec")
+			if sec.IsValid() && nsec.IsValid() && sec.CanInt() && nsec.CanInt() && sec.Int() > 0 {
+				return time.Unix(sec.Int(), nsec.Int())
+			}
+		}
+		return time.Time{}
+	}
+	// Windows 为 FILETIME，自 1601 年起的 100 纳秒数
+	filetime := func(name string) time.Time {
+		f := v.FieldByName(name)
+		if !f.IsValid() || f.Kind() != reflect.Struct {
+			return time.Time{}
+		}
+		low, high := f.FieldByName("LowDateTime"), f.FieldByName("HighDateTime")
+		if !low.IsValid() || !high.IsValid() || !low.CanUint() || !high.CanUint() {
+			return time.Time{}
+		}
+		ticks := int64(high.Uint()<<32 | low.Uint())
+		if ticks <= 0 {
+			return time.Time{}
+		}
+		return time.Unix(0, (ticks-116444736000000000)*100)
+	}
+	atime = timespec("Atim", "Atimespec")
+	ctime = timespec("Ctim", "Ctimespec")
+	btime = timespec("Birthtimespec")
+	if atime.IsZero() {
+		atime = filetime("LastAccessTime")
+	}
+	if btime.IsZero() {
+		btime = filetime("CreationTime")
+	}
+	return
+}
+
+// shannonEntropy 字节的香农熵，混淆或编码后的内容通常高于 5.5，普通代码在 4.5 到 5.2 之间
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var entropy float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(data))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+func longestLine(data []byte) int {
+	longest, start := 0, 0
+	for i, b := range data {
+		if b == '\n' {
+			longest = max(longest, i-start)
+			start = i + 1
+		}
+	}
+	return max(longest, len(data)-start)
+}
+
+// toRootPath 将配置中的路径转换为 root 下的路径，Windows 路径去掉盘符
+func toRootPath(root, p string) string {
+	if len(p) >= 2 && p[1] == ':' {
+		p = p[2:]
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(p, `\`, "/")))
+}
+
+// webRoots root 下存在的 Web 根目录，嵌套的目录只保留最上层的
+func webRoots(root string) []string {
+	var candidates []string
+	for _, pattern := range webRootPatterns {
+		matches, _ := filepath.Glob(filepath.Join(root, pattern))
+		candidates = append(candidates, matches...)
+	}
+	for _, config := range webServerConfigFiles(root) {
+		data, err := os.ReadFile(config)
+		if err != nil {
+			continue
+		}
+		for _, m := range webRootConfigRe.FindAllStringSubmatch(string(data), -1) {
+			p := m[1]
+			if strings.Contains(p, "$") {
+				continue
+			}
+			if filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+				candidates = append(candidates, toRootPath(root, p))
+			} else {
+				candidates = append(candidates, filepath.Join(filepath.Dir(filepath.Dir(config)), p))
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "Windows/System32/inetsrv/config/applicationHost.config")); err == nil {
+		for _, m := range iisPhysicalPathRe.FindAllStringSubmatch(string(data), -1) {
+			p := strings.NewReplacer("%SystemDrive%", "", "%SYSTEMDRIVE%", "").Replace(m[1])
+			candidates = append(candidates, toRootPath(root, p))
+		}
+	}
+	return topLevelDirs(candidates)
+}
+
+// topLevelDirs 去掉不存在、重复与嵌套的目录
+func topLevelDirs(candidates []string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dir := range candidates {
+		dir = filepath.Clean(dir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	var roots []string
+	for _, dir := range dirs {
+		if len(roots) > 0 {
+			parent := roots[len(roots)-1]
+			if dir == parent || strings.HasPrefix(dir, strings.TrimSuffix(parent, string(filepath.Separator))+string(filepath.Separator)) {
+				continue
+			}
+		}
+		roots = append(roots, dir)
+	}
+	return roots
+}
+
+// webshellSiblings 同目录文件的变更时间，用于判断文件是否比周围的文件新
+// 变更时间取修改与元数据变更时间中较晚的一个，修改时间被伪造时元数据变更时间仍会更新
+type webshellSiblings struct {
+	times []time.Time // 目录中所有文件的变更时间，按时间排序，每个目录只排序一次
+	self  int         // 当前文件在 times 中的位置
+}
+
+// len 除当前文件外的文件数
+func (s webshellSiblings) len() int {
+	return len(s.times) - 1
+}
+
+// at 除当前文件外按时间排序的第 i 个文件的变更时间
+func (s webshellSiblings) at(i int) time.Time {
+	if i >= s.self {
+		i++
+	}
+	return s.times[i]
+}
+
+// scanWebshellFile 为一个文件打分，siblings 为同目录的其他文件
+func scanWebshellFile(path string, info os.FileInfo, changed time.Time, siblings webshellSiblings) (WebshellFile, bool) {
+	name := strings.ToLower(info.Name())
+	ext := filepath.Ext(name)
+	lang, isScript := webshellLangs[ext]
+	isConfig := name == ".htaccess" || name == ".user.ini"
+	isImage := webshellImageExts[ext]
+	if !isScript && !isConfig && !isImage && !webScriptExts[ext] {
+		return WebshellFile{}, false
+	}
+	if info.Size() == 0 || info.Size() > webshellMaxFileSize {
+		return WebshellFile{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WebshellFile{}, false
+	}
+	content := string(data)
+
+	f := WebshellFile{Path: path, Size: info.Size()}
+	add := func(indicator string, weight int) {
+		f.Indicators = append(f.Indicators, fmt.Sprintf("%s(+%d)", indicator, weight))
+		f.Score += weight
+	}
+	switch {
+	case isConfig:
+		if m := webshellConfigRe.FindString(content); m != "" {
+			add("配置使其他文件按 PHP 执行: "+m, 50)
+		}
+	case isImage:
+		// 嵌入脚本的图片需要配合文件包含或解析漏洞执行，按所有语言的规则检查
+		m := webshellEmbeddedRe.FindString(content)
+		if m == "" {
+			return WebshellFile{}, false
+		}
+		add("文件中嵌入脚本: "+m, 40)
+		lang = ""
+	}
+
+	if !isConfig {
+		for _, r := range webshellRules {
+			if r.lang != "" && lang != "" && r.lang != lang {
+				continue
+			}
+			matched := true
+			for _, re := range r.all {
+				if !re.MatchString(content) {
+					matched = false
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+			add(r.name, r.weight)
+			if r.family != "" && f.Family == "" {
+				f.Family = r.family
+			}
+		}
+		// 一句话木马：很小的文件直接执行请求参数
+		if f.Score >= 60 && f.Size < 300 && f.Family == "" {
+			f.Family = "一句话木马"
+			add("一句话木马", 20)
+		}
+
+		f.Entropy = math.Round(shannonEntropy(data)*100) / 100
+		if f.Size >= 1024 && f.Entropy >= 5.5 {
+			add(fmt.Sprintf("高熵内容(%.2f)", f.Entropy), 20)
+		}
+		if f.LongestLine = longestLine(data); f.LongestLine >= 4096 {
+			add(fmt.Sprintf("超长行(%d 字符)", f.LongestLine), 15)
+		}
+	}
+
+	// 比同目录的其他文件都新，并且比它们的中位数晚一周以上，可能是之后上传的
+	if n := siblings.len(); n >= 3 {
+		median := siblings.at(n / 2)
+		if changed.After(siblings.at(n-1)) && changed.Sub(median) >= 7*24*time.Hour {
+			add(fmt.Sprintf("比同目录文件新 %d 天", int(changed.Sub(median).Hours()/24)), 15)
+		}
+	}
+
+	f.Score = min(f.Score, 100)
+	if f.Score < webshellMinScore {
+		return WebshellFile{}, false
+	}
+	switch {
+	case f.Score >= 80:
+		f.Level = "high"
+	case f.Score >= 50:
+		f.Level = "medium"
+	default:
+		f.Level = "low"
+	}
+	md5sum, sha := md5.Sum(data), sha256.Sum256(data)
+	f.MD5, f.SHA256 = hex.EncodeToString(md5sum[:]), hex.EncodeToString(sha[:])
+	atime, ctime, btime := webshellFileTimes(info)
+	f.ModTime = formatLocalTime(info.ModTime())
+	for _, t := range []struct {
+		dst *string
+		t   time.Time
+	}{{&f.AccessTime, atime}, {&f.ChangeTime, ctime}, {&f.CreateTime, btime}} {
+		if !t.t.IsZero() {
+			*t.dst = formatLocalTime(t.t)
+		}
+	}
+	return f, true
+}
+
+// scanWebshellDir 扫描一个目录及其子目录，不跟随符号链接
+func scanWebshellDir(ctx context.Context, root, dir string, result *WebshellScan) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		progressFrom(ctx).Fail(fmt.Errorf("%s: %v", dir, err))
+		return nil
+	}
+	type file struct {
+		path    string
+		info    os.FileInfo
+		changed time.Time
+	}
+	var files []file
+	var subdirs []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir():
+			subdirs = append(subdirs, path)
+		case e.Type().IsRegular():
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			changed := info.ModTime()
+			if _, ctime, _ := webshellFileTimes(info); ctime.After(changed) {
+				changed = ctime
+			}
+			files = append(files, file{path, info, changed})
+		}
+	}
+
+	// 按变更时间排序后，每个文件只需记录自己的位置，不必为每个文件复制并排序同目录的其他文件
+	sort.SliceStable(files, func(i, j int) bool { return files[i].changed.Before(files[j].changed) })
+	times := make([]time.Time, len(files))
+	for i, f := range files {
+		times[i] = f.changed
+	}
+
+	progress := progressFrom(ctx)
+	for i, f := range files {
+		result.Scanned++
+		if found, ok := scanWebshellFile(f.path, f.info, f.changed, webshellSiblings{times: times, self: i}); ok {
+			found.Root = root
+			result.Files = append(result.Files, found)
+		}
+	}
+	progress.Step(dir)
+
+	for _, sub := range subdirs {
+		if err := scanWebshellDir(ctx, root, sub, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanWebshells 扫描指定的目录，dirs 为空时扫描 root 下自动识别的 Web 根目录
+func scanWebshells(ctx context.Context, root string, dirs []string) (WebshellScan, error) {
+	roots := topLevelDirs(dirs)
+	if len(dirs) == 0 {
+		roots = webRoots(root)
+	}
+	result := WebshellScan{Roots: roots, Files: []WebshellFile{}}
+	if result.Roots == nil {
+		result.Roots = []string{}
+	}
+	for _, dir := range roots {
+		if err := scanWebshellDir(ctx, dir, dir, &result); err != nil {
+			return WebshellScan{}, err
+		}
+	}
+	sort.SliceStable(result.Files, func(i, j int) bool {
+		if result.Files[i].Score != result.Files[j].Score {
+			return result.Files[i].Score > result.Files[j].Score
+		}
+		return result.Files[i].Path < result.Files[j].Path
+	})
+	return result, nil
+}
+
+// ScanWebshells 扫描 Web 根目录中的可疑脚本，dirs 为空时自动识别，可通过 CancelTask("webshell") 取消
+func (a *App) ScanWebshells(dirs []string) (WebshellScan, error) {
+	ctx, done := a.startTask(nil, "webshell", "Webshell扫描")
+	result, err := scanWebshells(ctx, webLogRoot(), dirs)
+	done(err)
+	if err != nil {
+		return WebshellScan{}, taskError(err)
+	}
+	return result, nil
+}
+
+// webshellFileSchema Webshell 扫描结果表
+const webshellFileSchema = `CREATE TABLE IF NOT EXISTS webshell_file (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	path TEXT,
+	root TEXT,
+	score INTEGER,
+	level TEXT,
+	family TEXT,
+	indicators TEXT,
+	size INTEGER,
+	md5 TEXT,
+	sha256 TEXT,
+	entropy REAL,
+	longest_line INTEGER,
+	mod_time DATETIME,
+	access_time DATETIME,
+	change_time DATETIME,
+	create_time DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);`
+
+// migrateWebshell v15: Webshell 扫描结果表
+func migrateWebshell(tx *sql.Tx) error {
+	return execAll(tx, webshellFileSchema)
+}
+
+func init() {
+	RegisterCollector(&sliceCollector[WebshellScan]{
+		name:      "webshell",
+		title:     "Webshell扫描",
+		platforms: []string{"linux", "darwin", "windows"},
+		schema:    []string{webshellFileSchema},
+		collect: func(ctx context.Context, a *App) ([]WebshellScan, error) {
+			result, err := scanWebshells(ctx, webLogRoot(), nil)
+			if err != nil {
+				return nil, err
+			}
+			return []WebshellScan{result}, nil
+		},
+		save: func(a *App, results []WebshellScan) error {
+			for _, r := range results {
+				if err := a.SaveWebshellScan(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		count: sumRecords[WebshellScan],
+	})
+}
@@ -0,0 +1,368 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WinEvent 结构化记录对应的原始事件
+type WinEvent struct {
+	ID         int64  `json:"id"`
+	SourceFile string `json:"source_file"`
+	RecordID   int    `json:"record_id"`
+	Time       string `json:"time"` // UTC
+	EventID    int    `json:"event_id"`
+	Computer   string `json:"computer"`
+}
+
+func (w *WinEvent) event() *WinEvent { return w }
+
+// winEventPtr 嵌入了 WinEvent 的记录类型的指针
+type winEventPtr[T any] interface {
+	*T
+	event() *WinEvent
+}
+
+// winEventTable 从EVTX事件中提取一类结构化记录，每类记录保存在单独的表中
+type winEventTable[T any, P winEventPtr[T]] struct {
+	name    string
+	title   string
+	columns []string // 记录特有的列，格式为 "列名 类型"
+	keyword []string // 关键字搜索的列
+	process []string // 保存进程GUID的列，按进程查询时使用
+	extract func(e *EVTXEvent) (T, bool)
+	fields  func(r P) []any // 与 columns 顺序一致的字段指针
+}
+
+// winEventExtractor 不区分记录类型的提取器，导入事件时依次运行
+type winEventExtractor interface {
+	tableName() string
+	tableTitle() string
+	tableSchema() []string
+	extractRow(e *EVTXEvent) ([]any, bool)
+	insertRows(tx *sql.Tx, sessionID string, rows [][]any) error
+}
+
+// winEventCommonColumns 所有结构化记录表共有的来源事件列
+const winEventCommonColumns = `source_file, record_id, time, event_id, computer`
+
+func (t *winEventTable[T, P]) tableName() string  { return t.name }
+func (t *winEventTable[T, P]) tableTitle() string { return t.title }
+
+func (t *winEventTable[T, P]) columnNames() []string {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = strings.Fields(c)[0]
+	}
+	return names
+}
+
+// tableSchema 建表语句与索引，同一事件只记录一次，续传与重新提取时不会重复
+func (t *winEventTable[T, P]) tableSchema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS ` + t.name + ` (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	source_file TEXT,
+	record_id INTEGER,
+	time DATETIME,
+	event_id INTEGER,
+	computer TEXT,
+	` + strings.Join(t.columns, ",\n\t") + `,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_` + t.name + `_event ON ` + t.name + ` (session_id, source_file, record_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + t.name + `_time ON ` + t.name + ` (session_id, time)`,
+	}
+}
+
+// extractRow 提取记录并转换为插入时的参数
+func (t *winEventTable[T, P]) extractRow(e *EVTXEvent) ([]any, bool) {
+	r, ok := t.extract(e)
+	if !ok {
+		return nil, false
+	}
+	created, _ := time.Parse(sessionTimeLayout, e.Time)
+	row := []any{e.SourceFile, e.EventRecordID, created, e.EventID, e.Computer}
+	return append(row, t.fields(P(&r))...), true
+}
+
+func (t *winEventTable[T, P]) insertRows(tx *sql.Tx, sessionID string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	columns := append(strings.Split(winEventCommonColumns, ", "), t.columnNames()...)
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(columns)+1), ", ")
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO ` + t.name + ` (session_id, ` + strings.Join(columns, ", ") + `)
+		VALUES (` + marks + `)`)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.Exec(append([]any{sessionID}, row...)...); err != nil {
+			return fmt.Errorf("保存%s记录失败: %v", t.title, err)
+		}
+	}
+	return nil
+}
+
+// WinEventQuery 结构化记录的查询条件，时间为 UTC
+type WinEventQuery struct {
+	SessionID string `json:"session_id"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Computer  string `json:"computer"`
+	Keyword   string `json:"keyword"`
+	// ProcessGUID 只查询与该进程相关的记录，仅 Sysmon 记录支持
+	ProcessGUID string `json:"process_guid"`
+	Page        int    `json:"page"`
+	PageSize    int    `json:"page_size"`
+}
+
+// WinEventPageInfo 一页结构化记录的分页信息
+type WinEventPageInfo struct {
+	SessionID string `json:"session_id"`
+	Total     int    `json:"total"`
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+}
+
+// WinEventCount 会话中每类结构化记录的数量
+type WinEventCount struct {
+	Table string `json:"table"`
+	Title string `json:"title"`
+	Count int    `json:"count"`
+}
+
+// WinEventRebuildResult 对会话中已导入的事件重新提取的结果
+type WinEventRebuildResult struct {
+	SessionID string          `json:"session_id"`
+	Events    int             `json:"events"`
+	Counts    []WinEventCount `json:"counts"`
+}
+
+// where 生成查询条件，关键字在记录特有的文本列中搜索
+func (t *winEventTable[T, P]) where(sessionID string, q WinEventQuery) (string, []any, error) {
+	conds := []string{"session_id = ?"}
+	args := []any{sessionID}
+	if q.Start != "" {
+		start, err := time.Parse(sessionTimeLayout, q.Start)
+		if err != nil {
+			return "", nil, fmt.Errorf("开始时间格式错误: %s", q.Start)
+		}
+		conds = append(conds, "time >= ?")
+		args = append(args, start)
+	}
+	if q.End != "" {
+		end, err := time.Parse(sessionTimeLayout, q.End)
+		if err != nil {
+			return "", nil, fmt.Errorf("结束时间格式错误: %s", q.End)
+		}
+		conds = append(conds, "time < ?")
+		args = append(args, end.Add(time.Second))
+	}
+	if q.Computer != "" {
+		conds = append(conds, "computer = ?")
+		args = append(args, q.Computer)
+	}
+	if q.ProcessGUID != "" {
+		if len(t.process) == 0 {
+			return "", nil, fmt.Errorf("%s记录不支持按进程查询", t.title)
+		}
+		var ors []string
+		for _, c := range t.process {
+			ors = append(ors, c+" = ?")
+			args = append(args, q.ProcessGUID)
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		var ors []string
+		for _, c := range t.keyword {
+			ors = append(ors, c+" LIKE ?")
+			args = append(args, like)
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// query 分页查询记录，按时间排序
+func (t *winEventTable[T, P]) query(a *App, q WinEventQuery) ([]T, WinEventPageInfo, error) {
+	sessionID, err := a.evtxSessionID(q.SessionID)
+	if err != nil {
+		return nil, WinEventPageInfo{}, err
+	}
+	info := WinEventPageInfo{SessionID: sessionID, Page: q.Page, PageSize: q.PageSize}
+	if info.Page < 1 {
+		info.Page = 1
+	}
+	if info.PageSize < 1 {
+		info.PageSize = evtxDefaultPageSize
+	}
+	if info.PageSize > evtxMaxPageSize {
+		info.PageSize = evtxMaxPageSize
+	}
+	where, args, err := t.where(sessionID, q)
+	if err != nil {
+		return nil, WinEventPageInfo{}, err
+	}
+	if err := a.database().QueryRow(`SELECT COUNT(*) FROM `+t.name+` WHERE `+where, args...).Scan(&info.Total); err != nil {
+		return nil, WinEventPageInfo{}, fmt.Errorf("查询%s记录失败: %v", t.title, err)
+	}
+	args = append(args, info.PageSize, (info.Page-1)*info.PageSize)
+	rows, err := a.database().Query(`SELECT id, `+winEventCommonColumns+`, `+strings.Join(t.columnNames(), ", ")+`
+		FROM `+t.name+` WHERE `+where+` ORDER BY time, source_file, record_id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, WinEventPageInfo{}, fmt.Errorf("查询%s记录失败: %v", t.title, err)
+	}
+	defer rows.Close()
+
+	records := []T{}
+	for rows.Next() {
+		var (
+			r       T
+			created sql.NullTime
+		)
+		w := P(&r).event()
+		dest := append([]any{&w.ID, &w.SourceFile, &w.RecordID, &created, &w.EventID, &w.Computer}, t.fields(P(&r))...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, WinEventPageInfo{}, fmt.Errorf("读取%s记录失败: %v", t.title, err)
+		}
+		if created.Valid {
+			w.Time = created.Time.UTC().Format(sessionTimeLayout)
+		}
+		records = append(records, r)
+	}
+	return records, info, rows.Err()
+}
+
+// winEventExtractors 导入EVTX事件时运行的所有提取器，同时决定查询与统计的顺序
+var winEventExtractors = append(append([]winEventExtractor{}, securityEventExtractors...), sysmonEventExtractors...)
+
+// winEventRows 一批事件中提取出的记录，按表名分组
+type winEventRows map[string][][]any
+
+// extractWinEvents 对一批事件运行所有提取器
+func extractWinEvents(events []EVTXEvent) winEventRows {
+	rows := winEventRows{}
+	for i := range events {
+		for _, x := range winEventExtractors {
+			if row, ok := x.extractRow(&events[i]); ok {
+				rows[x.tableName()] = append(rows[x.tableName()], row)
+			}
+		}
+	}
+	return rows
+}
+
+// insertWinEvents 在事务中写入提取出的记录，已存在的记录忽略
+func insertWinEvents(tx *sql.Tx, sessionID string, rows winEventRows) error {
+	for _, x := range winEventExtractors {
+		if err := x.insertRows(tx, sessionID, rows[x.tableName()]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// winEventTables 所有结构化记录表的表名
+func winEventTables() []string {
+	tables := make([]string, len(winEventExtractors))
+	for i, x := range winEventExtractors {
+		tables[i] = x.tableName()
+	}
+	return tables
+}
+
+// migrateWinEvent 新增 Windows 安全事件的结构化记录表
+func migrateWinEvent(tx *sql.Tx) error {
+	return execAll(tx, winEventSchemas(securityEventExtractors)...)
+}
+
+func winEventSchemas(extractors []winEventExtractor) []string {
+	var statements []string
+	for _, x := range extractors {
+		statements = append(statements, x.tableSchema()...)
+	}
+	return statements
+}
+
+// GetWinEventSummary 统计会话中每类结构化记录的数量
+func (a *App) GetWinEventSummary(sessionID string) ([]WinEventCount, error) {
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return a.winEventCounts(sessionID)
+}
+
+func (a *App) winEventCounts(sessionID string) ([]WinEventCount, error) {
+	counts := make([]WinEventCount, 0, len(winEventExtractors))
+	for _, x := range winEventExtractors {
+		c := WinEventCount{Table: x.tableName(), Title: x.tableTitle()}
+		if err := a.database().QueryRow(`SELECT COUNT(*) FROM `+x.tableName()+` WHERE session_id = ?`, sessionID).Scan(&c.Count); err != nil {
+			return nil, fmt.Errorf("查询%s记录失败: %v", c.Title, err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
+// RebuildWinEvents 从会话中已导入的EVTX事件重新提取结构化记录，替换该会话原有的记录
+// 导入时已经提取过，用于升级前导入的会话，可通过 CancelTask("winevent") 取消
+func (a *App) RebuildWinEvents(sessionID string) (WinEventRebuildResult, error) {
+	if a.isReadOnly() {
+		return WinEventRebuildResult{}, errReadOnly
+	}
+	sessionID, err := a.evtxSessionID(sessionID)
+	if err != nil {
+		return WinEventRebuildResult{}, err
+	}
+	ctx, done := a.startTask(nil, "winevent", "提取Windows安全事件")
+	result, err := a.rebuildWinEvents(ctx, sessionID)
+	done(err)
+	return result, taskError(err)
+}
+
+// rebuildWinEvents 全部提取完成后在一个事务中替换记录，取消时不修改原有记录
+func (a *App) rebuildWinEvents(ctx context.Context, sessionID string) (WinEventRebuildResult, error) {
+	result := WinEventRebuildResult{SessionID: sessionID}
+	rows := winEventRows{}
+	err := a.scanEVTXEvents(ctx, sessionID, func(events []EVTXEvent) string {
+		result.Events += len(events)
+		for table, r := range extractWinEvents(events) {
+			rows[table] = append(rows[table], r...)
+		}
+		return fmt.Sprintf("%d 个事件", result.Events)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	a.evtxMu.Lock()
+	defer a.evtxMu.Unlock()
+	tx, err := a.database().Begin()
+	if err != nil {
+		return result, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	for _, table := range winEventTables() {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id = ?`, sessionID); err != nil {
+			return result, fmt.Errorf("清除旧的记录失败: %v", err)
+		}
+	}
+	if err := insertWinEvents(tx, sessionID, rows); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("提交事务失败: %v", err)
+	}
+	result.Counts, err = a.winEventCounts(sessionID)
+	return result, err
+}
@@ -0,0 +1,472 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// securityEventExtractors 安全日志与系统日志中的重点事件
+var securityEventExtractors = []winEventExtractor{
+	accountChangeTable,
+	serviceInstallTable,
+	scheduledTaskTable,
+	logClearTable,
+	processCreationTable,
+	specialPrivilegeTable,
+}
+
+const securityAuditingProvider = "Microsoft-Windows-Security-Auditing"
+
+// isSecurityAudit 判断是否为安全审核产生的指定事件
+func isSecurityAudit(e *EVTXEvent, ids ...int) bool {
+	if !strings.EqualFold(e.Provider, securityAuditingProvider) {
+		return false
+	}
+	for _, id := range ids {
+		if e.EventID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// winEventData 依次在 EventData 与 UserData 中查找字段，"-" 表示字段为空
+func winEventData(e *EVTXEvent, field string) string {
+	for _, m := range []map[string]any{e.EventData, e.UserData} {
+		if v, ok := sigmaLookup(m, field); ok {
+			s := strings.TrimSpace(strings.Join(sigmaStrings(v), " "))
+			if s == "-" {
+				return ""
+			}
+			return s
+		}
+	}
+	return ""
+}
+
+// winAccount 拼接 域\用户名，任一部分为空时只返回另一部分
+func winAccount(domain, user string) string {
+	switch {
+	case user == "":
+		return ""
+	case domain == "":
+		return user
+	}
+	return domain + `\` + user
+}
+
+// winSubject 事件中执行操作的账户
+func winSubject(e *EVTXEvent) string {
+	return winAccount(winEventData(e, "SubjectDomainName"), winEventData(e, "SubjectUserName"))
+}
+
+// winHexInt 解析事件中十六进制或十进制的进程ID
+func winHexInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 0, 64)
+	return n
+}
+
+// AccountChange 账户创建、启用、重置密码与加入安全组(4720/4722/4724/4728/4732)
+type AccountChange struct {
+	WinEvent
+	Action         string `json:"action"`
+	Account        string `json:"account"` // 被操作的账户，加入组时为成员
+	AccountSID     string `json:"account_sid"`
+	Group          string `json:"group"`
+	GroupSID       string `json:"group_sid"`
+	Subject        string `json:"subject"` // 执行操作的账户
+	SubjectLogonID string `json:"subject_logon_id"`
+}
+
+var accountChangeActions = map[int]string{
+	4720: "创建账户",
+	4722: "启用账户",
+	4724: "重置密码",
+	4728: "加入全局安全组",
+	4732: "加入本地安全组",
+}
+
+var accountChangeTable = &winEventTable[AccountChange, *AccountChange]{
+	name:  "win_account_change",
+	title: "账户变更",
+	columns: []string{"action TEXT", "account TEXT", "account_sid TEXT", "group_name TEXT", "group_sid TEXT",
+		"subject TEXT", "subject_logon_id TEXT"},
+	keyword: []string{"action", "account", "account_sid", "group_name", "subject"},
+	extract: func(e *EVTXEvent) (AccountChange, bool) {
+		if !isSecurityAudit(e, 4720, 4722, 4724, 4728, 4732) {
+			return AccountChange{}, false
+		}
+		r := AccountChange{
+			Action:         accountChangeActions[e.EventID],
+			Subject:        winSubject(e),
+			SubjectLogonID: winEventData(e, "SubjectLogonId"),
+		}
+		target := winAccount(winEventData(e, "TargetDomainName"), winEventData(e, "TargetUserName"))
+		if e.EventID == 4728 || e.EventID == 4732 {
+			// 加入组的事件中 Target 为组，成员名为 DN 格式，本地账户通常只有 SID
+			r.Account, r.AccountSID = winEventData(e, "MemberName"), winEventData(e, "MemberSid")
+			r.Group, r.GroupSID = target, winEventData(e, "TargetSid")
+		} else {
+			r.Account, r.AccountSID = target, winEventData(e, "TargetSid")
+		}
+		return r, true
+	},
+	fields: func(r *AccountChange) []any {
+		return []any{&r.Action, &r.Account, &r.AccountSID, &r.Group, &r.GroupSID, &r.Subject, &r.SubjectLogonID}
+	},
+}
+
+// ServiceInstall 服务安装(System 7045 与 Security 4697)
+type ServiceInstall struct {
+	WinEvent
+	ServiceName string `json:"service_name"`
+	ImagePath   string `json:"image_path"`
+	ServiceType string `json:"service_type"`
+	StartType   string `json:"start_type"`
+	Account     string `json:"account"` // 服务运行的账户
+	Subject     string `json:"subject"` // 安装服务的账户，7045 中为SID
+}
+
+// serviceStartTypes 4697 中为数字，7045 中为英文描述
+var serviceStartTypes = map[string]string{
+	"0": "引导", "boot start": "引导",
+	"1": "系统", "system start": "系统",
+	"2": "自动", "auto start": "自动",
+	"3": "手动", "demand start": "手动",
+	"4": "禁用", "disabled": "禁用",
+}
+
+var serviceTypes = map[string]string{
+	"0x1": "内核驱动", "kernel mode driver": "内核驱动",
+	"0x2": "文件系统驱动", "file system driver": "文件系统驱动",
+	"0x10": "独立进程", "user mode service": "独立进程",
+	"0x20": "共享进程", "share process": "共享进程",
+	"0x110": "可交互独立进程", "0x120": "可交互共享进程",
+}
+
+// winEnum 按映射转换取值，无法识别时保留原值
+func winEnum(m map[string]string, s string) string {
+	if v, ok := m[strings.ToLower(s)]; ok {
+		return v
+	}
+	return s
+}
+
+var serviceInstallTable = &winEventTable[ServiceInstall, *ServiceInstall]{
+	name:    "win_service_install",
+	title:   "服务安装",
+	columns: []string{"service_name TEXT", "image_path TEXT", "service_type TEXT", "start_type TEXT", "account TEXT", "subject TEXT"},
+	keyword: []string{"service_name", "image_path", "account", "subject"},
+	extract: func(e *EVTXEvent) (ServiceInstall, bool) {
+		switch {
+		case e.EventID == 7045 && strings.EqualFold(e.Provider, "Service Control Manager"):
+			return ServiceInstall{
+				ServiceName: winEventData(e, "ServiceName"),
+				ImagePath:   winEventData(e, "ImagePath"),
+				ServiceType: winEnum(serviceTypes, winEventData(e, "ServiceType")),
+				StartType:   winEnum(serviceStartTypes, winEventData(e, "StartType")),
+				Account:     winEventData(e, "AccountName"),
+				Subject:     e.UserID,
+			}, true
+		case isSecurityAudit(e, 4697):
+			return ServiceInstall{
+				ServiceName: winEventData(e, "ServiceName"),
+				ImagePath:   winEventData(e, "ServiceFileName"),
+				ServiceType: winEnum(serviceTypes, winEventData(e, "ServiceType")),
+				StartType:   winEnum(serviceStartTypes, winEventData(e, "ServiceStartType")),
+				Account:     winEventData(e, "ServiceAccount"),
+				Subject:     winSubject(e),
+			}, true
+		}
+		return ServiceInstall{}, false
+	},
+	fields: func(r *ServiceInstall) []any {
+		return []any{&r.ServiceName, &r.ImagePath, &r.ServiceType, &r.StartType, &r.Account, &r.Subject}
+	},
+}
+
+// ScheduledTask 计划任务创建与更新(4698/4702)，执行的命令从任务的XML定义中解析
+type ScheduledTask struct {
+	WinEvent
+	Action    string `json:"action"`
+	TaskName  string `json:"task_name"`
+	Command   string `json:"command"`
+	Arguments string `json:"arguments"`
+	RunAs     string `json:"run_as"`
+	Author    string `json:"author"`
+	Subject   string `json:"subject"`
+	Content   string `json:"content"` // 任务的XML定义
+}
+
+// taskDefinition 计划任务XML中需要的部分
+type taskDefinition struct {
+	Author     string `xml:"RegistrationInfo>Author"`
+	Principals []struct {
+		UserID  string `xml:"UserId"`
+		GroupID string `xml:"GroupId"`
+	} `xml:"Principals>Principal"`
+	Exec []struct {
+		Command   string `xml:"Command"`
+		Arguments string `xml:"Arguments"`
+	} `xml:"Actions>Exec"`
+	ComHandler []struct {
+		ClassID string `xml:"ClassId"`
+		Data    string `xml:"Data"`
+	} `xml:"Actions>ComHandler"`
+}
+
+// parseTaskDefinition 解析计划任务XML，多个操作时命令以换行分隔
+func parseTaskDefinition(content string, r *ScheduledTask) {
+	dec := xml.NewDecoder(bytes.NewReader([]byte(content)))
+	// 内容已经是 UTF-8，忽略XML声明中的 UTF-16 编码
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	var def taskDefinition
+	if err := dec.Decode(&def); err != nil {
+		return
+	}
+	r.Author = strings.TrimSpace(def.Author)
+	var commands, arguments []string
+	for _, x := range def.Exec {
+		commands = append(commands, strings.TrimSpace(x.Command))
+		arguments = append(arguments, strings.TrimSpace(x.Arguments))
+	}
+	for _, x := range def.ComHandler {
+		commands = append(commands, "COM "+strings.TrimSpace(x.ClassID))
+		arguments = append(arguments, strings.TrimSpace(x.Data))
+	}
+	r.Command = strings.Join(commands, "\n")
+	r.Arguments = strings.TrimSpace(strings.Join(arguments, "\n"))
+	for _, p := range def.Principals {
+		if p.UserID != "" {
+			r.RunAs = strings.TrimSpace(p.UserID)
+			break
+		}
+		if p.GroupID != "" {
+			r.RunAs = strings.TrimSpace(p.GroupID)
+		}
+	}
+}
+
+var scheduledTaskTable = &winEventTable[ScheduledTask, *ScheduledTask]{
+	name:  "win_scheduled_task",
+	title: "计划任务",
+	columns: []string{"action TEXT", "task_name TEXT", "command TEXT", "arguments TEXT", "run_as TEXT",
+		"author TEXT", "subject TEXT", "content TEXT"},
+	keyword: []string{"task_name", "command", "arguments", "run_as", "author", "subject"},
+	extract: func(e *EVTXEvent) (ScheduledTask, bool) {
+		if !isSecurityAudit(e, 4698, 4702) {
+			return ScheduledTask{}, false
+		}
+		r := ScheduledTask{Action: "创建", TaskName: winEventData(e, "TaskName"), Subject: winSubject(e)}
+		r.Content = winEventData(e, "TaskContent")
+		if e.EventID == 4702 {
+			r.Action, r.Content = "更新", winEventData(e, "TaskContentNew")
+		}
+		parseTaskDefinition(r.Content, &r)
+		return r, true
+	},
+	fields: func(r *ScheduledTask) []any {
+		return []any{&r.Action, &r.TaskName, &r.Command, &r.Arguments, &r.RunAs, &r.Author, &r.Subject, &r.Content}
+	},
+}
+
+// LogClear 日志被清除(Security 1102 与 System 104)
+type LogClear struct {
+	WinEvent
+	Log        string `json:"log"` // 被清除的日志
+	Subject    string `json:"subject"`
+	SubjectSID string `json:"subject_sid"`
+	BackupPath string `json:"backup_path"`
+}
+
+var logClearTable = &winEventTable[LogClear, *LogClear]{
+	name:    "win_log_clear",
+	title:   "日志清除",
+	columns: []string{"log TEXT", "subject TEXT", "subject_sid TEXT", "backup_path TEXT"},
+	keyword: []string{"log", "subject", "subject_sid", "backup_path"},
+	extract: func(e *EVTXEvent) (LogClear, bool) {
+		if (e.EventID != 1102 && e.EventID != 104) || !strings.EqualFold(e.Provider, "Microsoft-Windows-Eventlog") {
+			return LogClear{}, false
+		}
+		r := LogClear{
+			Log:        winEventData(e, "Channel"),
+			Subject:    winSubject(e),
+			SubjectSID: winEventData(e, "SubjectUserSid"),
+			BackupPath: winEventData(e, "BackupPath"),
+		}
+		// 1102 只出现在安全日志中，事件数据里没有日志名称
+		if r.Log == "" {
+			r.Log = e.Channel
+		}
+		if r.SubjectSID == "" {
+			r.SubjectSID = e.UserID
+		}
+		return r, true
+	},
+	fields: func(r *LogClear) []any {
+		return []any{&r.Log, &r.Subject, &r.SubjectSID, &r.BackupPath}
+	},
+}
+
+// ProcessCreation 进程创建(4688)
+type ProcessCreation struct {
+	WinEvent
+	ProcessID       int64  `json:"process_id"`
+	Image           string `json:"image"`
+	CommandLine     string `json:"command_line"` // 需要开启"在进程创建事件中加入命令行"策略
+	ParentProcessID int64  `json:"parent_process_id"`
+	ParentImage     string `json:"parent_image"`
+	Subject         string `json:"subject"`
+	TargetUser      string `json:"target_user"` // 以其他账户创建进程时的账户
+	Elevation       string `json:"elevation"`
+	Integrity       string `json:"integrity"`
+}
+
+var tokenElevationTypes = map[string]string{
+	"%%1936": "完整令牌",
+	"%%1937": "已提升",
+	"%%1938": "受限令牌",
+}
+
+var integrityLevels = map[string]string{
+	"s-1-16-0":     "不受信任",
+	"s-1-16-4096":  "低",
+	"s-1-16-8192":  "中",
+	"s-1-16-8448":  "中高",
+	"s-1-16-12288": "高",
+	"s-1-16-16384": "系统",
+	"s-1-16-20480": "受保护进程",
+}
+
+var processCreationTable = &winEventTable[ProcessCreation, *ProcessCreation]{
+	name:  "win_process_creation",
+	title: "进程创建",
+	columns: []string{"process_id INTEGER", "image TEXT", "command_line TEXT", "parent_process_id INTEGER",
+		"parent_image TEXT", "subject TEXT", "target_user TEXT", "elevation TEXT", "integrity TEXT"},
+	keyword: []string{"image", "command_line", "parent_image", "subject", "target_user"},
+	extract: func(e *EVTXEvent) (ProcessCreation, bool) {
+		if !isSecurityAudit(e, 4688) {
+			return ProcessCreation{}, false
+		}
+		return ProcessCreation{
+			ProcessID:       winHexInt(winEventData(e, "NewProcessId")),
+			Image:           winEventData(e, "NewProcessName"),
+			CommandLine:     winEventData(e, "CommandLine"),
+			ParentProcessID: winHexInt(winEventData(e, "ProcessId")),
+			ParentImage:     winEventData(e, "ParentProcessName"),
+			Subject:         winSubject(e),
+			TargetUser:      winAccount(winEventData(e, "TargetDomainName"), winEventData(e, "TargetUserName")),
+			Elevation:       winEnum(tokenElevationTypes, winEventData(e, "TokenElevationType")),
+			Integrity:       winEnum(integrityLevels, winEventData(e, "MandatoryLabel")),
+		}, true
+	},
+	fields: func(r *ProcessCreation) []any {
+		return []any{&r.ProcessID, &r.Image, &r.CommandLine, &r.ParentProcessID, &r.ParentImage,
+			&r.Subject, &r.TargetUser, &r.Elevation, &r.Integrity}
+	},
+}
+
+// SpecialPrivilege 新登录被分配特殊权限(4672)，通常意味着管理员登录
+type SpecialPrivilege struct {
+	WinEvent
+	Subject    string `json:"subject"`
+	SubjectSID string `json:"subject_sid"`
+	LogonID    string `json:"logon_id"`
+	Privileges string `json:"privileges"` // 逗号分隔
+}
+
+var specialPrivilegeTable = &winEventTable[SpecialPrivilege, *SpecialPrivilege]{
+	name:    "win_special_privilege",
+	title:   "特殊权限",
+	columns: []string{"subject TEXT", "subject_sid TEXT", "logon_id TEXT", "privileges TEXT"},
+	keyword: []string{"subject", "subject_sid", "logon_id", "privileges"},
+	extract: func(e *EVTXEvent) (SpecialPrivilege, bool) {
+		if !isSecurityAudit(e, 4672) {
+			return SpecialPrivilege{}, false
+		}
+		return SpecialPrivilege{
+			Subject:    winSubject(e),
+			SubjectSID: winEventData(e, "SubjectUserSid"),
+			LogonID:    winEventData(e, "SubjectLogonId"),
+			Privileges: strings.Join(strings.Fields(winEventData(e, "PrivilegeList")), ", "),
+		}, true
+	},
+	fields: func(r *SpecialPrivilege) []any {
+		return []any{&r.Subject, &r.SubjectSID, &r.LogonID, &r.Privileges}
+	},
+}
+
+// AccountChangePage 一页账户变更记录
+type AccountChangePage struct {
+	WinEventPageInfo
+	Records []AccountChange `json:"records"`
+}
+
+// ServiceInstallPage 一页服务安装记录
+type ServiceInstallPage struct {
+	WinEventPageInfo
+	Records []ServiceInstall `json:"records"`
+}
+
+// ScheduledTaskPage 一页计划任务记录
+type ScheduledTaskPage struct {
+	WinEventPageInfo
+	Records []ScheduledTask `json:"records"`
+}
+
+// LogClearPage 一页日志清除记录
+type LogClearPage struct {
+	WinEventPageInfo
+	Records []LogClear `json:"records"`
+}
+
+// ProcessCreationPage 一页进程创建记录
+type ProcessCreationPage struct {
+	WinEventPageInfo
+	Records []ProcessCreation `json:"records"`
+}
+
+// SpecialPrivilegePage 一页特殊权限记录
+type SpecialPrivilegePage struct {
+	WinEventPageInfo
+	Records []SpecialPrivilege `json:"records"`
+}
+
+// QueryAccountChanges 分页查询账户创建、启用、重置密码与加入安全组的记录
+func (a *App) QueryAccountChanges(q WinEventQuery) (AccountChangePage, error) {
+	records, info, err := accountChangeTable.query(a, q)
+	return AccountChangePage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QueryServiceInstalls 分页查询服务安装记录
+func (a *App) QueryServiceInstalls(q WinEventQuery) (ServiceInstallPage, error) {
+	records, info, err := serviceInstallTable.query(a, q)
+	return ServiceInstallPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QueryScheduledTasks 分页查询计划任务创建与更新记录
+func (a *App) QueryScheduledTasks(q WinEventQuery) (ScheduledTaskPage, error) {
+	records, info, err := scheduledTaskTable.query(a, q)
+	return ScheduledTaskPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QueryLogClears 分页查询日志清除记录
+func (a *App) QueryLogClears(q WinEventQuery) (LogClearPage, error) {
+	records, info, err := logClearTable.query(a, q)
+	return LogClearPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QueryProcessCreations 分页查询进程创建记录
+func (a *App) QueryProcessCreations(q WinEventQuery) (ProcessCreationPage, error) {
+	records, info, err := processCreationTable.query(a, q)
+	return ProcessCreationPage{WinEventPageInfo: info, Records: records}, err
+}
+
+// QuerySpecialPrivileges 分页查询特殊权限分配记录
+func (a *App) QuerySpecialPrivileges(q WinEventQuery) (SpecialPrivilegePage, error) {
+	records, info, err := specialPrivilegeTable.query(a, q)
+	return SpecialPrivilegePage{WinEventPageInfo: info, Records: records}, err
+}